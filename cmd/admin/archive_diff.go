@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	infraPostgres "hateblog/internal/infra/postgres"
+)
+
+// archiveDiffStore computes and applies drift between archive_counts and entries.
+type archiveDiffStore interface {
+	Diff(ctx context.Context) ([]infraPostgres.ArchiveCountDiff, error)
+	ApplyDiff(ctx context.Context, diffs []infraPostgres.ArchiveCountDiff) (int64, error)
+}
+
+type archiveDiffJSON struct {
+	Day       string `json:"day"`
+	Threshold int    `json:"threshold"`
+	Stored    int    `json:"stored"`
+	Actual    int    `json:"actual"`
+	Delta     int    `json:"delta"`
+}
+
+// printArchiveDiff writes one line per drifted (day, threshold) row.
+func printArchiveDiff(w io.Writer, diffs []infraPostgres.ArchiveCountDiff) {
+	for _, d := range diffs {
+		fmt.Fprintf(w, "%s threshold=%d stored=%d actual=%d delta=%+d\n",
+			d.Day.Format("2006-01-02"), d.Threshold, d.Stored, d.Actual, d.Actual-d.Stored)
+	}
+	fmt.Fprintf(w, "%d rows differ\n", len(diffs))
+}
+
+// writeArchiveDiffJSON writes diffs as a JSON array.
+func writeArchiveDiffJSON(w io.Writer, diffs []infraPostgres.ArchiveCountDiff) error {
+	out := make([]archiveDiffJSON, 0, len(diffs))
+	for _, d := range diffs {
+		out = append(out, archiveDiffJSON{
+			Day:       d.Day.Format("2006-01-02"),
+			Threshold: d.Threshold,
+			Stored:    d.Stored,
+			Actual:    d.Actual,
+			Delta:     d.Actual - d.Stored,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// writeArchiveDiffFile writes the JSON report to path; "-" means stdout.
+func writeArchiveDiffFile(path string, diffs []infraPostgres.ArchiveCountDiff) error {
+	if path == "-" {
+		return writeArchiveDiffJSON(os.Stdout, diffs)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	if err := writeArchiveDiffJSON(f, diffs); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// rebuildArchiveDiff updates only the drifted archive_counts rows and records the operation in
+// the audit log. The applied diffs are returned for reporting.
+func rebuildArchiveDiff(ctx context.Context, store archiveDiffStore, audit *auditor) ([]infraPostgres.ArchiveCountDiff, int64, error) {
+	var diffs []infraPostgres.ArchiveCountDiff
+	changed, err := audit.run(ctx, "archive.rebuild_diff", "archive_counts", func(ctx context.Context) (int64, error) {
+		var err error
+		diffs, err = store.Diff(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return store.ApplyDiff(ctx, diffs)
+	})
+	return diffs, changed, err
+}
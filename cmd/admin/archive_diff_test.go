@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	infraPostgres "hateblog/internal/infra/postgres"
+)
+
+type fakeArchiveDiffStore struct {
+	diffs   []infraPostgres.ArchiveCountDiff
+	applied []infraPostgres.ArchiveCountDiff
+}
+
+func (s *fakeArchiveDiffStore) Diff(ctx context.Context) ([]infraPostgres.ArchiveCountDiff, error) {
+	return s.diffs, nil
+}
+
+func (s *fakeArchiveDiffStore) ApplyDiff(ctx context.Context, diffs []infraPostgres.ArchiveCountDiff) (int64, error) {
+	s.applied = diffs
+	return int64(len(diffs)), nil
+}
+
+func sampleArchiveDiffs() []infraPostgres.ArchiveCountDiff {
+	return []infraPostgres.ArchiveCountDiff{
+		{Day: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), Threshold: 5, Stored: 3, Actual: 4},
+		{Day: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), Threshold: 50, Stored: 2, Actual: 0},
+	}
+}
+
+func TestPrintArchiveDiff(t *testing.T) {
+	var buf bytes.Buffer
+	printArchiveDiff(&buf, sampleArchiveDiffs())
+	require.Equal(t,
+		"2025-01-05 threshold=5 stored=3 actual=4 delta=+1\n"+
+			"2025-01-06 threshold=50 stored=2 actual=0 delta=-2\n"+
+			"2 rows differ\n",
+		buf.String())
+}
+
+func TestWriteArchiveDiffJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeArchiveDiffJSON(&buf, sampleArchiveDiffs()))
+
+	var got []archiveDiffJSON
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Equal(t, []archiveDiffJSON{
+		{Day: "2025-01-05", Threshold: 5, Stored: 3, Actual: 4, Delta: 1},
+		{Day: "2025-01-06", Threshold: 50, Stored: 2, Actual: 0, Delta: -2},
+	}, got)
+
+	buf.Reset()
+	require.NoError(t, writeArchiveDiffJSON(&buf, nil))
+	require.Equal(t, "[]\n", buf.String())
+}
+
+func TestRebuildArchiveDiffAppliesOnlyDrift(t *testing.T) {
+	var buf bytes.Buffer
+	audits := &fakeAuditStore{}
+	store := &fakeArchiveDiffStore{diffs: sampleArchiveDiffs()}
+
+	diffs, changed, err := rebuildArchiveDiff(context.Background(), store, newTestAuditor(&buf, audits))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), changed)
+	require.Equal(t, sampleArchiveDiffs(), diffs)
+	require.Equal(t, sampleArchiveDiffs(), store.applied)
+
+	require.Len(t, audits.records, 1)
+	require.Equal(t, "archive.rebuild_diff", audits.records[0].Operation)
+	require.Equal(t, int64(2), audits.records[0].Affected)
+}
+
+func TestRunArchiveRebuildFlagValidation(t *testing.T) {
+	ctx := context.Background()
+	require.EqualError(t, runArchiveRebuild(ctx, []string{"--diff", "--only-diff"}), "--diff and --only-diff are mutually exclusive")
+	require.EqualError(t, runArchiveRebuild(ctx, []string{"--json", "out.json", "--yes"}), "--json requires --diff or --only-diff")
+	require.EqualError(t, runArchiveRebuild(ctx, []string{"--only-diff"}), "--yes is required")
+}
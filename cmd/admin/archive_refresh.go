@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	infraPostgres "hateblog/internal/infra/postgres"
+	"hateblog/internal/pkg/apptime"
+	"hateblog/internal/platform/telemetry"
+)
+
+// archiveDayRefresher recomputes archive_counts for individual days.
+type archiveDayRefresher interface {
+	RefreshDays(ctx context.Context, days []time.Time) (int64, error)
+}
+
+func runArchiveRefreshRecent(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("archive refresh-recent", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	days := fs.Int("days", 3, "number of days to refresh, counting back from today")
+	yes := fs.Bool("yes", false, "required confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*yes {
+		return fmt.Errorf("--yes is required")
+	}
+	if *days <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	cfg, log, db, closeAll, sentryEnabled, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+	if sentryEnabled {
+		defer telemetry.Recover()
+	}
+
+	// connectDatabase has applied APP_TIMEZONE, so days follow the configured zone.
+	window := recentDays(apptime.Now(), *days)
+	audit := newAuditor(log, auditStoreFor(cfg, db.Pool))
+	rows, err := refreshRecentArchive(ctx, infraPostgres.NewArchiveCountRepository(db.Pool), audit, window)
+	if err != nil {
+		return fmt.Errorf("refresh recent archive counts: %w", err)
+	}
+	log.Info("archive refresh completed", "days", *days, "from", window[0].Format("2006-01-02"), "rows", rows)
+	return nil
+}
+
+// recentDays returns the n days ending today, oldest first.
+func recentDays(now time.Time, n int) []time.Time {
+	today := apptime.TruncateToDay(now)
+	days := make([]time.Time, 0, n)
+	for i := n - 1; i >= 0; i-- {
+		days = append(days, today.AddDate(0, 0, -i))
+	}
+	return days
+}
+
+// refreshRecentArchive recomputes archive_counts for days and records the operation in the audit log.
+func refreshRecentArchive(ctx context.Context, store archiveDayRefresher, audit *auditor, days []time.Time) (int64, error) {
+	return audit.run(ctx, "archive.refresh_recent", "archive_counts", func(ctx context.Context) (int64, error) {
+		return store.RefreshDays(ctx, days)
+	})
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeArchiveDayRefresher struct {
+	days []time.Time
+}
+
+func (s *fakeArchiveDayRefresher) RefreshDays(ctx context.Context, days []time.Time) (int64, error) {
+	s.days = days
+	return int64(len(days)) * 6, nil
+}
+
+func TestRecentDays(t *testing.T) {
+	prev := time.Local
+	time.Local = time.FixedZone("JST", 9*60*60)
+	t.Cleanup(func() { time.Local = prev })
+
+	// 2025-01-06 23:30 UTC is already 2025-01-07 in JST.
+	now := time.Date(2025, 1, 6, 23, 30, 0, 0, time.UTC)
+	got := recentDays(now, 3)
+	require.Len(t, got, 3)
+	for i, want := range []string{"2025-01-05", "2025-01-06", "2025-01-07"} {
+		require.Equal(t, want, got[i].Format("2006-01-02"))
+		require.Zero(t, got[i].Hour())
+	}
+}
+
+func TestRefreshRecentArchive(t *testing.T) {
+	var buf bytes.Buffer
+	audits := &fakeAuditStore{}
+	store := &fakeArchiveDayRefresher{}
+	days := recentDays(time.Date(2025, 1, 7, 12, 0, 0, 0, time.Local), 3)
+
+	rows, err := refreshRecentArchive(context.Background(), store, newTestAuditor(&buf, audits), days)
+	require.NoError(t, err)
+	require.Equal(t, int64(18), rows)
+	require.Equal(t, days, store.days)
+
+	require.Len(t, audits.records, 1)
+	require.Equal(t, "archive.refresh_recent", audits.records[0].Operation)
+	require.Equal(t, int64(18), audits.records[0].Affected)
+}
+
+func TestRunArchiveRefreshRecentFlagValidation(t *testing.T) {
+	ctx := context.Background()
+	require.EqualError(t, runArchiveRefreshRecent(ctx, []string{"--days", "3"}), "--yes is required")
+	require.EqualError(t, runArchiveRefreshRecent(ctx, []string{"--days", "0", "--yes"}), "--days must be positive")
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hateblog/internal/platform/config"
+	"hateblog/internal/platform/database"
+)
+
+// auditRecord describes a single admin operation for post-incident review.
+type auditRecord struct {
+	Operation string
+	Target    string
+	Affected  int64
+	Actor     string
+	Host      string
+	StartedAt time.Time
+	Duration  time.Duration
+	Err       error
+}
+
+// auditStore persists audit records in addition to the log output.
+type auditStore interface {
+	InsertAuditLog(ctx context.Context, rec auditRecord) error
+}
+
+// auditor emits audit records for admin operations.
+// The CLI has no authenticated principal, so actor and host come from the environment.
+type auditor struct {
+	log   *slog.Logger
+	store auditStore
+	actor string
+	host  string
+	now   func() time.Time
+}
+
+func newAuditor(log *slog.Logger, store auditStore) *auditor {
+	if log == nil {
+		log = slog.Default()
+	}
+	host, _ := os.Hostname()
+	return &auditor{
+		log:   log,
+		store: store,
+		actor: auditActorFromEnv(),
+		host:  host,
+		now:   time.Now,
+	}
+}
+
+func auditActorFromEnv() string {
+	for _, key := range []string{"SUDO_USER", "USER", "LOGNAME", "USERNAME"} {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return "unknown"
+}
+
+// run executes fn and records the outcome, including failures.
+// fn returns the number of affected rows or keys.
+func (a *auditor) run(ctx context.Context, operation, target string, fn func(ctx context.Context) (int64, error)) (int64, error) {
+	started := a.now()
+	affected, err := fn(ctx)
+	rec := auditRecord{
+		Operation: operation,
+		Target:    target,
+		Affected:  affected,
+		Actor:     a.actor,
+		Host:      a.host,
+		StartedAt: started,
+		Duration:  a.now().Sub(started),
+		Err:       err,
+	}
+	a.emit(ctx, rec)
+	return affected, err
+}
+
+func (a *auditor) emit(ctx context.Context, rec auditRecord) {
+	attrs := []any{
+		"operation", rec.Operation,
+		"target", rec.Target,
+		"affected", rec.Affected,
+		"actor", rec.Actor,
+		"host", rec.Host,
+		"started_at", rec.StartedAt,
+		"duration_ms", rec.Duration.Milliseconds(),
+	}
+	level := slog.LevelInfo
+	if rec.Err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, "error", rec.Err.Error())
+	}
+	a.log.Log(ctx, level, "admin audit", attrs...)
+
+	if a.store == nil {
+		return
+	}
+	// A failed audit write must not mask the outcome of the operation itself.
+	if err := a.store.InsertAuditLog(context.WithoutCancel(ctx), rec); err != nil {
+		a.log.Warn("audit log write failed", "operation", rec.Operation, "error", err)
+	}
+}
+
+// pgAuditStore writes audit records to the audit_log table.
+type pgAuditStore struct {
+	pool *pgxpool.Pool
+}
+
+func (s *pgAuditStore) InsertAuditLog(ctx context.Context, rec auditRecord) error {
+	var errText *string
+	if rec.Err != nil {
+		msg := rec.Err.Error()
+		errText = &msg
+	}
+	const query = `
+INSERT INTO audit_log (operation, target, affected, actor, host, error, started_at, duration_ms)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	if _, err := s.pool.Exec(ctx, query,
+		rec.Operation,
+		rec.Target,
+		rec.Affected,
+		rec.Actor,
+		rec.Host,
+		errText,
+		rec.StartedAt,
+		rec.Duration.Milliseconds(),
+	); err != nil {
+		return fmt.Errorf("insert audit log: %w", err)
+	}
+	return nil
+}
+
+// openAuditStore connects to the database when APP_AUDIT_LOG_DB is enabled.
+// It returns a nil store (log-only auditing) otherwise.
+func openAuditStore(ctx context.Context, cfg *config.Config, log *slog.Logger) (auditStore, func(), error) {
+	if cfg == nil || !cfg.App.AuditLogDB {
+		return nil, func() {}, nil
+	}
+	db, err := database.New(ctx, database.Config{
+		ConnectionString: cfg.Database.ConnectionString(),
+		MaxConns:         2,
+		MinConns:         0,
+		MaxConnLifetime:  cfg.Database.MaxConnLifetime,
+		MaxConnIdleTime:  cfg.Database.MaxConnIdleTime,
+		ConnectTimeout:   cfg.Database.ConnectTimeout,
+		TimeZone:         cfg.App.TimeZone,
+	}, log)
+	if err != nil {
+		return nil, func() {}, fmt.Errorf("connect audit database: %w", err)
+	}
+	return &pgAuditStore{pool: db.Pool}, db.Close, nil
+}
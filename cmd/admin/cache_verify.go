@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+
+	infraPostgres "hateblog/internal/infra/postgres"
+	infraRedis "hateblog/internal/infra/redis"
+	"hateblog/internal/platform/database"
+	"hateblog/internal/platform/telemetry"
+	usecaseEntry "hateblog/internal/usecase/entry"
+)
+
+// errStaleCache makes cache verify exit non-zero when a cached day differs from the database.
+var errStaleCache = errors.New("stale cache detected")
+
+// dayCacheVerifier compares a cached day with the database.
+type dayCacheVerifier interface {
+	VerifyDayCache(ctx context.Context, date string) (usecaseEntry.DayCacheReport, error)
+}
+
+func runCacheVerify(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("cache verify", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	dates := fs.String("date", "", "comma-separated YYYYMMDD list (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	targets := splitCSV(*dates)
+	if len(targets) == 0 {
+		return fmt.Errorf("--date is required")
+	}
+
+	cfg, log, redisClient, closeAll, sentryEnabled, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+	if sentryEnabled {
+		defer telemetry.Recover()
+	}
+
+	if !cfg.App.CacheEnabled {
+		return fmt.Errorf("cache is disabled (APP_CACHE_ENABLED=false)")
+	}
+
+	db, err := database.New(ctx, database.Config{
+		ConnectionString: cfg.Database.ConnectionString(),
+		MaxConns:         cfg.Database.MaxConns,
+		MinConns:         cfg.Database.MinConns,
+		MaxConnLifetime:  cfg.Database.MaxConnLifetime,
+		MaxConnIdleTime:  cfg.Database.MaxConnIdleTime,
+		ConnectTimeout:   cfg.Database.ConnectTimeout,
+		TimeZone:         cfg.App.TimeZone,
+	}, log)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	// The app caches days read through this repository, so apply the same host exclusions.
+	entryRepo := infraPostgres.NewEntryRepository(db.Pool).WithExcludedHosts(cfg.App.ExcludedDomains)
+	dayEntriesCache := infraRedis.NewDayEntriesCache(redisClient, cfg.Cache.EntriesDayTTL)
+	entryService := usecaseEntry.NewService(entryRepo, dayEntriesCache, nil, log)
+
+	return verifyDayCaches(ctx, entryService, targets, log)
+}
+
+// verifyDayCaches checks each date and logs the result. It returns errStaleCache when any
+// cached day differs from the database.
+func verifyDayCaches(ctx context.Context, verifier dayCacheVerifier, dates []string, log *slog.Logger) error {
+	stale := 0
+	for _, date := range dates {
+		report, err := verifier.VerifyDayCache(ctx, date)
+		if err != nil {
+			return fmt.Errorf("verify day cache %s: %w", date, err)
+		}
+		switch {
+		case !report.Cached:
+			log.Info("day not cached", "date", date, "db", report.DBCount)
+		case report.Stale():
+			stale++
+			log.Warn("stale day cache",
+				"date", date,
+				"cached", report.CachedCount,
+				"db", report.DBCount,
+				"missing", len(report.Missing),
+				"extra", len(report.Extra),
+				"missing_ids", report.Missing,
+				"extra_ids", report.Extra,
+			)
+		default:
+			log.Info("day cache consistent", "date", date, "entries", report.CachedCount)
+		}
+	}
+	if stale > 0 {
+		return fmt.Errorf("%w: %d of %d days", errStaleCache, stale, len(dates))
+	}
+	return nil
+}
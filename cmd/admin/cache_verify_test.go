@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	domainEntry "hateblog/internal/domain/entry"
+	usecaseEntry "hateblog/internal/usecase/entry"
+)
+
+type fakeDayCacheVerifier map[string]usecaseEntry.DayCacheReport
+
+func (v fakeDayCacheVerifier) VerifyDayCache(ctx context.Context, date string) (usecaseEntry.DayCacheReport, error) {
+	return v[date], nil
+}
+
+func TestVerifyDayCaches(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	verifier := fakeDayCacheVerifier{
+		"20250105": {Date: "20250105", Cached: true, CachedCount: 2, DBCount: 2},
+		"20250106": {Date: "20250106", DBCount: 3},
+		"20250107": {Date: "20250107", Cached: true, CachedCount: 1, DBCount: 2, Missing: []domainEntry.ID{uuid.New()}},
+	}
+
+	require.NoError(t, verifyDayCaches(context.Background(), verifier, []string{"20250105", "20250106"}, log))
+
+	err := verifyDayCaches(context.Background(), verifier, []string{"20250105", "20250107"}, log)
+	require.ErrorIs(t, err, errStaleCache)
+	require.Contains(t, err.Error(), "1 of 2 days")
+}
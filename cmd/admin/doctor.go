@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	infraPostgres "hateblog/internal/infra/postgres"
+	"hateblog/internal/platform/cache"
+	"hateblog/internal/platform/config"
+	"hateblog/internal/platform/database"
+	"hateblog/internal/platform/logger"
+)
+
+// doctorTables are the tables the application expects; schema_migrations is golang-migrate's.
+var doctorTables = []string{
+	"schema_migrations",
+	"entries",
+	"tags",
+	"entry_tags",
+	"click_metrics",
+	"tag_view_history",
+	"search_history",
+	"archive_counts",
+	"audit_log",
+}
+
+// doctorCheck is one pre-flight check of admin doctor.
+type doctorCheck struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// schemaInspector reads the migration state of the database.
+type schemaInspector interface {
+	Version(ctx context.Context) (int64, bool, error)
+	MissingTables(ctx context.Context, tables []string) ([]string, error)
+}
+
+func runDoctor(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	migrationsDir := fs.String("migrations-dir", "migrations", "directory of the migration files; the newest one is the expected schema version")
+	timeout := fs.Duration("timeout", 10*time.Second, "time limit for each check")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+
+	out := os.Stdout
+	cfg, err := config.Load()
+	if err != nil {
+		printDoctorResult(out, "config", err)
+		return fmt.Errorf("doctor: config is invalid")
+	}
+	log := logger.New(logger.Config{
+		Level:  logger.Level(cfg.App.LogLevel),
+		Format: logger.Format(cfg.App.LogFormat),
+	})
+
+	var db *database.DB
+	defer func() {
+		if db != nil {
+			db.Close()
+		}
+	}()
+	expected, expectedErr := latestMigrationVersion(*migrationsDir)
+
+	checks := []doctorCheck{
+		{Name: "config", Run: func(context.Context) error { return nil }},
+		{Name: "external config", Run: func(context.Context) error { return checkExternalConfig(cfg) }},
+		{Name: "postgres", Run: func(ctx context.Context) error {
+			var err error
+			db, err = database.New(ctx, database.Config{
+				ConnectionString: cfg.Database.ConnectionString(),
+				MaxConns:         2,
+				MinConns:         0,
+				ConnectTimeout:   cfg.Database.ConnectTimeout,
+				TimeZone:         cfg.App.TimeZone,
+			}, log)
+			return err
+		}},
+		{Name: "schema", Run: func(ctx context.Context) error {
+			if db == nil {
+				return errors.New("skipped: postgres is unavailable")
+			}
+			if expectedErr != nil {
+				// Without the migration files only the recorded state can be checked.
+				log.Warn("expected schema version unknown", "error", expectedErr)
+				expected = 0
+			}
+			return checkSchema(ctx, infraPostgres.NewSchemaInspector(db.Pool), expected, doctorTables)
+		}},
+		{Name: "redis", Run: func(ctx context.Context) error {
+			client, err := cache.New(cache.Config{
+				Address:      cfg.Redis.Address(),
+				Password:     cfg.Redis.Password,
+				DB:           cfg.Redis.DB,
+				MaxRetries:   cfg.Redis.MaxRetries,
+				DialTimeout:  cfg.Redis.DialTimeout,
+				ReadTimeout:  cfg.Redis.ReadTimeout,
+				WriteTimeout: cfg.Redis.WriteTimeout,
+			}, log)
+			if err != nil {
+				return err
+			}
+			return client.Close()
+		}},
+	}
+	if failed := runDoctorChecks(ctx, out, checks, *timeout); failed > 0 {
+		return fmt.Errorf("doctor: %d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// runDoctorChecks runs the checks in order, each under timeout, prints a PASS or FAIL line per
+// check and returns the number of failures.
+func runDoctorChecks(ctx context.Context, w io.Writer, checks []doctorCheck, timeout time.Duration) int {
+	failed := 0
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := check.Run(checkCtx)
+		cancel()
+		if err != nil {
+			failed++
+		}
+		printDoctorResult(w, check.Name, err)
+	}
+	if failed == 0 {
+		fmt.Fprintf(w, "all %d checks passed\n", len(checks))
+	} else {
+		fmt.Fprintf(w, "%d of %d checks failed\n", failed, len(checks))
+	}
+	return failed
+}
+
+func printDoctorResult(w io.Writer, name string, err error) {
+	if err != nil {
+		fmt.Fprintf(w, "FAIL  %-16s %v\n", name, err)
+		return
+	}
+	fmt.Fprintf(w, "PASS  %s\n", name)
+}
+
+// checkExternalConfig verifies the settings the batch jobs need that config.Validate leaves
+// optional: the fetcher feed URLs and a key for the tagging provider.
+func checkExternalConfig(cfg *config.Config) error {
+	var problems []string
+	if len(cfg.External.HatenaRSSFeedURLs) == 0 {
+		problems = append(problems, "HATENA_RSS_FEED_URLS is empty")
+	}
+	for _, raw := range cfg.External.HatenaRSSFeedURLs {
+		u, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("invalid feed url %q", raw))
+		}
+	}
+	provider := strings.ToLower(strings.TrimSpace(cfg.External.TagExtractor))
+	if (provider == "" || provider == "yahoo") && strings.TrimSpace(cfg.External.YahooAPIKey) == "" && !cfg.External.TagExtractorFallback {
+		// keyphrase.New silently disables tagging in this case.
+		problems = append(problems, "TAG_EXTRACTOR=yahoo needs YAHOO_APP_ID (or TAG_EXTRACTOR_FALLBACK=true, or TAG_EXTRACTOR=none)")
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkSchema verifies that the tables exist and that the migrations are clean and, when
+// expected is positive, at that version.
+func checkSchema(ctx context.Context, inspector schemaInspector, expected int64, tables []string) error {
+	missing, err := inspector.MissingTables(ctx, tables)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing tables: %s (run migrate up)", strings.Join(missing, ", "))
+	}
+	version, dirty, err := inspector.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("schema version %d is dirty (see migrate force)", version)
+	}
+	if expected > 0 && version != expected {
+		return fmt.Errorf("schema version is %d, want %d (run migrate up)", version, expected)
+	}
+	return nil
+}
+
+// latestMigrationVersion returns the highest version among the NNNNNN_name.up.sql files in dir.
+func latestMigrationVersion(dir string) (int64, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for _, file := range files {
+		prefix, _, ok := strings.Cut(filepath.Base(file), "_")
+		if !ok {
+			continue
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		latest = max(latest, version)
+	}
+	if latest == 0 {
+		return 0, fmt.Errorf("no migrations found in %s", dir)
+	}
+	return latest, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"hateblog/internal/platform/config"
+)
+
+type fakeSchemaInspector struct {
+	version int64
+	dirty   bool
+	missing []string
+	err     error
+}
+
+func (s *fakeSchemaInspector) Version(ctx context.Context) (int64, bool, error) {
+	return s.version, s.dirty, s.err
+}
+
+func (s *fakeSchemaInspector) MissingTables(ctx context.Context, tables []string) ([]string, error) {
+	return s.missing, nil
+}
+
+func TestRunDoctorChecks(t *testing.T) {
+	var out bytes.Buffer
+	var ran []string
+	check := func(name string, err error) doctorCheck {
+		return doctorCheck{Name: name, Run: func(ctx context.Context) error {
+			_, hasDeadline := ctx.Deadline()
+			require.True(t, hasDeadline)
+			ran = append(ran, name)
+			return err
+		}}
+	}
+
+	failed := runDoctorChecks(context.Background(), &out, []doctorCheck{
+		check("postgres", nil),
+		check("redis", errors.New("connection refused")),
+		check("schema", nil),
+	}, time.Second)
+	require.Equal(t, 1, failed)
+	// A failure does not stop the remaining checks.
+	require.Equal(t, []string{"postgres", "redis", "schema"}, ran)
+	require.Contains(t, out.String(), "PASS  postgres\n")
+	require.Contains(t, out.String(), "FAIL  redis")
+	require.Contains(t, out.String(), "connection refused")
+	require.Contains(t, out.String(), "1 of 3 checks failed")
+
+	out.Reset()
+	require.Zero(t, runDoctorChecks(context.Background(), &out, []doctorCheck{check("config", nil)}, time.Second))
+	require.Contains(t, out.String(), "all 1 checks passed")
+}
+
+func TestCheckExternalConfig(t *testing.T) {
+	valid := func() *config.Config {
+		cfg := &config.Config{}
+		cfg.External.TagExtractor = "yahoo"
+		cfg.External.YahooAPIKey = "app-id"
+		cfg.External.HatenaRSSFeedURLs = []string{"https://b.hatena.ne.jp/entrylist?mode=rss"}
+		return cfg
+	}
+	require.NoError(t, checkExternalConfig(valid()))
+
+	tests := []struct {
+		name   string
+		modify func(*config.Config)
+		want   string
+	}{
+		{name: "no feeds", modify: func(c *config.Config) { c.External.HatenaRSSFeedURLs = nil }, want: "HATENA_RSS_FEED_URLS is empty"},
+		{name: "bad feed", modify: func(c *config.Config) { c.External.HatenaRSSFeedURLs = []string{"ftp://example.com/rss"} }, want: "invalid feed url"},
+		{name: "yahoo without key", modify: func(c *config.Config) { c.External.YahooAPIKey = "" }, want: "needs YAHOO_APP_ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(cfg)
+			err := checkExternalConfig(cfg)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	// Tagging without a Yahoo key is fine when it does not use Yahoo.
+	for _, modify := range []func(*config.Config){
+		func(c *config.Config) { c.External.YahooAPIKey, c.External.TagExtractorFallback = "", true },
+		func(c *config.Config) { c.External.YahooAPIKey, c.External.TagExtractor = "", "local" },
+		func(c *config.Config) { c.External.YahooAPIKey, c.External.TagExtractor = "", "none" },
+	} {
+		cfg := valid()
+		modify(cfg)
+		require.NoError(t, checkExternalConfig(cfg))
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, checkSchema(ctx, &fakeSchemaInspector{version: 18}, 18, doctorTables))
+	// The version is not compared when the expected one is unknown.
+	require.NoError(t, checkSchema(ctx, &fakeSchemaInspector{version: 17}, 0, doctorTables))
+
+	tests := []struct {
+		name      string
+		inspector *fakeSchemaInspector
+		want      string
+	}{
+		{name: "missing tables", inspector: &fakeSchemaInspector{version: 18, missing: []string{"audit_log"}}, want: "missing tables: audit_log"},
+		{name: "dirty", inspector: &fakeSchemaInspector{version: 18, dirty: true}, want: "dirty"},
+		{name: "behind", inspector: &fakeSchemaInspector{version: 17}, want: "schema version is 17, want 18"},
+		{name: "no version", inspector: &fakeSchemaInspector{err: errors.New("no schema version recorded")}, want: "no schema version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSchema(ctx, tt.inspector, 18, doctorTables)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestLatestMigrationVersion(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"000001_a.up.sql", "000001_a.down.sql", "000012_b.up.sql", "000020_c.down.sql", "README.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+	version, err := latestMigrationVersion(dir)
+	require.NoError(t, err)
+	require.Equal(t, int64(12), version)
+
+	_, err = latestMigrationVersion(t.TempDir())
+	require.Error(t, err)
+
+	// The repository's own migrations resolve too.
+	version, err = latestMigrationVersion(filepath.Join("..", "..", "migrations"))
+	require.NoError(t, err)
+	require.Positive(t, version)
+}
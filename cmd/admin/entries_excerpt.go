@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	domainEntry "hateblog/internal/domain/entry"
+	"hateblog/internal/infra/external/pagemeta"
+	infraPostgres "hateblog/internal/infra/postgres"
+	"hateblog/internal/platform/telemetry"
+)
+
+// excerptStore lists entries without an excerpt and fills it in.
+type excerptStore interface {
+	CountMissingExcerpt(ctx context.Context) (int64, error)
+	ListMissingExcerpt(ctx context.Context, after uuid.UUID, limit int) ([]*domainEntry.Entry, error)
+	FillExcerpt(ctx context.Context, e *domainEntry.Entry, excerpt string) (bool, error)
+}
+
+// descriptionFetcher reads the description of the page at a URL.
+type descriptionFetcher interface {
+	Description(ctx context.Context, rawURL string) (string, error)
+}
+
+// excerptBackfillOptions controls an entries backfill-excerpts run.
+type excerptBackfillOptions struct {
+	BatchSize int
+	// Limit stops the run after this many entries (0 = all).
+	Limit int
+	// Interval is the pause between page fetches.
+	Interval time.Duration
+	DryRun   bool
+}
+
+// excerptBackfillResult tallies an entries backfill-excerpts run.
+type excerptBackfillResult struct {
+	Scanned int64
+	Filled  int64
+	// NoDescription counts pages without a description meta tag.
+	NoDescription int64
+	Failed        int64
+}
+
+func runEntriesBackfillExcerpts(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("entries backfill-excerpts", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	limit := fs.Int("limit", 0, "maximum number of entries to process (0 = all)")
+	batchSize := fs.Int("batch-size", 100, "number of entries read per query")
+	interval := fs.Duration("interval", 500*time.Millisecond, "pause between page fetches")
+	timeout := fs.Duration("timeout", 10*time.Second, "time limit for each page fetch")
+	dryRun := fs.Bool("dry-run", false, "fetch descriptions and log them without writing")
+	yes := fs.Bool("yes", false, "required confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*yes && !*dryRun {
+		return fmt.Errorf("--yes is required")
+	}
+	if *limit < 0 {
+		return fmt.Errorf("--limit must be >= 0")
+	}
+	if *batchSize <= 0 {
+		return fmt.Errorf("--batch-size must be positive")
+	}
+	if *interval < 0 {
+		return fmt.Errorf("--interval must be >= 0")
+	}
+
+	cfg, log, db, closeAll, sentryEnabled, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+	if sentryEnabled {
+		defer telemetry.Recover()
+	}
+
+	store := infraPostgres.NewEntryRepository(db.Pool)
+	fetcher := pagemeta.NewFetcher(pagemeta.Config{Timeout: *timeout})
+	opts := excerptBackfillOptions{BatchSize: *batchSize, Limit: *limit, Interval: *interval, DryRun: *dryRun}
+	if *dryRun {
+		res, err := backfillExcerpts(ctx, store, fetcher, log, opts)
+		if err != nil {
+			return fmt.Errorf("backfill excerpts: %w", err)
+		}
+		log.Info("entries backfill-excerpts dry run", "scanned", res.Scanned, "found", res.Filled, "no_description", res.NoDescription, "failed", res.Failed)
+		return nil
+	}
+
+	audit := newAuditor(log, auditStoreFor(cfg, db.Pool))
+	var res excerptBackfillResult
+	_, err = audit.run(ctx, "entries.backfill_excerpts", fmt.Sprintf("limit=%d", *limit), func(ctx context.Context) (int64, error) {
+		var err error
+		res, err = backfillExcerpts(ctx, store, fetcher, log, opts)
+		return res.Filled, err
+	})
+	if err != nil {
+		return fmt.Errorf("backfill excerpts: %w", err)
+	}
+	log.Info("entries backfill-excerpts completed", "scanned", res.Scanned, "filled", res.Filled, "no_description", res.NoDescription, "failed", res.Failed)
+	return nil
+}
+
+// backfillExcerpts walks the entries without an excerpt once, in ID order, and stores the
+// description of each page as its excerpt. Pages that fail to load or have no description are
+// counted and skipped. With DryRun, Filled counts the descriptions found.
+func backfillExcerpts(ctx context.Context, store excerptStore, fetcher descriptionFetcher, log *slog.Logger, opts excerptBackfillOptions) (excerptBackfillResult, error) {
+	total, err := store.CountMissingExcerpt(ctx)
+	if err != nil {
+		return excerptBackfillResult{}, err
+	}
+	var (
+		res   excerptBackfillResult
+		after uuid.UUID
+	)
+	for {
+		batchSize := opts.BatchSize
+		if opts.Limit > 0 {
+			batchSize = min(batchSize, opts.Limit-int(res.Scanned))
+			if batchSize <= 0 {
+				return res, nil
+			}
+		}
+		entries, err := store.ListMissingExcerpt(ctx, after, batchSize)
+		if err != nil {
+			return res, err
+		}
+		for _, e := range entries {
+			if res.Scanned > 0 && opts.Interval > 0 {
+				if err := sleepContext(ctx, opts.Interval); err != nil {
+					return res, err
+				}
+			}
+			res.Scanned++
+			after = e.ID
+			desc, err := fetcher.Description(ctx, e.URL)
+			if err != nil {
+				if ctx.Err() != nil {
+					return res, ctx.Err()
+				}
+				res.Failed++
+				log.Warn("fetch description failed", "id", e.ID, "url", e.URL, "err", err)
+				continue
+			}
+			if desc == "" {
+				res.NoDescription++
+				continue
+			}
+			if opts.DryRun {
+				res.Filled++
+				log.Info("description found", "id", e.ID, "url", e.URL, "excerpt", desc)
+				continue
+			}
+			ok, err := store.FillExcerpt(ctx, e, desc)
+			if err != nil {
+				return res, err
+			}
+			if ok {
+				res.Filled++
+			}
+		}
+		if len(entries) > 0 {
+			log.Info("entries backfill-excerpts progress", "scanned", res.Scanned, "total", total, "filled", res.Filled)
+		}
+		if len(entries) < batchSize {
+			return res, nil
+		}
+	}
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	domainEntry "hateblog/internal/domain/entry"
+)
+
+type fakeExcerptStore struct {
+	entries []*domainEntry.Entry
+	filled  map[string]string
+}
+
+func (s *fakeExcerptStore) CountMissingExcerpt(ctx context.Context) (int64, error) {
+	var n int64
+	for _, e := range s.entries {
+		if e.Excerpt == "" {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *fakeExcerptStore) ListMissingExcerpt(ctx context.Context, after uuid.UUID, limit int) ([]*domainEntry.Entry, error) {
+	var out []*domainEntry.Entry
+	for _, e := range s.entries {
+		if e.Excerpt == "" && bytes.Compare(e.ID[:], after[:]) > 0 && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeExcerptStore) FillExcerpt(ctx context.Context, e *domainEntry.Entry, excerpt string) (bool, error) {
+	e.Excerpt = excerpt
+	s.filled[e.URL] = excerpt
+	return true, nil
+}
+
+type fakeDescriptionFetcher map[string]string
+
+func (f fakeDescriptionFetcher) Description(ctx context.Context, rawURL string) (string, error) {
+	desc, ok := f[rawURL]
+	if !ok {
+		return "", errors.New("404 not found")
+	}
+	return desc, nil
+}
+
+func newExcerptStore(n int) *fakeExcerptStore {
+	store := &fakeExcerptStore{filled: map[string]string{}}
+	for i := 1; i <= n; i++ {
+		store.entries = append(store.entries, &domainEntry.Entry{
+			ID:  uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", i)),
+			URL: fmt.Sprintf("https://example.com/%d", i),
+		})
+	}
+	return store
+}
+
+func TestBackfillExcerpts(t *testing.T) {
+	store := newExcerptStore(5)
+	fetcher := fakeDescriptionFetcher{
+		"https://example.com/1": "first page",
+		"https://example.com/2": "",
+		"https://example.com/4": "fourth page",
+		"https://example.com/5": "fifth page",
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	res, err := backfillExcerpts(context.Background(), store, fetcher, log, excerptBackfillOptions{BatchSize: 2})
+	require.NoError(t, err)
+	require.Equal(t, excerptBackfillResult{Scanned: 5, Filled: 3, NoDescription: 1, Failed: 1}, res)
+	require.Equal(t, map[string]string{
+		"https://example.com/1": "first page",
+		"https://example.com/4": "fourth page",
+		"https://example.com/5": "fifth page",
+	}, store.filled)
+}
+
+func TestBackfillExcerptsLimitAndDryRun(t *testing.T) {
+	store := newExcerptStore(5)
+	fetcher := fakeDescriptionFetcher{
+		"https://example.com/1": "first page",
+		"https://example.com/2": "second page",
+		"https://example.com/3": "third page",
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	res, err := backfillExcerpts(context.Background(), store, fetcher, log, excerptBackfillOptions{BatchSize: 2, Limit: 3, DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, excerptBackfillResult{Scanned: 3, Filled: 3}, res)
+	require.Empty(t, store.filled)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	infraPostgres "hateblog/internal/infra/postgres"
+	"hateblog/internal/pkg/apptime"
+	"hateblog/internal/platform/telemetry"
+)
+
+// pruneArchiveWarnThreshold is the smallest archive threshold; pruning entries at or above it
+// changes archive_counts.
+const pruneArchiveWarnThreshold = 5
+
+// entryPruner counts and deletes old low-bookmark entries.
+type entryPruner interface {
+	CountPrunable(ctx context.Context, cutoff time.Time, maxBookmarks int) (int64, error)
+	PruneBatch(ctx context.Context, cutoff time.Time, maxBookmarks, limit int) (int64, error)
+}
+
+// pruneOptions controls an entries prune run.
+type pruneOptions struct {
+	Cutoff       time.Time
+	MaxBookmarks int
+	BatchSize    int
+}
+
+func runEntries(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		printUsage()
+		return fmt.Errorf("missing entries subcommand")
+	}
+	switch args[0] {
+	case "prune":
+		return runEntriesPrune(ctx, args[1:])
+	case "backfill-excerpts":
+		return runEntriesBackfillExcerpts(ctx, args[1:])
+	default:
+		printUsage()
+		return fmt.Errorf("unknown entries subcommand: %s", args[0])
+	}
+}
+
+func runEntriesPrune(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("entries prune", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	olderThan := fs.String("older-than", "", "prune entries created before this age, e.g. 5y, 180d or 720h (required)")
+	maxBookmarks := fs.Int("max-bookmarks", 1, "prune only entries with at most this many bookmarks")
+	batchSize := fs.Int("batch-size", 1000, "number of entries deleted per transaction")
+	dryRun := fs.Bool("dry-run", false, "print the number of matching entries without deleting")
+	yes := fs.Bool("yes", false, "required confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*yes && !*dryRun {
+		return fmt.Errorf("--yes is required")
+	}
+	if strings.TrimSpace(*olderThan) == "" {
+		return fmt.Errorf("--older-than is required")
+	}
+	if *maxBookmarks < 0 {
+		return fmt.Errorf("--max-bookmarks must be >= 0")
+	}
+	if *batchSize <= 0 {
+		return fmt.Errorf("--batch-size must be positive")
+	}
+
+	cfg, log, db, closeAll, sentryEnabled, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+	if sentryEnabled {
+		defer telemetry.Recover()
+	}
+
+	// connectDatabase has applied APP_TIMEZONE, so the cutoff follows the configured zone.
+	cutoff, err := pruneCutoff(apptime.Now(), *olderThan)
+	if err != nil {
+		return fmt.Errorf("invalid --older-than: %w", err)
+	}
+	opts := pruneOptions{Cutoff: cutoff, MaxBookmarks: *maxBookmarks, BatchSize: *batchSize}
+	store := infraPostgres.NewEntryRepository(db.Pool)
+
+	if *dryRun {
+		n, err := store.CountPrunable(ctx, opts.Cutoff, opts.MaxBookmarks)
+		if err != nil {
+			return err
+		}
+		log.Info("entries prune dry run", "cutoff", cutoff.Format(time.RFC3339), "max_bookmarks", *maxBookmarks, "entries", n)
+		return nil
+	}
+
+	audit := newAuditor(log, auditStoreFor(cfg, db.Pool))
+	target := fmt.Sprintf("older_than=%s max_bookmarks=%d", *olderThan, *maxBookmarks)
+	deleted, err := audit.run(ctx, "entries.prune", target, func(ctx context.Context) (int64, error) {
+		return pruneEntries(ctx, store, log, opts)
+	})
+	if err != nil {
+		return fmt.Errorf("prune entries: %w", err)
+	}
+	log.Info("entries prune completed", "cutoff", cutoff.Format(time.RFC3339), "max_bookmarks", *maxBookmarks, "deleted", deleted)
+	if *maxBookmarks >= pruneArchiveWarnThreshold && deleted > 0 {
+		log.Warn("pruned entries were counted in archive_counts; run `admin archive rebuild --only-diff --yes`")
+	}
+	return nil
+}
+
+// pruneEntries deletes matching entries in batches until a batch comes back short, logging
+// progress after each one. Deleted entry_tags and click_metrics rows follow by cascade.
+func pruneEntries(ctx context.Context, store entryPruner, log *slog.Logger, opts pruneOptions) (int64, error) {
+	total, err := store.CountPrunable(ctx, opts.Cutoff, opts.MaxBookmarks)
+	if err != nil {
+		return 0, err
+	}
+	var deleted int64
+	for deleted < total {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+		n, err := store.PruneBatch(ctx, opts.Cutoff, opts.MaxBookmarks, opts.BatchSize)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+		log.Info("entries prune progress", "deleted", deleted, "total", total)
+		if n < int64(opts.BatchSize) {
+			break
+		}
+	}
+	return deleted, nil
+}
+
+// pruneCutoff subtracts an age from now. Ages are a number followed by y (years) or d (days),
+// or anything time.ParseDuration accepts.
+func pruneCutoff(now time.Time, age string) (time.Time, error) {
+	age = strings.TrimSpace(age)
+	if n, ok := strings.CutSuffix(age, "y"); ok {
+		years, err := strconv.Atoi(n)
+		if err != nil || years <= 0 {
+			return time.Time{}, fmt.Errorf("invalid age %q", age)
+		}
+		return now.AddDate(-years, 0, 0), nil
+	}
+	if n, ok := strings.CutSuffix(age, "d"); ok {
+		days, err := strconv.Atoi(n)
+		if err != nil || days <= 0 {
+			return time.Time{}, fmt.Errorf("invalid age %q", age)
+		}
+		return now.AddDate(0, 0, -days), nil
+	}
+	d, err := time.ParseDuration(age)
+	if err != nil || d <= 0 {
+		return time.Time{}, fmt.Errorf("invalid age %q", age)
+	}
+	return now.Add(-d), nil
+}
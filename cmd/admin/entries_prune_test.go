@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEntryPruner struct {
+	remaining int64
+	batches   []int
+}
+
+func (s *fakeEntryPruner) CountPrunable(ctx context.Context, cutoff time.Time, maxBookmarks int) (int64, error) {
+	return s.remaining, nil
+}
+
+func (s *fakeEntryPruner) PruneBatch(ctx context.Context, cutoff time.Time, maxBookmarks, limit int) (int64, error) {
+	n := min(s.remaining, int64(limit))
+	s.remaining -= n
+	s.batches = append(s.batches, int(n))
+	return n, nil
+}
+
+func TestPruneEntriesBatches(t *testing.T) {
+	store := &fakeEntryPruner{remaining: 25}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	deleted, err := pruneEntries(context.Background(), store, log, pruneOptions{MaxBookmarks: 1, BatchSize: 10})
+	require.NoError(t, err)
+	require.Equal(t, int64(25), deleted)
+	require.Equal(t, []int{10, 10, 5}, store.batches)
+}
+
+func TestPruneEntriesNothingToDo(t *testing.T) {
+	store := &fakeEntryPruner{}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	deleted, err := pruneEntries(context.Background(), store, log, pruneOptions{BatchSize: 10})
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+	require.Empty(t, store.batches)
+}
+
+func TestPruneCutoff(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		age  string
+		want time.Time
+	}{
+		{"5y", time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"30d", time.Date(2025, 1, 30, 12, 0, 0, 0, time.UTC)},
+		{"36h", time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := pruneCutoff(now, tt.age)
+		require.NoError(t, err, tt.age)
+		require.Equal(t, tt.want, got, tt.age)
+	}
+	for _, age := range []string{"", "y", "-1y", "0d", "abc", "-1h"} {
+		_, err := pruneCutoff(now, age)
+		require.Error(t, err, age)
+	}
+}
+
+func TestRunEntriesPruneFlagValidation(t *testing.T) {
+	ctx := context.Background()
+	require.EqualError(t, runEntriesPrune(ctx, []string{"--older-than", "5y"}), "--yes is required")
+	require.EqualError(t, runEntriesPrune(ctx, []string{"--yes"}), "--older-than is required")
+	require.EqualError(t, runEntriesPrune(ctx, []string{"--older-than", "5y", "--max-bookmarks", "-1", "--yes"}), "--max-bookmarks must be >= 0")
+	require.EqualError(t, runEntriesPrune(ctx, []string{"--older-than", "5y", "--batch-size", "0", "--yes"}), "--batch-size must be positive")
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+
+	domainEntry "hateblog/internal/domain/entry"
+	infraPostgres "hateblog/internal/infra/postgres"
+	infraRedis "hateblog/internal/infra/redis"
+	"hateblog/internal/pkg/hostname"
+	"hateblog/internal/platform/database"
+	"hateblog/internal/platform/telemetry"
+	usecaseFavicon "hateblog/internal/usecase/favicon"
+)
+
+// hostLister pages through the distinct hosts of stored entries.
+type hostLister interface {
+	ListHosts(ctx context.Context, after string, limit int) ([]string, error)
+}
+
+// faviconRekeyer moves cached favicons from keys that the current host derivation no longer reads.
+type faviconRekeyer interface {
+	BuildKey(domain string) (string, error)
+	StaleKey(domain string) (string, bool, error)
+	Get(ctx context.Context, key string) ([]byte, string, bool, error)
+	Set(ctx context.Context, key string, data []byte, contentType string) error
+	Delete(ctx context.Context, key string) error
+}
+
+// faviconRecomputeResult tallies a favicon recompute run.
+type faviconRecomputeResult struct {
+	Hosts   int
+	Stale   int
+	Rewarm  int
+	Invalid int
+}
+
+func runFavicon(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		printUsage()
+		return fmt.Errorf("missing favicon subcommand")
+	}
+	switch args[0] {
+	case "recompute":
+		return runFaviconRecompute(ctx, args[1:])
+	default:
+		printUsage()
+		return fmt.Errorf("unknown favicon subcommand: %s", args[0])
+	}
+}
+
+func runFaviconRecompute(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("favicon recompute", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	batchSize := fs.Int("batch-size", 1000, "number of hosts read per query")
+	dryRun := fs.Bool("dry-run", false, "report stale cache keys without changing the cache")
+	yes := fs.Bool("yes", false, "required confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*yes && !*dryRun {
+		return fmt.Errorf("--yes is required")
+	}
+	if *batchSize <= 0 {
+		return fmt.Errorf("--batch-size must be positive")
+	}
+
+	cfg, log, redisClient, closeAll, sentryEnabled, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+	if sentryEnabled {
+		defer telemetry.Recover()
+	}
+
+	db, err := database.New(ctx, database.Config{
+		ConnectionString: cfg.Database.ConnectionString(),
+		MaxConns:         cfg.Database.MaxConns,
+		MinConns:         cfg.Database.MinConns,
+		MaxConnLifetime:  cfg.Database.MaxConnLifetime,
+		MaxConnIdleTime:  cfg.Database.MaxConnIdleTime,
+		ConnectTimeout:   cfg.Database.ConnectTimeout,
+		TimeZone:         cfg.App.TimeZone,
+	}, log)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	hosts := infraPostgres.NewEntryRepository(db.Pool)
+	store := infraRedis.NewFaviconCache(redisClient, cfg.Cache.FaviconTTL)
+
+	if *dryRun {
+		res, err := recomputeFavicons(ctx, hosts, store, log, *batchSize, true)
+		if err != nil {
+			return err
+		}
+		log.Info("favicon recompute dry run", "hosts", res.Hosts, "stale", res.Stale, "rewarm", res.Rewarm, "invalid", res.Invalid)
+		return nil
+	}
+
+	audit := newAuditor(log, auditStoreFor(cfg, db.Pool))
+	var res faviconRecomputeResult
+	_, err = audit.run(ctx, "favicon.recompute", "favicon:*", func(ctx context.Context) (int64, error) {
+		var err error
+		res, err = recomputeFavicons(ctx, hosts, store, log, *batchSize, false)
+		return int64(res.Stale), err
+	})
+	if err != nil {
+		return fmt.Errorf("recompute favicons: %w", err)
+	}
+	log.Info("favicon recompute completed", "hosts", res.Hosts, "stale", res.Stale, "rewarm", res.Rewarm, "invalid", res.Invalid)
+	return nil
+}
+
+// recomputeFavicons walks every entry host and, for hosts whose cache key changed with the
+// favicon host derivation, copies a cached icon to the current key when that key is empty and
+// then purges the stale key and its negative entry. With dryRun nothing is written.
+func recomputeFavicons(ctx context.Context, hosts hostLister, store faviconRekeyer, log *slog.Logger, batchSize int, dryRun bool) (faviconRecomputeResult, error) {
+	var res faviconRecomputeResult
+	after := ""
+	for {
+		page, err := hosts.ListHosts(ctx, after, batchSize)
+		if err != nil {
+			return res, err
+		}
+		for _, host := range page {
+			res.Hosts++
+			staleKey, stale, err := store.StaleKey(host)
+			if err != nil {
+				res.Invalid++
+				continue
+			}
+			if !stale {
+				continue
+			}
+			res.Stale++
+			rewarmed, err := rekeyFavicon(ctx, store, host, staleKey, dryRun)
+			if err != nil {
+				return res, fmt.Errorf("rekey favicon %s: %w", host, err)
+			}
+			if rewarmed {
+				res.Rewarm++
+			}
+			log.Debug("stale favicon key", "host", host, "key", staleKey, "rewarm", rewarmed)
+		}
+		if len(page) < batchSize {
+			return res, nil
+		}
+		after = page[len(page)-1]
+	}
+}
+
+// rekeyFavicon moves the icon cached under staleKey to the host's current key unless that key
+// already holds one, and reports whether it did (or would, with dryRun).
+func rekeyFavicon(ctx context.Context, store faviconRekeyer, host, staleKey string, dryRun bool) (bool, error) {
+	key, err := store.BuildKey(host)
+	if err != nil {
+		return false, err
+	}
+	data, contentType, found, err := store.Get(ctx, staleKey)
+	if err != nil {
+		return false, err
+	}
+	rewarm := false
+	if found {
+		_, _, exists, err := store.Get(ctx, key)
+		if err != nil {
+			return false, err
+		}
+		rewarm = !exists
+	}
+	if dryRun {
+		return rewarm, nil
+	}
+	if rewarm {
+		if err := store.Set(ctx, key, data, contentType); err != nil {
+			return false, err
+		}
+	}
+	return rewarm, store.Delete(ctx, staleKey)
+}
+
+// faviconWarmer fetches a favicon through the favicon cache and rate limiter, as the favicon
+// endpoint does.
+type faviconWarmer interface {
+	Fetch(ctx context.Context, domain string) ([]byte, string, bool, error)
+}
+
+// faviconWarmResult tallies a favicon prewarm run.
+type faviconWarmResult struct {
+	Domains     int
+	Warmed      int
+	Cached      int
+	RateLimited int
+}
+
+// faviconDomainSet collects the distinct favicon hosts of entries, up to max hosts.
+// A nil set ignores entries, so callers need not check whether prewarming is enabled.
+type faviconDomainSet struct {
+	max     int
+	seen    map[string]struct{}
+	domains []string
+}
+
+func newFaviconDomainSet(max int) *faviconDomainSet {
+	return &faviconDomainSet{max: max, seen: make(map[string]struct{})}
+}
+
+// add records the favicon hosts of entries in order of first appearance. Entries whose URL
+// has no valid host are skipped.
+func (s *faviconDomainSet) add(entries []*domainEntry.Entry) {
+	if s == nil {
+		return
+	}
+	for _, ent := range entries {
+		if len(s.domains) >= s.max {
+			return
+		}
+		host, err := hostname.Favicon(ent.URL)
+		if err != nil {
+			continue
+		}
+		if _, ok := s.seen[host]; ok {
+			continue
+		}
+		s.seen[host] = struct{}{}
+		s.domains = append(s.domains, host)
+	}
+}
+
+// warmFavicons fetches the favicon of each domain so that it is cached before the first page
+// load. Domains already cached are not fetched again, and domains the rate limiter rejects
+// are skipped and counted rather than retried.
+func warmFavicons(ctx context.Context, warmer faviconWarmer, domains []string, log *slog.Logger) (faviconWarmResult, error) {
+	var res faviconWarmResult
+	for _, domain := range domains {
+		res.Domains++
+		_, _, cacheHit, err := warmer.Fetch(ctx, domain)
+		switch {
+		case errors.Is(err, usecaseFavicon.ErrRateLimited):
+			res.RateLimited++
+			log.Debug("favicon warmup rate limited", "domain", domain)
+		case err != nil:
+			return res, fmt.Errorf("warm favicon %s: %w", domain, err)
+		case cacheHit:
+			res.Cached++
+		default:
+			res.Warmed++
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domainEntry "hateblog/internal/domain/entry"
+	infraRedis "hateblog/internal/infra/redis"
+	"hateblog/internal/platform/cache"
+	usecaseFavicon "hateblog/internal/usecase/favicon"
+)
+
+type fakeHostLister struct {
+	hosts []string
+	calls int
+}
+
+func (l *fakeHostLister) ListHosts(ctx context.Context, after string, limit int) ([]string, error) {
+	l.calls++
+	var page []string
+	for _, h := range l.hosts {
+		if h > after && len(page) < limit {
+			page = append(page, h)
+		}
+	}
+	return page, nil
+}
+
+type fakeFaviconClient struct {
+	store map[string]string
+}
+
+func (c *fakeFaviconClient) Get(ctx context.Context, key string) (string, error) {
+	if v, ok := c.store[key]; ok {
+		return v, nil
+	}
+	return "", cache.ErrCacheMiss
+}
+
+func (c *fakeFaviconClient) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	switch v := value.(type) {
+	case []byte:
+		c.store[key] = string(v)
+	case string:
+		c.store[key] = v
+	}
+	return nil
+}
+
+func (c *fakeFaviconClient) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(c.store, key)
+	}
+	return nil
+}
+
+func newFaviconFixture(t *testing.T) (*fakeFaviconClient, *infraRedis.FaviconCache) {
+	t.Helper()
+	client := &fakeFaviconClient{store: make(map[string]string)}
+	store := infraRedis.NewFaviconCache(client, time.Hour)
+	ctx := context.Background()
+	// Icons cached before www. was stripped from the favicon host.
+	require.NoError(t, store.Set(ctx, "favicon:www.a.example", []byte{1}, "image/png"))
+	require.NoError(t, store.Set(ctx, "favicon:www.b.example", []byte{2}, "image/png"))
+	require.NoError(t, store.Set(ctx, "favicon:b.example", []byte{3}, "image/png"))
+	require.NoError(t, store.SetNegative(ctx, "favicon:www.c.example"))
+	require.NoError(t, store.Set(ctx, "favicon:d.example", []byte{4}, "image/png"))
+	return client, store
+}
+
+func TestRecomputeFavicons(t *testing.T) {
+	client, store := newFaviconFixture(t)
+	hosts := &fakeHostLister{hosts: []string{"d.example", "www.a.example", "www.b.example", "www.c.example"}}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	res, err := recomputeFavicons(context.Background(), hosts, store, log, 2, false)
+	require.NoError(t, err)
+	require.Equal(t, faviconRecomputeResult{Hosts: 4, Stale: 3, Rewarm: 1}, res)
+	require.Equal(t, 3, hosts.calls)
+
+	for _, key := range []string{"favicon:www.a.example", "favicon:www.b.example", "favicon:www.c.example:neg"} {
+		require.NotContains(t, client.store, key)
+	}
+	data, _, ok, err := store.Get(context.Background(), "favicon:a.example")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte{1}, data)
+	// An icon already cached under the current key is kept.
+	data, _, ok, err = store.Get(context.Background(), "favicon:b.example")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte{3}, data)
+	require.Contains(t, client.store, "favicon:d.example")
+}
+
+func TestRecomputeFaviconsDryRun(t *testing.T) {
+	client, store := newFaviconFixture(t)
+	before := len(client.store)
+	hosts := &fakeHostLister{hosts: []string{"bad host", "d.example", "www.a.example", "www.b.example", "www.c.example"}}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	res, err := recomputeFavicons(context.Background(), hosts, store, log, 100, true)
+	require.NoError(t, err)
+	require.Equal(t, faviconRecomputeResult{Hosts: 5, Stale: 3, Rewarm: 1, Invalid: 1}, res)
+	require.Len(t, client.store, before)
+}
+
+type fakeGoogleClient struct {
+	fetched []string
+}
+
+func (c *fakeGoogleClient) Fetch(ctx context.Context, domain string) ([]byte, string, error) {
+	c.fetched = append(c.fetched, domain)
+	return []byte(domain), "image/png", nil
+}
+
+type fakeFaviconLimiter struct {
+	deny map[string]bool
+}
+
+func (l *fakeFaviconLimiter) Allow(ctx context.Context, domain string) (bool, error) {
+	return !l.deny[domain], nil
+}
+
+func TestFaviconDomainSet(t *testing.T) {
+	entryAt := func(url string) *domainEntry.Entry { return &domainEntry.Entry{URL: url} }
+	set := newFaviconDomainSet(3)
+	set.add([]*domainEntry.Entry{
+		entryAt("https://www.a.example/1"),
+		entryAt("https://a.example/2"),
+		entryAt("not a url"),
+		entryAt("https://B.example/3"),
+	})
+	set.add([]*domainEntry.Entry{entryAt("https://c.example/"), entryAt("https://d.example/")})
+	require.Equal(t, []string{"a.example", "b.example", "c.example"}, set.domains)
+
+	var disabled *faviconDomainSet
+	disabled.add([]*domainEntry.Entry{entryAt("https://a.example/")})
+}
+
+func TestWarmFavicons(t *testing.T) {
+	client := &fakeFaviconClient{store: make(map[string]string)}
+	store := infraRedis.NewFaviconCache(client, time.Hour)
+	require.NoError(t, store.Set(context.Background(), "favicon:cached.example", []byte{1}, "image/png"))
+	google := &fakeGoogleClient{}
+	limiter := &fakeFaviconLimiter{deny: map[string]bool{"busy.example": true}}
+	service := usecaseFavicon.NewService(google, store, limiter, nil)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	res, err := warmFavicons(context.Background(), service, []string{"a.example", "cached.example", "busy.example", "b.example"}, log)
+	require.NoError(t, err)
+	require.Equal(t, faviconWarmResult{Domains: 4, Warmed: 2, Cached: 1, RateLimited: 1}, res)
+	require.Equal(t, []string{"a.example", "b.example"}, google.fetched)
+
+	data, _, ok, err := store.Get(context.Background(), "favicon:a.example")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("a.example"), data)
+	require.NotContains(t, client.store, "favicon:busy.example")
+
+	// A second run is served from the cache.
+	res, err = warmFavicons(context.Background(), service, []string{"a.example", "b.example"}, log)
+	require.NoError(t, err)
+	require.Equal(t, faviconWarmResult{Domains: 2, Cached: 2}, res)
+	require.Len(t, google.fetched, 2)
+}
@@ -6,15 +6,24 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	domainEntry "hateblog/internal/domain/entry"
+	"hateblog/internal/domain/tag"
+	infraGoogle "hateblog/internal/infra/external/google"
 	infraPostgres "hateblog/internal/infra/postgres"
 	infraRedis "hateblog/internal/infra/redis"
+	"hateblog/internal/pkg/apptime"
+	"hateblog/internal/pkg/batchutil"
 	"hateblog/internal/platform/cache"
 	"hateblog/internal/platform/config"
 	"hateblog/internal/platform/database"
@@ -22,14 +31,27 @@ import (
 	"hateblog/internal/platform/telemetry"
 	usecaseArchive "hateblog/internal/usecase/archive"
 	usecaseEntry "hateblog/internal/usecase/entry"
+	usecaseFavicon "hateblog/internal/usecase/favicon"
 	usecaseRanking "hateblog/internal/usecase/ranking"
 	usecaseSearch "hateblog/internal/usecase/search"
 	usecaseTag "hateblog/internal/usecase/tag"
 )
 
 func main() {
-	if err := run(context.Background(), os.Args); err != nil {
+	// SIGINT/SIGTERM cancel the context: long commands stop dispatching work and report how
+	// far they got. A second signal kills the process as usual.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	err := run(ctx, os.Args)
+	stop()
+	if err != nil {
 		slog.Error("admin command failed", "error", err)
+		if ctx.Err() != nil {
+			os.Exit(130)
+		}
 		os.Exit(1)
 	}
 }
@@ -44,6 +66,16 @@ func run(ctx context.Context, args []string) error {
 		return runCache(ctx, args[2:])
 	case "archive":
 		return runArchive(ctx, args[2:])
+	case "tag":
+		return runTag(ctx, args[2:])
+	case "entries":
+		return runEntries(ctx, args[2:])
+	case "favicon":
+		return runFavicon(ctx, args[2:])
+	case "reindex":
+		return runReindex(ctx, args[2:])
+	case "doctor":
+		return runDoctor(ctx, args[2:])
 	default:
 		printUsage()
 		return fmt.Errorf("unknown command: %s", args[1])
@@ -54,7 +86,18 @@ func printUsage() {
 	fmt.Fprintln(os.Stderr, "usage:")
 	fmt.Fprintln(os.Stderr, "  admin cache purge --pattern 'hateblog:entries:*' --yes")
 	fmt.Fprintln(os.Stderr, "  admin cache warmup --dates 20250105,20250106 --tags go,web --yearly 2024,2025 --min-users 5,10,50")
+	fmt.Fprintln(os.Stderr, "  admin cache warmup --today [--favicons --favicon-limit 200] [--concurrency 4] --yes")
+	fmt.Fprintln(os.Stderr, "  admin cache verify --date 20250105[,20250106]")
 	fmt.Fprintln(os.Stderr, "  admin archive rebuild --yes")
+	fmt.Fprintln(os.Stderr, "  admin archive rebuild --diff [--json diff.json]")
+	fmt.Fprintln(os.Stderr, "  admin archive rebuild --only-diff [--json diff.json] --yes")
+	fmt.Fprintln(os.Stderr, "  admin archive refresh-recent --days 3 --yes")
+	fmt.Fprintln(os.Stderr, "  admin tag retag --from 20250101 --limit 100 [--after <entry-id>] [--dry-run] --yes")
+	fmt.Fprintln(os.Stderr, "  admin entries prune --older-than 5y --max-bookmarks 1 [--batch-size 1000] [--dry-run] --yes")
+	fmt.Fprintln(os.Stderr, "  admin entries backfill-excerpts [--limit 1000] [--batch-size 100] [--interval 500ms] [--dry-run] --yes")
+	fmt.Fprintln(os.Stderr, "  admin favicon recompute [--batch-size 1000] [--dry-run] --yes")
+	fmt.Fprintln(os.Stderr, "  admin reindex entries [--fields host,search_text] [--batch-size 1000] --yes")
+	fmt.Fprintln(os.Stderr, "  admin doctor [--migrations-dir migrations] [--timeout 10s]")
 }
 
 func runCache(ctx context.Context, args []string) error {
@@ -67,6 +110,8 @@ func runCache(ctx context.Context, args []string) error {
 		return runCachePurge(ctx, args[1:])
 	case "warmup":
 		return runCacheWarmup(ctx, args[1:])
+	case "verify":
+		return runCacheVerify(ctx, args[1:])
 	default:
 		printUsage()
 		return fmt.Errorf("unknown cache subcommand: %s", args[0])
@@ -81,6 +126,8 @@ func runArchive(ctx context.Context, args []string) error {
 	switch args[0] {
 	case "rebuild":
 		return runArchiveRebuild(ctx, args[1:])
+	case "refresh-recent":
+		return runArchiveRefreshRecent(ctx, args[1:])
 	default:
 		printUsage()
 		return fmt.Errorf("unknown archive subcommand: %s", args[0])
@@ -91,63 +138,81 @@ func runArchiveRebuild(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("archive rebuild", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 	yes := fs.Bool("yes", false, "required confirmation")
+	diffOnly := fs.Bool("diff", false, "print rows that differ from entries without writing")
+	onlyDiff := fs.Bool("only-diff", false, "update only the drifted rows instead of rebuilding the table")
+	jsonPath := fs.String("json", "", "also write the differences as JSON to this file (- for stdout)")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
-	if !*yes {
-		return fmt.Errorf("--yes is required")
+	if *diffOnly && *onlyDiff {
+		return fmt.Errorf("--diff and --only-diff are mutually exclusive")
 	}
-
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("load config: %w", err)
+	if *jsonPath != "" && !*diffOnly && !*onlyDiff {
+		return fmt.Errorf("--json requires --diff or --only-diff")
 	}
-	loc, err := time.LoadLocation(cfg.App.TimeZone)
-	if err != nil {
-		return fmt.Errorf("load timezone: %w", err)
+	if !*yes && !*diffOnly {
+		return fmt.Errorf("--yes is required")
 	}
-	time.Local = loc
 
-	sentryEnabled, err := telemetry.InitSentry(cfg.Sentry)
+	cfg, log, db, closeAll, sentryEnabled, err := connectDatabase(ctx)
 	if err != nil {
-		return fmt.Errorf("init sentry: %w", err)
+		return err
 	}
+	defer closeAll()
 	if sentryEnabled {
-		defer telemetry.Flush(2 * time.Second)
 		defer telemetry.Recover()
 	}
 
-	log := logger.New(logger.Config{
-		Level:  logger.Level(cfg.App.LogLevel),
-		Format: logger.Format(cfg.App.LogFormat),
-	})
-	if sentryEnabled {
-		log = logger.WrapWithSentry(log)
+	if *diffOnly || *onlyDiff {
+		store := infraPostgres.NewArchiveCountRepository(db.Pool)
+		var diffs []infraPostgres.ArchiveCountDiff
+		if *diffOnly {
+			if diffs, err = store.Diff(ctx); err != nil {
+				return fmt.Errorf("diff archive counts: %w", err)
+			}
+		} else {
+			audit := newAuditor(log, auditStoreFor(cfg, db.Pool))
+			var changed int64
+			if diffs, changed, err = rebuildArchiveDiff(ctx, store, audit); err != nil {
+				return fmt.Errorf("rebuild drifted archive counts: %w", err)
+			}
+			log.Info("archive rebuild completed", "rows", changed, "mode", "only-diff")
+		}
+		if *jsonPath != "" {
+			if err := writeArchiveDiffFile(*jsonPath, diffs); err != nil {
+				return err
+			}
+		}
+		if *jsonPath != "-" {
+			printArchiveDiff(os.Stdout, diffs)
+		}
+		return nil
 	}
-	logger.SetDefault(log)
 
-	db, err := database.New(ctx, database.Config{
-		ConnectionString: cfg.Database.ConnectionString(),
-		MaxConns:         cfg.Database.MaxConns,
-		MinConns:         cfg.Database.MinConns,
-		MaxConnLifetime:  cfg.Database.MaxConnLifetime,
-		MaxConnIdleTime:  cfg.Database.MaxConnIdleTime,
-		ConnectTimeout:   cfg.Database.ConnectTimeout,
-		TimeZone:         cfg.App.TimeZone,
-	}, log)
+	audit := newAuditor(log, auditStoreFor(cfg, db.Pool))
+	rows, err := rebuildArchive(ctx, db.Pool, audit)
 	if err != nil {
-		return fmt.Errorf("connect database: %w", err)
-	}
-	defer db.Close()
-
-	if err := rebuildArchiveCounts(ctx, db.Pool); err != nil {
 		return fmt.Errorf("rebuild archive counts: %w", err)
 	}
 
-	log.Info("archive rebuild completed")
+	log.Info("archive rebuild completed", "rows", rows)
 	return nil
 }
 
+// rebuildArchive rebuilds archive_counts and records the operation in the audit log.
+func rebuildArchive(ctx context.Context, db txBeginner, audit *auditor) (int64, error) {
+	return audit.run(ctx, "archive.rebuild", "archive_counts", func(ctx context.Context) (int64, error) {
+		return rebuildArchiveCounts(ctx, db)
+	})
+}
+
+func auditStoreFor(cfg *config.Config, pool *pgxpool.Pool) auditStore {
+	if cfg == nil || !cfg.App.AuditLogDB || pool == nil {
+		return nil
+	}
+	return &pgAuditStore{pool: pool}
+}
+
 func runCachePurge(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("cache purge", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
@@ -168,7 +233,6 @@ func runCachePurge(ctx context.Context, args []string) error {
 	}
 
 	cfg, log, redisClient, closeAll, sentryEnabled, err := connect(ctx)
-	_ = cfg
 	if err != nil {
 		return err
 	}
@@ -177,24 +241,83 @@ func runCachePurge(ctx context.Context, args []string) error {
 		defer telemetry.Recover()
 	}
 
-	deleted, err := redisClient.DeleteByPattern(ctx, *pattern, *batchSize)
+	store, closeStore, err := openAuditStore(ctx, cfg, log)
 	if err != nil {
 		return err
 	}
+	defer closeStore()
+
+	deleted, err := purgeCache(ctx, redisClient, newAuditor(log, store), *pattern, *batchSize)
+	if err != nil {
+		if ctx.Err() != nil {
+			log.Warn("cache purge interrupted; matching keys remain", "pattern", *pattern, "deleted", deleted)
+		}
+		return err
+	}
 	log.Info("cache purge completed", "pattern", *pattern, "deleted", deleted)
 	return nil
 }
 
+type patternDeleter interface {
+	DeleteByPattern(ctx context.Context, pattern string, batchSize int64) (int64, error)
+}
+
+// purgeCache deletes keys matching pattern and records the operation in the audit log.
+func purgeCache(ctx context.Context, store patternDeleter, audit *auditor, pattern string, batchSize int64) (int64, error) {
+	return audit.run(ctx, "cache.purge", pattern, func(ctx context.Context) (int64, error) {
+		return store.DeleteByPattern(ctx, pattern, batchSize)
+	})
+}
+
+// warmupTargets lists the caches that cache warmup populates.
+type warmupTargets struct {
+	Dates    []string
+	Tags     []string
+	MinUsers []int
+	Yearly   []int
+	Monthly  []string
+	Weekly   []string
+	Search   []string
+}
+
+// expandToday adds today's date and the current year/month/ISO-week rankings.
+// Values already present are not duplicated.
+func (t *warmupTargets) expandToday(now time.Time) {
+	year, week := now.ISOWeek()
+	t.Dates = appendUnique(t.Dates, now.Format("20060102"))
+	t.Monthly = appendUnique(t.Monthly, now.Format("2006-01"))
+	t.Weekly = appendUnique(t.Weekly, fmt.Sprintf("%04d-%02d", year, week))
+	for _, y := range t.Yearly {
+		if y == now.Year() {
+			return
+		}
+	}
+	t.Yearly = append(t.Yearly, now.Year())
+}
+
+func appendUnique(values []string, v string) []string {
+	for _, existing := range values {
+		if existing == v {
+			return values
+		}
+	}
+	return append(values, v)
+}
+
 func runCacheWarmup(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("cache warmup", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
-	dates := fs.String("dates", "", "comma-separated YYYYMMDD list (required)")
+	dates := fs.String("dates", "", "comma-separated YYYYMMDD list (required unless --today)")
 	tags := fs.String("tags", "", "comma-separated tag names")
 	minUsers := fs.String("min-users", "5,10,50,100,500,1000", "comma-separated min_users list for caches that vary by min_users")
 	yearly := fs.String("yearly", "", "comma-separated years for yearly rankings")
 	monthly := fs.String("monthly", "", "comma-separated YYYY-MM for monthly rankings")
 	weekly := fs.String("weekly", "", "comma-separated YYYY-WW (ISO week) for weekly rankings")
 	searchQueries := fs.String("search", "", "comma-separated search queries to warm")
+	today := fs.Bool("today", false, "warm today's entries and the current year/month/week rankings")
+	favicons := fs.Bool("favicons", false, "also prewarm the favicons of the warmed entries' domains")
+	faviconLimit := fs.Int("favicon-limit", 200, "maximum number of domains whose favicons are prewarmed")
+	concurrency := fs.Int("concurrency", 1, "number of targets warmed in parallel")
 	yes := fs.Bool("yes", false, "required confirmation")
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -202,9 +325,23 @@ func runCacheWarmup(ctx context.Context, args []string) error {
 	if !*yes {
 		return fmt.Errorf("--yes is required")
 	}
-	dateList := splitCSV(*dates)
-	if len(dateList) == 0 {
-		return fmt.Errorf("--dates is required")
+	targets := warmupTargets{
+		Dates:    splitCSV(*dates),
+		Tags:     splitCSV(*tags),
+		MinUsers: splitCSVInts(*minUsers),
+		Yearly:   splitCSVInts(*yearly),
+		Monthly:  splitCSV(*monthly),
+		Weekly:   splitCSV(*weekly),
+		Search:   splitCSV(*searchQueries),
+	}
+	if len(targets.Dates) == 0 && !*today {
+		return fmt.Errorf("--dates or --today is required")
+	}
+	if *favicons && *faviconLimit <= 0 {
+		return fmt.Errorf("--favicon-limit must be positive")
+	}
+	if *concurrency <= 0 {
+		return fmt.Errorf("--concurrency must be positive")
 	}
 
 	cfg, log, redisClient, closeAll, sentryEnabled, err := connect(ctx)
@@ -219,6 +356,10 @@ func runCacheWarmup(ctx context.Context, args []string) error {
 	if !cfg.App.CacheEnabled {
 		return fmt.Errorf("cache is disabled (APP_CACHE_ENABLED=false)")
 	}
+	if *today {
+		// connect has applied APP_TIMEZONE, so "today" follows the configured zone.
+		targets.expandToday(apptime.Now())
+	}
 
 	db, err := database.New(ctx, database.Config{
 		ConnectionString: cfg.Database.ConnectionString(),
@@ -234,114 +375,202 @@ func runCacheWarmup(ctx context.Context, args []string) error {
 	}
 	defer db.Close()
 
-	entryRepo := infraPostgres.NewEntryRepository(db.Pool)
+	// Warmed ranking and tag pages must be ordered the same way as the app orders them.
+	entryRepo := infraPostgres.NewEntryRepository(db.Pool).
+		WithHotTiebreak(domainEntry.HotTiebreak(cfg.App.HotTiebreak))
 	tagRepo := infraPostgres.NewTagRepository(db.Pool)
 	searchHistoryRepo := infraPostgres.NewSearchHistoryRepository(db.Pool)
 
-	dayEntriesCache := infraRedis.NewDayEntriesCache(redisClient, cfg.Cache.EntriesDayTTL)
-	tagEntriesCache := infraRedis.NewTagEntriesCache(redisClient, cfg.Cache.TagEntriesTTL)
-	searchCache := infraRedis.NewSearchCache(redisClient, cfg.Cache.SearchTTL)
-	tagsListCache := infraRedis.NewTagsListCache(redisClient, cfg.Cache.TagsListTTL)
-	archiveCache := infraRedis.NewArchiveCache(redisClient, cfg.Cache.EntriesDayTTL, cfg.Cache.ArchiveTTL)
-	yearlyRankingCache := infraRedis.NewYearlyRankingCache(redisClient, cfg.Cache.YearlyRankingCurrentTTL, cfg.Cache.YearlyRankingPastTTL)
-	monthlyRankingCache := infraRedis.NewMonthlyRankingCache(redisClient, cfg.Cache.MonthlyRankingCurrentTTL, cfg.Cache.MonthlyRankingPastTTL)
-	weeklyRankingCache := infraRedis.NewWeeklyRankingCache(redisClient, cfg.Cache.WeeklyRankingCurrentTTL, cfg.Cache.WeeklyRankingPastTTL)
+	apiCacheClient := infraRedis.NewSetRetryClient(redisClient, cfg.Cache.SetMaxAttempts, cfg.Cache.SetRetryBackoff)
+	dayEntriesCache := infraRedis.NewDayEntriesCache(apiCacheClient, cfg.Cache.EntriesDayTTL)
+	tagEntriesCache := infraRedis.NewTagEntriesCache(apiCacheClient, cfg.Cache.TagEntriesTTL)
+	searchCache := infraRedis.NewSearchCache(apiCacheClient, cfg.Cache.SearchTTL)
+	tagsListCache := infraRedis.NewTagsListCache(apiCacheClient, cfg.Cache.TagsListTTL)
+	archiveCache := infraRedis.NewArchiveCache(apiCacheClient, cfg.Cache.EntriesDayTTL, cfg.Cache.ArchiveTTL)
+	yearlyRankingCache := infraRedis.NewYearlyRankingCache(apiCacheClient, cfg.Cache.YearlyRankingCurrentTTL, cfg.Cache.YearlyRankingPastTTL)
+	monthlyRankingCache := infraRedis.NewMonthlyRankingCache(apiCacheClient, cfg.Cache.MonthlyRankingCurrentTTL, cfg.Cache.MonthlyRankingPastTTL)
+	weeklyRankingCache := infraRedis.NewWeeklyRankingCache(apiCacheClient, cfg.Cache.WeeklyRankingCurrentTTL, cfg.Cache.WeeklyRankingPastTTL)
 
 	entryService := usecaseEntry.NewService(entryRepo, dayEntriesCache, tagEntriesCache, log)
-	tagService := usecaseTag.NewService(tagRepo, tagsListCache)
+	tagService := usecaseTag.NewService(tagRepo, tagsListCache).WithMaxListLimit(cfg.App.TagListMaxLimit)
 	searchService := usecaseSearch.NewService(entryRepo, searchHistoryRepo, searchCache, log)
 	archiveService := usecaseArchive.NewService(entryRepo, archiveCache)
-	rankingService := usecaseRanking.NewService(entryRepo, yearlyRankingCache, monthlyRankingCache, weeklyRankingCache)
+	rankingService := usecaseRanking.NewService(entryRepo, yearlyRankingCache, monthlyRankingCache, weeklyRankingCache).
+		WithMinUsersFloor(cfg.App.RankingMinUsersFloor)
 
-	if _, err := tagService.List(ctx, 50, 0); err != nil {
-		return fmt.Errorf("warm tags list: %w", err)
-	}
-
-	for _, date := range dateList {
-		if _, err := entryService.ListNewEntries(ctx, usecaseEntry.DayListParams{
-			Date:             date,
-			MinBookmarkCount: 0,
-			Limit:            1,
-			Offset:           0,
-		}); err != nil {
-			return fmt.Errorf("warm day entries: %s: %w", date, err)
-		}
+	// Day and tag caches hold whole pages regardless of limit, so only --favicons needs
+	// the entries back; it asks for full pages to see their domains.
+	pageLimit := 1
+	var faviconDomains *faviconDomainSet
+	if *favicons {
+		pageLimit = domainEntry.MaxLimit
+		faviconDomains = newFaviconDomainSet(*faviconLimit)
 	}
 
-	for _, tagName := range splitCSV(*tags) {
-		if _, err := entryService.ListTagEntries(ctx, tagName, usecaseEntry.TagListParams{
-			MinBookmarkCount: 0,
-			Limit:            1,
-			Offset:           0,
-		}); err != nil {
-			return fmt.Errorf("warm tag entries: %s: %w", tagName, err)
-		}
-	}
-
-	for _, mu := range splitCSVInts(*minUsers) {
-		if _, err := archiveService.List(ctx, mu); err != nil {
-			return fmt.Errorf("warm archive: min_users=%d: %w", mu, err)
+	jobs := []warmupJob{func(ctx context.Context) ([]*domainEntry.Entry, error) {
+		if _, err := tagService.List(ctx, usecaseTag.DefaultListLimit, 0); err != nil {
+			return nil, fmt.Errorf("warm tags list: %w", err)
 		}
+		return nil, nil
+	}}
+
+	for _, date := range targets.Dates {
+		jobs = append(jobs, func(ctx context.Context) ([]*domainEntry.Entry, error) {
+			res, err := entryService.ListNewEntries(ctx, usecaseEntry.DayListParams{
+				Date:             date,
+				MinBookmarkCount: 0,
+				Limit:            pageLimit,
+				Offset:           0,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("warm day entries: %s: %w", date, err)
+			}
+			return res.Entries, nil
+		})
+	}
+
+	for _, tagName := range targets.Tags {
+		jobs = append(jobs, func(ctx context.Context) ([]*domainEntry.Entry, error) {
+			res, err := entryService.ListTagEntries(ctx, tagName, usecaseEntry.TagListParams{
+				MinBookmarkCount: 0,
+				Limit:            pageLimit,
+				Offset:           0,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("warm tag entries: %s: %w", tagName, err)
+			}
+			return res.Entries, nil
+		})
 	}
 
-	for _, year := range splitCSVInts(*yearly) {
-		for _, mu := range splitCSVInts(*minUsers) {
-			if _, err := rankingService.Yearly(ctx, year, 1000, 0, mu); err != nil {
-				return fmt.Errorf("warm yearly ranking: year=%d min_users=%d: %w", year, mu, err)
+	for _, mu := range targets.MinUsers {
+		jobs = append(jobs, func(ctx context.Context) ([]*domainEntry.Entry, error) {
+			if _, err := archiveService.List(ctx, mu); err != nil {
+				return nil, fmt.Errorf("warm archive: min_users=%d: %w", mu, err)
 			}
+			return nil, nil
+		})
+	}
+
+	for _, year := range targets.Yearly {
+		for _, mu := range targets.MinUsers {
+			jobs = append(jobs, func(ctx context.Context) ([]*domainEntry.Entry, error) {
+				res, err := rankingService.Yearly(ctx, year, 1000, 0, mu)
+				if err != nil {
+					return nil, fmt.Errorf("warm yearly ranking: year=%d min_users=%d: %w", year, mu, err)
+				}
+				return res.Entries, nil
+			})
 		}
 	}
-	for _, ym := range splitCSV(*monthly) {
+	for _, ym := range targets.Monthly {
 		year, month, err := parseYearMonth(ym)
 		if err != nil {
 			return err
 		}
-		for _, mu := range splitCSVInts(*minUsers) {
-			if _, err := rankingService.Monthly(ctx, year, month, 100, 0, mu); err != nil {
-				return fmt.Errorf("warm monthly ranking: %s min_users=%d: %w", ym, mu, err)
-			}
+		for _, mu := range targets.MinUsers {
+			jobs = append(jobs, func(ctx context.Context) ([]*domainEntry.Entry, error) {
+				res, err := rankingService.Monthly(ctx, year, month, 100, 0, mu)
+				if err != nil {
+					return nil, fmt.Errorf("warm monthly ranking: %s min_users=%d: %w", ym, mu, err)
+				}
+				return res.Entries, nil
+			})
 		}
 	}
-	for _, yw := range splitCSV(*weekly) {
+	for _, yw := range targets.Weekly {
 		year, week, err := parseYearWeek(yw)
 		if err != nil {
 			return err
 		}
-		for _, mu := range splitCSVInts(*minUsers) {
-			if _, err := rankingService.Weekly(ctx, year, week, 100, 0, mu); err != nil {
-				return fmt.Errorf("warm weekly ranking: %s min_users=%d: %w", yw, mu, err)
-			}
+		for _, mu := range targets.MinUsers {
+			jobs = append(jobs, func(ctx context.Context) ([]*domainEntry.Entry, error) {
+				res, err := rankingService.Weekly(ctx, year, week, 100, 0, mu)
+				if err != nil {
+					return nil, fmt.Errorf("warm weekly ranking: %s min_users=%d: %w", yw, mu, err)
+				}
+				return res.Entries, nil
+			})
 		}
 	}
 
-	for _, q := range splitCSV(*searchQueries) {
-		if _, err := searchService.Search(ctx, q, usecaseSearch.Params{
-			MinBookmarkCount: 5,
-			Limit:            25,
-			Offset:           0,
-		}); err != nil {
-			return fmt.Errorf("warm search: %q: %w", q, err)
+	for _, q := range targets.Search {
+		jobs = append(jobs, func(ctx context.Context) ([]*domainEntry.Entry, error) {
+			res, err := searchService.Search(ctx, q, usecaseSearch.Params{
+				MinBookmarkCount: 5,
+				Limit:            25,
+				Offset:           0,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("warm search: %q: %w", q, err)
+			}
+			return res.Entries, nil
+		})
+	}
+
+	run := runWarmupJobs(ctx, jobs, *concurrency)
+	for _, entries := range run.Entries {
+		faviconDomains.add(entries)
+	}
+	if ctx.Err() != nil {
+		log.Warn("cache warmup interrupted; some caches were not warmed",
+			"jobs", len(jobs),
+			"completed", run.Completed,
+			"failed", run.Failed,
+			"skipped", run.Skipped,
+		)
+		return run.Err
+	}
+
+	if faviconDomains != nil {
+		faviconService := usecaseFavicon.NewService(
+			infraGoogle.NewClient(infraGoogle.Config{
+				HTTPClient: &http.Client{Timeout: cfg.External.FaviconAPITimeout},
+				UserAgent:  "hateblog-favicon-proxy",
+			}),
+			infraRedis.NewFaviconCache(redisClient, cfg.Cache.FaviconTTL),
+			infraRedis.NewFaviconRateLimiter(redisClient, cfg.External.FaviconRateLimit),
+			log,
+		)
+		res, err := warmFavicons(ctx, faviconService, faviconDomains.domains, log)
+		if err != nil {
+			return err
 		}
+		log.Info("favicon warmup completed",
+			"domains", res.Domains,
+			"warmed", res.Warmed,
+			"cached", res.Cached,
+			"rate_limited", res.RateLimited,
+		)
 	}
 
 	log.Info("cache warmup completed",
-		"dates", len(dateList),
-		"tags", len(splitCSV(*tags)),
-		"yearly", len(splitCSV(*yearly)),
-		"monthly", len(splitCSV(*monthly)),
-		"weekly", len(splitCSV(*weekly)),
-		"search", len(splitCSV(*searchQueries)),
+		"dates", len(targets.Dates),
+		"tags", len(targets.Tags),
+		"yearly", len(targets.Yearly),
+		"monthly", len(targets.Monthly),
+		"weekly", len(targets.Weekly),
+		"search", len(targets.Search),
+		"jobs", len(jobs),
+		"completed", run.Completed,
+		"failed", run.Failed,
+		"concurrency", *concurrency,
 	)
-	return nil
+	return run.Err
+}
+
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
-func rebuildArchiveCounts(ctx context.Context, pool *pgxpool.Pool) error {
-	if pool == nil {
-		return fmt.Errorf("pool is nil")
+// rebuildArchiveCounts recomputes archive_counts and returns the number of rows inserted.
+func rebuildArchiveCounts(ctx context.Context, db txBeginner) (int64, error) {
+	if db == nil {
+		return 0, fmt.Errorf("pool is nil")
 	}
-	tx, err := pool.Begin(ctx)
+	tx, err := db.Begin(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer func() {
 		if err != nil {
@@ -349,8 +578,11 @@ func rebuildArchiveCounts(ctx context.Context, pool *pgxpool.Pool) error {
 		}
 	}()
 
+	if err = batchutil.AdvisoryXactLock(ctx, tx, infraPostgres.ArchiveCountsLock); err != nil {
+		return 0, err
+	}
 	if _, err = tx.Exec(ctx, "TRUNCATE TABLE archive_counts"); err != nil {
-		return err
+		return 0, err
 	}
 	const insertQuery = `
 INSERT INTO archive_counts (day, threshold, count)
@@ -359,12 +591,16 @@ FROM entries
 CROSS JOIN (VALUES (5), (10), (50), (100), (500), (1000)) AS t(threshold)
 WHERE entries.bookmark_count >= t.threshold
 GROUP BY day, t.threshold`
-	if _, err = tx.Exec(ctx, insertQuery); err != nil {
-		return err
+	tag, err := tx.Exec(ctx, insertQuery)
+	if err != nil {
+		return 0, err
 	}
 
 	err = tx.Commit(ctx)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
 }
 
 func connect(ctx context.Context) (*config.Config, *slog.Logger, *cache.Cache, func(), bool, error) {
@@ -372,6 +608,10 @@ func connect(ctx context.Context) (*config.Config, *slog.Logger, *cache.Cache, f
 	if err != nil {
 		return nil, nil, nil, func() {}, false, fmt.Errorf("load config: %w", err)
 	}
+	tag.SetNormalizeOptions(tag.NormalizeOptions{
+		StripControl: cfg.App.TagStripControl,
+		StripEmoji:   cfg.App.TagStripEmoji,
+	})
 	loc, err := time.LoadLocation(cfg.App.TimeZone)
 	if err != nil {
 		return nil, nil, nil, func() {}, false, fmt.Errorf("load timezone: %w", err)
@@ -415,6 +655,56 @@ func connect(ctx context.Context) (*config.Config, *slog.Logger, *cache.Cache, f
 	return cfg, log, redisClient, closeAll, sentryEnabled, nil
 }
 
+// connectDatabase loads the configuration and opens PostgreSQL for commands that do not need Redis.
+func connectDatabase(ctx context.Context) (*config.Config, *slog.Logger, *database.DB, func(), bool, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, nil, func() {}, false, fmt.Errorf("load config: %w", err)
+	}
+	loc, err := time.LoadLocation(cfg.App.TimeZone)
+	if err != nil {
+		return nil, nil, nil, func() {}, false, fmt.Errorf("load timezone: %w", err)
+	}
+	time.Local = loc
+
+	sentryEnabled, err := telemetry.InitSentry(cfg.Sentry)
+	if err != nil {
+		return nil, nil, nil, func() {}, false, fmt.Errorf("init sentry: %w", err)
+	}
+
+	log := logger.New(logger.Config{
+		Level:  logger.Level(cfg.App.LogLevel),
+		Format: logger.Format(cfg.App.LogFormat),
+	})
+	if sentryEnabled {
+		log = logger.WrapWithSentry(log)
+	}
+	logger.SetDefault(log)
+
+	db, err := database.New(ctx, database.Config{
+		ConnectionString: cfg.Database.ConnectionString(),
+		MaxConns:         cfg.Database.MaxConns,
+		MinConns:         cfg.Database.MinConns,
+		MaxConnLifetime:  cfg.Database.MaxConnLifetime,
+		MaxConnIdleTime:  cfg.Database.MaxConnIdleTime,
+		ConnectTimeout:   cfg.Database.ConnectTimeout,
+		TimeZone:         cfg.App.TimeZone,
+	}, log)
+	if err != nil {
+		if sentryEnabled {
+			telemetry.Flush(2 * time.Second)
+		}
+		return nil, nil, nil, func() {}, sentryEnabled, fmt.Errorf("connect database: %w", err)
+	}
+	closeAll := func() {
+		if sentryEnabled {
+			telemetry.Flush(2 * time.Second)
+		}
+		db.Close()
+	}
+	return cfg, log, db, closeAll, sentryEnabled, nil
+}
+
 func splitCSV(value string) []string {
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDeleter struct {
+	deleted int64
+	err     error
+	pattern string
+}
+
+func (f *fakeDeleter) DeleteByPattern(ctx context.Context, pattern string, batchSize int64) (int64, error) {
+	f.pattern = pattern
+	return f.deleted, f.err
+}
+
+type fakeAuditStore struct {
+	records []auditRecord
+}
+
+func (s *fakeAuditStore) InsertAuditLog(ctx context.Context, rec auditRecord) error {
+	s.records = append(s.records, rec)
+	return nil
+}
+
+// fakeTx implements the subset of pgx.Tx used by rebuildArchiveCounts.
+type fakeTx struct {
+	pgx.Tx
+	execs     []string
+	committed bool
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tx.execs = append(tx.execs, sql)
+	switch {
+	case strings.Contains(sql, "pg_advisory_xact_lock"):
+		return pgconn.NewCommandTag("SELECT 1"), nil
+	case strings.HasPrefix(sql, "TRUNCATE"):
+		return pgconn.NewCommandTag("TRUNCATE TABLE"), nil
+	}
+	return pgconn.NewCommandTag("INSERT 0 42"), nil
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	return nil
+}
+
+type fakeBeginner struct {
+	tx *fakeTx
+}
+
+func (b *fakeBeginner) Begin(ctx context.Context) (pgx.Tx, error) {
+	return b.tx, nil
+}
+
+func newTestAuditor(buf *bytes.Buffer, store auditStore) *auditor {
+	a := newAuditor(slog.New(slog.NewJSONHandler(buf, nil)), store)
+	a.actor = "tester"
+	a.host = "test-host"
+	return a
+}
+
+func decodeAuditLog(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var out map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var rec map[string]any
+		require.NoError(t, json.Unmarshal(line, &rec))
+		if rec["msg"] == "admin audit" {
+			out = rec
+		}
+	}
+	require.NotNil(t, out, "audit log line not found")
+	return out
+}
+
+func TestPurgeCacheEmitsAuditRecord(t *testing.T) {
+	var buf bytes.Buffer
+	store := &fakeAuditStore{}
+	deleter := &fakeDeleter{deleted: 7}
+
+	deleted, err := purgeCache(context.Background(), deleter, newTestAuditor(&buf, store), "hateblog:entries:*", 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), deleted)
+
+	require.Len(t, store.records, 1)
+	rec := store.records[0]
+	require.Equal(t, "cache.purge", rec.Operation)
+	require.Equal(t, "hateblog:entries:*", rec.Target)
+	require.Equal(t, int64(7), rec.Affected)
+	require.Equal(t, "tester", rec.Actor)
+	require.Equal(t, "test-host", rec.Host)
+	require.NoError(t, rec.Err)
+
+	logged := decodeAuditLog(t, &buf)
+	require.Equal(t, "INFO", logged["level"])
+	require.Equal(t, "cache.purge", logged["operation"])
+	require.Equal(t, "hateblog:entries:*", logged["target"])
+	require.EqualValues(t, 7, logged["affected"])
+	require.Equal(t, "tester", logged["actor"])
+}
+
+func TestPurgeCacheAuditsInterruption(t *testing.T) {
+	var buf bytes.Buffer
+	store := &fakeAuditStore{}
+	deleter := &fakeDeleter{deleted: 42, err: context.Canceled}
+
+	deleted, err := purgeCache(context.Background(), deleter, newTestAuditor(&buf, store), "hateblog:entries:*", 100)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, int64(42), deleted)
+
+	require.Len(t, store.records, 1)
+	require.Equal(t, int64(42), store.records[0].Affected)
+	require.ErrorIs(t, store.records[0].Err, context.Canceled)
+}
+
+func TestPurgeCacheAuditsFailure(t *testing.T) {
+	var buf bytes.Buffer
+	store := &fakeAuditStore{}
+	deleter := &fakeDeleter{err: errors.New("redis down")}
+
+	_, err := purgeCache(context.Background(), deleter, newTestAuditor(&buf, store), "hateblog:tags:*", 100)
+	require.Error(t, err)
+
+	require.Len(t, store.records, 1)
+	require.EqualError(t, store.records[0].Err, "redis down")
+
+	logged := decodeAuditLog(t, &buf)
+	require.Equal(t, "ERROR", logged["level"])
+	require.Equal(t, "redis down", logged["error"])
+}
+
+func TestRebuildArchiveEmitsAuditRecord(t *testing.T) {
+	var buf bytes.Buffer
+	store := &fakeAuditStore{}
+	tx := &fakeTx{}
+
+	rows, err := rebuildArchive(context.Background(), &fakeBeginner{tx: tx}, newTestAuditor(&buf, store))
+	require.NoError(t, err)
+	require.Equal(t, int64(42), rows)
+	require.True(t, tx.committed)
+	require.Len(t, tx.execs, 3)
+	require.Contains(t, tx.execs[0], "pg_advisory_xact_lock", "the rebuild must serialize with other archive writers")
+
+	require.Len(t, store.records, 1)
+	rec := store.records[0]
+	require.Equal(t, "archive.rebuild", rec.Operation)
+	require.Equal(t, "archive_counts", rec.Target)
+	require.Equal(t, int64(42), rec.Affected)
+
+	logged := decodeAuditLog(t, &buf)
+	require.Equal(t, "archive.rebuild", logged["operation"])
+	require.EqualValues(t, 42, logged["affected"])
+}
+
+func TestAuditActorFromEnv(t *testing.T) {
+	t.Setenv("SUDO_USER", "")
+	t.Setenv("USER", "alice")
+	require.Equal(t, "alice", auditActorFromEnv())
+
+	t.Setenv("SUDO_USER", "bob")
+	require.Equal(t, "bob", auditActorFromEnv())
+}
+
+func TestWarmupTargetsExpandToday(t *testing.T) {
+	now := time.Date(2025, 12, 30, 9, 0, 0, 0, time.UTC)
+
+	t.Run("adds today's date and current rankings", func(t *testing.T) {
+		targets := warmupTargets{MinUsers: splitCSVInts("5,10,50,100,500,1000")}
+		targets.expandToday(now)
+
+		require.Equal(t, []string{"20251230"}, targets.Dates)
+		require.Equal(t, []int{2025}, targets.Yearly)
+		require.Equal(t, []string{"2025-12"}, targets.Monthly)
+		// 2025-12-30 belongs to ISO week 1 of 2026.
+		require.Equal(t, []string{"2026-01"}, targets.Weekly)
+		require.Equal(t, []int{5, 10, 50, 100, 500, 1000}, targets.MinUsers)
+	})
+
+	t.Run("keeps explicit targets without duplicates", func(t *testing.T) {
+		targets := warmupTargets{
+			Dates:   []string{"20251229", "20251230"},
+			Yearly:  []int{2024, 2025},
+			Monthly: []string{"2025-12"},
+		}
+		targets.expandToday(now)
+
+		require.Equal(t, []string{"20251229", "20251230"}, targets.Dates)
+		require.Equal(t, []int{2024, 2025}, targets.Yearly)
+		require.Equal(t, []string{"2025-12"}, targets.Monthly)
+		require.Equal(t, []string{"2026-01"}, targets.Weekly)
+	})
+}
+
+func TestRunCacheWarmupRequiresDatesOrToday(t *testing.T) {
+	err := runCacheWarmup(context.Background(), []string{"--yes"})
+	require.EqualError(t, err, "--dates or --today is required")
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	infraPostgres "hateblog/internal/infra/postgres"
+	"hateblog/internal/platform/telemetry"
+)
+
+// reindexFieldNames are the values accepted by reindex entries --fields.
+var reindexFieldNames = []string{"host", "search_text"}
+
+// entryReindexer walks all entries in ID order and recomputes their derived columns.
+type entryReindexer interface {
+	CountAll(ctx context.Context) (int64, error)
+	ReindexBatch(ctx context.Context, after uuid.UUID, limit int, fields infraPostgres.ReindexFields) (infraPostgres.ReindexResult, error)
+}
+
+// reindexOptions controls a reindex entries run.
+type reindexOptions struct {
+	Fields    infraPostgres.ReindexFields
+	BatchSize int
+}
+
+// reindexResult tallies a reindex entries run.
+type reindexResult struct {
+	Scanned         int64
+	HostFixed       int64
+	SearchTextFixed int64
+}
+
+func runReindex(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		printUsage()
+		return fmt.Errorf("missing reindex subcommand")
+	}
+	switch args[0] {
+	case "entries":
+		return runReindexEntries(ctx, args[1:])
+	default:
+		printUsage()
+		return fmt.Errorf("unknown reindex subcommand: %s", args[0])
+	}
+}
+
+func runReindexEntries(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("reindex entries", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fieldsFlag := fs.String("fields", strings.Join(reindexFieldNames, ","), "comma-separated derived columns to recompute: host, search_text")
+	batchSize := fs.Int("batch-size", 1000, "number of entries read per transaction")
+	yes := fs.Bool("yes", false, "required confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*yes {
+		return fmt.Errorf("--yes is required")
+	}
+	fields, err := parseReindexFields(*fieldsFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --fields: %w", err)
+	}
+	if *batchSize <= 0 {
+		return fmt.Errorf("--batch-size must be positive")
+	}
+
+	cfg, log, db, closeAll, sentryEnabled, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+	if sentryEnabled {
+		defer telemetry.Recover()
+	}
+
+	store := infraPostgres.NewEntryRepository(db.Pool)
+	opts := reindexOptions{Fields: fields, BatchSize: *batchSize}
+	audit := newAuditor(log, auditStoreFor(cfg, db.Pool))
+	var res reindexResult
+	_, err = audit.run(ctx, "entries.reindex", "fields="+*fieldsFlag, func(ctx context.Context) (int64, error) {
+		var err error
+		res, err = reindexEntries(ctx, store, log, opts)
+		return res.HostFixed + res.SearchTextFixed, err
+	})
+	if err != nil {
+		return fmt.Errorf("reindex entries: %w", err)
+	}
+	log.Info("reindex entries completed", "scanned", res.Scanned, "host_fixed", res.HostFixed, "search_text_fixed", res.SearchTextFixed)
+	return nil
+}
+
+// parseReindexFields parses a comma-separated list of reindexFieldNames.
+func parseReindexFields(s string) (infraPostgres.ReindexFields, error) {
+	var fields infraPostgres.ReindexFields
+	for _, name := range strings.Split(s, ",") {
+		switch strings.TrimSpace(name) {
+		case "host":
+			fields.Host = true
+		case "search_text":
+			fields.SearchText = true
+		case "":
+		default:
+			return fields, fmt.Errorf("unknown field %q", strings.TrimSpace(name))
+		}
+	}
+	if !fields.Host && !fields.SearchText {
+		return fields, fmt.Errorf("at least one field is required")
+	}
+	return fields, nil
+}
+
+// reindexEntries walks all entries once in batches, recomputing the selected columns, and logs
+// progress with an ETA after each batch.
+func reindexEntries(ctx context.Context, store entryReindexer, log *slog.Logger, opts reindexOptions) (reindexResult, error) {
+	total, err := store.CountAll(ctx)
+	if err != nil {
+		return reindexResult{}, err
+	}
+	var (
+		res   reindexResult
+		after uuid.UUID
+		start = time.Now()
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		batch, err := store.ReindexBatch(ctx, after, opts.BatchSize, opts.Fields)
+		if err != nil {
+			return res, err
+		}
+		res.Scanned += int64(batch.Scanned)
+		res.HostFixed += batch.HostFixed
+		res.SearchTextFixed += batch.SearchTextFixed
+		after = batch.Last
+		if batch.Scanned > 0 {
+			log.Info("reindex entries progress",
+				"scanned", res.Scanned,
+				"total", total,
+				"host_fixed", res.HostFixed,
+				"search_text_fixed", res.SearchTextFixed,
+				"eta", reindexETA(time.Since(start), res.Scanned, total).String())
+		}
+		if batch.Scanned < opts.BatchSize {
+			return res, nil
+		}
+	}
+}
+
+// reindexETA extrapolates the remaining time from the average time per entry so far. Entries
+// added since the count may push done past total; the ETA is then zero.
+func reindexETA(elapsed time.Duration, done, total int64) time.Duration {
+	if done <= 0 || done >= total {
+		return 0
+	}
+	return (elapsed / time.Duration(done) * time.Duration(total-done)).Round(time.Second)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	infraPostgres "hateblog/internal/infra/postgres"
+)
+
+type fakeEntryReindexer struct {
+	ids    []uuid.UUID
+	stale  map[uuid.UUID]bool
+	fields []infraPostgres.ReindexFields
+}
+
+func (s *fakeEntryReindexer) CountAll(ctx context.Context) (int64, error) {
+	return int64(len(s.ids)), nil
+}
+
+func (s *fakeEntryReindexer) ReindexBatch(ctx context.Context, after uuid.UUID, limit int, fields infraPostgres.ReindexFields) (infraPostgres.ReindexResult, error) {
+	s.fields = append(s.fields, fields)
+	var res infraPostgres.ReindexResult
+	for _, id := range s.ids {
+		if id.String() <= after.String() || res.Scanned >= limit {
+			continue
+		}
+		res.Scanned++
+		res.Last = id
+		if s.stale[id] {
+			if fields.Host {
+				res.HostFixed++
+			}
+			if fields.SearchText {
+				res.SearchTextFixed++
+			}
+			delete(s.stale, id)
+		}
+	}
+	return res, nil
+}
+
+func TestReindexEntries(t *testing.T) {
+	ids := []uuid.UUID{
+		uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+		uuid.MustParse("00000000-0000-0000-0000-000000000003"),
+		uuid.MustParse("00000000-0000-0000-0000-000000000004"),
+	}
+	store := &fakeEntryReindexer{ids: ids, stale: map[uuid.UUID]bool{ids[1]: true, ids[3]: true}}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	fields := infraPostgres.ReindexFields{Host: true, SearchText: true}
+
+	res, err := reindexEntries(context.Background(), store, log, reindexOptions{Fields: fields, BatchSize: 2})
+	require.NoError(t, err)
+	require.Equal(t, reindexResult{Scanned: 4, HostFixed: 2, SearchTextFixed: 2}, res)
+	// Both columns are recomputed in the same single walk: two full batches and a short one.
+	require.Len(t, store.fields, 3)
+	for _, f := range store.fields {
+		require.Equal(t, fields, f)
+	}
+}
+
+func TestParseReindexFields(t *testing.T) {
+	fields, err := parseReindexFields("host,search_text")
+	require.NoError(t, err)
+	require.Equal(t, infraPostgres.ReindexFields{Host: true, SearchText: true}, fields)
+
+	fields, err = parseReindexFields(" search_text ")
+	require.NoError(t, err)
+	require.Equal(t, infraPostgres.ReindexFields{SearchText: true}, fields)
+
+	_, err = parseReindexFields("host,title")
+	require.Error(t, err)
+	_, err = parseReindexFields("")
+	require.Error(t, err)
+}
+
+func TestReindexETA(t *testing.T) {
+	require.Equal(t, 30*time.Second, reindexETA(10*time.Second, 25, 100))
+	require.Zero(t, reindexETA(10*time.Second, 0, 100))
+	require.Zero(t, reindexETA(10*time.Second, 120, 100))
+}
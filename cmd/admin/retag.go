@@ -0,0 +1,387 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hateblog/internal/domain/tag"
+	"hateblog/internal/infra/external/keyphrase"
+	"hateblog/internal/pkg/apptime"
+	"hateblog/internal/pkg/batchutil"
+	"hateblog/internal/platform/database"
+	"hateblog/internal/platform/telemetry"
+)
+
+// retagEntry is an entry selected for re-tagging.
+type retagEntry struct {
+	ID        uuid.UUID
+	Title     string
+	Excerpt   string
+	CreatedAt time.Time
+}
+
+// retagStore reads candidate entries and replaces their tags.
+type retagStore interface {
+	ListRetagEntries(ctx context.Context, from time.Time, after uuid.UUID, limit int) ([]retagEntry, error)
+	EntryTagNames(ctx context.Context, entryID uuid.UUID) ([]string, error)
+	ReplaceEntryTags(ctx context.Context, entryID uuid.UUID, tags []tag.ScoredTag) error
+}
+
+// retagOptions controls a retag run.
+type retagOptions struct {
+	From     time.Time
+	After    uuid.UUID
+	Limit    int
+	DryRun   bool
+	Interval time.Duration
+}
+
+// retagResult summarizes a retag run.
+type retagResult struct {
+	Processed int
+	Changed   int
+	Abnormal  int
+	// LastID is the last entry that was fully processed; pass it to --after to resume.
+	LastID uuid.UUID
+	// Stopped is set when the run ended early because of a rate limit.
+	Stopped bool
+}
+
+type retagger struct {
+	store     retagStore
+	extractor tag.KeyphraseExtractor
+	// minScore drops phrases scoring below it, as TAG_MIN_SCORE does in the fetcher.
+	minScore int
+	log      *slog.Logger
+	sleep    func(time.Duration)
+}
+
+func (r *retagger) run(ctx context.Context, opts retagOptions) (retagResult, error) {
+	result := retagResult{LastID: opts.After}
+	entries, err := r.store.ListRetagEntries(ctx, opts.From, opts.After, opts.Limit)
+	if err != nil {
+		return result, fmt.Errorf("list entries: %w", err)
+	}
+
+	for i, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if i > 0 && opts.Interval > 0 {
+			r.sleep(opts.Interval)
+		}
+
+		input := strings.TrimSpace(strings.Join([]string{e.Title, e.Excerpt}, "\n"))
+		phrases, err := r.extractor.Extract(ctx, input)
+		if err != nil {
+			if _, ok := r.extractor.IsTooManyRequests(err); ok {
+				r.log.Warn("retag stopped due to rate limit", "entry_id", e.ID, "err", err)
+				result.Stopped = true
+				return result, nil
+			}
+			return result, fmt.Errorf("extract keyphrases: %s: %w", e.ID, err)
+		}
+		tags, abnormal := buildRetagTags(phrases, r.minScore)
+		result.Abnormal += abnormal
+
+		current, err := r.store.EntryTagNames(ctx, e.ID)
+		if err != nil {
+			return result, fmt.Errorf("load tags: %s: %w", e.ID, err)
+		}
+		next := make([]string, 0, len(tags))
+		for _, t := range tags {
+			next = append(next, t.Name)
+		}
+		sort.Strings(current)
+		sort.Strings(next)
+
+		if !slices.Equal(current, next) {
+			result.Changed++
+			r.log.Info("retag entry",
+				"entry_id", e.ID,
+				"title", e.Title,
+				"before", current,
+				"after", next,
+				"dry_run", opts.DryRun,
+			)
+			if !opts.DryRun {
+				if err := r.store.ReplaceEntryTags(ctx, e.ID, tags); err != nil {
+					return result, fmt.Errorf("replace tags: %s: %w", e.ID, err)
+				}
+			}
+		}
+		result.Processed++
+		result.LastID = e.ID
+	}
+	return result, nil
+}
+
+// buildRetagTags normalizes extracted keyphrases the same way the fetcher does.
+// It falls back to the placeholder tag when nothing usable was extracted.
+func buildRetagTags(phrases []tag.Keyphrase, minScore int) ([]tag.ScoredTag, int) {
+	tags, abnormal := tag.ScoreKeyphrases(phrases, minScore)
+	if len(tags) == 0 {
+		return []tag.ScoredTag{{Name: tag.NoKeyphraseTagName, Score: tag.NoKeyphraseTagScore}}, abnormal
+	}
+	return tags, abnormal
+}
+
+func runTag(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		printUsage()
+		return fmt.Errorf("missing tag subcommand")
+	}
+	switch args[0] {
+	case "retag":
+		return runTagRetag(ctx, args[1:])
+	default:
+		printUsage()
+		return fmt.Errorf("unknown tag subcommand: %s", args[0])
+	}
+}
+
+func runTagRetag(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tag retag", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	from := fs.String("from", "", "re-tag entries created on or after YYYYMMDD (required)")
+	after := fs.String("after", "", "resume after this entry id (printed by a previous run)")
+	limit := fs.Int("limit", 100, "maximum number of entries to process")
+	interval := fs.Duration("interval", 200*time.Millisecond, "minimum interval between keyphrase provider requests")
+	lockName := fs.String("lock", "fetcher", "advisory lock name (shared with the fetcher to avoid concurrent tagging)")
+	dryRun := fs.Bool("dry-run", false, "print tag changes without writing them")
+	yes := fs.Bool("yes", false, "required confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*yes && !*dryRun {
+		return fmt.Errorf("--yes is required")
+	}
+	if strings.TrimSpace(*from) == "" {
+		return fmt.Errorf("--from is required")
+	}
+	if *limit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+	var afterID uuid.UUID
+	if *after != "" {
+		id, err := uuid.Parse(*after)
+		if err != nil {
+			return fmt.Errorf("invalid --after: %w", err)
+		}
+		afterID = id
+	}
+
+	cfg, log, _, closeAll, sentryEnabled, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+	if sentryEnabled {
+		defer telemetry.Recover()
+	}
+	extractor, err := keyphrase.New(keyphrase.Config{
+		Provider:   cfg.External.TagExtractor,
+		YahooAppID: cfg.External.YahooAPIKey,
+		Stopwords:  cfg.External.TagExtractorStopwords,
+		Fallback:   cfg.External.TagExtractorFallback,
+	})
+	if err != nil {
+		return err
+	}
+	if extractor == nil {
+		return fmt.Errorf("keyphrase provider is not configured (TAG_EXTRACTOR=%s)", cfg.External.TagExtractor)
+	}
+
+	// --from is parsed after connect so that it follows APP_TIMEZONE.
+	fromDay, err := apptime.ParseDate(*from)
+	if err != nil {
+		return fmt.Errorf("invalid --from: %w", err)
+	}
+
+	db, err := database.New(ctx, database.Config{
+		ConnectionString: cfg.Database.ConnectionString(),
+		MaxConns:         cfg.Database.MaxConns,
+		MinConns:         cfg.Database.MinConns,
+		MaxConnLifetime:  cfg.Database.MaxConnLifetime,
+		MaxConnIdleTime:  cfg.Database.MaxConnIdleTime,
+		ConnectTimeout:   cfg.Database.ConnectTimeout,
+		TimeZone:         cfg.App.TimeZone,
+	}, log)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	locked, unlock, err := batchutil.TryAdvisoryLock(ctx, db.Pool, *lockName)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return fmt.Errorf("advisory lock %q is held by another process", *lockName)
+	}
+	defer func() {
+		unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if err := unlock(unlockCtx); err != nil {
+			log.Warn("unlock failed", "err", err)
+		}
+	}()
+
+	r := &retagger{
+		store:     &pgRetagStore{pool: db.Pool},
+		extractor: extractor,
+		minScore:  cfg.External.TagMinScore,
+		log:       log,
+		sleep:     time.Sleep,
+	}
+	opts := retagOptions{
+		From:     fromDay,
+		After:    afterID,
+		Limit:    *limit,
+		DryRun:   *dryRun,
+		Interval: *interval,
+	}
+
+	var result retagResult
+	if *dryRun {
+		result, err = r.run(ctx, opts)
+	} else {
+		audit := newAuditor(log, auditStoreFor(cfg, db.Pool))
+		_, err = audit.run(ctx, "tag.retag", "from="+*from, func(ctx context.Context) (int64, error) {
+			result, err = r.run(ctx, opts)
+			return int64(result.Changed), err
+		})
+	}
+	if result.LastID != uuid.Nil {
+		log.Info("retag resume point", "after", result.LastID.String())
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Info("tag retag completed",
+		"processed", result.Processed,
+		"changed", result.Changed,
+		"dry_run", *dryRun,
+		"stopped_by_rate_limit", result.Stopped,
+	)
+	if result.Abnormal > 0 {
+		return fmt.Errorf("abnormal scores detected from keyphrase provider: %d", result.Abnormal)
+	}
+	return nil
+}
+
+// pgRetagStore implements retagStore on Postgres.
+type pgRetagStore struct {
+	pool *pgxpool.Pool
+}
+
+// ListRetagEntries pages through entries in (created_at, id) order so that a run can resume after any entry.
+func (s *pgRetagStore) ListRetagEntries(ctx context.Context, from time.Time, after uuid.UUID, limit int) ([]retagEntry, error) {
+	const query = `
+SELECT e.id, e.title, e.excerpt, e.created_at
+FROM entries e
+WHERE e.created_at >= $1
+  AND ($2::uuid IS NULL OR (e.created_at, e.id) > (SELECT a.created_at, a.id FROM entries a WHERE a.id = $2))
+ORDER BY e.created_at, e.id
+LIMIT $3`
+
+	var afterArg *uuid.UUID
+	if after != uuid.Nil {
+		afterArg = &after
+	}
+	rows, err := s.pool.Query(ctx, query, from, afterArg, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]retagEntry, 0, limit)
+	for rows.Next() {
+		var e retagEntry
+		var excerpt *string
+		if err := rows.Scan(&e.ID, &e.Title, &excerpt, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if excerpt != nil {
+			e.Excerpt = *excerpt
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (s *pgRetagStore) EntryTagNames(ctx context.Context, entryID uuid.UUID) ([]string, error) {
+	const query = `
+SELECT t.name
+FROM entry_tags et
+INNER JOIN tags t ON t.id = et.tag_id
+WHERE et.entry_id = $1`
+
+	rows, err := s.pool.Query(ctx, query, entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// ReplaceEntryTags swaps the entry's tags in a single transaction.
+func (s *pgRetagStore) ReplaceEntryTags(ctx context.Context, entryID uuid.UUID, tags []tag.ScoredTag) (err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if _, err = tx.Exec(ctx, `DELETE FROM entry_tags WHERE entry_id = $1`, entryID); err != nil {
+		return err
+	}
+
+	const upsertTag = `
+INSERT INTO tags (id, name, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (name) DO UPDATE SET
+	name = EXCLUDED.name
+RETURNING id`
+	const insertEntryTag = `
+INSERT INTO entry_tags (entry_id, tag_id, score)
+VALUES ($1, $2, $3)
+ON CONFLICT (entry_id, tag_id) DO NOTHING`
+	now := apptime.Now()
+	for _, t := range tags {
+		var tagID uuid.UUID
+		if err = tx.QueryRow(ctx, upsertTag, uuid.New(), t.Name, now).Scan(&tagID); err != nil {
+			return fmt.Errorf("upsert tag: %w", err)
+		}
+		if _, err = tx.Exec(ctx, insertEntryTag, entryID, tagID, t.Score); err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit(ctx)
+	return err
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"hateblog/internal/domain/tag"
+)
+
+// errFakeRateLimited is the error fakeExtractor reports as a rate limit.
+var errFakeRateLimited = errors.New("fake: too many requests")
+
+type fakeExtractor struct {
+	results map[string][]tag.Keyphrase
+	errs    map[string]error
+	calls   []string
+}
+
+func (f *fakeExtractor) Extract(ctx context.Context, text string) ([]tag.Keyphrase, error) {
+	f.calls = append(f.calls, text)
+	if err := f.errs[text]; err != nil {
+		return nil, err
+	}
+	return f.results[text], nil
+}
+
+func (f *fakeExtractor) IsTooManyRequests(err error) (time.Duration, bool) {
+	return time.Second, errors.Is(err, errFakeRateLimited)
+}
+
+type fakeRetagStore struct {
+	entries  []retagEntry
+	tags     map[uuid.UUID][]string
+	replaced map[uuid.UUID][]tag.ScoredTag
+	after    uuid.UUID
+}
+
+func (s *fakeRetagStore) ListRetagEntries(ctx context.Context, from time.Time, after uuid.UUID, limit int) ([]retagEntry, error) {
+	s.after = after
+	out := s.entries
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *fakeRetagStore) EntryTagNames(ctx context.Context, entryID uuid.UUID) ([]string, error) {
+	return s.tags[entryID], nil
+}
+
+func (s *fakeRetagStore) ReplaceEntryTags(ctx context.Context, entryID uuid.UUID, tags []tag.ScoredTag) error {
+	if s.replaced == nil {
+		s.replaced = make(map[uuid.UUID][]tag.ScoredTag)
+	}
+	s.replaced[entryID] = tags
+	return nil
+}
+
+func newTestRetagger(store retagStore, extractor tag.KeyphraseExtractor) *retagger {
+	return &retagger{
+		store:     store,
+		extractor: extractor,
+		log:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		sleep:     func(time.Duration) {},
+	}
+}
+
+func TestRetaggerReplacesChangedTags(t *testing.T) {
+	unchanged := retagEntry{ID: uuid.New(), Title: "Go 1.25", Excerpt: "release"}
+	changed := retagEntry{ID: uuid.New(), Title: "Rust async"}
+	store := &fakeRetagStore{
+		entries: []retagEntry{unchanged, changed},
+		tags: map[uuid.UUID][]string{
+			unchanged.ID: {"go"},
+			changed.ID:   {"rust", "old"},
+		},
+	}
+	extractor := &fakeExtractor{results: map[string][]tag.Keyphrase{
+		"Go 1.25\nrelease": {{Text: "Go", Score: 100}},
+		"Rust async":       {{Text: "Async", Score: 40}, {Text: "Rust", Score: 90}},
+	}}
+
+	result, err := newTestRetagger(store, extractor).run(context.Background(), retagOptions{Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Processed)
+	require.Equal(t, 1, result.Changed)
+	require.Equal(t, changed.ID, result.LastID)
+	require.False(t, result.Stopped)
+
+	require.Len(t, store.replaced, 1)
+	require.Equal(t, []tag.ScoredTag{{Name: "rust", Score: 90}, {Name: "async", Score: 40}}, store.replaced[changed.ID])
+}
+
+func TestRetaggerDryRunDoesNotWrite(t *testing.T) {
+	e := retagEntry{ID: uuid.New(), Title: "Kubernetes"}
+	store := &fakeRetagStore{entries: []retagEntry{e}}
+	extractor := &fakeExtractor{results: map[string][]tag.Keyphrase{
+		"Kubernetes": {{Text: "Kubernetes", Score: 100}},
+	}}
+
+	result, err := newTestRetagger(store, extractor).run(context.Background(), retagOptions{Limit: 10, DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Changed)
+	require.Empty(t, store.replaced)
+}
+
+func TestRetaggerStopsOnRateLimit(t *testing.T) {
+	first := retagEntry{ID: uuid.New(), Title: "first"}
+	second := retagEntry{ID: uuid.New(), Title: "second"}
+	resumeFrom := uuid.New()
+	store := &fakeRetagStore{entries: []retagEntry{first, second}}
+	extractor := &fakeExtractor{
+		results: map[string][]tag.Keyphrase{"first": {{Text: "first", Score: 50}}},
+		errs:    map[string]error{"second": errFakeRateLimited},
+	}
+
+	result, err := newTestRetagger(store, extractor).run(context.Background(), retagOptions{After: resumeFrom, Limit: 10})
+	require.NoError(t, err)
+	require.True(t, result.Stopped)
+	require.Equal(t, 1, result.Processed)
+	require.Equal(t, first.ID, result.LastID)
+	require.Equal(t, resumeFrom, store.after)
+	require.Contains(t, store.replaced, first.ID)
+	require.NotContains(t, store.replaced, second.ID)
+}
+
+func TestRetaggerReturnsExtractError(t *testing.T) {
+	e := retagEntry{ID: uuid.New(), Title: "broken"}
+	store := &fakeRetagStore{entries: []retagEntry{e}}
+	extractor := &fakeExtractor{errs: map[string]error{"broken": errors.New("boom")}}
+
+	result, err := newTestRetagger(store, extractor).run(context.Background(), retagOptions{Limit: 10})
+	require.Error(t, err)
+	require.Equal(t, 0, result.Processed)
+	require.Equal(t, uuid.Nil, result.LastID)
+}
+
+func TestBuildRetagTags(t *testing.T) {
+	t.Run("drops phrases below the minimum score", func(t *testing.T) {
+		tags, _ := buildRetagTags([]tag.Keyphrase{
+			{Text: "Go", Score: 80},
+			{Text: "noise", Score: 10},
+		}, 50)
+		require.Equal(t, []tag.ScoredTag{{Name: "go", Score: 80}}, tags)
+
+		tags, _ = buildRetagTags([]tag.Keyphrase{{Text: "noise", Score: 10}}, 50)
+		require.Equal(t, []tag.ScoredTag{{Name: tag.NoKeyphraseTagName, Score: tag.NoKeyphraseTagScore}}, tags)
+	})
+
+	t.Run("falls back to dummy tag", func(t *testing.T) {
+		tags, abnormal := buildRetagTags(nil, 0)
+		require.Zero(t, abnormal)
+		require.Equal(t, []tag.ScoredTag{{Name: tag.NoKeyphraseTagName, Score: tag.NoKeyphraseTagScore}}, tags)
+	})
+}
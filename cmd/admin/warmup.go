@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	domainEntry "hateblog/internal/domain/entry"
+)
+
+// warmupJob warms one independent cache target (a date, a tag, a ranking period, ...) and
+// returns the entries it loaded, for favicon prewarming.
+type warmupJob func(ctx context.Context) ([]*domainEntry.Entry, error)
+
+// warmupRun is the outcome of runWarmupJobs. Entries holds each job's entries in job order,
+// so that what is collected from them does not depend on scheduling.
+type warmupRun struct {
+	Entries   [][]*domainEntry.Entry
+	Completed int
+	Failed    int
+	// Skipped counts the jobs never started because ctx was cancelled.
+	Skipped int
+	Err     error
+}
+
+// runWarmupJobs runs every job with at most concurrency of them at a time (values below 1
+// mean one). A failing job does not stop the others; the errors are joined in Err. Once ctx
+// is cancelled no further jobs are started, the running ones are waited for, and Err
+// includes ctx.Err().
+func runWarmupJobs(ctx context.Context, jobs []warmupJob, concurrency int) warmupRun {
+	concurrency = max(concurrency, 1)
+	entries := make([][]*domainEntry.Entry, len(jobs))
+	errs := make([]error, len(jobs))
+	started := make([]bool, len(jobs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+dispatch:
+	for i, job := range jobs {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case sem <- struct{}{}:
+		}
+		// Both cases may be ready; do not start more work after cancellation.
+		if ctx.Err() != nil {
+			<-sem
+			break dispatch
+		}
+		started[i] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			entries[i], errs[i] = job(ctx)
+		}()
+	}
+	wg.Wait()
+
+	res := warmupRun{Entries: entries}
+	joined := make([]error, 0)
+	for i, err := range errs {
+		switch {
+		case !started[i]:
+			res.Skipped++
+		case err != nil:
+			res.Failed++
+			joined = append(joined, err)
+		default:
+			res.Completed++
+		}
+	}
+	if res.Skipped > 0 {
+		joined = append(joined, ctx.Err())
+	}
+	res.Err = errors.Join(joined...)
+	return res
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domainEntry "hateblog/internal/domain/entry"
+)
+
+func TestRunWarmupJobsConcurrently(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		warmed  []int
+		running atomic.Int32
+		peak    atomic.Int32
+	)
+	errFailed := errors.New("redis down")
+	jobs := make([]warmupJob, 10)
+	for i := range jobs {
+		jobs[i] = func(ctx context.Context) ([]*domainEntry.Entry, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			warmed = append(warmed, i)
+			mu.Unlock()
+			if i%4 == 1 {
+				return nil, fmt.Errorf("warm day entries: %d: %w", i, errFailed)
+			}
+			return []*domainEntry.Entry{{URL: fmt.Sprintf("https://example.com/%d", i)}}, nil
+		}
+	}
+
+	run := runWarmupJobs(context.Background(), jobs, 3)
+	require.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, warmed)
+	require.LessOrEqual(t, peak.Load(), int32(3))
+	require.Greater(t, peak.Load(), int32(1))
+
+	require.Equal(t, 3, run.Failed)
+	require.ErrorIs(t, run.Err, errFailed)
+	for _, i := range []int{1, 5, 9} {
+		require.ErrorContains(t, run.Err, fmt.Sprintf("warm day entries: %d:", i))
+	}
+
+	// Entries stay in job order whatever order the jobs finished in.
+	require.Len(t, run.Entries, len(jobs))
+	for i, entries := range run.Entries {
+		if i%4 == 1 {
+			require.Empty(t, entries)
+			continue
+		}
+		require.Equal(t, fmt.Sprintf("https://example.com/%d", i), entries[0].URL)
+	}
+}
+
+func TestRunWarmupJobsSequential(t *testing.T) {
+	var order []int
+	jobs := make([]warmupJob, 4)
+	for i := range jobs {
+		jobs[i] = func(ctx context.Context) ([]*domainEntry.Entry, error) {
+			order = append(order, i)
+			return nil, nil
+		}
+	}
+
+	run := runWarmupJobs(context.Background(), jobs, 0)
+	require.NoError(t, run.Err)
+	require.Zero(t, run.Failed)
+	require.Equal(t, []int{0, 1, 2, 3}, order)
+}
+
+func TestRunWarmupJobsStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls atomic.Int32
+	jobs := make([]warmupJob, 10)
+	for i := range jobs {
+		jobs[i] = func(ctx context.Context) ([]*domainEntry.Entry, error) {
+			calls.Add(1)
+			if i == 3 {
+				// Interrupted while the fourth target is being warmed.
+				cancel()
+			}
+			return nil, nil
+		}
+	}
+
+	run := runWarmupJobs(ctx, jobs, 1)
+	require.Equal(t, int32(4), calls.Load())
+	require.Equal(t, 4, run.Completed)
+	require.Zero(t, run.Failed)
+	require.Equal(t, 6, run.Skipped)
+	require.ErrorIs(t, run.Err, context.Canceled)
+}
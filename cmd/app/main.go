@@ -13,6 +13,8 @@ import (
 	sentryhttp "github.com/getsentry/sentry-go/http"
 	"github.com/lib/pq"
 
+	domainEntry "hateblog/internal/domain/entry"
+	domainTag "hateblog/internal/domain/tag"
 	infraGoogle "hateblog/internal/infra/external/google"
 	"hateblog/internal/infra/handler"
 	infraPostgres "hateblog/internal/infra/postgres"
@@ -20,6 +22,7 @@ import (
 	"hateblog/internal/platform/cache"
 	"hateblog/internal/platform/config"
 	"hateblog/internal/platform/database"
+	"hateblog/internal/platform/lifecycle"
 	"hateblog/internal/platform/logger"
 	"hateblog/internal/platform/metrics"
 	"hateblog/internal/platform/migration"
@@ -32,6 +35,7 @@ import (
 	usecaseMetrics "hateblog/internal/usecase/metrics"
 	usecaseRanking "hateblog/internal/usecase/ranking"
 	usecaseSearch "hateblog/internal/usecase/search"
+	usecaseSource "hateblog/internal/usecase/source"
 	usecaseTag "hateblog/internal/usecase/tag"
 )
 
@@ -287,6 +291,10 @@ func run(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
+	domainTag.SetNormalizeOptions(domainTag.NormalizeOptions{
+		StripControl: cfg.App.TagStripControl,
+		StripEmoji:   cfg.App.TagStripEmoji,
+	})
 
 	sentryEnabled, err := telemetry.InitSentry(cfg.Sentry)
 	if err != nil {
@@ -339,7 +347,14 @@ func run(ctx context.Context) error {
 		}
 	}()
 
-	entryRepo := infraPostgres.NewEntryRepository(db.Pool)
+	// Background workers are registered here and stopped after the HTTP server has drained,
+	// before the deferred Redis and database closes run.
+	workers := lifecycle.NewGroup(log)
+
+	hotTiebreak := domainEntry.HotTiebreak(cfg.App.HotTiebreak)
+	entryRepo := infraPostgres.NewEntryRepository(db.Pool).
+		WithExcludedHosts(cfg.App.ExcludedDomains).
+		WithHotTiebreak(hotTiebreak)
 	tagRepo := infraPostgres.NewTagRepository(db.Pool)
 	searchHistoryRepo := infraPostgres.NewSearchHistoryRepository(db.Pool)
 	clickMetricsRepo := infraPostgres.NewClickMetricsRepository(db.Pool)
@@ -354,26 +369,55 @@ func run(ctx context.Context) error {
 		monthlyRankingCache usecaseRanking.CacheMonthly
 		weeklyRankingCache  usecaseRanking.CacheWeekly
 		faviconCache        usecaseFavicon.Cache
+		metricsSummaryCache usecaseMetrics.SummaryCache
+		engagementCache     usecaseRanking.CacheEngagement
 	)
 
 	if cfg.App.CacheEnabled {
-		dayEntriesCache = infraRedis.NewDayEntriesCache(redisClient, cfg.Cache.EntriesDayTTL)
-		tagEntriesCache = infraRedis.NewTagEntriesCache(redisClient, cfg.Cache.TagEntriesTTL)
-		searchCache = infraRedis.NewSearchCache(redisClient, cfg.Cache.SearchTTL)
-		tagsListCache = infraRedis.NewTagsListCache(redisClient, cfg.Cache.TagsListTTL)
-		archiveCache = infraRedis.NewArchiveCache(redisClient, cfg.Cache.EntriesDayTTL, cfg.Cache.ArchiveTTL)
-		yearlyRankingCache = infraRedis.NewYearlyRankingCache(redisClient, cfg.Cache.YearlyRankingCurrentTTL, cfg.Cache.YearlyRankingPastTTL)
-		monthlyRankingCache = infraRedis.NewMonthlyRankingCache(redisClient, cfg.Cache.MonthlyRankingCurrentTTL, cfg.Cache.MonthlyRankingPastTTL)
-		weeklyRankingCache = infraRedis.NewWeeklyRankingCache(redisClient, cfg.Cache.WeeklyRankingCurrentTTL, cfg.Cache.WeeklyRankingPastTTL)
+		apiCacheClient := infraRedis.NewSetRetryClient(redisClient, cfg.Cache.SetMaxAttempts, cfg.Cache.SetRetryBackoff)
+		dayEntriesCache = infraRedis.NewDayEntriesCache(apiCacheClient, cfg.Cache.EntriesDayTTL)
+		tagEntriesCache = infraRedis.NewTagEntriesCache(apiCacheClient, cfg.Cache.TagEntriesTTL)
+		searchCache = infraRedis.NewSearchCache(apiCacheClient, cfg.Cache.SearchTTL)
+		tagsListCache = infraRedis.NewTagsListCache(apiCacheClient, cfg.Cache.TagsListTTL)
+		archiveCache = infraRedis.NewArchiveCache(apiCacheClient, cfg.Cache.EntriesDayTTL, cfg.Cache.ArchiveTTL)
+		yearlyRankingCache = infraRedis.NewYearlyRankingCache(apiCacheClient, cfg.Cache.YearlyRankingCurrentTTL, cfg.Cache.YearlyRankingPastTTL)
+		monthlyRankingCache = infraRedis.NewMonthlyRankingCache(apiCacheClient, cfg.Cache.MonthlyRankingCurrentTTL, cfg.Cache.MonthlyRankingPastTTL)
+		weeklyRankingCache = infraRedis.NewWeeklyRankingCache(apiCacheClient, cfg.Cache.WeeklyRankingCurrentTTL, cfg.Cache.WeeklyRankingPastTTL)
 		faviconCache = infraRedis.NewFaviconCache(redisClient, cfg.Cache.FaviconTTL)
+		metricsSummaryCache = infraRedis.NewMetricsSummaryCache(apiCacheClient, cfg.Cache.MetricsSummaryTTL)
+		engagementCache = infraRedis.NewEngagementRankingCache(apiCacheClient, cfg.Cache.EngagementRankingTTL)
 	}
 
-	entryService := usecaseEntry.NewService(entryRepo, dayEntriesCache, tagEntriesCache, log)
+	entryService := usecaseEntry.NewService(entryRepo, dayEntriesCache, tagEntriesCache, log).
+		WithTagLinker(tagRepo).
+		WithMaxTags(cfg.App.MaxTagsPerRequest).
+		WithHotTiebreak(hotTiebreak).
+		WithHotMinAge(cfg.App.HotMinAge).
+		WithMaxDayEntries(cfg.App.MaxDayEntries).
+		WithMinResults(cfg.App.MinResults)
 	archiveService := usecaseArchive.NewService(entryRepo, archiveCache)
-	rankingService := usecaseRanking.NewService(entryRepo, yearlyRankingCache, monthlyRankingCache, weeklyRankingCache)
-	tagService := usecaseTag.NewService(tagRepo, tagsListCache)
-	searchService := usecaseSearch.NewService(entryRepo, searchHistoryRepo, searchCache, log)
-	metricsService := usecaseMetrics.NewService(entryRepo, clickMetricsRepo)
+	rankingService := usecaseRanking.NewService(entryRepo, yearlyRankingCache, monthlyRankingCache, weeklyRankingCache).
+		WithMinUsersFloor(cfg.App.RankingMinUsersFloor)
+	if cfg.App.RankingEngagementEnabled {
+		rankingService.WithEngagement(clickMetricsRepo, usecaseRanking.Weights{
+			Bookmarks: cfg.App.RankingBookmarkWeight,
+			Clicks:    cfg.App.RankingClickWeight,
+		}, engagementCache)
+	}
+	tagService := usecaseTag.NewService(tagRepo, tagsListCache).WithMaxListLimit(cfg.App.TagListMaxLimit)
+	searchService := usecaseSearch.NewService(entryRepo, searchHistoryRepo, searchCache, log).
+		WithMinResults(cfg.App.MinResults).
+		WithMinTermLength(cfg.App.SearchMinTermLength).
+		WithMaxTerms(cfg.App.SearchMaxTerms, cfg.App.SearchTruncateTerms).
+		WithHistoryList(searchHistoryRepo).
+		WithHistoryPrivacy(usecaseSearch.HistoryPrivacy{
+			Hash:      cfg.App.SearchHistoryHash,
+			Salt:      cfg.App.SearchHistorySalt,
+			MaxLength: cfg.App.SearchHistoryMaxLength,
+		})
+	metricsService := usecaseMetrics.NewService(entryRepo, clickMetricsRepo).
+		WithSummary(clickMetricsRepo, searchHistoryRepo, metricsSummaryCache)
+	sourceService := usecaseSource.NewService(entryRepo)
 
 	// API Key service
 	apiKeyRepo := infraRedis.NewAPIKeyRepository(redisClient)
@@ -400,12 +444,42 @@ func run(ctx context.Context) error {
 	})
 	faviconService := usecaseFavicon.NewService(googleClient, faviconCache, faviconLimiter, log)
 
-	entryHandler := handler.NewEntryHandler(entryService, apiBasePath)
+	// One middleware instance so that all protected routes share the key cache.
+	apiKeyAuth := server.APIKeyAuthWithConfig(server.APIKeyAuthConfig{
+		MasterKey: cfg.App.MasterAPIKey,
+		Keys:      apiKeyRepo,
+		CacheTTL:  cfg.App.APIKeyCacheTTL,
+		Logger:    log,
+	})
+
+	// Curation endpoints and the metrics summary always need an API key. When
+	// APP_API_KEY_REQUIRED is on, the global middleware below already checks it.
+	curationAuth := apiKeyAuth
+	if cfg.App.APIKeyRequired {
+		curationAuth = func(next http.Handler) http.Handler { return next }
+	}
+	entryHandler := handler.NewEntryHandler(entryService, apiBasePath).
+		WithFeedBaseURL(cfg.App.FeedBaseURL).
+		WithCurationAuth(curationAuth).
+		WithMaxOffset(cfg.App.MaxOffset).
+		WithMaxTags(cfg.App.MaxTagsPerRequest).
+		WithTrendingSort(cfg.Feature(config.FeatureTrending))
 	archiveHandler := handler.NewArchiveHandler(archiveService)
-	rankingHandler := handler.NewRankingHandler(rankingService, apiBasePath)
-	tagHandler := handler.NewTagHandler(tagService, entryService, apiBasePath)
-	searchHandler := handler.NewSearchHandler(searchService, apiBasePath)
-	metricsHandler := handler.NewMetricsHandler(metricsService)
+	rankingHandler := handler.NewRankingHandler(rankingService, apiBasePath).WithMaxOffset(cfg.App.MaxOffset)
+	tagHandler := handler.NewTagHandler(tagService, entryService, apiBasePath).
+		WithFeedBaseURL(cfg.App.FeedBaseURL).
+		WithMaxOffset(cfg.App.MaxOffset)
+	searchHandler := handler.NewSearchHandler(searchService, apiBasePath).
+		WithMaxOffset(cfg.App.MaxOffset).
+		WithWordBoundaryDefault(cfg.App.SearchWordBoundary)
+	if cfg.App.MasterAPIKey != "" {
+		// Admin endpoints accept only the master key, not stored keys.
+		masterAuth := server.APIKeyAuth(cfg.App.MasterAPIKey, log)
+		searchHandler.WithHistoryAuth(masterAuth)
+		entryHandler.WithAdminAuth(masterAuth)
+	}
+	metricsHandler := handler.NewMetricsHandler(metricsService).WithSummaryAuth(curationAuth)
+	sourceHandler := handler.NewSourceHandler(sourceService)
 	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService, cfg.App.APIKeyTTL)
 	faviconHandler := handler.NewFaviconHandler(faviconService)
 	healthHandler := &handler.HealthHandler{
@@ -423,40 +497,63 @@ func run(ctx context.Context) error {
 			return sentryHandler.Handle(next)
 		})
 	}
+	if len(cfg.App.CORSAllowedOrigins) > 0 || len(cfg.App.CORSCredentialedOrigins) > 0 {
+		middlewares = append(middlewares, server.CORSWithConfig(server.CORSConfig{
+			AllowedOrigins:      cfg.App.CORSAllowedOrigins,
+			CredentialedOrigins: cfg.App.CORSCredentialedOrigins,
+			MaxAge:              cfg.App.CORSMaxAge,
+		}))
+	}
 	if cfg.App.EnableMetrics {
 		httpMetrics := metrics.NewHTTPMetrics()
+		httpMetrics.RegisterNewestEntryAge(entryRepo.NewestCreatedAt)
 		middlewares = append(middlewares, httpMetrics.Middleware)
 		promHandler = httpMetrics.Handler()
 		if cfg.App.APIKeyRequired {
-			promHandler = server.DynamicAPIKeyAuth(apiKeyRepo, log)(promHandler)
+			promHandler = apiKeyAuth(promHandler)
 		}
 	}
+	if cfg.App.ReadOnly {
+		log.Info("read-only mode enabled; write requests are answered with 405")
+	}
+	if cfg.App.DebugRequestLog {
+		log.Warn("debug request logging enabled; do not use in production")
+		middlewares = append(middlewares, server.DebugRequestLog(server.DebugRequestLogConfig{
+			Logger:       log,
+			Headers:      cfg.App.DebugRequestLogHeaders,
+			MaxBodyBytes: cfg.App.DebugRequestLogMaxBody,
+			RedactParams: cfg.App.LogRedactQueryParams,
+			RedactPaths:  cfg.App.LogRedactQueryPaths,
+		}))
+	}
+	// Health checks and the configured exempt paths are never throttled, so probes keep
+	// working while the API is busy.
+	healthPath := apiBasePath + "/health"
+	if apiBasePath == "/" {
+		healthPath = "/health"
+	}
+	skipLimits := server.SkipPaths(append([]string{healthPath + "/*"}, cfg.App.RateLimitExemptPaths...))
+	if cfg.App.MaxInFlight > 0 {
+		middlewares = append(middlewares, server.ConcurrencyLimit(server.ConcurrencyLimitConfig{
+			MaxInFlight: cfg.App.MaxInFlight,
+			RetryAfter:  cfg.App.MaxInFlightRetryAfter,
+			Logger:      log,
+			Skip:        skipLimits,
+		}))
+	}
 	if cfg.App.RateLimitEnabled {
-		healthPath := apiBasePath + "/health"
-		if apiBasePath == "/" {
-			healthPath = "/health"
-		}
 		middlewares = append(middlewares, server.RateLimit(server.RateLimitConfig{
-			Cache:  redisClient,
-			Limit:  cfg.App.RateLimitMaxRequests,
-			Window: cfg.App.RateLimitWindow,
-			Logger: log,
-			Prefix: "http",
-			Skip: func(r *http.Request) bool {
-				switch r.URL.Path {
-				case healthPath:
-					return true
-				default:
-					return false
-				}
-			},
+			Cache:      redisClient,
+			Limit:      cfg.App.RateLimitMaxRequests,
+			Window:     cfg.App.RateLimitWindow,
+			RetryAfter: cfg.App.RateLimitRetryAfter,
+			Algorithm:  cfg.App.RateLimitAlgorithm,
+			Logger:     log,
+			Prefix:     "http",
+			Skip:       skipLimits,
 		}))
 	}
 	if cfg.App.APIKeyRequired {
-		healthPath := apiBasePath + "/health"
-		if apiBasePath == "/" {
-			healthPath = "/health"
-		}
 		apiKeysPath := apiBasePath + "/api-keys"
 		if apiBasePath == "/" {
 			apiKeysPath = "/api-keys"
@@ -466,8 +563,7 @@ func run(ctx context.Context) error {
 			faviconsPath = "/favicons"
 		}
 		middlewares = append(middlewares, func(next http.Handler) http.Handler {
-			dynamicAuth := server.DynamicAPIKeyAuth(apiKeyRepo, log)
-			protected := dynamicAuth(next)
+			protected := apiKeyAuth(next)
 			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				if apiBasePath != "/" && !strings.HasPrefix(r.URL.Path, apiBasePath+"/") {
 					next.ServeHTTP(w, r)
@@ -490,12 +586,21 @@ func run(ctx context.Context) error {
 		TagHandler:        tagHandler,
 		SearchHandler:     searchHandler,
 		MetricsHandler:    metricsHandler,
+		SourceHandler:     sourceHandler,
 		APIKeyHandler:     apiKeyHandler,
 		FaviconHandler:    faviconHandler,
 		HealthHandler:     healthHandler,
 		APIBasePath:       apiBasePath,
+		DefaultAPIVersion: cfg.App.APIDefaultVersion,
+		Msgpack:           cfg.App.MsgpackResponses,
+		ReadOnly:          cfg.App.ReadOnly,
 		Middlewares:       middlewares,
 		PrometheusHandler: promHandler,
+		RequestLogger: server.RequestLoggerWithConfig(server.RequestLoggerConfig{
+			Logger:       log,
+			RedactParams: cfg.App.LogRedactQueryParams,
+			RedactPaths:  cfg.App.LogRedactQueryPaths,
+		}),
 	})
 
 	srv := server.New(server.Config{
@@ -504,6 +609,8 @@ func run(ctx context.Context) error {
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}, router, log)
+	srv.OnShutdown(workers.Stop)
+	workers.Start()
 
 	return srv.ListenAndServeWithGracefulShutdown()
 }
@@ -2,14 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"sort"
 	"strings"
 	"time"
-	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -18,21 +19,20 @@ import (
 	domainEntry "hateblog/internal/domain/entry"
 	"hateblog/internal/domain/tag"
 	"hateblog/internal/infra/external/hatena"
-	"hateblog/internal/infra/external/yahoo"
+	"hateblog/internal/infra/external/httpsprobe"
+	"hateblog/internal/infra/external/keyphrase"
+	"hateblog/internal/infra/external/redirect"
 	"hateblog/internal/infra/postgres"
 	"hateblog/internal/pkg/apptime"
 	"hateblog/internal/pkg/batchutil"
+	"hateblog/internal/pkg/textutil"
 	"hateblog/internal/platform/config"
 	"hateblog/internal/platform/database"
 	platformLogger "hateblog/internal/platform/logger"
+	"hateblog/internal/platform/metrics"
 	"hateblog/internal/platform/telemetry"
 )
 
-const (
-	dummyTagName  = "__yahoo_no_keyphrase__"
-	dummyTagScore = 1
-)
-
 func main() {
 	os.Exit(run())
 }
@@ -41,9 +41,13 @@ func run() int {
 	var (
 		lockName          = flag.String("lock", "fetcher", "advisory lock name")
 		maxEntries        = flag.Int("max-entries", 300, "maximum number of unique entries to process per run")
-		noTags            = flag.Bool("no-tags", false, "disable Yahoo keyphrase tagging even when YAHOO_APP_ID is set")
-		yahooMinInterval  = flag.Duration("yahoo-interval", 200*time.Millisecond, "minimum interval between Yahoo API requests")
+		maxPerFeed        = flag.Int("max-per-feed", 0, "maximum number of unique entries taken from each feed (0 = unlimited)")
+		minBookmarks      = flag.Int("min-bookmarks", 0, "skip feed entries with fewer bookmarks than this")
+		noTags            = flag.Bool("no-tags", false, "disable keyphrase tagging even when a provider is configured")
+		noArchiveRefresh  = flag.Bool("no-archive-refresh", false, "skip refreshing archive_counts for affected days (use admin archive refresh-recent instead)")
+		yahooMinInterval  = flag.Duration("yahoo-interval", 200*time.Millisecond, "minimum interval between keyphrase provider requests")
 		executionDeadline = flag.Duration("deadline", 5*time.Minute, "overall execution deadline")
+		tagDeadlineOK     = flag.Bool("tag-deadline-ok", false, "exit 0 with a warning when the deadline is hit during tagging, after inserts succeeded")
 	)
 	flag.Parse()
 
@@ -55,6 +59,10 @@ func run() int {
 		_, _ = fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
+	tag.SetNormalizeOptions(tag.NormalizeOptions{
+		StripControl: cfg.App.TagStripControl,
+		StripEmoji:   cfg.App.TagStripEmoji,
+	})
 	loc, err := time.LoadLocation(cfg.App.TimeZone)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "failed to load timezone %s: %v\n", cfg.App.TimeZone, err)
@@ -79,9 +87,10 @@ func run() int {
 	}
 	platformLogger.SetDefault(log)
 	startedAt := apptime.Now()
-	log.Info("fetcher started", "max_entries", *maxEntries, "deadline", *executionDeadline)
+	log.Info("fetcher started", "max_entries", *maxEntries, "max_per_feed", *maxPerFeed, "deadline", *executionDeadline)
+	deadlineTolerated := false
 	defer func() {
-		if ctx.Err() == context.DeadlineExceeded {
+		if ctx.Err() == context.DeadlineExceeded && !deadlineTolerated {
 			log.Error("fetcher deadline exceeded", "elapsed", time.Since(startedAt), "err", ctx.Err())
 		}
 	}()
@@ -120,24 +129,54 @@ func run() int {
 		db.Close()
 	}()
 
+	fetcherMetrics := metrics.NewFetcherMetrics()
+	defer func() {
+		pushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := fetcherMetrics.Push(pushCtx, cfg.App.MetricsPushgatewayURL, "hateblog_fetcher"); err != nil {
+			log.Warn("metrics push failed", "err", err)
+		}
+	}()
+
 	httpClient := &http.Client{Timeout: cfg.External.HatenaAPITimeout}
 	hatenaClient := hatena.NewClient(hatena.ClientConfig{HTTPClient: httpClient})
 
-	feedEntries, err := fetchEntries(ctx, hatenaClient, cfg.External.HatenaRSSFeedURLs, *maxEntries)
+	skipped := make(skipCounts)
+	filter := newEntryFilter(cfg.App.ExcludedDomains, *minBookmarks).
+		withPostedAtWindow(cfg.External.PostedAtWindow(startedAt))
+	feedEntries, err := fetchEntries(ctx, hatenaClient, cfg.External.HatenaRSSFeedURLs, *maxEntries, *maxPerFeed, filter, skipped)
 	if err != nil {
 		log.Error("fetch entries failed", "err", err)
 		return 1
 	}
-	log.Info("fetched entries", "count", len(feedEntries))
+	if resolver := redirect.NewResolver(redirect.Config{
+		Hosts:        cfg.External.RedirectHosts,
+		Timeout:      cfg.External.RedirectTimeout,
+		MaxRedirects: cfg.External.RedirectMaxRedirects,
+	}); resolver != nil {
+		feedEntries = resolveRedirects(ctx, resolver, feedEntries, filter, skipped, log)
+	}
+	if cfg.External.HTTPSUpgrade {
+		// Storing the https URL now saves the updater from merging the http entry later.
+		prober := httpsprobe.NewProber(httpsprobe.Config{Timeout: cfg.External.HTTPSUpgradeTimeout})
+		feedEntries = resolveRedirects(ctx, prober, feedEntries, filter, skipped, log)
+	}
+	log.Info("fetched entries", "count", len(feedEntries), "skipped", skipped.total())
 
 	tagRepo := postgres.NewTagRepository(db.Pool)
-	yahooClient := yahoo.NewClient(yahoo.ClientConfig{
-		AppID: cfg.External.YahooAPIKey,
+	extractor, err := keyphrase.New(keyphrase.Config{
+		Provider:   cfg.External.TagExtractor,
+		YahooAppID: cfg.External.YahooAPIKey,
+		Stopwords:  cfg.External.TagExtractorStopwords,
+		Fallback:   cfg.External.TagExtractorFallback,
 	})
+	if err != nil {
+		log.Error("keyphrase provider init failed", "err", err)
+		return 1
+	}
 
 	inserted := 0
 	updated := 0
-	skipped := 0
 	affectedDays := make(map[time.Time]struct{})
 	for _, item := range feedEntries {
 		select {
@@ -153,11 +192,12 @@ func run() int {
 			return 1
 		}
 		if isInsert == nil {
-			skipped++
+			skipped.add(skipAlreadyPresent)
 			continue
 		}
 		if *isInsert {
 			inserted++
+			fetcherMetrics.AddInserted(1)
 		} else {
 			updated++
 		}
@@ -168,57 +208,50 @@ func run() int {
 
 	}
 
-	tagged := 0
-	abnormalScoreCount := 0
-	if !*noTags && strings.TrimSpace(cfg.External.YahooAPIKey) != "" {
+	// Archive counts depend only on the inserted entries, so they are refreshed before the
+	// optional tagging phase.
+	if !*noArchiveRefresh {
+		archiveRepo := postgres.NewArchiveCountRepository(db.Pool)
+		for day := range affectedDays {
+			if _, err := archiveRepo.RefreshDay(ctx, day); err != nil {
+				log.Error("refresh archive counts failed", "day", day.Format("2006-01-02"), "err", err)
+				return 1
+			}
+		}
+	}
+
+	var tagging taggingResult
+	if !*noTags && extractor != nil {
 		untagged, err := fetchUntaggedEntries(ctx, db.Pool, *maxEntries)
 		if err != nil {
 			log.Error("fetch untagged entries failed", "err", err)
 			return 1
 		}
-		for _, entry := range untagged {
-			select {
-			case <-ctx.Done():
-				log.Error("deadline exceeded", "err", ctx.Err())
-				return 1
-			default:
-			}
-
-			item := feedItem{
-				Title:   entry.Title,
-				URL:     entry.URL,
-				Excerpt: entry.Excerpt,
-			}
-			tagCount, abnormal, err := attachTags(ctx, tagRepo, db.Pool, yahooClient, entry.ID, item)
-			if err != nil {
-				if _, ok := yahoo.IsTooManyRequests(err); ok {
-					log.Warn("tagging stopped due to rate limit", "url", entry.URL, "err", err)
-					break
+		tagging, err = tagEntries(ctx, untagged, extractor, *yahooMinInterval, log,
+			func(ctx context.Context, entry tagEntry) (int, int, error) {
+				item := feedItem{
+					Title:   entry.Title,
+					URL:     entry.URL,
+					Excerpt: entry.Excerpt,
 				}
-				log.Error("attach tags failed", "url", entry.URL, "err", err)
-				return 1
-			}
-			if tagCount > 0 {
-				tagged++
-			}
-			abnormalScoreCount += abnormal
-			if *yahooMinInterval > 0 {
-				time.Sleep(*yahooMinInterval)
+				return attachTags(ctx, tagRepo, db.Pool, extractor, entry.ID, item, cfg.External.TagMinScore)
+			})
+		if err != nil {
+			if code := taggingFailureExit(err, *tagDeadlineOK, tagging, log); code != 0 {
+				return code
 			}
+			deadlineTolerated = true
 		}
 	}
+	tagged, abnormalScoreCount := tagging.Tagged, tagging.Abnormal
 
-	for day := range affectedDays {
-		if err := refreshArchiveCountsForDay(ctx, db.Pool, day); err != nil {
-			log.Error("refresh archive counts failed", "day", day.Format("2006-01-02"), "err", err)
-			return 1
-		}
+	for _, reason := range skipReasons {
+		fetcherMetrics.AddSkipped(string(reason), skipped[reason])
 	}
-
-	log.Info("fetcher finished", "inserted", inserted, "updated", updated, "skipped", skipped, "tagged", tagged, "elapsed", time.Since(startedAt))
+	log.Info("fetcher finished", "inserted", inserted, "updated", updated, "skipped", skipped.total(), skipped.logAttr(), "tagged", tagged, "elapsed", time.Since(startedAt))
 
 	if abnormalScoreCount > 0 {
-		log.Error("abnormal scores detected from Yahoo API", "count", abnormalScoreCount)
+		log.Error("abnormal scores detected from keyphrase provider", "count", abnormalScoreCount)
 		return 1
 	}
 
@@ -234,7 +267,16 @@ type feedItem struct {
 	PostedAt      time.Time
 }
 
-func fetchEntries(ctx context.Context, client *hatena.Client, feedURLs []string, max int) ([]feedItem, error) {
+// feedFetcher retrieves a single RSS feed.
+type feedFetcher interface {
+	FetchFeed(ctx context.Context, feedURL string) (*hatena.Feed, error)
+}
+
+// fetchEntries collects unique entries from feedURLs up to max in total. When perFeed is
+// positive, each feed contributes at most perFeed new entries so that one busy feed cannot
+// fill the global cap on its own. Entries rejected by filter or already collected from an
+// earlier feed are tallied in skipped and do not count toward either cap.
+func fetchEntries(ctx context.Context, client feedFetcher, feedURLs []string, max, perFeed int, filter entryFilter, skipped skipCounts) ([]feedItem, error) {
 	if max <= 0 {
 		max = 1
 	}
@@ -248,23 +290,31 @@ func fetchEntries(ctx context.Context, client *hatena.Client, feedURLs []string,
 		if err != nil {
 			return nil, err
 		}
+		taken := 0
 		for _, e := range feed.Entries {
-			url := strings.TrimSpace(e.URL)
-			if url == "" {
+			if perFeed > 0 && taken >= perFeed {
+				break
+			}
+			if reason, skip := filter.check(e); skip {
+				skipped.add(reason)
 				continue
 			}
+			url := strings.TrimSpace(e.URL)
 			if _, ok := seen[url]; ok {
+				skipped.add(skipAlreadyPresent)
 				continue
 			}
+			// Feeds can carry invalid UTF-8; strip it so stored text is always valid.
 			subject := strings.Join(e.Subjects, ",")
 			seen[url] = feedItem{
-				Title:         strings.TrimSpace(e.Title),
+				Title:         strings.TrimSpace(textutil.SanitizeUTF8(e.Title)),
 				URL:           url,
-				Excerpt:       strings.TrimSpace(e.Excerpt),
-				Subject:       strings.TrimSpace(subject),
+				Excerpt:       strings.TrimSpace(textutil.SanitizeUTF8(e.Excerpt)),
+				Subject:       strings.TrimSpace(textutil.SanitizeUTF8(subject)),
 				BookmarkCount: e.BookmarkCount,
 				PostedAt:      e.PublishedAt.In(time.Local),
 			}
+			taken++
 			if len(seen) >= max {
 				break
 			}
@@ -284,6 +334,44 @@ func fetchEntries(ctx context.Context, client *hatena.Client, feedURLs []string,
 	return items, nil
 }
 
+// urlResolver maps a feed URL to its canonical destination.
+type urlResolver interface {
+	Resolve(ctx context.Context, rawURL string) (string, error)
+}
+
+// resolveRedirects replaces URLs on redirector hosts with their destination so that entries
+// are stored, de-duplicated and given favicons by the real URL. It also applies the https
+// upgrade, with an httpsprobe.Prober as resolver. Items whose destination is on
+// an excluded host or was already collected are dropped and tallied in skipped. A failed
+// lookup keeps the feed URL.
+func resolveRedirects(ctx context.Context, resolver urlResolver, items []feedItem, filter entryFilter, skipped skipCounts, log *slog.Logger) []feedItem {
+	seen := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		seen[item.URL] = struct{}{}
+	}
+	out := make([]feedItem, 0, len(items))
+	for _, item := range items {
+		resolved, err := resolver.Resolve(ctx, item.URL)
+		if err != nil {
+			log.Warn("resolve redirect failed", "url", item.URL, "err", err)
+		}
+		if resolved != item.URL {
+			if filter.blockedHost(resolved) {
+				skipped.add(skipBlockedDomain)
+				continue
+			}
+			if _, ok := seen[resolved]; ok {
+				skipped.add(skipAlreadyPresent)
+				continue
+			}
+			seen[resolved] = struct{}{}
+			item.URL = resolved
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
 func insertEntry(ctx context.Context, pool *pgxpool.Pool, item feedItem) (id uuid.UUID, isInsert *bool, createdAt time.Time, err error) {
 	if pool == nil {
 		return uuid.Nil, nil, time.Time{}, fmt.Errorf("pool is nil")
@@ -339,43 +427,6 @@ func resolveCreatedAt(now, postedAt time.Time) time.Time {
 	return apptime.ResolveCreatedAt(now, postedAt)
 }
 
-func refreshArchiveCountsForDay(ctx context.Context, pool *pgxpool.Pool, day time.Time) error {
-	if pool == nil {
-		return fmt.Errorf("pool is nil")
-	}
-	tx, err := pool.Begin(ctx)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			_ = tx.Rollback(ctx)
-		}
-	}()
-
-	const deleteQuery = `
-DELETE FROM archive_counts
-WHERE day = DATE($1)`
-	if _, err = tx.Exec(ctx, deleteQuery, day); err != nil {
-		return err
-	}
-
-	const insertQuery = `
-INSERT INTO archive_counts (day, threshold, count)
-SELECT DATE($1) AS day, t.threshold, COUNT(1)
-FROM entries
-CROSS JOIN (VALUES (5), (10), (50), (100), (500), (1000)) AS t(threshold)
-WHERE DATE(entries.created_at) = DATE($1)
-  AND entries.bookmark_count >= t.threshold
-GROUP BY t.threshold`
-	if _, err = tx.Exec(ctx, insertQuery, day); err != nil {
-		return err
-	}
-
-	err = tx.Commit(ctx)
-	return err
-}
-
 func nullableText(s string) any {
 	if strings.TrimSpace(s) == "" {
 		return nil
@@ -383,72 +434,55 @@ func nullableText(s string) any {
 	return s
 }
 
+// extractTags runs the extractor on the entry text and normalizes the phrases into tags with
+// tag.ScoreKeyphrases. It returns no tags when nothing usable was extracted, plus the number of
+// out-of-range scores.
+func extractTags(ctx context.Context, extractor tag.KeyphraseExtractor, item feedItem, minScore int) ([]tag.ScoredTag, int, error) {
+	input := strings.TrimSpace(strings.Join([]string{item.Title, item.Excerpt}, "\n"))
+	phrases, err := extractor.Extract(ctx, input)
+	if err != nil {
+		return nil, 0, err
+	}
+	tags, abnormalCount := tag.ScoreKeyphrases(phrases, minScore)
+	return tags, abnormalCount, nil
+}
+
 func attachTags(
 	ctx context.Context,
 	tagRepo *postgres.TagRepository,
 	pool *pgxpool.Pool,
-	yahooClient *yahoo.Client,
+	extractor tag.KeyphraseExtractor,
 	entryID uuid.UUID,
 	item feedItem,
+	minScore int,
 ) (int, int, error) {
 	if pool == nil {
 		return 0, 0, fmt.Errorf("pool is nil")
 	}
-	input := strings.TrimSpace(strings.Join([]string{item.Title, item.Excerpt}, "\n"))
-	phrases, err := yahooClient.Extract(ctx, input)
+	tags, abnormalCount, err := extractTags(ctx, extractor, item, minScore)
 	if err != nil {
 		return 0, 0, err
 	}
-	if len(phrases) == 0 {
+	if len(tags) == 0 {
 		if err := attachDummyTag(ctx, tagRepo, pool, entryID); err != nil {
-			return 0, 0, err
+			return 0, abnormalCount, err
 		}
-		return 1, 0, nil
+		return 1, abnormalCount, nil
 	}
 
-	sort.Slice(phrases, func(i, j int) bool { return phrases[i].Score > phrases[j].Score })
-
-	added := 0
-	abnormalCount := 0
-	for _, p := range phrases {
-		// Sanitize UTF-8 from Yahoo API response before normalizing
-		sanitized := sanitizeUTF8(p.Text)
-		name := tag.NormalizeName(sanitized)
-		if name == "" {
-			continue
-		}
-		t := &tag.Tag{Name: name}
+	taggings := make([]domainEntry.Tagging, 0, len(tags))
+	for _, st := range tags {
+		t := &tag.Tag{Name: st.Name}
 		if err := tagRepo.Upsert(ctx, t); err != nil {
-			return added, abnormalCount, err
-		}
-
-		score := p.Score
-		if score < 0 || score > 100 {
-			abnormalCount++
-		}
-		if score < 0 {
-			score = 0
-		}
-		if score > 100 {
-			score = 100
-		}
-
-		const q = `
-INSERT INTO entry_tags (entry_id, tag_id, score)
-VALUES ($1, $2, $3)
-ON CONFLICT (entry_id, tag_id) DO NOTHING`
-		if _, err := pool.Exec(ctx, q, entryID, t.ID, score); err != nil {
-			return added, abnormalCount, err
-		}
-		added++
-	}
-	if added == 0 {
-		if err := attachDummyTag(ctx, tagRepo, pool, entryID); err != nil {
 			return 0, abnormalCount, err
 		}
-		return 1, abnormalCount, nil
+		taggings = append(taggings, domainEntry.Tagging{TagID: t.ID, Name: t.Name, Score: st.Score})
 	}
-	return added, abnormalCount, nil
+	added, err := tagRepo.AttachEntryTags(ctx, entryID, taggings)
+	if err != nil {
+		return 0, abnormalCount, err
+	}
+	return int(added), abnormalCount, nil
 }
 
 func attachDummyTag(
@@ -457,7 +491,7 @@ func attachDummyTag(
 	pool *pgxpool.Pool,
 	entryID uuid.UUID,
 ) error {
-	t := &tag.Tag{Name: dummyTagName}
+	t := &tag.Tag{Name: tag.NoKeyphraseTagName}
 	if err := tagRepo.Upsert(ctx, t); err != nil {
 		return err
 	}
@@ -465,10 +499,65 @@ func attachDummyTag(
 INSERT INTO entry_tags (entry_id, tag_id, score)
 VALUES ($1, $2, $3)
 ON CONFLICT (entry_id, tag_id) DO NOTHING`
-	_, err := pool.Exec(ctx, q, entryID, t.ID, dummyTagScore)
+	_, err := pool.Exec(ctx, q, entryID, t.ID, tag.NoKeyphraseTagScore)
 	return err
 }
 
+// taggingResult tallies the tagging phase.
+type taggingResult struct {
+	Tagged   int
+	Abnormal int
+}
+
+// entryTagger attaches tags to one entry and returns the number attached and the number of
+// out-of-range scores (see attachTags).
+type entryTagger func(ctx context.Context, entry tagEntry) (int, int, error)
+
+// tagEntries tags entries one by one, pausing interval between them. It stops without error when
+// the provider rate limits requests, and returns ctx's error when ctx ends during the phase.
+func tagEntries(ctx context.Context, entries []tagEntry, extractor tag.KeyphraseExtractor, interval time.Duration, log *slog.Logger, attach entryTagger) (taggingResult, error) {
+	var res taggingResult
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		tagCount, abnormal, err := attach(ctx, entry)
+		if err != nil {
+			if _, ok := extractor.IsTooManyRequests(err); ok {
+				log.Warn("tagging stopped due to rate limit", "url", entry.URL, "err", err)
+				return res, nil
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return res, fmt.Errorf("attach tags: %s: %w: %w", entry.URL, ctxErr, err)
+			}
+			return res, fmt.Errorf("attach tags: %s: %w", entry.URL, err)
+		}
+		if tagCount > 0 {
+			res.Tagged++
+		}
+		res.Abnormal += abnormal
+		if interval > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(interval):
+			}
+		}
+	}
+	return res, nil
+}
+
+// taggingFailureExit logs a tagging error and returns the exit code for it. With tolerate
+// (--tag-deadline-ok), hitting the execution deadline is only a warning and exits 0: inserts
+// have already been committed, and untagged entries are picked up again by the next run.
+func taggingFailureExit(err error, tolerate bool, res taggingResult, log *slog.Logger) int {
+	if tolerate && errors.Is(err, context.DeadlineExceeded) {
+		log.Warn("deadline during tagging; remaining entries are tagged by the next run", "tagged", res.Tagged, "err", err)
+		return 0
+	}
+	log.Error("tagging failed", "tagged", res.Tagged, "err", err)
+	return 1
+}
+
 type tagEntry struct {
 	ID      uuid.UUID
 	URL     string
@@ -514,14 +603,3 @@ LIMIT $1`
 	}
 	return entries, nil
 }
-
-// sanitizeUTF8 removes invalid UTF-8 sequences from a string
-func sanitizeUTF8(s string) string {
-	if utf8.ValidString(s) {
-		return s
-	}
-
-	// Convert to valid UTF-8 by replacing invalid sequences
-	// strings.ToValidUTF8 replaces invalid UTF-8 with the replacement string
-	return strings.ToValidUTF8(s, "")
-}
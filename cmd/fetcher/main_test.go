@@ -1,8 +1,23 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
+
+	"hateblog/internal/domain/tag"
+	"hateblog/internal/infra/external/hatena"
+	"hateblog/internal/infra/external/httpsprobe"
 )
 
 func TestNullableText(t *testing.T) {
@@ -43,43 +58,6 @@ func TestNullableText(t *testing.T) {
 	}
 }
 
-func TestSanitizeUTF8(t *testing.T) {
-	tests := []struct {
-		name  string
-		input string
-		want  string
-	}{
-		{
-			name:  "valid ascii",
-			input: "hello",
-			want:  "hello",
-		},
-		{
-			name:  "valid utf8",
-			input: "こんにちは",
-			want:  "こんにちは",
-		},
-		{
-			name:  "invalid utf8",
-			input: "hello\xffworld",
-			want:  "helloworld",
-		},
-		{
-			name:  "mixed valid and invalid",
-			input: string([]byte{0xe3, 0x81, 0x82, 0xff, 0xe3, 0x81, 0x84}), // "あ" + invalid + "い"
-			want:  "あい",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := sanitizeUTF8(tt.input); got != tt.want {
-				t.Errorf("sanitizeUTF8(%q) = %q, want %q", tt.input, got, tt.want)
-			}
-		})
-	}
-}
-
 func TestResolveCreatedAt(t *testing.T) {
 	jst := time.FixedZone("JST", 9*60*60)
 	now := time.Date(2026, 2, 3, 12, 0, 0, 0, jst)
@@ -125,3 +103,411 @@ func TestResolveCreatedAt(t *testing.T) {
 		})
 	}
 }
+
+type fakeExtractor struct {
+	phrases []tag.Keyphrase
+	err     error
+	input   string
+}
+
+func (f *fakeExtractor) Extract(ctx context.Context, text string) ([]tag.Keyphrase, error) {
+	f.input = text
+	return f.phrases, f.err
+}
+
+func (f *fakeExtractor) IsTooManyRequests(err error) (time.Duration, bool) {
+	return 0, false
+}
+
+func TestExtractTags(t *testing.T) {
+	item := feedItem{Title: "Go 1.25 released", Excerpt: "New features"}
+
+	t.Run("normalizes and clamps phrases from the provider", func(t *testing.T) {
+		ext := &fakeExtractor{phrases: []tag.Keyphrase{
+			{Text: "Release", Score: 40},
+			{Text: " Go ", Score: 120},
+			{Text: "  ", Score: 80},
+			{Text: "bad\xff", Score: -5},
+		}}
+		tags, abnormal, err := extractTags(context.Background(), ext, item, 0)
+		if err != nil {
+			t.Fatalf("extractTags() error = %v", err)
+		}
+		if ext.input != "Go 1.25 released\nNew features" {
+			t.Errorf("extractor input = %q", ext.input)
+		}
+		want := []tag.ScoredTag{{Name: "go", Score: 100}, {Name: "release", Score: 40}, {Name: "bad", Score: 0}}
+		if !reflect.DeepEqual(tags, want) {
+			t.Errorf("extractTags() = %v, want %v", tags, want)
+		}
+		if abnormal != 2 {
+			t.Errorf("abnormal = %d, want 2", abnormal)
+		}
+	})
+
+	t.Run("drops phrases below the minimum score", func(t *testing.T) {
+		ext := &fakeExtractor{phrases: []tag.Keyphrase{
+			{Text: "noise", Score: 12},
+			{Text: "Go", Score: 95},
+			{Text: "release", Score: 30},
+			{Text: "weak", Score: 29},
+		}}
+		tags, _, err := extractTags(context.Background(), ext, item, 30)
+		if err != nil {
+			t.Fatalf("extractTags() error = %v", err)
+		}
+		want := []tag.ScoredTag{{Name: "go", Score: 95}, {Name: "release", Score: 30}}
+		if !reflect.DeepEqual(tags, want) {
+			t.Errorf("extractTags() = %v, want %v", tags, want)
+		}
+	})
+
+	t.Run("returns no tags when nothing is extracted", func(t *testing.T) {
+		tags, abnormal, err := extractTags(context.Background(), &fakeExtractor{}, item, 0)
+		if err != nil {
+			t.Fatalf("extractTags() error = %v", err)
+		}
+		if len(tags) != 0 || abnormal != 0 {
+			t.Errorf("extractTags() = %v, %d, want empty", tags, abnormal)
+		}
+	})
+
+	t.Run("propagates provider errors", func(t *testing.T) {
+		providerErr := errors.New("provider down")
+		_, _, err := extractTags(context.Background(), &fakeExtractor{err: providerErr}, item, 0)
+		if !errors.Is(err, providerErr) {
+			t.Errorf("extractTags() error = %v, want %v", err, providerErr)
+		}
+	})
+}
+
+type fakeFeedFetcher map[string]*hatena.Feed
+
+func (f fakeFeedFetcher) FetchFeed(ctx context.Context, feedURL string) (*hatena.Feed, error) {
+	feed, ok := f[feedURL]
+	if !ok {
+		return nil, errors.New("unknown feed")
+	}
+	return feed, nil
+}
+
+func feedOf(prefix string, n int) *hatena.Feed {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	feed := &hatena.Feed{}
+	for i := 0; i < n; i++ {
+		feed.Entries = append(feed.Entries, hatena.FeedEntry{
+			Title:       fmt.Sprintf("%s %d", prefix, i),
+			URL:         fmt.Sprintf("https://%s.example.com/%d", prefix, i),
+			PublishedAt: base.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	return feed
+}
+
+func TestFetchEntriesPerFeedCap(t *testing.T) {
+	client := fakeFeedFetcher{
+		"busy":  feedOf("busy", 10),
+		"quiet": feedOf("quiet", 3),
+	}
+	feeds := []string{"busy", "quiet"}
+
+	countByHost := func(items []feedItem) map[string]int {
+		counts := make(map[string]int)
+		for _, item := range items {
+			host, _, _ := strings.Cut(strings.TrimPrefix(item.URL, "https://"), ".")
+			counts[host]++
+		}
+		return counts
+	}
+
+	t.Run("global cap alone lets the first feed crowd out others", func(t *testing.T) {
+		items, err := fetchEntries(context.Background(), client, feeds, 6, 0, entryFilter{}, make(skipCounts))
+		if err != nil {
+			t.Fatalf("fetchEntries() error = %v", err)
+		}
+		got := countByHost(items)
+		if got["busy"] != 6 || got["quiet"] != 0 {
+			t.Errorf("contribution = %v, want busy=6 quiet=0", got)
+		}
+	})
+
+	t.Run("per-feed cap balances contribution", func(t *testing.T) {
+		items, err := fetchEntries(context.Background(), client, feeds, 6, 3, entryFilter{}, make(skipCounts))
+		if err != nil {
+			t.Fatalf("fetchEntries() error = %v", err)
+		}
+		got := countByHost(items)
+		if got["busy"] != 3 || got["quiet"] != 3 {
+			t.Errorf("contribution = %v, want busy=3 quiet=3", got)
+		}
+	})
+
+	t.Run("global cap still applies after per-feed cap", func(t *testing.T) {
+		items, err := fetchEntries(context.Background(), client, feeds, 4, 3, entryFilter{}, make(skipCounts))
+		if err != nil {
+			t.Fatalf("fetchEntries() error = %v", err)
+		}
+		got := countByHost(items)
+		if got["busy"] != 3 || got["quiet"] != 1 {
+			t.Errorf("contribution = %v, want busy=3 quiet=1", got)
+		}
+	})
+
+	t.Run("duplicates do not count toward the per-feed cap", func(t *testing.T) {
+		dup := fakeFeedFetcher{
+			"a": feedOf("shared", 2),
+			"b": feedOf("shared", 4),
+		}
+		items, err := fetchEntries(context.Background(), dup, []string{"a", "b"}, 10, 2, entryFilter{}, make(skipCounts))
+		if err != nil {
+			t.Fatalf("fetchEntries() error = %v", err)
+		}
+		if len(items) != 4 {
+			t.Errorf("len(items) = %d, want 4", len(items))
+		}
+	})
+}
+
+func TestFetchEntriesSkipReasons(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := fakeFeedFetcher{
+		"hot": &hatena.Feed{Entries: []hatena.FeedEntry{
+			{URL: "https://example.com/a", BookmarkCount: 10, PublishedAt: base},
+			{URL: "  ", BookmarkCount: 10, PublishedAt: base},
+			{URL: "https://Spam.Example.net/x", BookmarkCount: 50, PublishedAt: base},
+			{URL: "https://example.com/low", BookmarkCount: 2, PublishedAt: base},
+		}},
+		"new": &hatena.Feed{Entries: []hatena.FeedEntry{
+			{URL: "https://example.com/a", BookmarkCount: 10, PublishedAt: base},
+			{URL: "https://example.com/b", BookmarkCount: 3, PublishedAt: base},
+			{URL: "", PublishedAt: base},
+		}},
+	}
+	filter := newEntryFilter([]string{"spam.example.net"}, 3)
+	skipped := make(skipCounts)
+
+	items, err := fetchEntries(context.Background(), client, []string{"hot", "new"}, 10, 0, filter, skipped)
+	if err != nil {
+		t.Fatalf("fetchEntries() error = %v", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("len(items) = %d, want 2", len(items))
+	}
+	want := skipCounts{
+		skipAlreadyPresent:    1,
+		skipBlockedDomain:     1,
+		skipBelowMinBookmarks: 1,
+		skipEmptyURL:          2,
+	}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped = %v, want %v", skipped, want)
+	}
+	if skipped.total() != 5 {
+		t.Errorf("skipped.total() = %d, want 5", skipped.total())
+	}
+}
+
+func TestFetchEntriesPostedAtWindow(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := fakeFeedFetcher{
+		"hot": &hatena.Feed{Entries: []hatena.FeedEntry{
+			{URL: "https://example.com/ok", BookmarkCount: 10, PublishedAt: now.Add(-time.Hour)},
+			{URL: "https://example.com/skew", BookmarkCount: 10, PublishedAt: now.Add(time.Hour)},
+			{URL: "https://example.com/future", BookmarkCount: 10, PublishedAt: now.AddDate(1, 0, 0)},
+			{URL: "https://example.com/ancient", BookmarkCount: 10, PublishedAt: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{URL: "https://example.com/floor", BookmarkCount: 10, PublishedAt: time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC)},
+		}},
+	}
+	filter := newEntryFilter(nil, 0).
+		withPostedAtWindow(time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC), now.Add(24*time.Hour))
+	skipped := make(skipCounts)
+
+	items, err := fetchEntries(context.Background(), client, []string{"hot"}, 10, 0, filter, skipped)
+	if err != nil {
+		t.Fatalf("fetchEntries() error = %v", err)
+	}
+	got := make([]string, 0, len(items))
+	for _, it := range items {
+		got = append(got, it.URL)
+	}
+	sort.Strings(got)
+	want := []string{"https://example.com/floor", "https://example.com/ok", "https://example.com/skew"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("urls = %v, want %v", got, want)
+	}
+	wantSkipped := skipCounts{skipPostedInFuture: 1, skipPostedTooOld: 1}
+	if !reflect.DeepEqual(skipped, wantSkipped) {
+		t.Errorf("skipped = %v, want %v", skipped, wantSkipped)
+	}
+}
+
+func TestFetchEntriesSanitizesUTF8(t *testing.T) {
+	client := fakeFeedFetcher{
+		"hot": &hatena.Feed{Entries: []hatena.FeedEntry{{
+			URL:           "https://example.com/broken",
+			Title:         " Go\xff言語 ",
+			Excerpt:       "bad\xfebytes",
+			Subjects:      []string{"テクノロジー\xe3\x81", "it"},
+			BookmarkCount: 10,
+			PublishedAt:   time.Now(),
+		}}},
+	}
+	items, err := fetchEntries(context.Background(), client, []string{"hot"}, 10, 0, newEntryFilter(nil, 0), make(skipCounts))
+	if err != nil {
+		t.Fatalf("fetchEntries() error = %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want 1", len(items))
+	}
+	got := items[0]
+	if got.Title != "Go言語" || got.Excerpt != "badbytes" || got.Subject != "テクノロジー,it" {
+		t.Errorf("item = %+v, want sanitized text", got)
+	}
+}
+
+// fakeResolver maps feed URLs to destinations; URLs in fail return an error.
+type fakeResolver struct {
+	dest map[string]string
+	fail map[string]bool
+}
+
+func (f fakeResolver) Resolve(_ context.Context, rawURL string) (string, error) {
+	if f.fail[rawURL] {
+		return rawURL, errors.New("timeout")
+	}
+	if d, ok := f.dest[rawURL]; ok {
+		return d, nil
+	}
+	return rawURL, nil
+}
+
+func TestResolveRedirects(t *testing.T) {
+	items := []feedItem{
+		{URL: "https://t.example/a"},
+		{URL: "https://t.example/b"},
+		{URL: "https://example.com/c"},
+		{URL: "https://t.example/spam"},
+		{URL: "https://t.example/down"},
+	}
+	resolver := fakeResolver{
+		dest: map[string]string{
+			"https://t.example/a":    "https://blog.example.org/a",
+			"https://t.example/b":    "https://example.com/c",
+			"https://t.example/spam": "https://spam.example.net/x",
+		},
+		fail: map[string]bool{"https://t.example/down": true},
+	}
+	filter := newEntryFilter([]string{"spam.example.net"}, 0)
+	skipped := make(skipCounts)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	got := resolveRedirects(context.Background(), resolver, items, filter, skipped, log)
+
+	var urls []string
+	for _, item := range got {
+		urls = append(urls, item.URL)
+	}
+	want := []string{"https://blog.example.org/a", "https://example.com/c", "https://t.example/down"}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("urls = %v, want %v", urls, want)
+	}
+	wantSkipped := skipCounts{skipAlreadyPresent: 1, skipBlockedDomain: 1}
+	if !reflect.DeepEqual(skipped, wantSkipped) {
+		t.Errorf("skipped = %v, want %v", skipped, wantSkipped)
+	}
+}
+
+func TestResolveRedirectsHTTPSUpgrade(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain-only" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	httpBase := "http://" + strings.TrimPrefix(server.URL, "https://")
+	items := []feedItem{
+		{URL: httpBase + "/a"},
+		{URL: httpBase + "/plain-only"},
+		// The feed also lists the https URL of /b; the http one becomes a duplicate.
+		{URL: server.URL + "/b"},
+		{URL: httpBase + "/b"},
+	}
+	prober := httpsprobe.NewProber(httpsprobe.Config{HTTPClient: server.Client()})
+	skipped := make(skipCounts)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	got := resolveRedirects(context.Background(), prober, items, newEntryFilter(nil, 0), skipped, log)
+
+	var urls []string
+	for _, item := range got {
+		urls = append(urls, item.URL)
+	}
+	want := []string{server.URL + "/a", httpBase + "/plain-only", server.URL + "/b"}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("urls = %v, want %v", urls, want)
+	}
+	if !reflect.DeepEqual(skipped, skipCounts{skipAlreadyPresent: 1}) {
+		t.Errorf("skipped = %v", skipped)
+	}
+}
+
+// slowTagger attaches one tag per entry, then blocks until ctx ends from the second entry on.
+type slowTagger struct {
+	calls int
+}
+
+func (s *slowTagger) attach(ctx context.Context, entry tagEntry) (int, int, error) {
+	s.calls++
+	if s.calls == 1 {
+		return 1, 0, nil
+	}
+	<-ctx.Done()
+	return 0, 0, fmt.Errorf("insert entry_tags: %w", errors.New("conn closed"))
+}
+
+func TestTagEntriesDeadline(t *testing.T) {
+	entries := []tagEntry{{URL: "https://a.example/"}, {URL: "https://b.example/"}, {URL: "https://c.example/"}}
+
+	for _, tt := range []struct {
+		name     string
+		tolerate bool
+		wantExit int
+		wantLog  string
+	}{
+		{name: "tolerated", tolerate: true, wantExit: 0, wantLog: "level=WARN msg=\"deadline during tagging"},
+		{name: "default", tolerate: false, wantExit: 1, wantLog: "level=ERROR msg=\"tagging failed\""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			var buf bytes.Buffer
+			log := slog.New(slog.NewTextHandler(&buf, nil))
+			tagger := &slowTagger{}
+
+			res, err := tagEntries(ctx, entries, &fakeExtractor{}, 0, log, tagger.attach)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("tagEntries() error = %v, want deadline exceeded", err)
+			}
+			if res.Tagged != 1 || tagger.calls != 2 {
+				t.Errorf("tagged = %d, calls = %d, want 1 and 2", res.Tagged, tagger.calls)
+			}
+
+			if got := taggingFailureExit(err, tt.tolerate, res, log); got != tt.wantExit {
+				t.Errorf("exit = %d, want %d", got, tt.wantExit)
+			}
+			if !strings.Contains(buf.String(), tt.wantLog) {
+				t.Errorf("log = %q, want %q", buf.String(), tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestTaggingFailureExitOtherErrors(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+	if got := taggingFailureExit(errors.New("db down"), true, taggingResult{}, log); got != 1 {
+		t.Errorf("exit = %d, want 1", got)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"hateblog/internal/infra/external/hatena"
+	"hateblog/internal/pkg/hostname"
+)
+
+// skipReason labels why a feed entry was not ingested.
+type skipReason string
+
+const (
+	skipAlreadyPresent    skipReason = "already_present"
+	skipBlockedDomain     skipReason = "blocked_domain"
+	skipBelowMinBookmarks skipReason = "below_min_bookmarks"
+	skipEmptyURL          skipReason = "empty_url"
+	skipPostedInFuture    skipReason = "posted_in_future"
+	skipPostedTooOld      skipReason = "posted_too_old"
+)
+
+// skipReasons lists every reason in the order used for log output.
+var skipReasons = []skipReason{skipAlreadyPresent, skipBlockedDomain, skipBelowMinBookmarks, skipEmptyURL, skipPostedInFuture, skipPostedTooOld}
+
+// skipCounts tallies skipped entries per reason.
+type skipCounts map[skipReason]int
+
+func (c skipCounts) add(reason skipReason) {
+	c[reason]++
+}
+
+func (c skipCounts) total() int {
+	n := 0
+	for _, v := range c {
+		n += v
+	}
+	return n
+}
+
+// logAttr groups the per-reason counts, including zeros, for the summary log.
+func (c skipCounts) logAttr() slog.Attr {
+	attrs := make([]any, 0, len(skipReasons))
+	for _, reason := range skipReasons {
+		attrs = append(attrs, slog.Int(string(reason), c[reason]))
+	}
+	return slog.Group("skipped_by_reason", attrs...)
+}
+
+// entryFilter drops feed entries that should not be ingested.
+type entryFilter struct {
+	excludedHosts map[string]struct{}
+	minBookmarks  int
+	// notBefore and notAfter bound posted_at; a zero bound is not checked.
+	notBefore time.Time
+	notAfter  time.Time
+}
+
+func newEntryFilter(excludedDomains []string, minBookmarks int) entryFilter {
+	hosts := make(map[string]struct{}, len(excludedDomains))
+	for _, domain := range excludedDomains {
+		if host, err := hostname.Normalize(domain); err == nil {
+			hosts[host] = struct{}{}
+		}
+	}
+	return entryFilter{excludedHosts: hosts, minBookmarks: minBookmarks}
+}
+
+// withPostedAtWindow makes the filter reject entries posted before notBefore or after notAfter,
+// so that back-dated or far-future feed items cannot skew archives and rankings.
+// A zero bound disables that side of the window.
+func (f entryFilter) withPostedAtWindow(notBefore, notAfter time.Time) entryFilter {
+	f.notBefore = notBefore
+	f.notAfter = notAfter
+	return f
+}
+
+// check returns the reason to skip e, or false when e should be ingested.
+// Duplicates are detected by the caller since they depend on what has been collected.
+func (f entryFilter) check(e hatena.FeedEntry) (skipReason, bool) {
+	raw := strings.TrimSpace(e.URL)
+	if raw == "" {
+		return skipEmptyURL, true
+	}
+	if f.blockedHost(raw) {
+		return skipBlockedDomain, true
+	}
+	if !f.notAfter.IsZero() && e.PublishedAt.After(f.notAfter) {
+		return skipPostedInFuture, true
+	}
+	if !f.notBefore.IsZero() && e.PublishedAt.Before(f.notBefore) {
+		return skipPostedTooOld, true
+	}
+	if e.BookmarkCount < f.minBookmarks {
+		return skipBelowMinBookmarks, true
+	}
+	return "", false
+}
+
+// blockedHost reports whether the host of rawURL is excluded.
+func (f entryFilter) blockedHost(rawURL string) bool {
+	if len(f.excludedHosts) == 0 {
+		return false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	_, ok := f.excludedHosts[strings.ToLower(u.Hostname())]
+	return ok
+}
@@ -3,12 +3,18 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"os/signal"
+	"sort"
 	"strings"
+	"syscall"
 	"time"
-	"unicode/utf8"
 
 	"github.com/caarlos0/env/v10"
 	_ "github.com/go-sql-driver/mysql"
@@ -18,6 +24,7 @@ import (
 
 	domainEntry "hateblog/internal/domain/entry"
 	"hateblog/internal/domain/tag"
+	"hateblog/internal/pkg/textutil"
 )
 
 // Config stores migration configuration values.
@@ -37,15 +44,64 @@ type Config struct {
 	PostgresTimeout time.Duration `env:"POSTGRES_CONNECT_TIMEOUT" envDefault:"10s"`
 
 	BatchSize int `env:"MIGRATION_BATCH_SIZE" envDefault:"1000"`
+	// CommitSize commits each fetched batch in transactions of at most this many bookmarks
+	// (0 commits the whole batch at once).
+	CommitSize int `env:"MIGRATION_COMMIT_SIZE" envDefault:"0"`
+	// ReportJSON, when set, is the path the final verification is also written to as JSON.
+	ReportJSON string `env:"MIGRATION_REPORT_JSON" envDefault:""`
+
+	// Must match the app's settings so migrated tags can be looked up.
+	TagStripControl bool `env:"TAG_STRIP_CONTROL" envDefault:"true"`
+	TagStripEmoji   bool `env:"TAG_STRIP_EMOJI" envDefault:"false"`
 }
 
-const batchSize = 1000
+// Validate checks values that would otherwise make the batch loop misbehave.
+func (c Config) Validate() error {
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("MIGRATION_BATCH_SIZE must be positive: %d", c.BatchSize)
+	}
+	if c.CommitSize < 0 {
+		return fmt.Errorf("MIGRATION_COMMIT_SIZE must be >= 0: %d", c.CommitSize)
+	}
+	return nil
+}
+
+// batchOptions controls batch sizing and progress output of migrateBatches.
+type batchOptions struct {
+	batchSize int
+	// commitSize splits each fetched batch into transactions of this many bookmarks;
+	// 0 commits the batch at once.
+	commitSize int
+	// progressEvery prints a progress line every N batches (and after the last one).
+	progressEvery int
+	// reportJSON is Config.ReportJSON.
+	reportJSON string
+}
 
 func main() {
+	progressEvery := flag.Int("progress-every", 1, "print a progress line every N batches")
+	flag.Parse()
+
 	cfg := Config{}
 	if err := env.Parse(&cfg); err != nil {
 		log.Fatalf("Failed to parse config: %v", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	tag.SetNormalizeOptions(tag.NormalizeOptions{
+		StripControl: cfg.TagStripControl,
+		StripEmoji:   cfg.TagStripEmoji,
+	})
+	if *progressEvery <= 0 {
+		log.Fatalf("-progress-every must be positive: %d", *progressEvery)
+	}
+	opts := batchOptions{
+		batchSize:     cfg.BatchSize,
+		commitSize:    cfg.CommitSize,
+		progressEvery: *progressEvery,
+		reportJSON:    cfg.ReportJSON,
+	}
 
 	mysqlDB, err := connectMySQL(cfg)
 	if err != nil {
@@ -60,12 +116,25 @@ func main() {
 		log.Fatalf("Failed to connect PostgreSQL: %v", err)
 	}
 
-	ctx := context.Background()
 	defer func() {
-		_ = pgDB.Close(ctx)
+		_ = pgDB.Close(context.Background())
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		// Restore default handling so a second Ctrl-C aborts immediately.
+		stop()
 	}()
 
-	if err := migrate(ctx, mysqlDB, pgDB); err != nil {
+	if err := migrate(ctx, mysqlDB, pgDB, opts); err != nil {
+		var interrupted *interruptedError
+		if errors.As(err, &interrupted) {
+			fmt.Printf("Migration interrupted: %v\nRe-run the migrator to resume.\n", err)
+			stop()
+			os.Exit(130)
+		}
 		log.Fatalf("Migration failed: %v", err)
 	}
 
@@ -144,15 +213,16 @@ func getValidBookmarksCount(ctx context.Context, db *sql.DB) (int64, error) {
 	return count, nil
 }
 
-func migrate(ctx context.Context, mysqlDB *sql.DB, pgDB *pgx.Conn) error {
+func migrate(ctx context.Context, mysqlDB *sql.DB, pgDB *pgx.Conn, opts batchOptions) error {
 	fmt.Println("=== Migrating bookmarks, keywords, keyphrases ===")
-	if err := migrateBatches(ctx, mysqlDB, pgDB); err != nil {
+	stats, err := migrateBatches(ctx, mysqlDB, pgDB, opts)
+	if err != nil {
 		return fmt.Errorf("batch migration failed: %w", err)
 	}
 
 	// Verification
 	fmt.Println("\n=== Row Count Verification ===")
-	return verifyMigration(ctx, mysqlDB, pgDB)
+	return verifyMigration(ctx, mysqlDB, pgDB, stats, opts.reportJSON)
 }
 
 type bookmarkRow struct {
@@ -184,29 +254,128 @@ type batchStats struct {
 	skippedEmptyKeyword int64
 }
 
-func migrateBatches(ctx context.Context, mysqlDB *sql.DB, pgDB *pgx.Conn) error {
+func (s *batchStats) add(o batchStats) {
+	s.processedBookmarks += o.processedBookmarks
+	s.insertedBookmarks += o.insertedBookmarks
+	s.skippedBookmarks += o.skippedBookmarks
+	s.insertedKeywords += o.insertedKeywords
+	s.insertedKeyphrases += o.insertedKeyphrases
+	s.skippedKeyphrases += o.skippedKeyphrases
+	s.skippedEmptyKeyword += o.skippedEmptyKeyword
+}
+
+// interruptedError reports where a migration stopped after its context was canceled.
+type interruptedError struct {
+	lastID    int64
+	processed int64
+}
+
+func (e *interruptedError) Error() string {
+	return fmt.Sprintf("stopped after bookmark id=%d (%d bookmarks processed in this run)", e.lastID, e.processed)
+}
+
+func (e *interruptedError) Unwrap() error {
+	return context.Canceled
+}
+
+// migrateBatches migrates the remaining bookmarks and returns the stats of this run.
+func migrateBatches(ctx context.Context, mysqlDB *sql.DB, pgDB *pgx.Conn, opts batchOptions) (batchStats, error) {
+	var stats batchStats
 	total, err := getTableCount(ctx, mysqlDB, "bookmarks")
 	if err != nil {
-		return err
+		return stats, err
+	}
+
+	lastID, err := getResumeLastID(ctx, mysqlDB, pgDB)
+	if err != nil {
+		return stats, err
+	}
+	if lastID > 0 {
+		fmt.Printf("[resume] Starting after bookmark id=%d based on latest entries.created_at\n", lastID)
+	}
+
+	loop := batchLoop{
+		total:  total,
+		opts:   opts,
+		totals: &stats,
+		fetch: func(ctx context.Context, lastID int64, limit int) ([]bookmarkRow, error) {
+			return fetchBookmarksBatch(ctx, mysqlDB, lastID, limit)
+		},
+		apply: func(ctx context.Context, bookmarks []bookmarkRow) (batchStats, error) {
+			tx, err := pgDB.Begin(ctx)
+			if err != nil {
+				return batchStats{}, err
+			}
+			stats, err := migrateBatch(ctx, mysqlDB, tx, bookmarks)
+			if err != nil {
+				rollbackTx(ctx, tx)
+				return stats, err
+			}
+			return stats, tx.Commit(ctx)
+		},
+	}
+	err = loop.run(ctx, lastID)
+	return stats, err
+}
+
+// batchLoop drives the keyset-paginated batch migration.
+// Each fetched batch is applied in chunks of opts.commitSize bookmarks, one transaction each.
+// Cancellation is only observed between chunks: a chunk that has started runs to commit
+// (or rolls back on its own error), so an interrupt never leaves a half-applied chunk.
+// Chunks commit in id order, so the resume point stays the last committed bookmark.
+type batchLoop struct {
+	total int64
+	opts  batchOptions
+	fetch func(ctx context.Context, lastID int64, limit int) ([]bookmarkRow, error)
+	// apply migrates bookmarks in one transaction.
+	apply func(ctx context.Context, bookmarks []bookmarkRow) (batchStats, error)
+	// out receives progress lines; nil means stdout.
+	out io.Writer
+	// totals, when set, accumulates the stats of every applied batch.
+	totals *batchStats
+}
+
+func (l batchLoop) run(ctx context.Context, lastID int64) error {
+	out := l.out
+	if out == nil {
+		out = os.Stdout
+	}
+	progressEvery := l.opts.progressEvery
+	if progressEvery <= 0 {
+		progressEvery = 1
 	}
 
 	var (
-		lastID          int64
 		processed       int64
+		batches         int
 		totalSkipped    int64
 		totalKeySkipped int64
+		pending         batchStats
+		pendingBatches  int
 	)
-
-	lastID, err = getResumeLastID(ctx, mysqlDB, pgDB)
-	if err != nil {
-		return err
-	}
-	if lastID > 0 {
-		fmt.Printf("[resume] Starting after bookmark id=%d based on latest entries.created_at\n", lastID)
+	printProgress := func() {
+		if pendingBatches == 0 {
+			return
+		}
+		progress := float64(0)
+		if l.total > 0 {
+			progress = float64(processed) * 100 / float64(l.total)
+		}
+		_, _ = fmt.Fprintf(out, "[batch] %d/%d (%.1f%%) | entries=%d | tags=%d | entry_tags=%d | skipped bookmarks=%d | skipped keyphrases=%d\n",
+			processed, l.total, progress, pending.insertedBookmarks, pending.insertedKeywords, pending.insertedKeyphrases, pending.skippedBookmarks, pending.skippedKeyphrases)
+		pending = batchStats{}
+		pendingBatches = 0
 	}
+	// Batches run on a context that ignores cancellation; ctx is checked between batches.
+	batchCtx := context.WithoutCancel(ctx)
 
 	for {
-		bookmarks, err := fetchBookmarksBatch(ctx, mysqlDB, lastID, batchSize)
+		if ctx.Err() != nil {
+			printProgress()
+			return &interruptedError{lastID: lastID, processed: processed}
+		}
+
+		bookmarks, err := l.fetch(batchCtx, lastID, l.opts.batchSize)
 		if err != nil {
 			return err
 		}
@@ -214,41 +383,44 @@ func migrateBatches(ctx context.Context, mysqlDB *sql.DB, pgDB *pgx.Conn) error
 			break
 		}
 
-		lastID = bookmarks[len(bookmarks)-1].id
-		processed += int64(len(bookmarks))
-
-		tx, err := pgDB.Begin(ctx)
-		if err != nil {
-			return err
+		commitSize := l.opts.commitSize
+		if commitSize <= 0 || commitSize > len(bookmarks) {
+			commitSize = len(bookmarks)
 		}
+		for start := 0; start < len(bookmarks); start += commitSize {
+			if start > 0 && ctx.Err() != nil {
+				printProgress()
+				return &interruptedError{lastID: lastID, processed: processed}
+			}
+			chunk := bookmarks[start:min(start+commitSize, len(bookmarks))]
+			stats, err := l.apply(batchCtx, chunk)
+			if err != nil {
+				return err
+			}
 
-		stats, err := migrateBatch(ctx, mysqlDB, tx, bookmarks)
-		if err != nil {
-			rollbackTx(ctx, tx)
-			return err
-		}
+			lastID = chunk[len(chunk)-1].id
+			processed += int64(len(chunk))
+			totalSkipped += stats.skippedBookmarks
+			totalKeySkipped += stats.skippedKeyphrases
 
-		if err := tx.Commit(ctx); err != nil {
-			return err
+			pending.add(stats)
+			if l.totals != nil {
+				l.totals.add(stats)
+			}
 		}
-
-		totalSkipped += stats.skippedBookmarks
-		totalKeySkipped += stats.skippedKeyphrases
-
-		progress := float64(0)
-		if total > 0 {
-			progress = float64(processed) * 100 / float64(total)
+		batches++
+		pendingBatches++
+		if batches%progressEvery == 0 {
+			printProgress()
 		}
-
-		fmt.Printf("[batch] %d/%d (%.1f%%) | entries=%d | tags=%d | entry_tags=%d | skipped bookmarks=%d | skipped keyphrases=%d\n",
-			processed, total, progress, stats.insertedBookmarks, stats.insertedKeywords, stats.insertedKeyphrases, stats.skippedBookmarks, stats.skippedKeyphrases)
 	}
+	printProgress()
 
 	if totalSkipped > 0 {
-		fmt.Printf("[bookmarks] Warning: Skipped %d records due to NULL/empty required fields\n", totalSkipped)
+		_, _ = fmt.Fprintf(out, "[bookmarks] Warning: Skipped %d records due to NULL/empty required fields\n", totalSkipped)
 	}
 	if totalKeySkipped > 0 {
-		fmt.Printf("[keyphrases] Warning: Skipped %d records due to missing mappings\n", totalKeySkipped)
+		_, _ = fmt.Fprintf(out, "[keyphrases] Warning: Skipped %d records due to missing mappings\n", totalKeySkipped)
 	}
 
 	return nil
@@ -492,7 +664,7 @@ func fetchKeywordsByIDs(ctx context.Context, db *sql.DB, keywordIDs map[int64]st
 			return nil, 0, err
 		}
 		// Sanitize UTF-8 immediately after reading from MySQL
-		keyword = sanitizeUTF8(keyword)
+		keyword = textutil.SanitizeUTF8(keyword)
 		if keyword == "" {
 			skippedEmpty++
 			continue
@@ -512,38 +684,25 @@ func ensureTags(ctx context.Context, tx pgx.Tx, keywords map[int64]string, now t
 		return map[int64]string{}, 0, nil
 	}
 
-	nameSet := make(map[string]struct{}, len(keywords))
-	names := make([]string, 0, len(keywords))
-	for _, name := range keywords {
-		// Sanitize and normalize the tag name
-		sanitized := sanitizeUTF8(name)
-		normalized := tag.NormalizeName(sanitized)
-
-		// Skip empty tags after normalization
-		if normalized == "" {
-			continue
-		}
-
-		if _, exists := nameSet[normalized]; exists {
-			continue
-		}
-		nameSet[normalized] = struct{}{}
-		names = append(names, normalized)
+	names, keywordToName := normalizeKeywords(keywords)
+	if len(names) == 0 {
+		return map[int64]string{}, 0, nil
 	}
 
-	var inserted int64
-	for _, name := range names {
-		newID := uuid.New().String()
-		commandTag, err := tx.Exec(ctx, `
-			INSERT INTO tags (id, name, created_at)
-			VALUES ($1, $2, $3)
-			ON CONFLICT (name) DO NOTHING
-		`, newID, name, now)
-		if err != nil {
-			return nil, 0, err
-		}
-		inserted += commandTag.RowsAffected()
+	ids := make([]uuid.UUID, len(names))
+	for i := range names {
+		ids[i] = uuid.New()
+	}
+	commandTag, err := tx.Exec(ctx, `
+		INSERT INTO tags (id, name, created_at)
+		SELECT id, name, $3
+		FROM unnest($1::uuid[], $2::text[]) AS t(id, name)
+		ON CONFLICT (name) DO NOTHING
+	`, ids, names, now)
+	if err != nil {
+		return nil, 0, err
 	}
+	inserted := commandTag.RowsAffected()
 
 	rows, err := tx.Query(ctx, "SELECT id, name FROM tags WHERE name = ANY($1)", names)
 	if err != nil {
@@ -564,35 +723,51 @@ func ensureTags(ctx context.Context, tx pgx.Tx, keywords map[int64]string, now t
 		return nil, 0, err
 	}
 
-	keywordToTag := make(map[int64]string, len(keywords))
-	for keywordID, name := range keywords {
-		// Apply the same normalization to match what was inserted
-		sanitized := sanitizeUTF8(name)
-		normalized := tag.NormalizeName(sanitized)
+	keywordToTag, err := mapKeywordsToTags(keywordToName, nameToID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return keywordToTag, inserted, nil
+}
+
+// normalizeKeywords returns the distinct normalized tag names in first-seen keyword ID order,
+// and the normalized name of every keyword. Keywords that normalize to an empty name are dropped.
+func normalizeKeywords(keywords map[int64]string) ([]string, map[int64]string) {
+	keywordIDs := make([]int64, 0, len(keywords))
+	for id := range keywords {
+		keywordIDs = append(keywordIDs, id)
+	}
+	sort.Slice(keywordIDs, func(i, j int) bool { return keywordIDs[i] < keywordIDs[j] })
 
+	nameSet := make(map[string]struct{}, len(keywords))
+	names := make([]string, 0, len(keywords))
+	keywordToName := make(map[int64]string, len(keywords))
+	for _, id := range keywordIDs {
+		normalized := tag.NormalizeName(textutil.SanitizeUTF8(keywords[id]))
 		if normalized == "" {
 			continue
 		}
-
-		tagID, ok := nameToID[normalized]
-		if !ok {
-			return nil, 0, fmt.Errorf("tag id not found for keyword: %s", normalized)
+		keywordToName[id] = normalized
+		if _, exists := nameSet[normalized]; exists {
+			continue
 		}
-		keywordToTag[keywordID] = tagID
+		nameSet[normalized] = struct{}{}
+		names = append(names, normalized)
 	}
-
-	return keywordToTag, inserted, nil
+	return names, keywordToName
 }
 
-// sanitizeUTF8 removes invalid UTF-8 sequences from a string
-func sanitizeUTF8(s string) string {
-	if utf8.ValidString(s) {
-		return s
+// mapKeywordsToTags resolves each keyword's normalized name to a tag ID.
+func mapKeywordsToTags(keywordToName map[int64]string, nameToID map[string]string) (map[int64]string, error) {
+	keywordToTag := make(map[int64]string, len(keywordToName))
+	for keywordID, name := range keywordToName {
+		tagID, ok := nameToID[name]
+		if !ok {
+			return nil, fmt.Errorf("tag id not found for keyword: %s", name)
+		}
+		keywordToTag[keywordID] = tagID
 	}
-
-	// Convert to valid UTF-8 by replacing invalid sequences
-	// strings.ToValidUTF8 replaces invalid UTF-8 with the replacement string
-	return strings.ToValidUTF8(s, "")
+	return keywordToTag, nil
 }
 
 func buildPlaceholders(count int) string {
@@ -700,7 +875,7 @@ func unixToTimestamp(unixTime int64) time.Time {
 	return time.Unix(unixTime, 0).UTC()
 }
 
-func verifyMigration(ctx context.Context, mysqlDB *sql.DB, pgDB *pgx.Conn) error {
+func verifyMigration(ctx context.Context, mysqlDB *sql.DB, pgDB *pgx.Conn, stats batchStats, reportPath string) error {
 	mysqlCount, err := getValidBookmarksCount(ctx, mysqlDB)
 	if err != nil {
 		return fmt.Errorf("failed to count valid bookmarks: %w", err)
@@ -711,17 +886,95 @@ func verifyMigration(ctx context.Context, mysqlDB *sql.DB, pgDB *pgx.Conn) error
 		return fmt.Errorf("failed to count entries: %w", err)
 	}
 
-	status := "✓"
-	if mysqlCount != pgCount {
-		status = "✗"
+	report := newVerificationReport([]tableCount{
+		{Source: "bookmarks(valid)", Target: "entries", SourceCount: mysqlCount, TargetCount: pgCount},
+	}, stats)
+	report.print(os.Stdout)
+	if reportPath != "" {
+		if err := report.writeJSON(reportPath); err != nil {
+			return fmt.Errorf("failed to write verification report: %w", err)
+		}
 	}
 
-	fmt.Printf("%s bookmarks(valid) -> entries: MySQL=%d, PostgreSQL=%d\n", status, mysqlCount, pgCount)
-
-	if mysqlCount != pgCount {
+	if !report.Passed {
 		return fmt.Errorf("row count mismatch detected")
 	}
-
-	fmt.Println("\n✓ Migration verified successfully!")
 	return nil
 }
+
+// verificationReport is the result of the final verification. It is printed to the console
+// and, with MIGRATION_REPORT_JSON, written as JSON for CI.
+type verificationReport struct {
+	Passed bool         `json:"passed"`
+	Tables []tableCount `json:"tables"`
+	// Skipped counts the rows this run skipped, by reason. A resumed run counts only the
+	// bookmarks it processed itself.
+	Skipped map[string]int64 `json:"skipped"`
+}
+
+// tableCount compares the row counts of a source table and its migration target.
+type tableCount struct {
+	Source      string `json:"source"`
+	Target      string `json:"target"`
+	SourceCount int64  `json:"source_count"`
+	TargetCount int64  `json:"target_count"`
+	Match       bool   `json:"match"`
+}
+
+// Skip reasons reported in verificationReport.Skipped.
+const (
+	skipReasonBookmarkRequiredFields = "bookmark_required_fields"
+	skipReasonKeyphraseMapping       = "keyphrase_missing_mapping"
+	skipReasonEmptyKeyword           = "empty_keyword"
+)
+
+func newVerificationReport(tables []tableCount, stats batchStats) verificationReport {
+	report := verificationReport{
+		Passed: true,
+		Tables: make([]tableCount, 0, len(tables)),
+		Skipped: map[string]int64{
+			skipReasonBookmarkRequiredFields: stats.skippedBookmarks,
+			skipReasonKeyphraseMapping:       stats.skippedKeyphrases,
+			skipReasonEmptyKeyword:           stats.skippedEmptyKeyword,
+		},
+	}
+	for _, t := range tables {
+		t.Match = t.SourceCount == t.TargetCount
+		if !t.Match {
+			report.Passed = false
+		}
+		report.Tables = append(report.Tables, t)
+	}
+	return report
+}
+
+// print writes the human-readable summary.
+func (r verificationReport) print(out io.Writer) {
+	for _, t := range r.Tables {
+		status := "✓"
+		if !t.Match {
+			status = "✗"
+		}
+		_, _ = fmt.Fprintf(out, "%s %s -> %s: MySQL=%d, PostgreSQL=%d\n", status, t.Source, t.Target, t.SourceCount, t.TargetCount)
+	}
+	reasons := make([]string, 0, len(r.Skipped))
+	for reason := range r.Skipped {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		_, _ = fmt.Fprintf(out, "  skipped %s=%d\n", reason, r.Skipped[reason])
+	}
+	if r.Passed {
+		_, _ = fmt.Fprintln(out, "\n✓ Migration verified successfully!")
+	}
+}
+
+// writeJSON writes the report to path, replacing an existing file.
+func (r verificationReport) writeJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func bookmarkRange(from, to int64) []bookmarkRow {
+	rows := make([]bookmarkRow, 0, to-from+1)
+	for id := from; id <= to; id++ {
+		rows = append(rows, bookmarkRow{id: id})
+	}
+	return rows
+}
+
+func TestBatchLoopStopsBetweenBatchesOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var applied [][]bookmarkRow
+	loop := batchLoop{
+		total: 6,
+		out:   io.Discard,
+		fetch: func(ctx context.Context, lastID int64, limit int) ([]bookmarkRow, error) {
+			if lastID >= 6 {
+				return nil, nil
+			}
+			return bookmarkRange(lastID+1, lastID+2), nil
+		},
+		apply: func(ctx context.Context, bookmarks []bookmarkRow) (batchStats, error) {
+			// The interrupt arrives while the second batch is in flight.
+			if len(applied) == 1 {
+				cancel()
+			}
+			require.NoError(t, ctx.Err(), "in-flight batch must not observe cancellation")
+			applied = append(applied, bookmarks)
+			return batchStats{processedBookmarks: int64(len(bookmarks))}, nil
+		},
+	}
+
+	err := loop.run(ctx, 0)
+
+	var interrupted *interruptedError
+	require.ErrorAs(t, err, &interrupted)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, int64(4), interrupted.lastID)
+	require.Equal(t, int64(4), interrupted.processed)
+	require.Len(t, applied, 2)
+}
+
+func TestBatchLoopResumesFromLastID(t *testing.T) {
+	var fetchedFrom []int64
+	loop := batchLoop{
+		out: io.Discard,
+		fetch: func(ctx context.Context, lastID int64, limit int) ([]bookmarkRow, error) {
+			fetchedFrom = append(fetchedFrom, lastID)
+			if lastID >= 14 {
+				return nil, nil
+			}
+			return bookmarkRange(lastID+1, lastID+2), nil
+		},
+		apply: func(ctx context.Context, bookmarks []bookmarkRow) (batchStats, error) {
+			return batchStats{}, nil
+		},
+	}
+
+	require.NoError(t, loop.run(context.Background(), 10))
+	require.Equal(t, []int64{10, 12, 14}, fetchedFrom)
+}
+
+func TestBatchLoopReturnsApplyError(t *testing.T) {
+	boom := errors.New("insert failed")
+	loop := batchLoop{
+		out: io.Discard,
+		fetch: func(ctx context.Context, lastID int64, limit int) ([]bookmarkRow, error) {
+			return bookmarkRange(lastID+1, lastID+1), nil
+		},
+		apply: func(ctx context.Context, bookmarks []bookmarkRow) (batchStats, error) {
+			return batchStats{}, boom
+		},
+	}
+
+	require.ErrorIs(t, loop.run(context.Background(), 0), boom)
+}
+
+func TestBatchLoopUsesConfiguredBatchSize(t *testing.T) {
+	var limits []int
+	loop := batchLoop{
+		opts: batchOptions{batchSize: 250},
+		out:  io.Discard,
+		fetch: func(ctx context.Context, lastID int64, limit int) ([]bookmarkRow, error) {
+			limits = append(limits, limit)
+			if lastID >= 500 {
+				return nil, nil
+			}
+			return bookmarkRange(lastID+1, lastID+int64(limit)), nil
+		},
+		apply: func(ctx context.Context, bookmarks []bookmarkRow) (batchStats, error) {
+			return batchStats{}, nil
+		},
+	}
+
+	require.NoError(t, loop.run(context.Background(), 0))
+	require.Equal(t, []int{250, 250, 250}, limits)
+}
+
+func TestBatchLoopProgressEvery(t *testing.T) {
+	var out bytes.Buffer
+	loop := batchLoop{
+		total: 5,
+		opts:  batchOptions{batchSize: 1, progressEvery: 2},
+		out:   &out,
+		fetch: func(ctx context.Context, lastID int64, limit int) ([]bookmarkRow, error) {
+			if lastID >= 5 {
+				return nil, nil
+			}
+			return bookmarkRange(lastID+1, lastID+1), nil
+		},
+		apply: func(ctx context.Context, bookmarks []bookmarkRow) (batchStats, error) {
+			return batchStats{insertedBookmarks: 1}, nil
+		},
+	}
+
+	require.NoError(t, loop.run(context.Background(), 0))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	require.True(t, strings.HasPrefix(lines[0], "[batch] 2/5 (40.0%) | entries=2 |"), lines[0])
+	require.True(t, strings.HasPrefix(lines[1], "[batch] 4/5 (80.0%) | entries=2 |"), lines[1])
+	require.True(t, strings.HasPrefix(lines[2], "[batch] 5/5 (100.0%) | entries=1 |"), lines[2])
+}
+
+func TestBatchLoopCommitsInChunks(t *testing.T) {
+	var chunks [][]int64
+	loop := batchLoop{
+		opts: batchOptions{batchSize: 5, commitSize: 2},
+		out:  io.Discard,
+		fetch: func(ctx context.Context, lastID int64, limit int) ([]bookmarkRow, error) {
+			if lastID >= 10 {
+				return nil, nil
+			}
+			return bookmarkRange(lastID+1, lastID+int64(limit)), nil
+		},
+		apply: func(ctx context.Context, bookmarks []bookmarkRow) (batchStats, error) {
+			ids := make([]int64, 0, len(bookmarks))
+			for _, bm := range bookmarks {
+				ids = append(ids, bm.id)
+			}
+			chunks = append(chunks, ids)
+			return batchStats{}, nil
+		},
+	}
+
+	require.NoError(t, loop.run(context.Background(), 0))
+	require.Equal(t, [][]int64{{1, 2}, {3, 4}, {5}, {6, 7}, {8, 9}, {10}}, chunks)
+}
+
+func TestBatchLoopChunkCommitsResumeWithoutDuplicates(t *testing.T) {
+	// committed maps bookmark id to the number of times it was inserted; a failed chunk
+	// rolls back, so it inserts nothing.
+	committed := map[int64]int{}
+	boom := errors.New("boom")
+	failOnce := true
+	newLoop := func() batchLoop {
+		return batchLoop{
+			opts: batchOptions{batchSize: 4, commitSize: 2},
+			out:  io.Discard,
+			fetch: func(ctx context.Context, lastID int64, limit int) ([]bookmarkRow, error) {
+				if lastID >= 12 {
+					return nil, nil
+				}
+				return bookmarkRange(lastID+1, min(lastID+int64(limit), 12)), nil
+			},
+			apply: func(ctx context.Context, bookmarks []bookmarkRow) (batchStats, error) {
+				if failOnce && bookmarks[0].id == 7 {
+					failOnce = false
+					return batchStats{}, boom
+				}
+				for _, bm := range bookmarks {
+					committed[bm.id]++
+				}
+				return batchStats{insertedBookmarks: int64(len(bookmarks))}, nil
+			},
+		}
+	}
+	// resumeID mirrors getResumeLastID: the latest committed bookmark.
+	resumeID := func() int64 {
+		var last int64
+		for id := range committed {
+			last = max(last, id)
+		}
+		return last
+	}
+
+	// The second batch (5-8) fails after its first chunk (5-6) has committed.
+	require.ErrorIs(t, newLoop().run(context.Background(), 0), boom)
+	require.Equal(t, int64(6), resumeID())
+
+	require.NoError(t, newLoop().run(context.Background(), resumeID()))
+	require.Len(t, committed, 12)
+	for id := int64(1); id <= 12; id++ {
+		require.Equal(t, 1, committed[id], "bookmark %d", id)
+	}
+}
+
+func TestBatchLoopStopsBetweenChunksOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var applied int
+	loop := batchLoop{
+		opts: batchOptions{batchSize: 6, commitSize: 2},
+		out:  io.Discard,
+		fetch: func(ctx context.Context, lastID int64, limit int) ([]bookmarkRow, error) {
+			return bookmarkRange(lastID+1, lastID+int64(limit)), nil
+		},
+		apply: func(ctx context.Context, bookmarks []bookmarkRow) (batchStats, error) {
+			applied++
+			cancel()
+			return batchStats{}, nil
+		},
+	}
+
+	var interrupted *interruptedError
+	require.ErrorAs(t, loop.run(ctx, 0), &interrupted)
+	require.Equal(t, 1, applied)
+	require.Equal(t, int64(2), interrupted.lastID)
+	require.Equal(t, int64(2), interrupted.processed)
+}
+
+func TestConfigValidateBatchSize(t *testing.T) {
+	require.NoError(t, Config{BatchSize: 1000}.Validate())
+	require.Error(t, Config{BatchSize: 0}.Validate())
+	require.Error(t, Config{BatchSize: -1}.Validate())
+	require.NoError(t, Config{BatchSize: 1000, CommitSize: 250}.Validate())
+	require.Error(t, Config{BatchSize: 1000, CommitSize: -1}.Validate())
+}
+
+func TestNormalizeKeywordsDedupesNormalizedNames(t *testing.T) {
+	keywords := map[int64]string{
+		1: "Go",
+		2: "  go ",
+		3: "GO\x00",
+		4: "Rust",
+		5: "   ",
+		6: "rust",
+	}
+
+	names, keywordToName := normalizeKeywords(keywords)
+	require.Equal(t, []string{"go", "rust"}, names)
+	require.Equal(t, map[int64]string{1: "go", 2: "go", 3: "go", 4: "rust", 6: "rust"}, keywordToName)
+
+	keywordToTag, err := mapKeywordsToTags(keywordToName, map[string]string{"go": "tag-go", "rust": "tag-rust"})
+	require.NoError(t, err)
+	require.Equal(t, map[int64]string{
+		1: "tag-go",
+		2: "tag-go",
+		3: "tag-go",
+		4: "tag-rust",
+		6: "tag-rust",
+	}, keywordToTag)
+}
+
+func TestMapKeywordsToTagsMissingTag(t *testing.T) {
+	_, err := mapKeywordsToTags(map[int64]string{1: "go"}, map[string]string{})
+	require.EqualError(t, err, "tag id not found for keyword: go")
+}
+
+func TestBatchLoopAccumulatesTotals(t *testing.T) {
+	var totals batchStats
+	loop := batchLoop{
+		out:    io.Discard,
+		opts:   batchOptions{batchSize: 2},
+		totals: &totals,
+		fetch: func(ctx context.Context, lastID int64, limit int) ([]bookmarkRow, error) {
+			if lastID >= 4 {
+				return nil, nil
+			}
+			return bookmarkRange(lastID+1, lastID+2), nil
+		},
+		apply: func(ctx context.Context, bookmarks []bookmarkRow) (batchStats, error) {
+			return batchStats{skippedBookmarks: 1, skippedKeyphrases: 2, skippedEmptyKeyword: 3}, nil
+		},
+	}
+
+	require.NoError(t, loop.run(context.Background(), 0))
+	require.Equal(t, batchStats{skippedBookmarks: 2, skippedKeyphrases: 4, skippedEmptyKeyword: 6}, totals)
+}
+
+func TestVerificationReportJSONMatchesPrintedSummary(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		target int64
+		passed bool
+	}{
+		{name: "match", target: 10, passed: true},
+		{name: "mismatch", target: 9, passed: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			report := newVerificationReport([]tableCount{
+				{Source: "bookmarks(valid)", Target: "entries", SourceCount: 10, TargetCount: tt.target},
+			}, batchStats{skippedBookmarks: 2, skippedKeyphrases: 5})
+			require.Equal(t, tt.passed, report.Passed)
+
+			var printed bytes.Buffer
+			report.print(&printed)
+			path := filepath.Join(t.TempDir(), "report.json")
+			require.NoError(t, report.writeJSON(path))
+
+			raw, err := os.ReadFile(path)
+			require.NoError(t, err)
+			var decoded verificationReport
+			require.NoError(t, json.Unmarshal(raw, &decoded))
+			require.Equal(t, report, decoded)
+
+			// Every value in the JSON report appears in the console summary.
+			out := printed.String()
+			for _, table := range decoded.Tables {
+				status := "✓"
+				if !table.Match {
+					status = "✗"
+				}
+				require.Contains(t, out, fmt.Sprintf("%s %s -> %s: MySQL=%d, PostgreSQL=%d\n",
+					status, table.Source, table.Target, table.SourceCount, table.TargetCount))
+			}
+			require.Len(t, decoded.Skipped, 3)
+			for reason, count := range decoded.Skipped {
+				require.Contains(t, out, fmt.Sprintf("skipped %s=%d\n", reason, count))
+			}
+			require.Equal(t, decoded.Passed, strings.Contains(out, "Migration verified successfully"))
+		})
+	}
+}
@@ -19,11 +19,22 @@ type APIKey struct {
 	Description      *string
 	CreatedAt        time.Time
 	ExpiresAt        *time.Time
+	RevokedAt        *time.Time
 	CreatedIP        *string
 	CreatedUserAgent *string
 	CreatedReferrer  *string
 }
 
+// IsExpired reports whether the key has an expiry at or before now.
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// IsRevoked reports whether the key has been revoked.
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
 // Params contains parameters for creating an API key.
 type Params struct {
 	ID               ID
@@ -21,3 +21,21 @@ func ValidateMinUsers(value int) error {
 	}
 	return nil
 }
+
+// allowedMinUsers lists the archive thresholds in descending order.
+var allowedMinUsers = []int{1000, 500, 100, 50, 10, 5}
+
+// LowerMinUsers returns the archive thresholds below value in descending order, followed by 0.
+// It is the ladder used to relax a min_users filter step by step.
+func LowerMinUsers(value int) []int {
+	if value <= 0 {
+		return nil
+	}
+	var out []int
+	for _, threshold := range allowedMinUsers {
+		if threshold < value {
+			out = append(out, threshold)
+		}
+	}
+	return append(out, 0)
+}
@@ -0,0 +1,15 @@
+package archive
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLowerMinUsers(t *testing.T) {
+	assert.Equal(t, []int{50, 10, 5, 0}, LowerMinUsers(100))
+	assert.Equal(t, []int{5, 0}, LowerMinUsers(7))
+	assert.Equal(t, []int{0}, LowerMinUsers(5))
+	assert.Equal(t, []int{1000, 500, 100, 50, 10, 5, 0}, LowerMinUsers(5000))
+	assert.Nil(t, LowerMinUsers(0))
+}
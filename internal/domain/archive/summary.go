@@ -0,0 +1,37 @@
+package archive
+
+import (
+	"time"
+
+	"hateblog/internal/domain/repository"
+)
+
+// Summary aggregates archive counts.
+type Summary struct {
+	// Total is the sum of all daily counts.
+	Total int
+	// From and To are the earliest and latest dates covered. Both are zero when there are no items.
+	From time.Time
+	To   time.Time
+}
+
+// IsEmpty reports whether the summary covers no days.
+func (s Summary) IsEmpty() bool {
+	return s.From.IsZero() && s.To.IsZero()
+}
+
+// Summarize totals the daily counts and finds the date range they cover.
+// Items may be in any order.
+func Summarize(items []repository.ArchiveCount) Summary {
+	var s Summary
+	for _, item := range items {
+		s.Total += item.Count
+		if s.From.IsZero() || item.Date.Before(s.From) {
+			s.From = item.Date
+		}
+		if s.To.IsZero() || item.Date.After(s.To) {
+			s.To = item.Date
+		}
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package archive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"hateblog/internal/domain/repository"
+)
+
+func TestSummarize(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name  string
+		items []repository.ArchiveCount
+		want  Summary
+	}{
+		{
+			name:  "empty",
+			items: nil,
+			want:  Summary{},
+		},
+		{
+			name:  "single day",
+			items: []repository.ArchiveCount{{Date: day(5), Count: 12}},
+			want:  Summary{Total: 12, From: day(5), To: day(5)},
+		},
+		{
+			name: "multiple days in descending order",
+			items: []repository.ArchiveCount{
+				{Date: day(20), Count: 3},
+				{Date: day(10), Count: 7},
+				{Date: day(1), Count: 5},
+			},
+			want: Summary{Total: 15, From: day(1), To: day(20)},
+		},
+		{
+			name: "unordered days",
+			items: []repository.ArchiveCount{
+				{Date: day(10), Count: 1},
+				{Date: day(31), Count: 2},
+				{Date: day(2), Count: 0},
+			},
+			want: Summary{Total: 3, From: day(2), To: day(31)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Summarize(tt.items)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.items) == 0, got.IsEmpty())
+		})
+	}
+}
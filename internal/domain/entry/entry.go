@@ -3,6 +3,7 @@ package entry
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/url"
 	"sort"
 	"strings"
@@ -16,6 +17,9 @@ import (
 // ErrInvalidEntry signals invalid entry parameters.
 var ErrInvalidEntry = errors.New("invalid entry")
 
+// ErrNotFound signals that the entry does not exist.
+var ErrNotFound = errors.New("entry not found")
+
 // ErrInvalidListQuery signals invalid query parameters.
 var ErrInvalidListQuery = errors.New("invalid list query")
 
@@ -30,15 +34,110 @@ const (
 	SortNew SortType = "new"
 	// SortHot orders entries by bookmark_count DESC.
 	SortHot SortType = "hot"
+	// SortTrending orders entries by Hotness DESC. It applies to in-memory day lists only and
+	// is not accepted by ListQuery.
+	SortTrending SortType = "trending"
+	// SortDiscovered orders a day's new entries by created_at DESC, when they were ingested.
+	// It is the default order of the new list and names it explicitly.
+	SortDiscovered SortType = "discovered"
+	// SortPosted orders a day's new entries by PostedLess. Like SortTrending it applies to
+	// in-memory day lists only.
+	SortPosted SortType = "posted"
 )
 
+// HotTiebreak orders SortHot entries that have the same bookmark_count.
+type HotTiebreak string
+
+const (
+	// HotTiebreakNewest puts the most recently created entry first. It is the default.
+	HotTiebreakNewest HotTiebreak = "newest"
+	// HotTiebreakOldest puts the earliest created entry first.
+	HotTiebreakOldest HotTiebreak = "oldest"
+	// HotTiebreakTitle orders tied entries by title.
+	HotTiebreakTitle HotTiebreak = "title"
+)
+
+// Valid reports whether t is a known tiebreak. The empty value means HotTiebreakNewest.
+func (t HotTiebreak) Valid() bool {
+	switch t {
+	case "", HotTiebreakNewest, HotTiebreakOldest, HotTiebreakTitle:
+		return true
+	default:
+		return false
+	}
+}
+
+// HotLess reports whether a ranks before b in a SortHot list broken by t.
+func HotLess(a, b *Entry, t HotTiebreak) bool {
+	if a.BookmarkCount != b.BookmarkCount {
+		return a.BookmarkCount > b.BookmarkCount
+	}
+	switch t {
+	case HotTiebreakOldest:
+		return a.CreatedAt.Before(b.CreatedAt)
+	case HotTiebreakTitle:
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	default:
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+}
+
+// PostedLess reports whether a ranks before b in a list ordered by posted_at DESC, then
+// created_at DESC.
+func PostedLess(a, b *Entry) bool {
+	if !a.PostedAt.Equal(b.PostedAt) {
+		return a.PostedAt.After(b.PostedAt)
+	}
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+// HotnessGravity is the exponent of the age penalty in Hotness. Larger values make scores
+// decay faster.
+const HotnessGravity = 1.8
+
+// Hotness returns the time-decayed popularity of e at now, Hacker News style:
+// bookmark_count / (age in hours + 2)^HotnessGravity, where age counts from PostedAt.
+// Entries posted after now are treated as brand new.
+func (e *Entry) Hotness(now time.Time) float64 {
+	if e.BookmarkCount <= 0 {
+		return 0
+	}
+	age := now.Sub(e.PostedAt).Hours()
+	if age < 0 {
+		age = 0
+	}
+	return float64(e.BookmarkCount) / math.Pow(age+2, HotnessGravity)
+}
+
+// TrendingLess reports whether a ranks before b in a SortTrending list at now.
+// Equal scores fall back to the default SortHot order.
+func TrendingLess(a, b *Entry, now time.Time) bool {
+	if ha, hb := a.Hotness(now), b.Hotness(now); ha != hb {
+		return ha > hb
+	}
+	return HotLess(a, b, HotTiebreakNewest)
+}
+
 const (
 	// DefaultLimit is used when ListQuery.Limit is zero.
 	DefaultLimit = 20
 	// MaxLimit caps ListQuery.Limit to avoid unbounded queries.
 	MaxLimit = 1000
+	// MaxBatchIDs caps the number of IDs looked up at once by ID.
+	MaxBatchIDs = 100
+	// DefaultRankingMinBookmarks is the default floor of the bookmark count rankings filter on.
+	DefaultRankingMinBookmarks = 3
 )
 
+// RankingMinBookmarks returns the bookmark count a ranking filters on: the requested count
+// raised to floor. Higher requests are kept; negative values count as 0.
+func RankingMinBookmarks(requested, floor int) int {
+	return max(requested, floor, 0)
+}
+
 // Entry represents a hateblog entry domain model.
 type Entry struct {
 	ID            ID
@@ -53,6 +152,20 @@ type Entry struct {
 	UpdatedAt     time.Time
 }
 
+// Source is a URL host with the number of entries posted from it.
+type Source struct {
+	Host       string
+	EntryCount int64
+}
+
+// ClickedEntry is an entry with the number of clicks it received in a period.
+type ClickedEntry struct {
+	ID         ID
+	Title      string
+	URL        string
+	ClickCount int64
+}
+
 // Tagging represents an attached tag with score.
 type Tagging struct {
 	TagID tag.ID
@@ -189,11 +302,21 @@ type ListQuery struct {
 	Limit            int
 	Sort             SortType
 	Keyword          string
+	// MatchTags makes a keyword term also match entries with a tag name containing it.
+	MatchTags bool
+	// EnglishSubstring makes English keyword terms match as substrings, like CJK terms,
+	// instead of whole words ("go" then also matches "google").
+	EnglishSubstring bool
+	// HotTiebreak orders entries with equal bookmark counts when Sort is SortHot.
+	HotTiebreak HotTiebreak
 	// PostedAtFrom/To are kept for API compatibility.
 	// Repository implementations currently apply this range to created_at.
 	PostedAtFrom     time.Time
 	PostedAtTo       time.Time
 	MaxLimitOverride int
+	// ExcludeHosts drops entries whose URL host matches one of these names exactly,
+	// regardless of scheme.
+	ExcludeHosts []string
 }
 
 // Normalize validates and applies defaults to the query.
@@ -223,6 +346,9 @@ func (q *ListQuery) Normalize() error {
 	default:
 		return fmt.Errorf("%w: unsupported sort %q", ErrInvalidListQuery, q.Sort)
 	}
+	if !q.HotTiebreak.Valid() {
+		return fmt.Errorf("%w: unsupported hot tiebreak %q", ErrInvalidListQuery, q.HotTiebreak)
+	}
 
 	if !q.PostedAtFrom.IsZero() {
 		q.PostedAtFrom = q.PostedAtFrom.In(time.Local)
@@ -241,5 +367,31 @@ func (q *ListQuery) Normalize() error {
 		sort.Strings(q.Tags)
 	}
 
+	q.ExcludeHosts = normalizeHosts(q.ExcludeHosts)
+
 	return nil
 }
+
+func normalizeHosts(hosts []string) []string {
+	if len(hosts) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(hosts))
+	seen := make(map[string]struct{}, len(hosts))
+	for _, h := range hosts {
+		h = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(h)), ".")
+		if h == "" {
+			continue
+		}
+		if _, ok := seen[h]; ok {
+			continue
+		}
+		seen[h] = struct{}{}
+		out = append(out, h)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	sort.Strings(out)
+	return out
+}
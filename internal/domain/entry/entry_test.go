@@ -1,6 +1,8 @@
 package entry
 
 import (
+	"math"
+	"sort"
 	"testing"
 	"time"
 
@@ -588,6 +590,15 @@ func TestListQuery_Normalize(t *testing.T) {
 			wantErr: true,
 			errMsg:  "unsupported sort",
 		},
+		{
+			name: "invalid hot tiebreak",
+			query: ListQuery{
+				Sort:        SortHot,
+				HotTiebreak: "random",
+			},
+			wantErr: true,
+			errMsg:  "unsupported hot tiebreak",
+		},
 		{
 			name: "converts posted_at_from to UTC",
 			query: ListQuery{
@@ -666,6 +677,18 @@ func TestListQuery_Normalize(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "normalizes excluded hosts",
+			query: ListQuery{
+				ExcludeHosts: []string{" Example.COM ", "blog.example.org.", "", "example.com"},
+			},
+			want: ListQuery{
+				Limit:        DefaultLimit,
+				Sort:         SortNew,
+				ExcludeHosts: []string{"blog.example.org", "example.com"},
+			},
+			wantErr: false,
+		},
 		{
 			name: "complex valid query",
 			query: ListQuery{
@@ -706,6 +729,7 @@ func TestListQuery_Normalize(t *testing.T) {
 				assert.Equal(t, tt.want.Sort, q.Sort)
 				assert.Equal(t, tt.want.Keyword, q.Keyword)
 				assert.Equal(t, tt.want.Tags, q.Tags)
+				assert.Equal(t, tt.want.ExcludeHosts, q.ExcludeHosts)
 				assert.Equal(t, tt.want.MinBookmarkCount, q.MinBookmarkCount)
 				assert.Equal(t, tt.want.Offset, q.Offset)
 
@@ -719,3 +743,86 @@ func TestListQuery_Normalize(t *testing.T) {
 		})
 	}
 }
+
+func TestHotLess(t *testing.T) {
+	now := time.Now()
+	top := &Entry{Title: "Zeta", BookmarkCount: 20, CreatedAt: now.Add(-3 * time.Hour)}
+	older := &Entry{Title: "Alpha", BookmarkCount: 10, CreatedAt: now.Add(-2 * time.Hour)}
+	newer := &Entry{Title: "Beta", BookmarkCount: 10, CreatedAt: now.Add(-1 * time.Hour)}
+
+	tests := []struct {
+		tiebreak HotTiebreak
+		want     []*Entry
+	}{
+		{"", []*Entry{top, newer, older}},
+		{HotTiebreakNewest, []*Entry{top, newer, older}},
+		{HotTiebreakOldest, []*Entry{top, older, newer}},
+		{HotTiebreakTitle, []*Entry{top, older, newer}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.tiebreak), func(t *testing.T) {
+			got := []*Entry{older, newer, top}
+			sort.Slice(got, func(i, j int) bool { return HotLess(got[i], got[j], tt.tiebreak) })
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.True(t, HotTiebreakTitle.Valid())
+	assert.False(t, HotTiebreak("random").Valid())
+}
+
+func TestEntry_Hotness(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	entryAt := func(count int, age time.Duration) *Entry {
+		return &Entry{BookmarkCount: count, PostedAt: now.Add(-age)}
+	}
+
+	tests := []struct {
+		name  string
+		entry *Entry
+		want  float64
+	}{
+		{"brand new", entryAt(100, 0), 100 / math.Pow(2, HotnessGravity)},
+		{"one hour", entryAt(100, time.Hour), 100 / math.Pow(3, HotnessGravity)},
+		{"one day", entryAt(100, 24*time.Hour), 100 / math.Pow(26, HotnessGravity)},
+		{"one week", entryAt(1000, 7*24*time.Hour), 1000 / math.Pow(170, HotnessGravity)},
+		{"posted in the future", entryAt(100, -time.Hour), 100 / math.Pow(2, HotnessGravity)},
+		{"no bookmarks", entryAt(0, time.Hour), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, tt.entry.Hotness(now), 1e-9)
+		})
+	}
+
+	// The score decays monotonically with age and grows with the bookmark count.
+	prev := math.Inf(1)
+	for _, hours := range []int{0, 1, 3, 6, 12, 24, 48, 168} {
+		score := entryAt(50, time.Duration(hours)*time.Hour).Hotness(now)
+		assert.Less(t, score, prev, "age %dh", hours)
+		prev = score
+	}
+	assert.Greater(t, entryAt(60, 6*time.Hour).Hotness(now), entryAt(50, 6*time.Hour).Hotness(now))
+	// A fresh entry overtakes an older one with several times the bookmarks.
+	assert.Greater(t, entryAt(20, time.Hour).Hotness(now), entryAt(200, 24*time.Hour).Hotness(now))
+}
+
+func TestTrendingLess(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	fresh := &Entry{Title: "fresh", BookmarkCount: 20, PostedAt: now.Add(-time.Hour)}
+	stale := &Entry{Title: "stale", BookmarkCount: 200, PostedAt: now.Add(-24 * time.Hour)}
+	tiedOld := &Entry{Title: "tied old", BookmarkCount: 10, PostedAt: now.Add(-2 * time.Hour), CreatedAt: now.Add(-2 * time.Hour)}
+	tiedNew := &Entry{Title: "tied new", BookmarkCount: 10, PostedAt: now.Add(-2 * time.Hour), CreatedAt: now.Add(-time.Hour)}
+
+	got := []*Entry{tiedOld, stale, tiedNew, fresh}
+	sort.Slice(got, func(i, j int) bool { return TrendingLess(got[i], got[j], now) })
+	assert.Equal(t, []*Entry{fresh, tiedNew, tiedOld, stale}, got)
+}
+
+func TestRankingMinBookmarks(t *testing.T) {
+	assert.Equal(t, 3, RankingMinBookmarks(1, 3))
+	assert.Equal(t, 3, RankingMinBookmarks(-5, 3))
+	assert.Equal(t, 3, RankingMinBookmarks(3, 3))
+	assert.Equal(t, 50, RankingMinBookmarks(50, 3))
+	assert.Equal(t, 0, RankingMinBookmarks(-5, 0))
+}
@@ -14,12 +14,15 @@ import (
 // EntryRepository defines storage operations for entries.
 type EntryRepository interface {
 	Get(ctx context.Context, id entry.ID) (*entry.Entry, error)
+	GetByIDs(ctx context.Context, ids []entry.ID) ([]*entry.Entry, error)
 	List(ctx context.Context, query entry.ListQuery) ([]*entry.Entry, error)
 	Count(ctx context.Context, query entry.ListQuery) (int64, error)
 	Create(ctx context.Context, entry *entry.Entry) error
 	Update(ctx context.Context, entry *entry.Entry) error
 	Delete(ctx context.Context, id entry.ID) error
 	ListArchiveCounts(ctx context.Context, minBookmarkCount int) ([]ArchiveCount, error)
+	ListUntagged(ctx context.Context, limit, offset int) ([]*entry.Entry, error)
+	CountUntagged(ctx context.Context) (int64, error)
 }
 
 //go:generate mockgen -destination=./mocks_tag_repository.go -package=repository hateblog/internal/domain/repository TagRepository
@@ -32,6 +35,8 @@ type TagRepository interface {
 	Upsert(ctx context.Context, tag *tag.Tag) error
 	Delete(ctx context.Context, id tag.ID) error
 	IncrementViewHistory(ctx context.Context, tagID tag.ID, viewedAt time.Time) error
+	GetUsage(ctx context.Context, tagID tag.ID) (tag.Usage, error)
+	EntryCounts(ctx context.Context, tagIDs []tag.ID) (map[tag.ID]int, error)
 	GetTrending(ctx context.Context, hours int, minBookmarkCount int, limit int) ([]tag.TrendingTag, error)
 	GetClicked(ctx context.Context, days int, limit int) ([]tag.ClickedTag, error)
 }
@@ -39,6 +44,8 @@ type TagRepository interface {
 // SearchHistoryRepository stores aggregated search metrics.
 type SearchHistoryRepository interface {
 	Record(ctx context.Context, query string, searchedAt time.Time) error
+	List(ctx context.Context, from, to time.Time, limit int) ([]SearchQueryCount, error)
+	Top(ctx context.Context, days int, limit int) ([]SearchQueryCount, error)
 }
 
 // ClickMetricsRepository stores click counts per entry/date.
@@ -52,6 +59,15 @@ type APIKeyRepository interface {
 	GetByID(ctx context.Context, id api_key.ID) (*api_key.APIKey, error)
 }
 
+// SearchQueryCount aggregates the recorded searches of one query over a range of days.
+type SearchQueryCount struct {
+	Query string
+	Count int64
+	// FirstSearchedAt and LastSearchedAt are the first and last days the query was searched.
+	FirstSearchedAt time.Time
+	LastSearchedAt  time.Time
+}
+
 // ArchiveCount represents aggregated entry counts per day.
 type ArchiveCount struct {
 	Date  time.Time
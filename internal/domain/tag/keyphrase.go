@@ -0,0 +1,70 @@
+package tag
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The placeholder tag attached to entries with no usable keyphrase, so that they are not
+// picked up again as untagged.
+const (
+	NoKeyphraseTagName  = "__yahoo_no_keyphrase__"
+	NoKeyphraseTagScore = 1
+)
+
+// Keyphrase is a candidate tag extracted from entry text.
+// Score is the provider's relevance score, expected in 0-100.
+type Keyphrase struct {
+	Text  string
+	Score int
+}
+
+// KeyphraseExtractor extracts candidate tags from entry text.
+type KeyphraseExtractor interface {
+	Extract(ctx context.Context, text string) ([]Keyphrase, error)
+	// IsTooManyRequests reports whether an Extract error means the provider is rate limiting
+	// requests, with the advised wait before retrying (0 when unknown).
+	IsTooManyRequests(err error) (time.Duration, bool)
+}
+
+// ScoredTag is a normalized tag name with its clamped score.
+type ScoredTag struct {
+	Name  string
+	Score int
+}
+
+// ScoreKeyphrases normalizes extracted keyphrases into tags, highest score first. Invalid
+// UTF-8 from the provider is dropped, scores are clamped to 0-100, tags below minScore are
+// skipped and a name seen twice keeps its higher score. It also returns the number of
+// out-of-range scores. The fetcher and the retag command both tag entries through it, so
+// retagged entries look the same as freshly tagged ones.
+func ScoreKeyphrases(phrases []Keyphrase, minScore int) ([]ScoredTag, int) {
+	sorted := slices.Clone(phrases)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Score > sorted[j].Score })
+
+	seen := make(map[string]struct{}, len(sorted))
+	tags := make([]ScoredTag, 0, len(sorted))
+	abnormal := 0
+	for _, p := range sorted {
+		name := NormalizeName(strings.ToValidUTF8(p.Text, ""))
+		if name == "" {
+			continue
+		}
+		if p.Score < 0 || p.Score > 100 {
+			abnormal++
+		}
+		score := min(max(p.Score, 0), 100)
+		if score < minScore {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		tags = append(tags, ScoredTag{Name: name, Score: score})
+	}
+	return tags, abnormal
+}
@@ -0,0 +1,67 @@
+package tag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoreKeyphrases(t *testing.T) {
+	tests := []struct {
+		name         string
+		phrases      []Keyphrase
+		minScore     int
+		want         []ScoredTag
+		wantAbnormal int
+	}{
+		{
+			name: "normalizes, clamps and orders by score",
+			phrases: []Keyphrase{
+				{Text: "Release", Score: 40},
+				{Text: " Go ", Score: 120},
+				{Text: "  ", Score: 80},
+				{Text: "bad\xff", Score: -5},
+			},
+			want:         []ScoredTag{{Name: "go", Score: 100}, {Name: "release", Score: 40}, {Name: "bad", Score: 0}},
+			wantAbnormal: 2,
+		},
+		{
+			name: "keeps the higher score of a duplicate name",
+			phrases: []Keyphrase{
+				{Text: "go", Score: 60},
+				{Text: "Go", Score: 80},
+				{Text: "Web", Score: 70},
+			},
+			want: []ScoredTag{{Name: "go", Score: 80}, {Name: "web", Score: 70}},
+		},
+		{
+			name: "ties keep the provider order",
+			phrases: []Keyphrase{
+				{Text: "b", Score: 50},
+				{Text: "a", Score: 50},
+			},
+			want: []ScoredTag{{Name: "b", Score: 50}, {Name: "a", Score: 50}},
+		},
+		{
+			name: "drops tags below the minimum score",
+			phrases: []Keyphrase{
+				{Text: "noise", Score: 12},
+				{Text: "Go", Score: 95},
+				{Text: "weak", Score: 29},
+			},
+			minScore: 30,
+			want:     []ScoredTag{{Name: "go", Score: 95}},
+		},
+		{
+			name: "nothing extracted",
+			want: []ScoredTag{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, abnormal := ScoreKeyphrases(tt.phrases, tt.minScore)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantAbnormal, abnormal)
+		})
+	}
+}
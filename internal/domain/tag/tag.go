@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
+	"unicode"
 
 	"github.com/google/uuid"
 )
@@ -11,6 +13,9 @@ import (
 // ErrInvalidTag signals invalid tag parameters.
 var ErrInvalidTag = errors.New("invalid tag")
 
+// ErrNotFound signals that no tag has the requested name.
+var ErrNotFound = errors.New("tag not found")
+
 // ID represents tag identifier.
 type ID = uuid.UUID
 
@@ -20,8 +25,45 @@ type Tag struct {
 	Name string
 }
 
-// NormalizeName trims spaces, converts the tag name to lower-case, and truncates to 255 bytes.
+// NormalizeOptions controls the optional cleanup steps of NormalizeName.
+type NormalizeOptions struct {
+	// StripControl removes control and zero-width characters; tabs and newlines become spaces.
+	StripControl bool
+	// StripEmoji removes emoji, including modifiers, variation selectors and joiners.
+	StripEmoji bool
+}
+
+// DefaultNormalizeOptions strips control characters and keeps emoji.
+var DefaultNormalizeOptions = NormalizeOptions{StripControl: true}
+
+var normalizeOptions atomic.Pointer[NormalizeOptions]
+
+// SetNormalizeOptions changes the options used by NormalizeName process-wide.
+// Call it once at startup: tags are looked up by their normalized name, so every process
+// sharing a database must normalize the same way.
+func SetNormalizeOptions(opts NormalizeOptions) {
+	normalizeOptions.Store(&opts)
+}
+
+// CurrentNormalizeOptions returns the options used by NormalizeName.
+func CurrentNormalizeOptions() NormalizeOptions {
+	if opts := normalizeOptions.Load(); opts != nil {
+		return *opts
+	}
+	return DefaultNormalizeOptions
+}
+
+// NormalizeName trims spaces, converts the tag name to lower-case, and truncates to 255 bytes,
+// applying the process-wide NormalizeOptions first.
 func NormalizeName(name string) string {
+	return NormalizeNameWith(name, CurrentNormalizeOptions())
+}
+
+// NormalizeNameWith is NormalizeName with explicit options.
+func NormalizeNameWith(name string, opts NormalizeOptions) string {
+	if opts.StripControl || opts.StripEmoji {
+		name = stripRunes(name, opts)
+	}
 	normalized := strings.ToLower(strings.TrimSpace(name))
 	// Truncate to 255 bytes to match DB constraint
 	// Must ensure we don't cut in the middle of a UTF-8 multibyte character
@@ -32,6 +74,54 @@ func NormalizeName(name string) string {
 	return normalized
 }
 
+func stripRunes(s string, opts NormalizeOptions) string {
+	return strings.Map(func(r rune) rune {
+		if opts.StripControl {
+			if unicode.IsControl(r) {
+				if unicode.IsSpace(r) {
+					return ' '
+				}
+				return -1
+			}
+			if isZeroWidth(r) {
+				return -1
+			}
+		}
+		if opts.StripEmoji && isEmoji(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func isZeroWidth(r rune) bool {
+	switch r {
+	case '\u200b', '\u2060', '\ufeff': // zero width space, word joiner, BOM
+		return true
+	}
+	return false
+}
+
+// isEmoji reports whether r belongs to the emoji blocks or is one of the code points used to
+// build emoji sequences. Plain symbols such as © or ™ are kept.
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // pictographs, emoticons, transport, flags, skin tones
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // miscellaneous symbols and dingbats
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // arrows and symbols such as ⭐
+		return true
+	case r >= 0x231A && r <= 0x23FF: // watch, hourglass and media control symbols
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // tag characters used by subdivision flags
+		return true
+	case r == 0x200D, r == 0x20E3, r == 0xFE0E, r == 0xFE0F: // ZWJ, keycap, variation selectors
+		return true
+	}
+	return false
+}
+
 // truncateUTF8 truncates a string to maxBytes without breaking UTF-8 encoding
 func truncateUTF8(s string, maxBytes int) string {
 	if len(s) <= maxBytes {
@@ -59,6 +149,19 @@ func New(id ID, name string) (Tag, error) {
 	}, nil
 }
 
+// Usage holds how often a tag is attached to entries and viewed.
+type Usage struct {
+	EntryCount int // Total number of entries with this tag
+	ViewCount  int // Total views of the tag's entry list
+}
+
+// CountedTag represents a tag with the number of entries it is attached to.
+type CountedTag struct {
+	ID         ID
+	Name       string
+	EntryCount int // Total number of entries with this tag
+}
+
 // TrendingTag represents a tag with its occurrence count in recent entries.
 type TrendingTag struct {
 	ID              ID
@@ -74,3 +177,17 @@ type ClickedTag struct {
 	ClickCount int // Number of clicks on entries with this tag in the specified period
 	EntryCount int // Total number of entries with this tag
 }
+
+// ListSort selects the order of the tag list.
+type ListSort string
+
+// ListSortName orders tags by name. It is the only order supported by the tag list today.
+const ListSortName ListSort = "name"
+
+// ListView identifies one variant of the tag list. Sorts that depend on activity over a recent
+// period set WindowHours; other sorts leave it zero. Caches key on the whole view so that
+// different variants never share an entry.
+type ListView struct {
+	Sort        ListSort
+	WindowHours int
+}
@@ -263,3 +263,89 @@ func TestNormalizeName(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeNameWith(t *testing.T) {
+	all := NormalizeOptions{StripControl: true, StripEmoji: true}
+
+	tests := []struct {
+		name  string
+		input string
+		opts  NormalizeOptions
+		want  string
+	}{
+		{
+			name:  "control characters removed",
+			input: "go\x00lang\x07",
+			opts:  DefaultNormalizeOptions,
+			want:  "golang",
+		},
+		{
+			name:  "embedded tab becomes space",
+			input: "web\tdevelopment",
+			opts:  DefaultNormalizeOptions,
+			want:  "web development",
+		},
+		{
+			name:  "zero width characters removed",
+			input: "\ufeffgo\u200blang",
+			opts:  DefaultNormalizeOptions,
+			want:  "golang",
+		},
+		{
+			name:  "emoji kept by default",
+			input: "寿司🍣",
+			opts:  DefaultNormalizeOptions,
+			want:  "寿司🍣",
+		},
+		{
+			name:  "control characters kept when disabled",
+			input: "go\x00lang",
+			opts:  NormalizeOptions{},
+			want:  "go\x00lang",
+		},
+		{
+			name:  "emoji removed",
+			input: "🍣 寿司",
+			opts:  all,
+			want:  "寿司",
+		},
+		{
+			name:  "emoji sequences removed entirely",
+			input: "Family\U0001F468\u200d\U0001F469\u200d\U0001F467 \U0001F44D\U0001F3FD \u2764\ufe0f 1\ufe0f\u20e3 \U0001F1EF\U0001F1F5",
+			opts:  all,
+			want:  "family   1",
+		},
+		{
+			name:  "symbols that are not emoji kept",
+			input: "C++ ©",
+			opts:  all,
+			want:  "c++ ©",
+		},
+		{
+			name:  "emoji only becomes empty",
+			input: "🔥🔥",
+			opts:  all,
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeNameWith(tt.input, tt.opts))
+		})
+	}
+}
+
+func TestSetNormalizeOptions(t *testing.T) {
+	t.Cleanup(func() { SetNormalizeOptions(DefaultNormalizeOptions) })
+
+	assert.Equal(t, "golang🚀", NormalizeName("Go\x01lang🚀"))
+
+	SetNormalizeOptions(NormalizeOptions{StripControl: true, StripEmoji: true})
+	assert.Equal(t, "golang", NormalizeName("Go\x01lang🚀"))
+	// Lookups and ingestion agree on the same name.
+	assert.Equal(t, NormalizeName("🚀 GoLang"), NormalizeName("golang\n"))
+
+	_, err := New(uuid.New(), "🚀")
+	require.ErrorIs(t, err, ErrInvalidTag)
+}
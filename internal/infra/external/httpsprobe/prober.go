@@ -0,0 +1,112 @@
+package httpsprobe
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout = 3 * time.Second
+	defaultUA      = "hateblog-bot/1.0"
+	maxRedirects   = 5
+)
+
+// errLeftHTTPS stops the client when the https variant redirects back to plain http.
+var errLeftHTTPS = errors.New("redirected away from https")
+
+// Prober checks whether http URLs are also served over https, so that the fetcher can store
+// the https URL up front instead of leaving the http/https merge to the updater.
+type Prober struct {
+	httpClient *http.Client
+	userAgent  string
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// Config configures the Prober.
+type Config struct {
+	// HTTPClient is copied; its CheckRedirect is replaced.
+	HTTPClient *http.Client
+	Timeout    time.Duration
+	UserAgent  string
+}
+
+// NewProber builds a Prober.
+func NewProber(cfg Config) *Prober {
+	var client http.Client
+	if cfg.HTTPClient != nil {
+		client = *cfg.HTTPClient
+	}
+	client.Timeout = cfg.Timeout
+	if client.Timeout <= 0 {
+		client.Timeout = defaultTimeout
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if req.URL.Scheme != "https" {
+			return errLeftHTTPS
+		}
+		if len(via) > maxRedirects {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+	ua := strings.TrimSpace(cfg.UserAgent)
+	if ua == "" {
+		ua = defaultUA
+	}
+	return &Prober{
+		httpClient: &client,
+		userAgent:  ua,
+		cache:      make(map[string]string),
+	}
+}
+
+// Resolve returns the https variant of an http rawURL when a HEAD request to it succeeds
+// without redirecting back to http, and rawURL otherwise. An unavailable https variant is not
+// an error. Results are cached for the lifetime of the Prober.
+func (p *Prober) Resolve(ctx context.Context, rawURL string) (string, error) {
+	if p == nil || !strings.HasPrefix(rawURL, "http://") {
+		return rawURL, nil
+	}
+
+	p.mu.Lock()
+	cached, ok := p.cache[rawURL]
+	p.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	httpsURL := "https://" + strings.TrimPrefix(rawURL, "http://")
+	if !p.available(ctx, httpsURL) {
+		if err := ctx.Err(); err != nil {
+			// Not cached: the probe did not get a chance to run.
+			return rawURL, err
+		}
+		httpsURL = rawURL
+	}
+	p.mu.Lock()
+	p.cache[rawURL] = httpsURL
+	p.mu.Unlock()
+	return httpsURL, nil
+}
+
+// available reports whether a HEAD request to httpsURL gets a non-error response.
+func (p *Prober) available(ctx context.Context, httpsURL string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, httpsURL, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("User-Agent", p.userAgent)
+
+	resp, err := p.httpClient.Do(req) // #nosec G704
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode < http.StatusBadRequest
+}
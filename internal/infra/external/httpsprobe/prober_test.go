@@ -0,0 +1,90 @@
+package httpsprobe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newHTTPSServer starts a TLS server: /ok answers 200, /gone 404 and /downgrade redirects to
+// plain http. hits counts the requests.
+func newHTTPSServer(t *testing.T, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		require.Equal(t, http.MethodHead, r.Method)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/downgrade", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.Redirect(w, r, "http://"+r.Host+"/ok", http.StatusMovedPermanently)
+	})
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// httpURL is the plain http variant of a path on server.
+func httpURL(server *httptest.Server, path string) string {
+	return "http://" + strings.TrimPrefix(server.URL, "https://") + path
+}
+
+func TestResolveUpgradesWhenHTTPSAvailable(t *testing.T) {
+	var hits atomic.Int32
+	server := newHTTPSServer(t, &hits)
+	prober := NewProber(Config{HTTPClient: server.Client()})
+
+	for i := 0; i < 2; i++ {
+		got, err := prober.Resolve(context.Background(), httpURL(server, "/ok"))
+		require.NoError(t, err)
+		require.Equal(t, server.URL+"/ok", got)
+	}
+	require.Equal(t, int32(1), hits.Load(), "results are cached")
+}
+
+func TestResolveKeepsHTTPWhenHTTPSUnavailable(t *testing.T) {
+	var hits atomic.Int32
+	server := newHTTPSServer(t, &hits)
+	prober := NewProber(Config{HTTPClient: server.Client()})
+
+	for _, path := range []string{"/gone", "/downgrade"} {
+		raw := httpURL(server, path)
+		got, err := prober.Resolve(context.Background(), raw)
+		require.NoError(t, err)
+		require.Equal(t, raw, got, path)
+	}
+
+	// Nothing listens for TLS on a closed server.
+	closed := httptest.NewTLSServer(http.NotFoundHandler())
+	raw := httpURL(closed, "/ok")
+	closed.Close()
+	got, err := NewProber(Config{}).Resolve(context.Background(), raw)
+	require.NoError(t, err)
+	require.Equal(t, raw, got)
+}
+
+func TestResolveSkipsNonHTTPURLs(t *testing.T) {
+	var hits atomic.Int32
+	server := newHTTPSServer(t, &hits)
+	prober := NewProber(Config{HTTPClient: server.Client()})
+
+	got, err := prober.Resolve(context.Background(), server.URL+"/ok")
+	require.NoError(t, err)
+	require.Equal(t, server.URL+"/ok", got)
+	require.Zero(t, hits.Load())
+
+	var nilProber *Prober
+	got, err = nilProber.Resolve(context.Background(), "http://example.com/")
+	require.NoError(t, err)
+	require.Equal(t, "http://example.com/", got)
+}
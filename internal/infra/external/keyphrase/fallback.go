@@ -0,0 +1,82 @@
+package keyphrase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"hateblog/internal/domain/tag"
+)
+
+// defaultFallbackCooldown is how long FallbackExtractor stays on the fallback after a primary
+// failure that carries no Retry-After hint.
+const defaultFallbackCooldown = 5 * time.Minute
+
+// FallbackExtractor uses a primary extractor and switches to a fallback when the primary fails.
+// A failure opens a breaker: the primary is not called again until the cooldown (or the
+// provider's Retry-After) has passed, so a rate-limited or unreachable API is not hammered while
+// entries keep getting tags from the fallback.
+type FallbackExtractor struct {
+	primary  tag.KeyphraseExtractor
+	fallback tag.KeyphraseExtractor
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	openUntil time.Time
+}
+
+// NewFallbackExtractor builds a FallbackExtractor. cooldown <= 0 uses five minutes.
+func NewFallbackExtractor(primary, fallback tag.KeyphraseExtractor, cooldown time.Duration) *FallbackExtractor {
+	if cooldown <= 0 {
+		cooldown = defaultFallbackCooldown
+	}
+	return &FallbackExtractor{
+		primary:  primary,
+		fallback: fallback,
+		cooldown: cooldown,
+		now:      time.Now,
+	}
+}
+
+var _ tag.KeyphraseExtractor = (*FallbackExtractor)(nil)
+
+// Extract returns the primary's keyphrases, or the fallback's while the breaker is open or when
+// the primary fails. Context errors are returned as is.
+func (e *FallbackExtractor) Extract(ctx context.Context, text string) ([]tag.Keyphrase, error) {
+	if e.open() {
+		return e.fallback.Extract(ctx, text)
+	}
+	phrases, err := e.primary.Extract(ctx, text)
+	if err == nil {
+		return phrases, nil
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil, err
+	}
+	e.trip(err)
+	return e.fallback.Extract(ctx, text)
+}
+
+// IsTooManyRequests reports the fallback's rate limits; those of the primary are absorbed.
+func (e *FallbackExtractor) IsTooManyRequests(err error) (time.Duration, bool) {
+	return e.fallback.IsTooManyRequests(err)
+}
+
+// open reports whether the breaker is open, i.e. Extract currently skips the primary.
+func (e *FallbackExtractor) open() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.now().Before(e.openUntil)
+}
+
+func (e *FallbackExtractor) trip(err error) {
+	wait := e.cooldown
+	if retryAfter, ok := e.primary.IsTooManyRequests(err); ok && retryAfter > 0 {
+		wait = retryAfter
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.openUntil = e.now().Add(wait)
+}
@@ -0,0 +1,98 @@
+package keyphrase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"hateblog/internal/domain/tag"
+)
+
+// errPrimaryLimited is the error fakePrimary reports as a rate limit.
+var errPrimaryLimited = errors.New("primary: too many requests")
+
+type fakePrimary struct {
+	err        error
+	retryAfter time.Duration
+	calls      int
+}
+
+func (p *fakePrimary) Extract(ctx context.Context, text string) ([]tag.Keyphrase, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []tag.Keyphrase{{Text: "primary", Score: 90}}, nil
+}
+
+func (p *fakePrimary) IsTooManyRequests(err error) (time.Duration, bool) {
+	return p.retryAfter, errors.Is(err, errPrimaryLimited)
+}
+
+func newTestFallback(primary *fakePrimary) (*FallbackExtractor, *time.Time) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ext := NewFallbackExtractor(primary, NewLocalExtractor(nil), time.Minute)
+	ext.now = func() time.Time { return now }
+	return ext, &now
+}
+
+func TestFallbackExtractor(t *testing.T) {
+	ctx := context.Background()
+	const text = "Rust async runtime"
+
+	t.Run("uses the primary while it works", func(t *testing.T) {
+		primary := &fakePrimary{}
+		ext, _ := newTestFallback(primary)
+		phrases, err := ext.Extract(ctx, text)
+		require.NoError(t, err)
+		require.Equal(t, []string{"primary"}, phraseTexts(phrases))
+		require.False(t, ext.open())
+	})
+
+	t.Run("falls back and skips the primary until the cooldown ends", func(t *testing.T) {
+		primary := &fakePrimary{err: errors.New("boom")}
+		ext, now := newTestFallback(primary)
+
+		phrases, err := ext.Extract(ctx, text)
+		require.NoError(t, err)
+		require.Equal(t, []string{"rust", "async", "runtime"}, phraseTexts(phrases))
+		require.True(t, ext.open())
+
+		_, err = ext.Extract(ctx, text)
+		require.NoError(t, err)
+		require.Equal(t, 1, primary.calls)
+
+		primary.err = nil
+		*now = now.Add(time.Minute)
+		phrases, err = ext.Extract(ctx, text)
+		require.NoError(t, err)
+		require.Equal(t, []string{"primary"}, phraseTexts(phrases))
+		require.Equal(t, 2, primary.calls)
+	})
+
+	t.Run("rate limit keeps the breaker open for Retry-After", func(t *testing.T) {
+		primary := &fakePrimary{err: errPrimaryLimited, retryAfter: time.Hour}
+		ext, now := newTestFallback(primary)
+
+		_, err := ext.Extract(ctx, text)
+		require.NoError(t, err)
+		*now = now.Add(30 * time.Minute)
+		require.True(t, ext.open())
+		*now = now.Add(30 * time.Minute)
+		require.False(t, ext.open())
+
+		_, limited := ext.IsTooManyRequests(errPrimaryLimited)
+		require.False(t, limited)
+	})
+
+	t.Run("context errors are returned", func(t *testing.T) {
+		primary := &fakePrimary{err: context.DeadlineExceeded}
+		ext, _ := newTestFallback(primary)
+		_, err := ext.Extract(ctx, text)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.False(t, ext.open())
+	})
+}
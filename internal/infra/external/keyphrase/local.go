@@ -0,0 +1,197 @@
+package keyphrase
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"hateblog/internal/domain/tag"
+)
+
+const (
+	defaultLocalMaxPhrases = 5
+	localTitleWeight       = 2
+	// maxHanToken is the longest kanji run kept whole. Longer runs are usually compounds of
+	// two-kanji words (機械学習入門) and are cut into bigrams.
+	maxHanToken = 4
+)
+
+// defaultStopwords filters tokens that carry no topic on their own.
+var defaultStopwords = []string{
+	// English
+	"a", "an", "and", "are", "as", "at", "be", "by", "for", "from", "has", "have", "how",
+	"in", "is", "it", "its", "of", "on", "or", "that", "the", "this", "to", "was", "we",
+	"what", "when", "why", "will", "with", "you", "your",
+	// Japanese (kanji and katakana runs that survive segmentation)
+	"今回", "場合", "自分", "方法", "記事", "紹介", "感想", "話題", "理由", "必要", "問題",
+	"今日", "最近", "全部", "一覧", "以上", "以下", "本当", "部分", "内容", "結果",
+	"ブログ", "サイト", "ページ", "ニュース", "まとめ",
+}
+
+// LocalExtractor derives candidate tags without network access.
+// Text is segmented at script boundaries (Latin words, katakana runs and kanji runs; hiragana and
+// punctuation act as separators), stopwords are dropped and the remaining tokens are ranked by
+// frequency. Kanji runs longer than four characters are cut into bigrams. The first line is
+// treated as the title and weighted higher.
+type LocalExtractor struct {
+	stopwords  map[string]struct{}
+	maxPhrases int
+}
+
+// NewLocalExtractor builds a LocalExtractor. Extra stopwords are added to the built-in list.
+func NewLocalExtractor(extraStopwords []string) *LocalExtractor {
+	stopwords := make(map[string]struct{}, len(defaultStopwords)+len(extraStopwords))
+	for _, list := range [][]string{defaultStopwords, extraStopwords} {
+		for _, w := range list {
+			if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+				stopwords[w] = struct{}{}
+			}
+		}
+	}
+	return &LocalExtractor{stopwords: stopwords, maxPhrases: defaultLocalMaxPhrases}
+}
+
+var _ tag.KeyphraseExtractor = (*LocalExtractor)(nil)
+
+// Extract returns up to five keyphrases scored 1-100 relative to the strongest one.
+func (e *LocalExtractor) Extract(ctx context.Context, text string) ([]tag.Keyphrase, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	title, body, _ := strings.Cut(text, "\n")
+	weights := make(map[string]int)
+	firstSeen := make(map[string]int)
+	add := func(s string, weight int) {
+		for _, token := range segment(s) {
+			if !e.keep(token) {
+				continue
+			}
+			if _, ok := firstSeen[token]; !ok {
+				firstSeen[token] = len(firstSeen)
+			}
+			weights[token] += weight
+		}
+	}
+	add(title, localTitleWeight)
+	add(body, 1)
+	if len(weights) == 0 {
+		return nil, nil
+	}
+
+	tokens := make([]string, 0, len(weights))
+	for token := range weights {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if weights[tokens[i]] != weights[tokens[j]] {
+			return weights[tokens[i]] > weights[tokens[j]]
+		}
+		return firstSeen[tokens[i]] < firstSeen[tokens[j]]
+	})
+	if len(tokens) > e.maxPhrases {
+		tokens = tokens[:e.maxPhrases]
+	}
+
+	top := float64(weights[tokens[0]])
+	phrases := make([]tag.Keyphrase, 0, len(tokens))
+	for _, token := range tokens {
+		phrases = append(phrases, tag.Keyphrase{
+			Text:  token,
+			Score: int(math.Round(100 * float64(weights[token]) / top)),
+		})
+	}
+	return phrases, nil
+}
+
+// IsTooManyRequests always reports false: local extraction has no rate limit.
+func (e *LocalExtractor) IsTooManyRequests(err error) (time.Duration, bool) {
+	return 0, false
+}
+
+func (e *LocalExtractor) keep(token string) bool {
+	if utf8.RuneCountInString(token) < 2 {
+		return false
+	}
+	if _, ok := e.stopwords[token]; ok {
+		return false
+	}
+	return strings.IndexFunc(token, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0
+}
+
+// scriptClass groups runes for segmentation. Letters other than kana and kanji count as Latin.
+type scriptClass int
+
+const (
+	scriptOther scriptClass = iota
+	scriptLatin
+	scriptKatakana
+	scriptHan
+)
+
+func classify(r rune) scriptClass {
+	switch {
+	case r == 'ー' || unicode.Is(unicode.Katakana, r):
+		return scriptKatakana
+	case unicode.Is(unicode.Han, r):
+		return scriptHan
+	case unicode.IsDigit(r), unicode.IsLetter(r) && !unicode.Is(unicode.Hiragana, r):
+		return scriptLatin
+	default:
+		return scriptOther
+	}
+}
+
+// segment splits text into lower-cased runs of the same script class, with long kanji runs
+// cut into bigrams (see splitHan).
+func segment(text string) []string {
+	var tokens []string
+	var current strings.Builder
+	currentClass := scriptOther
+	flush := func() {
+		if current.Len() == 0 {
+			return
+		}
+		if currentClass == scriptHan {
+			tokens = append(tokens, splitHan(current.String())...)
+		} else {
+			tokens = append(tokens, strings.ToLower(current.String()))
+		}
+		current.Reset()
+	}
+	for _, r := range text {
+		class := classify(r)
+		// Punctuation, spaces and hiragana end the current token.
+		if class != currentClass {
+			flush()
+			currentClass = class
+		}
+		if class != scriptOther {
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// splitHan returns run unchanged when it has at most maxHanToken kanji, and otherwise its
+// consecutive non-overlapping bigrams. A trailing single kanji is kept as a token and later
+// dropped for being too short.
+func splitHan(run string) []string {
+	runes := []rune(run)
+	if len(runes) <= maxHanToken {
+		return []string{run}
+	}
+	out := make([]string, 0, (len(runes)+1)/2)
+	for i := 0; i < len(runes); i += 2 {
+		out = append(out, string(runes[i:min(i+2, len(runes))]))
+	}
+	return out
+}
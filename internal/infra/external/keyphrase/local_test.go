@@ -0,0 +1,78 @@
+package keyphrase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"hateblog/internal/domain/tag"
+)
+
+func phraseTexts(phrases []tag.Keyphrase) []string {
+	out := make([]string, 0, len(phrases))
+	for _, p := range phrases {
+		out = append(out, p.Text)
+	}
+	return out
+}
+
+func TestLocalExtractor(t *testing.T) {
+	ext := NewLocalExtractor(nil)
+	ctx := context.Background()
+
+	t.Run("english title and excerpt", func(t *testing.T) {
+		phrases, err := ext.Extract(ctx, "Go generics in practice\nGenerics make Go code reusable. This is how we use generics.")
+		require.NoError(t, err)
+		require.Equal(t, []string{"generics", "go", "practice", "make", "code"}, phraseTexts(phrases))
+		require.Equal(t, 100, phrases[0].Score)
+		for _, p := range phrases {
+			require.GreaterOrEqual(t, p.Score, 1)
+			require.LessOrEqual(t, p.Score, 100)
+		}
+	})
+
+	t.Run("japanese text is segmented by script", func(t *testing.T) {
+		phrases, err := ext.Extract(ctx, "Kubernetesでコンテナを運用する方法\nコンテナ運用の基本とKubernetesの設定について紹介します")
+		require.NoError(t, err)
+		texts := phraseTexts(phrases)
+		require.Equal(t, []string{"kubernetes", "コンテナ", "運用", "基本", "設定"}, texts)
+		require.NotContains(t, texts, "方法")
+		require.NotContains(t, texts, "紹介")
+	})
+
+	t.Run("long kanji runs are cut into bigrams", func(t *testing.T) {
+		phrases, err := ext.Extract(ctx, "機械学習入門\n機械学習の基礎と深層学習の実装を解説")
+		require.NoError(t, err)
+		// Runs of up to four kanji stay whole.
+		require.Equal(t, []string{"機械", "学習", "入門", "機械学習", "基礎"}, phraseTexts(phrases))
+	})
+
+	t.Run("drops short, numeric and stopword tokens", func(t *testing.T) {
+		phrases, err := ext.Extract(ctx, "The 2025 a x of 100")
+		require.NoError(t, err)
+		require.Empty(t, phrases)
+	})
+
+	t.Run("custom stopwords", func(t *testing.T) {
+		custom := NewLocalExtractor([]string{" Rust "})
+		phrases, err := custom.Extract(ctx, "Rust async runtime")
+		require.NoError(t, err)
+		require.Equal(t, []string{"async", "runtime"}, phraseTexts(phrases))
+	})
+
+	t.Run("empty text", func(t *testing.T) {
+		phrases, err := ext.Extract(ctx, "  ")
+		require.NoError(t, err)
+		require.Nil(t, phrases)
+	})
+}
+
+func TestNewLocalProvider(t *testing.T) {
+	ext, err := New(Config{Provider: ProviderLocal})
+	require.NoError(t, err)
+	require.IsType(t, &LocalExtractor{}, ext)
+	_, limited := ext.IsTooManyRequests(errors.New("boom"))
+	require.False(t, limited)
+}
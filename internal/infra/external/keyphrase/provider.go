@@ -0,0 +1,59 @@
+// Package keyphrase selects the keyphrase extraction provider used for tagging.
+package keyphrase
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"hateblog/internal/domain/tag"
+	"hateblog/internal/infra/external/yahoo"
+)
+
+// Supported providers.
+const (
+	ProviderYahoo = "yahoo"
+	ProviderLocal = "local"
+	ProviderNone  = "none"
+)
+
+// Config selects and configures a provider.
+type Config struct {
+	Provider   string
+	YahooAppID string
+	HTTPClient *http.Client
+	// Stopwords are added to the local extractor's built-in list.
+	Stopwords []string
+	// Fallback makes the yahoo provider fall back to the local extractor: always when
+	// YahooAPIKey is empty, and while the API is failing otherwise (see FallbackExtractor).
+	Fallback bool
+}
+
+// New returns the configured extractor.
+// It returns nil when tagging is disabled, either explicitly or because the provider lacks
+// credentials and no fallback is configured.
+func New(cfg Config) (tag.KeyphraseExtractor, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Provider)) {
+	case "", ProviderYahoo:
+		if strings.TrimSpace(cfg.YahooAppID) == "" {
+			if cfg.Fallback {
+				return NewLocalExtractor(cfg.Stopwords), nil
+			}
+			return nil, nil
+		}
+		client := yahoo.NewClient(yahoo.ClientConfig{
+			HTTPClient: cfg.HTTPClient,
+			AppID:      cfg.YahooAppID,
+		})
+		if cfg.Fallback {
+			return NewFallbackExtractor(client, NewLocalExtractor(cfg.Stopwords), 0), nil
+		}
+		return client, nil
+	case ProviderLocal:
+		return NewLocalExtractor(cfg.Stopwords), nil
+	case ProviderNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown keyphrase provider: %s", cfg.Provider)
+	}
+}
@@ -0,0 +1,55 @@
+package keyphrase
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"hateblog/internal/infra/external/yahoo"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("yahoo with app id", func(t *testing.T) {
+		ext, err := New(Config{Provider: ProviderYahoo, YahooAppID: "appid"})
+		require.NoError(t, err)
+		require.IsType(t, &yahoo.Client{}, ext)
+		_, limited := ext.IsTooManyRequests(&yahoo.StatusError{StatusCode: http.StatusTooManyRequests})
+		require.True(t, limited)
+	})
+
+	t.Run("empty provider defaults to yahoo", func(t *testing.T) {
+		ext, err := New(Config{YahooAppID: "appid"})
+		require.NoError(t, err)
+		require.IsType(t, &yahoo.Client{}, ext)
+	})
+
+	t.Run("yahoo without app id disables tagging", func(t *testing.T) {
+		ext, err := New(Config{Provider: ProviderYahoo})
+		require.NoError(t, err)
+		require.Nil(t, ext)
+	})
+
+	t.Run("fallback without app id uses local", func(t *testing.T) {
+		ext, err := New(Config{Provider: ProviderYahoo, Fallback: true})
+		require.NoError(t, err)
+		require.IsType(t, &LocalExtractor{}, ext)
+	})
+
+	t.Run("fallback with app id wraps yahoo", func(t *testing.T) {
+		ext, err := New(Config{Provider: ProviderYahoo, YahooAppID: "appid", Fallback: true})
+		require.NoError(t, err)
+		require.IsType(t, &FallbackExtractor{}, ext)
+	})
+
+	t.Run("none disables tagging", func(t *testing.T) {
+		ext, err := New(Config{Provider: ProviderNone, YahooAppID: "appid"})
+		require.NoError(t, err)
+		require.Nil(t, ext)
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, err := New(Config{Provider: "openai"})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,122 @@
+package pagemeta
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"hateblog/internal/pkg/textutil"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	defaultUA      = "hateblog-bot/1.0"
+	// maxHeadBytes bounds the bytes read from a page; meta tags live in <head>.
+	maxHeadBytes = 256 << 10
+	// MaxDescriptionRunes caps the returned description.
+	MaxDescriptionRunes = 500
+)
+
+var (
+	metaTagPattern   = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	metaAttrPattern  = regexp.MustCompile(`(?is)([a-z:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	headClosePattern = regexp.MustCompile(`(?i)</head\s*>`)
+)
+
+// Fetcher reads the description of a web page from its meta tags.
+type Fetcher struct {
+	httpClient *http.Client
+	userAgent  string
+}
+
+// Config configures the Fetcher.
+type Config struct {
+	HTTPClient *http.Client
+	Timeout    time.Duration
+	UserAgent  string
+}
+
+// NewFetcher builds a Fetcher.
+func NewFetcher(cfg Config) *Fetcher {
+	var client http.Client
+	if cfg.HTTPClient != nil {
+		client = *cfg.HTTPClient
+	}
+	client.Timeout = cfg.Timeout
+	if client.Timeout <= 0 {
+		client.Timeout = defaultTimeout
+	}
+	ua := strings.TrimSpace(cfg.UserAgent)
+	if ua == "" {
+		ua = defaultUA
+	}
+	return &Fetcher{httpClient: &client, userAgent: ua}
+}
+
+// Description fetches rawURL and returns its og:description, or its description meta tag when
+// there is none. It returns "" without an error when the page has neither.
+func (f *Fetcher) Description(ctx context.Context, rawURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := f.httpClient.Do(req) // #nosec G704
+	if err != nil {
+		return "", fmt.Errorf("fetch page %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch page %s: status %d", rawURL, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.Contains(strings.ToLower(ct), "html") {
+		return "", nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHeadBytes))
+	if err != nil {
+		return "", fmt.Errorf("read page %s: %w", rawURL, err)
+	}
+	return ExtractDescription(string(body)), nil
+}
+
+// ExtractDescription returns the og:description of an HTML document, falling back to its
+// description meta tag, unescaped, whitespace-collapsed and capped at MaxDescriptionRunes.
+func ExtractDescription(doc string) string {
+	if loc := headClosePattern.FindStringIndex(doc); loc != nil {
+		doc = doc[:loc[0]]
+	}
+	var og, plain string
+	for _, tag := range metaTagPattern.FindAllString(doc, -1) {
+		attrs := make(map[string]string, 3)
+		for _, m := range metaAttrPattern.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(m[1])] = m[2] + m[3]
+		}
+		content, ok := attrs["content"]
+		if !ok {
+			continue
+		}
+		switch {
+		case og == "" && strings.EqualFold(attrs["property"], "og:description"):
+			og = content
+		case plain == "" && strings.EqualFold(attrs["name"], "description"):
+			plain = content
+		}
+	}
+	desc := og
+	if strings.TrimSpace(desc) == "" {
+		desc = plain
+	}
+	desc = strings.Join(strings.Fields(html.UnescapeString(textutil.SanitizeUTF8(desc))), " ")
+	if utf8.RuneCountInString(desc) > MaxDescriptionRunes {
+		desc = string([]rune(desc)[:MaxDescriptionRunes])
+	}
+	return desc
+}
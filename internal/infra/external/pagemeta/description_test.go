@@ -0,0 +1,79 @@
+package pagemeta
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractDescription(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{
+			name: "og description wins",
+			doc: `<html><head><meta name="description" content="plain">
+<meta property="og:description" content="Open &amp; Graph
+  text"></head></html>`,
+			want: "Open & Graph text",
+		},
+		{
+			name: "description fallback",
+			doc:  `<head><META NAME='Description' CONTENT='日本語の説明'><meta property="og:description" content=" "></head>`,
+			want: "日本語の説明",
+		},
+		{
+			name: "content before name",
+			doc:  `<head><meta content="reversed" name="description" /></head>`,
+			want: "reversed",
+		},
+		{
+			name: "body meta tags are ignored",
+			doc:  `<head><title>x</title></head><body><meta name="description" content="body"></body>`,
+			want: "",
+		},
+		{name: "none", doc: `<html><head></head></html>`, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ExtractDescription(tt.doc))
+		})
+	}
+
+	long := `<head><meta name="description" content="` + strings.Repeat("あ", MaxDescriptionRunes+10) + `"></head>`
+	require.Equal(t, MaxDescriptionRunes, len([]rune(ExtractDescription(long))))
+}
+
+func TestFetcherDescription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/article":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<head><meta property="og:description" content="An article"></head>`))
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte(`<meta name="description" content="not html">`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	fetcher := NewFetcher(Config{HTTPClient: server.Client()})
+
+	got, err := fetcher.Description(context.Background(), server.URL+"/article")
+	require.NoError(t, err)
+	require.Equal(t, "An article", got)
+
+	got, err = fetcher.Description(context.Background(), server.URL+"/image")
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	_, err = fetcher.Description(context.Background(), server.URL+"/missing")
+	require.ErrorContains(t, err, "status 404")
+}
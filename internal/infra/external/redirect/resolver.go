@@ -0,0 +1,150 @@
+package redirect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"hateblog/internal/pkg/hostname"
+)
+
+const (
+	defaultTimeout      = 3 * time.Second
+	defaultMaxRedirects = 5
+	defaultUA           = "hateblog-bot/1.0"
+)
+
+// errTooManyRedirects stops the client once the redirect cap is reached.
+var errTooManyRedirects = errors.New("too many redirects")
+
+// Resolver follows redirects of URLs on opted-in redirector hosts (URL shorteners, feed
+// proxies) to find the destination URL. Other URLs are returned unchanged.
+type Resolver struct {
+	httpClient *http.Client
+	hosts      map[string]struct{}
+	userAgent  string
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// Config configures the Resolver.
+type Config struct {
+	// HTTPClient is copied; its CheckRedirect is replaced to enforce MaxRedirects.
+	HTTPClient *http.Client
+	// Hosts lists the redirector hosts to resolve. Invalid entries are ignored.
+	Hosts        []string
+	Timeout      time.Duration
+	MaxRedirects int
+	UserAgent    string
+}
+
+// NewResolver builds a Resolver. It returns nil when no valid host is configured so that
+// callers can skip resolution entirely.
+func NewResolver(cfg Config) *Resolver {
+	hosts := make(map[string]struct{}, len(cfg.Hosts))
+	for _, h := range cfg.Hosts {
+		if host, err := hostname.Normalize(h); err == nil {
+			hosts[host] = struct{}{}
+		}
+	}
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	var client http.Client
+	if cfg.HTTPClient != nil {
+		client = *cfg.HTTPClient
+	}
+	client.Timeout = cfg.Timeout
+	if client.Timeout <= 0 {
+		client.Timeout = defaultTimeout
+	}
+	maxRedirects := cfg.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+	client.CheckRedirect = func(_ *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return errTooManyRedirects
+		}
+		return nil
+	}
+	ua := strings.TrimSpace(cfg.UserAgent)
+	if ua == "" {
+		ua = defaultUA
+	}
+	return &Resolver{
+		httpClient: &client,
+		hosts:      hosts,
+		userAgent:  ua,
+		cache:      make(map[string]string),
+	}
+}
+
+// Resolve returns the destination of rawURL when its host is a configured redirector, and
+// rawURL itself otherwise. Results, including failures, are cached for the lifetime of the
+// Resolver so that each URL is looked up at most once per run.
+func (r *Resolver) Resolve(ctx context.Context, rawURL string) (string, error) {
+	if r == nil || !r.applies(rawURL) {
+		return rawURL, nil
+	}
+
+	r.mu.Lock()
+	cached, ok := r.cache[rawURL]
+	r.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	resolved, err := r.lookup(ctx, rawURL)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.store(rawURL, rawURL)
+		}
+		return rawURL, err
+	}
+	r.store(rawURL, resolved)
+	return resolved, nil
+}
+
+func (r *Resolver) applies(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	_, ok := r.hosts[strings.ToLower(u.Hostname())]
+	return ok
+}
+
+func (r *Resolver) store(rawURL, resolved string) {
+	r.mu.Lock()
+	r.cache[rawURL] = resolved
+	r.mu.Unlock()
+}
+
+// lookup issues a HEAD request and returns the URL of the last response in the redirect chain.
+func (r *Resolver) lookup(ctx context.Context, rawURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", r.userAgent)
+
+	resp, err := r.httpClient.Do(req) // #nosec G704
+	if err != nil {
+		return "", fmt.Errorf("resolve redirect %s: %w", rawURL, err)
+	}
+	_ = resp.Body.Close()
+
+	final := resp.Request.URL
+	if final == nil || final.Host == "" {
+		return "", fmt.Errorf("resolve redirect %s: no destination", rawURL)
+	}
+	return final.String(), nil
+}
@@ -0,0 +1,108 @@
+package redirect
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newRedirector starts a fake shortener: /s/abc redirects to /articles/1 and /loop redirects
+// to itself. hits counts the redirect responses.
+func newRedirector(t *testing.T, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/s/abc", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		require.Equal(t, http.MethodHead, r.Method)
+		http.Redirect(w, r, "/articles/1?ref=feed", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/articles/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func serverHost(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return u.Hostname()
+}
+
+func TestResolveFollowsRedirect(t *testing.T) {
+	var hits atomic.Int32
+	server := newRedirector(t, &hits)
+	resolver := NewResolver(Config{HTTPClient: server.Client(), Hosts: []string{serverHost(t, server)}})
+
+	got, err := resolver.Resolve(context.Background(), server.URL+"/s/abc")
+	require.NoError(t, err)
+	require.Equal(t, server.URL+"/articles/1?ref=feed", got)
+	require.Equal(t, int32(1), hits.Load())
+}
+
+func TestResolveCachesResults(t *testing.T) {
+	var hits atomic.Int32
+	server := newRedirector(t, &hits)
+	resolver := NewResolver(Config{HTTPClient: server.Client(), Hosts: []string{serverHost(t, server)}})
+
+	for i := 0; i < 3; i++ {
+		got, err := resolver.Resolve(context.Background(), server.URL+"/s/abc")
+		require.NoError(t, err)
+		require.Equal(t, server.URL+"/articles/1?ref=feed", got)
+	}
+	require.Equal(t, int32(1), hits.Load())
+}
+
+func TestResolveSkipsHostsNotOptedIn(t *testing.T) {
+	var hits atomic.Int32
+	server := newRedirector(t, &hits)
+	resolver := NewResolver(Config{HTTPClient: server.Client(), Hosts: []string{"t.example.com"}})
+
+	raw := server.URL + "/s/abc"
+	got, err := resolver.Resolve(context.Background(), raw)
+	require.NoError(t, err)
+	require.Equal(t, raw, got)
+	require.Zero(t, hits.Load())
+}
+
+func TestResolveRedirectCap(t *testing.T) {
+	var hits atomic.Int32
+	server := newRedirector(t, &hits)
+	resolver := NewResolver(Config{
+		HTTPClient:   server.Client(),
+		Hosts:        []string{serverHost(t, server)},
+		MaxRedirects: 2,
+	})
+
+	raw := server.URL + "/loop"
+	got, err := resolver.Resolve(context.Background(), raw)
+	require.ErrorIs(t, err, errTooManyRedirects)
+	require.Equal(t, raw, got)
+	require.Equal(t, int32(3), hits.Load())
+
+	// The failure is cached as well.
+	got, err = resolver.Resolve(context.Background(), raw)
+	require.NoError(t, err)
+	require.Equal(t, raw, got)
+	require.Equal(t, int32(3), hits.Load())
+}
+
+func TestNewResolverWithoutHosts(t *testing.T) {
+	require.Nil(t, NewResolver(Config{Hosts: []string{"", "bad host"}}))
+
+	var resolver *Resolver
+	got, err := resolver.Resolve(context.Background(), "https://t.example.com/x")
+	require.NoError(t, err)
+	require.Equal(t, "https://t.example.com/x", got)
+}
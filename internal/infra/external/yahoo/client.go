@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"hateblog/internal/domain/tag"
 )
 
 const (
@@ -56,10 +58,9 @@ func NewClient(cfg ClientConfig) *Client {
 }
 
 // Keyphrase represents Yahoo keyphrase output.
-type Keyphrase struct {
-	Text  string `json:"text"`
-	Score int    `json:"score"`
-}
+type Keyphrase = tag.Keyphrase
+
+var _ tag.KeyphraseExtractor = (*Client)(nil)
 
 // Extract returns keyphrases for the provided text.
 func (c *Client) Extract(ctx context.Context, text string) ([]Keyphrase, error) {
@@ -148,7 +149,13 @@ type keyphraseResponse struct {
 }
 
 type keyphraseResult struct {
-	Phrases []Keyphrase `json:"phrases"`
+	Phrases []keyphrasePhrase `json:"phrases"`
+}
+
+// keyphrasePhrase is the wire form of a phrase; Keyphrase is the domain type without json tags.
+type keyphrasePhrase struct {
+	Text  string `json:"text"`
+	Score int    `json:"score"`
 }
 
 type keyphraseErrorBody struct {
@@ -181,6 +188,11 @@ func IsTooManyRequests(err error) (time.Duration, bool) {
 	return statusErr.RetryAfter, true
 }
 
+// IsTooManyRequests implements tag.KeyphraseExtractor using the package-level IsTooManyRequests.
+func (c *Client) IsTooManyRequests(err error) (time.Duration, bool) {
+	return IsTooManyRequests(err)
+}
+
 func retryAfterDuration(raw string) time.Duration {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
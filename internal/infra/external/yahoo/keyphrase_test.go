@@ -22,14 +22,7 @@ func TestExtractKeyphrasesSuccess(t *testing.T) {
 		require.Equal(t, keyphraseMethod, req.Method)
 		require.Equal(t, "Hello world", req.Params.Query)
 
-		_ = json.NewEncoder(w).Encode(keyphraseResponse{
-			Result: &keyphraseResult{
-				Phrases: []Keyphrase{
-					{Text: "Go", Score: 100},
-					{Text: "world", Score: 50},
-				},
-			},
-		})
+		_, _ = w.Write([]byte(`{"id":"1","jsonrpc":"2.0","result":{"phrases":[{"text":"Go","score":100},{"text":"world","score":50}]}}`))
 	}))
 	defer server.Close()
 
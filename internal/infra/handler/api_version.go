@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// apiVersion selects the response envelope. v1 is the original shape; v2 wraps lists in
+// {"data", "meta"} and errors in {"error": {"code", "message"}}.
+type apiVersion int
+
+const (
+	apiVersion1 apiVersion = 1
+	apiVersion2 apiVersion = 2
+
+	// mediaTypeV2 is the Accept value that selects v2 and the Content-Type of v2 responses.
+	mediaTypeV2 = "application/vnd.hateblog.v2+json"
+	// mediaTypeVersioned is the vendor media type prefix; "application/vnd.hateblog.v1+json" selects v1.
+	mediaTypeVersioned = "application/vnd.hateblog.v"
+)
+
+type apiVersionKey struct{}
+
+// apiVersionMiddleware stores the API version negotiated from the Accept header in the request
+// context. Requests without a vendor media type get defaultVersion (v1 when not 2).
+func apiVersionMiddleware(defaultVersion int) func(http.Handler) http.Handler {
+	fallback := apiVersion1
+	if apiVersion(defaultVersion) == apiVersion2 {
+		fallback = apiVersion2
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept")
+			version := negotiateAPIVersion(r.Header.Values("Accept"), fallback)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, version)))
+		})
+	}
+}
+
+// negotiateAPIVersion returns the version of the first vendor media type in accept that names a
+// known version, or fallback.
+func negotiateAPIVersion(accept []string, fallback apiVersion) apiVersion {
+	for _, header := range accept {
+		for _, part := range strings.Split(header, ",") {
+			mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil || !strings.HasPrefix(mediaType, mediaTypeVersioned) {
+				continue
+			}
+			switch strings.TrimPrefix(mediaType, mediaTypeVersioned) {
+			case "1+json":
+				return apiVersion1
+			case "2+json":
+				return apiVersion2
+			}
+		}
+	}
+	return fallback
+}
+
+// apiVersionOf returns the negotiated API version of r (v1 outside apiVersionMiddleware).
+func apiVersionOf(r *http.Request) apiVersion {
+	if r == nil {
+		return apiVersion1
+	}
+	if v, ok := r.Context().Value(apiVersionKey{}).(apiVersion); ok {
+		return v
+	}
+	return apiVersion1
+}
+
+// listEnvelope is the v2 shape of list responses.
+type listEnvelope struct {
+	Data any `json:"data"`
+	Meta any `json:"meta"`
+}
+
+// listMeta is the pagination part of a v2 list envelope.
+type listMeta struct {
+	Total    int64 `json:"total"`
+	Limit    int   `json:"limit"`
+	Offset   int   `json:"offset"`
+	Relaxed  bool  `json:"relaxed,omitempty"`
+	MinUsers *int  `json:"min_users,omitempty"`
+}
+
+// enveloper is implemented by list responses that have a v2 envelope.
+type enveloper interface {
+	envelope() listEnvelope
+	// resultCount returns the number of items in the page.
+	resultCount() int
+}
+
+// writeList writes a list response in the envelope of the negotiated API version, as
+// MessagePack when the request asked for it.
+func writeList(w http.ResponseWriter, r *http.Request, status int, resp enveloper) {
+	setResultCountHeader(w, resp.resultCount())
+	var payload any = resp
+	if apiVersionOf(r) == apiVersion2 {
+		payload = resp.envelope()
+	}
+	switch {
+	case wantsMsgpack(r):
+		writeMsgpack(w, status, payload)
+	case apiVersionOf(r) == apiVersion2:
+		writeJSONAs(w, status, mediaTypeV2, payload)
+	default:
+		writeJSON(w, status, payload)
+	}
+}
+
+// errorEnvelope is the v2 shape of error responses.
+type errorEnvelope struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// errorCode returns the v2 error code of an HTTP status.
+func errorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusTooEarly:
+		return "too_early"
+	case http.StatusTooManyRequests:
+		return "too_many_requests"
+	case http.StatusServiceUnavailable:
+		return "service_unavailable"
+	default:
+		if status >= 500 {
+			return "internal_error"
+		}
+		return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+	}
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	domainEntry "hateblog/internal/domain/entry"
+	usecaseRanking "hateblog/internal/usecase/ranking"
+)
+
+// getAccept performs a GET request with the given Accept header ("" sends none).
+func (ts *testServer) getAccept(t *testing.T, path, accept string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	return resp
+}
+
+func TestNegotiateAPIVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   []string
+		fallback apiVersion
+		want     apiVersion
+	}{
+		{name: "no header", want: apiVersion1, fallback: apiVersion1},
+		{name: "no header with v2 default", fallback: apiVersion2, want: apiVersion2},
+		{name: "plain json", accept: []string{"application/json"}, fallback: apiVersion1, want: apiVersion1},
+		{name: "v2", accept: []string{mediaTypeV2}, fallback: apiVersion1, want: apiVersion2},
+		{name: "v2 among others", accept: []string{"text/html, application/vnd.hateblog.v2+json;q=0.9"}, fallback: apiVersion1, want: apiVersion2},
+		{name: "v2 in second header", accept: []string{"application/json", mediaTypeV2}, fallback: apiVersion1, want: apiVersion2},
+		{name: "v1 overrides v2 default", accept: []string{"application/vnd.hateblog.v1+json"}, fallback: apiVersion2, want: apiVersion1},
+		{name: "unknown version", accept: []string{"application/vnd.hateblog.v9+json"}, fallback: apiVersion1, want: apiVersion1},
+		{name: "malformed", accept: []string{";;"}, fallback: apiVersion1, want: apiVersion1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := negotiateAPIVersion(tt.accept, tt.fallback); got != tt.want {
+				t.Errorf("negotiateAPIVersion(%q) = %d, want %d", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIVersion_EntryListShapes(t *testing.T) {
+	entries := []*domainEntry.Entry{
+		newTestEntry(uuid.New(), "Entry 1", 100),
+		newTestEntry(uuid.New(), "Entry 2", 50),
+	}
+	mockRepo := &mockEntryRepository{entries: entries, total: 2}
+	ts := newTestServer(RouterConfig{
+		EntryHandler: NewEntryHandler(newTestEntryService(mockRepo), testAPIBasePath),
+	})
+	defer ts.Close()
+	path := apiPath("/entries/new?date=20240101&limit=10")
+
+	resp := ts.getAccept(t, path, "")
+	defer resp.Body.Close()
+	assertStatus(t, resp, http.StatusOK)
+	assertContentType(t, resp, "application/json")
+	var v1 map[string]json.RawMessage
+	decodeJSON(t, resp, &v1)
+	for _, key := range []string{"entries", "total", "limit", "offset"} {
+		if _, ok := v1[key]; !ok {
+			t.Errorf("v1 response lacks %q: %v", key, v1)
+		}
+	}
+	if _, ok := v1["data"]; ok {
+		t.Errorf("v1 response must not carry data")
+	}
+
+	resp = ts.getAccept(t, path, mediaTypeV2)
+	defer resp.Body.Close()
+	assertStatus(t, resp, http.StatusOK)
+	assertContentType(t, resp, mediaTypeV2)
+	if vary := resp.Header.Values("Vary"); !slices.Contains(vary, "Accept") {
+		t.Errorf("Vary = %v, want Accept", vary)
+	}
+	var raw map[string]json.RawMessage
+	decodeJSON(t, resp, &raw)
+	if len(raw) != 2 {
+		t.Errorf("v2 response keys = %v, want data and meta", raw)
+	}
+	var (
+		data []entryResponse
+		meta listMeta
+	)
+	if err := json.Unmarshal(raw["data"], &data); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	if err := json.Unmarshal(raw["meta"], &meta); err != nil {
+		t.Fatalf("decode meta: %v", err)
+	}
+
+	var v1Entries []entryResponse
+	if err := json.Unmarshal(v1["entries"], &v1Entries); err != nil {
+		t.Fatalf("decode v1 entries: %v", err)
+	}
+	if len(data) != len(v1Entries) || data[0].ID != v1Entries[0].ID || data[1].Title != v1Entries[1].Title {
+		t.Errorf("v2 data = %+v, want v1 entries %+v", data, v1Entries)
+	}
+	if meta != (listMeta{Total: 2, Limit: 10, Offset: 0}) {
+		t.Errorf("v2 meta = %+v", meta)
+	}
+}
+
+func TestAPIVersion_ErrorShapes(t *testing.T) {
+	ts := newTestServer(RouterConfig{
+		EntryHandler: NewEntryHandler(newTestEntryService(&mockEntryRepository{}), testAPIBasePath),
+	})
+	defer ts.Close()
+	path := apiPath("/entries/new?date=invalid")
+
+	resp := ts.getAccept(t, path, "")
+	defer resp.Body.Close()
+	v1 := assertErrorResponse(t, resp, http.StatusBadRequest)
+
+	resp = ts.getAccept(t, path, mediaTypeV2)
+	defer resp.Body.Close()
+	assertStatus(t, resp, http.StatusBadRequest)
+	assertContentType(t, resp, mediaTypeV2)
+	var v2 errorEnvelope
+	decodeJSON(t, resp, &v2)
+	if v2.Error.Code != "bad_request" {
+		t.Errorf("code = %q, want bad_request", v2.Error.Code)
+	}
+	if v2.Error.Message != v1["error"] {
+		t.Errorf("message = %q, want %q", v2.Error.Message, v1["error"])
+	}
+}
+
+func TestAPIVersion_DefaultVersion(t *testing.T) {
+	mockRepo := &mockRankingRepository{
+		result: usecaseRanking.Result{Entries: []*domainEntry.Entry{newTestEntry(uuid.New(), "Ranked", 10)}, Total: 1},
+	}
+	ts := newTestServer(RouterConfig{
+		RankingHandler:    NewRankingHandler(usecaseRanking.NewService(mockRepo, nil, nil, nil), testAPIBasePath),
+		DefaultAPIVersion: 2,
+	})
+	defer ts.Close()
+	path := apiPath("/rankings/yearly?year=2024")
+
+	resp := ts.getAccept(t, path, "")
+	defer resp.Body.Close()
+	assertStatus(t, resp, http.StatusOK)
+	var v2 struct {
+		Data []rankingEntryResponse `json:"data"`
+		Meta map[string]any         `json:"meta"`
+	}
+	decodeJSON(t, resp, &v2)
+	if len(v2.Data) != 1 || v2.Data[0].Rank != 1 {
+		t.Errorf("data = %+v", v2.Data)
+	}
+	if v2.Meta["period_type"] != "yearly" || v2.Meta["year"] != float64(2024) || v2.Meta["total"] != float64(1) {
+		t.Errorf("meta = %v", v2.Meta)
+	}
+
+	resp = ts.getAccept(t, path, "application/vnd.hateblog.v1+json")
+	defer resp.Body.Close()
+	assertStatus(t, resp, http.StatusOK)
+	var v1 rankingResponse
+	decodeJSON(t, resp, &v1)
+	if v1.PeriodType != "yearly" || len(v1.Entries) != 1 {
+		t.Errorf("v1 response = %+v", v1)
+	}
+}
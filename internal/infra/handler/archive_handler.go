@@ -42,8 +42,14 @@ func (h *ArchiveHandler) handleArchive(w http.ResponseWriter, r *http.Request) {
 	}
 	setCacheStatusHeader(w, cacheHit)
 
+	summary := domainArchive.Summarize(items)
 	resp := archiveResponse{
-		Items: make([]archiveItemResponse, 0, len(items)),
+		Items:   make([]archiveItemResponse, 0, len(items)),
+		Summary: archiveSummaryResponse{Total: summary.Total},
+	}
+	if !summary.IsEmpty() {
+		resp.Summary.From = summary.From.Format("2006-01-02")
+		resp.Summary.To = summary.To.Format("2006-01-02")
 	}
 	for _, item := range items {
 		resp.Items = append(resp.Items, archiveItemResponse{
@@ -56,7 +62,14 @@ func (h *ArchiveHandler) handleArchive(w http.ResponseWriter, r *http.Request) {
 }
 
 type archiveResponse struct {
-	Items []archiveItemResponse `json:"items"`
+	Items   []archiveItemResponse  `json:"items"`
+	Summary archiveSummaryResponse `json:"summary"`
+}
+
+type archiveSummaryResponse struct {
+	Total int    `json:"total"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
 }
 
 type archiveItemResponse struct {
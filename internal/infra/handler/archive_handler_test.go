@@ -185,6 +185,56 @@ func TestArchiveHandler_ResponseFormat(t *testing.T) {
 	if item.Count != 100 {
 		t.Errorf("count = %d, want %d", item.Count, 100)
 	}
+
+	want := archiveSummaryResponse{Total: 100, From: "2025-12-20", To: "2025-12-20"}
+	if result.Summary != want {
+		t.Errorf("summary = %+v, want %+v", result.Summary, want)
+	}
+}
+
+func TestArchiveHandler_Summary(t *testing.T) {
+	date1, _ := time.Parse("2006-01-02", "2025-01-15")
+	date2, _ := time.Parse("2006-01-02", "2025-01-03")
+
+	tests := []struct {
+		name  string
+		items []repository.ArchiveCount
+		want  archiveSummaryResponse
+	}{
+		{
+			name: "multiple days",
+			items: []repository.ArchiveCount{
+				{Date: date1, Count: 150},
+				{Date: date2, Count: 25},
+			},
+			want: archiveSummaryResponse{Total: 175, From: "2025-01-03", To: "2025-01-15"},
+		},
+		{
+			name:  "empty",
+			items: []repository.ArchiveCount{},
+			want:  archiveSummaryResponse{Total: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := usecaseArchive.NewService(&mockArchiveRepository{items: tt.items}, nil)
+			ts := newTestServer(RouterConfig{
+				ArchiveHandler: NewArchiveHandler(service),
+			})
+			defer ts.Close()
+
+			resp := ts.get(t, apiPath("/archive"))
+			defer resp.Body.Close()
+			assertStatus(t, resp, http.StatusOK)
+
+			var result archiveResponse
+			decodeJSON(t, resp, &result)
+			if result.Summary != tt.want {
+				t.Errorf("summary = %+v, want %+v", result.Summary, tt.want)
+			}
+		})
+	}
 }
 
 // mockArchiveRepository implements usecaseArchive.Repository for testing.
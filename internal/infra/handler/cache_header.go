@@ -3,6 +3,7 @@ package handler
 import "net/http"
 
 const (
+	// cacheStatusHeader is exposed to cross-origin scripts by the server's CORS middleware.
 	cacheStatusHeader = "X-Cache"
 	cacheStatusHit    = "HIT"
 	cacheStatusMiss   = "MISS"
@@ -2,15 +2,23 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
 	domainEntry "hateblog/internal/domain/entry"
 	"hateblog/internal/domain/tag"
+	"hateblog/internal/pkg/hostname"
+	"hateblog/internal/pkg/textutil"
 	usecaseEntry "hateblog/internal/usecase/entry"
 )
 
@@ -21,8 +29,14 @@ const (
 
 // EntryHandler exposes entry endpoints.
 type EntryHandler struct {
-	service     *usecaseEntry.Service
-	apiBasePath string
+	service      *usecaseEntry.Service
+	apiBasePath  string
+	feedBaseURL  string
+	maxOffset    int
+	maxTags      int
+	trendingSort bool
+	curationAuth func(http.Handler) http.Handler
+	adminAuth    func(http.Handler) http.Handler
 }
 
 // NewEntryHandler creates a new EntryHandler.
@@ -33,14 +47,73 @@ func NewEntryHandler(service *usecaseEntry.Service, apiBasePath string) *EntryHa
 	}
 }
 
+// WithFeedBaseURL enables RSS discovery Link headers pointing under baseURL.
+func (h *EntryHandler) WithFeedBaseURL(baseURL string) *EntryHandler {
+	h.feedBaseURL = strings.TrimSpace(baseURL)
+	return h
+}
+
+// WithMaxOffset rejects list requests with an offset above maxOffset (0 means no limit).
+func (h *EntryHandler) WithMaxOffset(maxOffset int) *EntryHandler {
+	h.maxOffset = maxOffset
+	return h
+}
+
+// WithMaxTags rejects tag lists longer than maxTags before they reach the service
+// (0 means no limit).
+func (h *EntryHandler) WithMaxTags(maxTags int) *EntryHandler {
+	h.maxTags = maxTags
+	return h
+}
+
+// WithTrendingSort accepts sort=trending on the hot list when enabled.
+func (h *EntryHandler) WithTrendingSort(enabled bool) *EntryHandler {
+	h.trendingSort = enabled
+	return h
+}
+
+// WithCurationAuth enables the editor-only endpoints behind auth.
+// Without it, /entries/untagged and the entry tag endpoints are not registered.
+func (h *EntryHandler) WithCurationAuth(auth func(http.Handler) http.Handler) *EntryHandler {
+	h.curationAuth = auth
+	return h
+}
+
+// WithAdminAuth enables the master-key-only endpoints behind auth.
+// Without it, PUT /entries/{id}/tags/{tag} is not registered.
+func (h *EntryHandler) WithAdminAuth(auth func(http.Handler) http.Handler) *EntryHandler {
+	h.adminAuth = auth
+	return h
+}
+
 // RegisterRoutes registers entry handlers on the router.
 func (h *EntryHandler) RegisterRoutes(r chiRouter) {
+	r.Get("/entries", h.handleEntriesByIDs)
 	r.Get("/entries/new", h.handleNewEntries)
 	r.Get("/entries/hot", h.handleHotEntries)
+	if h.curationAuth != nil {
+		r.Get("/entries/untagged", h.curationAuth(http.HandlerFunc(h.handleUntaggedEntries)).ServeHTTP)
+		r.Post("/entries/{id}/tags", h.curationAuth(http.HandlerFunc(h.handleAddEntryTags)).ServeHTTP)
+		r.Delete("/entries/{id}/tags/{tag}", h.curationAuth(http.HandlerFunc(h.handleRemoveEntryTag)).ServeHTTP)
+	}
+	if h.adminAuth != nil {
+		r.Put("/entries/{id}/tags/{tag}", h.adminAuth(http.HandlerFunc(h.handleUpdateEntryTagScore)).ServeHTTP)
+	}
 }
 
 func (h *EntryHandler) handleNewEntries(w http.ResponseWriter, r *http.Request) {
-	params, err := buildDayListParams(r)
+	params, err := buildDayListParams(w, r, h.maxOffset)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	sortType, err := readQueryNewSort(r, "sort")
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	params.ByPostedAt = sortType == domainEntry.SortPosted
+	maxTags, err := readQueryMaxTags(r)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
@@ -52,12 +125,24 @@ func (h *EntryHandler) handleNewEntries(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	setFeedLinkHeader(w, h.feedBaseURL, "/entries/new", dayFeedQuery(params))
 	setCacheStatusHeader(w, cacheHit)
-	writeJSON(w, http.StatusOK, buildEntryListResponse(result, params.Limit, params.Offset, h.apiBasePath))
+	writeList(w, r, http.StatusOK, buildEntryListResponse(result, params.Limit, params.Offset, h.apiBasePath, maxTags))
 }
 
 func (h *EntryHandler) handleHotEntries(w http.ResponseWriter, r *http.Request) {
-	params, err := buildDayListParams(r)
+	params, err := buildDayListParams(w, r, h.maxOffset)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	sortType, err := readQueryHotSort(r, "sort", h.trendingSort)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	params.Trending = sortType == domainEntry.SortTrending
+	maxTags, err := readQueryMaxTags(r)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
@@ -69,29 +154,198 @@ func (h *EntryHandler) handleHotEntries(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	setFeedLinkHeader(w, h.feedBaseURL, "/entries/hot", dayFeedQuery(params))
 	setCacheStatusHeader(w, cacheHit)
-	writeJSON(w, http.StatusOK, buildEntryListResponse(result, params.Limit, params.Offset, h.apiBasePath))
+	writeList(w, r, http.StatusOK, buildEntryListResponse(result, params.Limit, params.Offset, h.apiBasePath, maxTags))
+}
+
+func (h *EntryHandler) handleEntriesByIDs(w http.ResponseWriter, r *http.Request) {
+	ids, err := readQueryEntryIDs(r, "ids", domainEntry.MaxBatchIDs)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	maxTags, err := readQueryMaxTags(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	entries, err := h.service.GetEntriesByIDs(r.Context(), ids)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domainEntry.ErrInvalidListQuery) {
+			status = http.StatusBadRequest
+		}
+		writeError(w, r, status, err)
+		return
+	}
+
+	resp := entryBatchResponse{Entries: make([]entryResponse, 0, len(entries))}
+	for _, ent := range entries {
+		resp.Entries = append(resp.Entries, toEntryResponse(ent, h.apiBasePath, maxTags))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// readQueryEntryIDs parses a comma-separated list of entry IDs. Empty items are ignored.
+func readQueryEntryIDs(r *http.Request, key string, max int) ([]domainEntry.ID, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(key))
+	if raw == "" {
+		return nil, fmt.Errorf("%s is required", key)
+	}
+	parts := strings.Split(raw, ",")
+	ids := make([]domainEntry.ID, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := uuid.Parse(part)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidEntryID, part)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%s is required", key)
+	}
+	if len(ids) > max {
+		return nil, fmt.Errorf("%s must have at most %d items", key, max)
+	}
+	return ids, nil
+}
+
+func (h *EntryHandler) handleUntaggedEntries(w http.ResponseWriter, r *http.Request) {
+	limit, err := readQueryLimit(w, r, 1, 100, defaultLimit)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	offset, err := readQueryOffset(r, h.maxOffset)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	maxTags, err := readQueryMaxTags(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := h.service.ListUntaggedEntries(r.Context(), usecaseEntry.UntaggedListParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeList(w, r, http.StatusOK, buildEntryListResponse(result, limit, offset, h.apiBasePath, maxTags))
 }
 
-func buildEntryListResponse(result usecaseEntry.ListResult, limit, offset int, apiBasePath string) entryListResponse {
+func (h *EntryHandler) handleAddEntryTags(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errInvalidEntryID)
+		return
+	}
+	var req addEntryTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if h.maxTags > 0 && len(req.Tags) > h.maxTags {
+		writeError(w, r, http.StatusBadRequest, fmt.Errorf("tags must have at most %d items", h.maxTags))
+		return
+	}
+
+	ent, err := h.service.AddTags(r.Context(), id, req.Tags, req.Score)
+	if err != nil {
+		writeError(w, r, entryTagErrorStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toEntryResponse(ent, h.apiBasePath, 0))
+}
+
+func (h *EntryHandler) handleRemoveEntryTag(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errInvalidEntryID)
+		return
+	}
+
+	if err := h.service.RemoveTag(r.Context(), id, chi.URLParam(r, "tag")); err != nil {
+		writeError(w, r, entryTagErrorStatus(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *EntryHandler) handleUpdateEntryTagScore(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errInvalidEntryID)
+		return
+	}
+	var req updateEntryTagScoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if req.Score == nil {
+		writeError(w, r, http.StatusBadRequest, fmt.Errorf("score is required"))
+		return
+	}
+
+	ent, err := h.service.UpdateTagScore(r.Context(), id, chi.URLParam(r, "tag"), *req.Score)
+	if err != nil {
+		writeError(w, r, entryTagErrorStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toEntryResponse(ent, h.apiBasePath, 0))
+}
+
+// entryTagErrorStatus maps AddTags/RemoveTag/UpdateTagScore errors to HTTP status codes.
+func entryTagErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, tag.ErrInvalidTag):
+		return http.StatusBadRequest
+	case errors.Is(err, domainEntry.ErrNotFound), errors.Is(err, usecaseEntry.ErrTagNotAttached):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func buildEntryListResponse(result usecaseEntry.ListResult, limit, offset int, apiBasePath string, maxTags int) entryListResponse {
 	resp := entryListResponse{
 		Entries: make([]entryResponse, 0, len(result.Entries)),
 		Total:   result.Total,
 		Limit:   limit,
 		Offset:  offset,
 	}
+	if result.Relaxed {
+		resp.Relaxed = true
+		resp.MinUsers = &result.MinBookmarkCount
+	}
 
 	for _, ent := range result.Entries {
-		resp.Entries = append(resp.Entries, toEntryResponse(ent, apiBasePath))
+		resp.Entries = append(resp.Entries, toEntryResponse(ent, apiBasePath, maxTags))
 	}
 
 	return resp
 }
 
-func toEntryResponse(ent *domainEntry.Entry, apiBasePath string) entryResponse {
+// toEntryResponse converts an entry. A positive maxTags keeps only the highest-scored maxTags
+// taggings and reports the full count in TotalTags.
+func toEntryResponse(ent *domainEntry.Entry, apiBasePath string, maxTags int) entryResponse {
+	// Stored text is sanitized at ingest; this is a backstop for older or foreign rows, since
+	// encoding/json would otherwise turn invalid bytes into U+FFFD.
 	resp := entryResponse{
 		ID:            ent.ID,
-		Title:         ent.Title,
+		Title:         textutil.SanitizeUTF8(ent.Title),
 		URL:           ent.URL,
 		BookmarkCount: ent.BookmarkCount,
 		PostedAt:      ent.PostedAt,
@@ -102,15 +356,25 @@ func toEntryResponse(ent *domainEntry.Entry, apiBasePath string) entryResponse {
 	}
 
 	if ent.Excerpt != "" {
-		text := ent.Excerpt
+		text := textutil.SanitizeUTF8(ent.Excerpt)
 		resp.Excerpt = &text
 	}
 	if ent.Subject != "" {
-		subject := ent.Subject
+		subject := textutil.SanitizeUTF8(ent.Subject)
 		resp.Subject = &subject
 	}
 
-	for _, tagging := range ent.Tags {
+	taggings := ent.Tags
+	if maxTags > 0 {
+		total := len(taggings)
+		resp.TotalTags = &total
+		if total > maxTags {
+			taggings = slices.Clone(taggings)
+			slices.SortStableFunc(taggings, func(a, b domainEntry.Tagging) int { return b.Score - a.Score })
+			taggings = taggings[:maxTags]
+		}
+	}
+	for _, tagging := range taggings {
 		resp.Tags = append(resp.Tags, entryTagResponse{
 			TagID: tagging.TagID,
 			Name:  tagging.Name,
@@ -121,7 +385,7 @@ func toEntryResponse(ent *domainEntry.Entry, apiBasePath string) entryResponse {
 	return resp
 }
 
-func buildDayListParams(r *http.Request) (usecaseEntry.DayListParams, error) {
+func buildDayListParams(w http.ResponseWriter, r *http.Request, maxOffset int) (usecaseEntry.DayListParams, error) {
 	params := usecaseEntry.DayListParams{
 		Limit:  defaultLimit,
 		Offset: 0,
@@ -146,7 +410,7 @@ func buildDayListParams(r *http.Request) (usecaseEntry.DayListParams, error) {
 		}
 		params.Limit = limit
 	} else {
-		params.Limit = defaultLimit
+		params.Limit = preferredLimit(w, r, 1, domainEntry.MaxLimit, defaultLimit)
 	}
 
 	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
@@ -154,6 +418,9 @@ func buildDayListParams(r *http.Request) (usecaseEntry.DayListParams, error) {
 		if err != nil || offset < 0 {
 			return usecaseEntry.DayListParams{}, fmt.Errorf("offset must be >= 0")
 		}
+		if err := checkOffset(offset, maxOffset); err != nil {
+			return usecaseEntry.DayListParams{}, err
+		}
 		params.Offset = offset
 	}
 
@@ -167,9 +434,31 @@ func buildDayListParams(r *http.Request) (usecaseEntry.DayListParams, error) {
 	}
 	params.MinBookmarkCount = minUsers
 
+	loc, err := readQueryLocation(r, "tz")
+	if err != nil {
+		return usecaseEntry.DayListParams{}, err
+	}
+	params.Location = loc
+
+	relax, err := readQueryBool(r, "relax", false)
+	if err != nil {
+		return usecaseEntry.DayListParams{}, err
+	}
+	params.Relax = relax
+
 	return params, nil
 }
 
+func dayFeedQuery(params usecaseEntry.DayListParams) url.Values {
+	q := url.Values{}
+	q.Set("date", params.Date)
+	q.Set("min_users", strconv.Itoa(params.MinBookmarkCount))
+	if params.Location != nil {
+		q.Set("tz", params.Location.String())
+	}
+	return q
+}
+
 func isValidDate(value string) bool {
 	if len(value) != 8 {
 		return false
@@ -178,12 +467,18 @@ func isValidDate(value string) bool {
 	return err == nil
 }
 
+// buildFaviconURL points at the favicon endpoint for the entry's favicon host.
+// Keep the host derivation in sync with the cache keys; after changing it, run
+// `admin favicon recompute` to drop keys that are no longer read.
 func buildFaviconURL(raw, apiBasePath string) string {
 	u, err := url.Parse(raw)
 	if err != nil || u.Host == "" {
 		return ""
 	}
-	host := u.Hostname()
+	host, err := hostname.Favicon(u.Hostname())
+	if err != nil {
+		return ""
+	}
 	return fmt.Sprintf("%s?domain=%s", joinAPIPath(apiBasePath, "favicons"), host)
 }
 
@@ -193,6 +488,25 @@ type entryListResponse struct {
 	Total   int64           `json:"total"`
 	Limit   int             `json:"limit"`
 	Offset  int             `json:"offset"`
+	// Relaxed and MinUsers report the lowered min_users of a relax=true request.
+	Relaxed  bool `json:"relaxed,omitempty"`
+	MinUsers *int `json:"min_users,omitempty"`
+}
+
+func (r entryListResponse) resultCount() int {
+	return len(r.Entries)
+}
+
+func (r entryListResponse) envelope() listEnvelope {
+	return listEnvelope{
+		Data: r.Entries,
+		Meta: listMeta{Total: r.Total, Limit: r.Limit, Offset: r.Offset, Relaxed: r.Relaxed, MinUsers: r.MinUsers},
+	}
+}
+
+// entryBatchResponse matches EntryBatchResponse schema.
+type entryBatchResponse struct {
+	Entries []entryResponse `json:"entries"`
 }
 
 type entryResponse struct {
@@ -204,6 +518,7 @@ type entryResponse struct {
 	Excerpt       *string            `json:"excerpt,omitempty"`
 	Subject       *string            `json:"subject,omitempty"`
 	Tags          []entryTagResponse `json:"tags"`
+	TotalTags     *int               `json:"total_tags,omitempty"`
 	FaviconURL    string             `json:"favicon_url"`
 	CreatedAt     time.Time          `json:"created_at"`
 	UpdatedAt     time.Time          `json:"updated_at"`
@@ -215,8 +530,21 @@ type entryTagResponse struct {
 	Score int    `json:"score"`
 }
 
+type addEntryTagsRequest struct {
+	Tags  []string `json:"tags"`
+	Score *int     `json:"score"`
+}
+
+type updateEntryTagScoreRequest struct {
+	Score *int `json:"score"`
+}
+
 func writeJSON(w http.ResponseWriter, status int, payload any) {
-	w.Header().Set("Content-Type", "application/json")
+	writeJSONAs(w, status, "application/json", payload)
+}
+
+func writeJSONAs(w http.ResponseWriter, status int, contentType string, payload any) {
+	w.Header().Set("Content-Type", contentType)
 	if w.Header().Get(cacheStatusHeader) == "" {
 		w.Header().Set(cacheStatusHeader, cacheStatusMiss)
 	}
@@ -224,11 +552,27 @@ func writeJSON(w http.ResponseWriter, status int, payload any) {
 	_ = json.NewEncoder(w).Encode(payload)
 }
 
+// notFoundErrors are the causes of a 404 whose text is safe to show to clients.
+var notFoundErrors = []error{domainEntry.ErrNotFound, tag.ErrNotFound, usecaseEntry.ErrTagNotAttached}
+
+// notFoundMessage names what was not found without the wrapped storage error.
+func notFoundMessage(err error) string {
+	for _, target := range notFoundErrors {
+		if errors.Is(err, target) {
+			return target.Error()
+		}
+	}
+	return "not found"
+}
+
 func writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
 	message := "internal error"
-	if status == http.StatusBadRequest && err != nil {
+	if (status == http.StatusBadRequest || status == http.StatusMethodNotAllowed) && err != nil {
 		message = err.Error()
 	}
+	if status == http.StatusNotFound {
+		message = notFoundMessage(err)
+	}
 	if status >= 500 {
 		fields := []any{"status", status}
 		if err != nil {
@@ -245,5 +589,9 @@ func writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
 		slog.Error("internal server error", fields...) // #nosec G706
 		message = "internal error"
 	}
+	if apiVersionOf(r) == apiVersion2 {
+		writeJSONAs(w, status, mediaTypeV2, errorEnvelope{Error: errorBody{Code: errorCode(status), Message: message}})
+		return
+	}
 	writeJSON(w, status, map[string]string{"error": message})
 }
@@ -1,14 +1,22 @@
 package handler
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 
 	domainEntry "hateblog/internal/domain/entry"
+	domainTag "hateblog/internal/domain/tag"
 	usecaseEntry "hateblog/internal/usecase/entry"
 )
 
@@ -141,6 +149,28 @@ func TestEntryHandler_NewEntries(t *testing.T) {
 			queryParams: "?date=20240101&min_users=-1",
 			wantStatus:  http.StatusBadRequest,
 		},
+		{
+			name:        "success with tz",
+			queryParams: "?date=20240101&tz=America/New_York",
+			mockResult: buildTestListResult([]*domainEntry.Entry{
+				newTestEntry(uuid.New(), "Entry 1", 100),
+			}, 1),
+			wantStatus:     http.StatusOK,
+			wantEntryCount: 1,
+			wantTotal:      1,
+			wantLimit:      defaultLimit,
+			wantOffset:     0,
+		},
+		{
+			name:        "error: unknown tz",
+			queryParams: "?date=20240101&tz=Mars/Olympus",
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "error: Local tz",
+			queryParams: "?date=20240101&tz=Local",
+			wantStatus:  http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -223,6 +253,124 @@ func TestEntryHandler_NewEntries_ServiceError(t *testing.T) {
 	}
 }
 
+func TestEntryHandler_NewEntries_TimeZone(t *testing.T) {
+	var gotFrom time.Time
+	mockRepo := &mockEntryRepository{
+		listFunc: func(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
+			gotFrom = query.PostedAtFrom
+			return []*domainEntry.Entry{}, nil
+		},
+	}
+
+	service := newTestEntryService(mockRepo)
+	handler := NewEntryHandler(service, testAPIBasePath)
+	ts := newTestServer(RouterConfig{
+		EntryHandler: handler,
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/entries/new?date=20240101&tz=UTC"))
+	defer resp.Body.Close()
+
+	assertStatus(t, resp, http.StatusOK)
+	want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if !gotFrom.Equal(want) {
+		t.Errorf("PostedAtFrom = %v, want %v", gotFrom, want)
+	}
+}
+
+func TestEntryHandler_NewEntries_Sort(t *testing.T) {
+	found := newTestEntry(uuid.New(), "found late", 10)
+	found.PostedAt = found.CreatedAt.Add(-6 * time.Hour)
+	recent := newTestEntry(uuid.New(), "recent", 10)
+	recent.CreatedAt = found.CreatedAt.Add(-time.Hour)
+	recent.PostedAt = recent.CreatedAt
+	mockRepo := &mockEntryRepository{
+		listFunc: func(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
+			// The repository returns day lists in created_at order.
+			return []*domainEntry.Entry{found, recent}, nil
+		},
+	}
+	ts := newTestServer(RouterConfig{
+		EntryHandler: NewEntryHandler(newTestEntryService(mockRepo), testAPIBasePath),
+	})
+	defer ts.Close()
+
+	for _, tt := range []struct {
+		sort string
+		want []string
+	}{
+		{sort: "", want: []string{"found late", "recent"}},
+		{sort: "discovered", want: []string{"found late", "recent"}},
+		{sort: "new", want: []string{"found late", "recent"}},
+		{sort: "posted", want: []string{"recent", "found late"}},
+	} {
+		resp := ts.get(t, apiPath("/entries/new?date=20240101&sort="+tt.sort))
+		result := assertEntryListResponse(t, resp)
+		resp.Body.Close()
+		var got []string
+		for _, e := range result.Entries {
+			got = append(got, e.Title)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("sort=%q: got %v, want %v", tt.sort, got, tt.want)
+		}
+	}
+
+	resp := ts.get(t, apiPath("/entries/new?date=20240101&sort=hot"))
+	defer resp.Body.Close()
+	body := assertErrorResponse(t, resp, http.StatusBadRequest)
+	if body["error"] != "sort must be one of discovered, posted" {
+		t.Errorf("error = %q", body["error"])
+	}
+}
+
+func TestEntryHandler_FeedLink(t *testing.T) {
+	tests := []struct {
+		name        string
+		feedBaseURL string
+		path        string
+		wantLink    string
+	}{
+		{
+			name:        "new entries",
+			feedBaseURL: "https://feeds.example.com",
+			path:        "/entries/new?date=20240101&limit=10",
+			wantLink:    `<https://feeds.example.com/entries/new?date=20240101&min_users=5>; rel="alternate"; type="application/rss+xml"`,
+		},
+		{
+			name:        "hot entries with filters",
+			feedBaseURL: "https://feeds.example.com/",
+			path:        "/entries/hot?date=20240101&min_users=100&tz=UTC",
+			wantLink:    `<https://feeds.example.com/entries/hot?date=20240101&min_users=100&tz=UTC>; rel="alternate"; type="application/rss+xml"`,
+		},
+		{
+			name:     "disabled without base url",
+			path:     "/entries/new?date=20240101",
+			wantLink: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockEntryRepository{}
+			handler := NewEntryHandler(newTestEntryService(mockRepo), testAPIBasePath).WithFeedBaseURL(tt.feedBaseURL)
+			ts := newTestServer(RouterConfig{
+				EntryHandler: handler,
+			})
+			defer ts.Close()
+
+			resp := ts.get(t, apiPath(tt.path))
+			defer resp.Body.Close()
+
+			assertStatus(t, resp, http.StatusOK)
+			if got := resp.Header.Get("Link"); got != tt.wantLink {
+				t.Errorf("Link = %q, want %q", got, tt.wantLink)
+			}
+		})
+	}
+}
+
 func TestEntryHandler_HotEntries(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -281,11 +429,28 @@ func TestEntryHandler_HotEntries(t *testing.T) {
 			queryParams: "",
 			wantStatus:  http.StatusBadRequest,
 		},
+		{
+			name:        "success with trending sort",
+			queryParams: "?date=20240101&sort=trending",
+			mockResult: buildTestListResult([]*domainEntry.Entry{
+				newTestEntry(uuid.New(), "Hot Entry", 1000),
+			}, 1),
+			wantStatus:     http.StatusOK,
+			wantEntryCount: 1,
+			wantTotal:      1,
+			wantLimit:      defaultLimit,
+			wantOffset:     0,
+		},
 		{
 			name:        "error: invalid date",
 			queryParams: "?date=invalid",
 			wantStatus:  http.StatusBadRequest,
 		},
+		{
+			name:        "error: unsupported sort",
+			queryParams: "?date=20240101&sort=new",
+			wantStatus:  http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -301,7 +466,7 @@ func TestEntryHandler_HotEntries(t *testing.T) {
 			}
 
 			service := newTestEntryService(mockRepo)
-			handler := NewEntryHandler(service, testAPIBasePath)
+			handler := NewEntryHandler(service, testAPIBasePath).WithTrendingSort(true)
 			ts := newTestServer(RouterConfig{
 				EntryHandler: handler,
 			})
@@ -325,6 +490,24 @@ func TestEntryHandler_HotEntries(t *testing.T) {
 	}
 }
 
+func TestEntryHandler_HotEntries_TrendingDisabled(t *testing.T) {
+	ts := newTestServer(RouterConfig{
+		EntryHandler: NewEntryHandler(newTestEntryService(&mockEntryRepository{}), testAPIBasePath),
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/entries/hot?date=20240101&sort=trending"))
+	defer resp.Body.Close()
+	body := assertErrorResponse(t, resp, http.StatusBadRequest)
+	if body["error"] != "sort must be hot" {
+		t.Errorf("error = %q", body["error"])
+	}
+
+	resp = ts.get(t, apiPath("/entries/hot?date=20240101&sort=hot"))
+	defer resp.Body.Close()
+	assertStatus(t, resp, http.StatusOK)
+}
+
 func TestEntryHandler_HotEntries_ServiceError(t *testing.T) {
 	mockRepo := &mockEntryRepository{
 		listFunc: func(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
@@ -416,6 +599,147 @@ func TestEntryHandler_ResponseFormat(t *testing.T) {
 	}
 }
 
+func TestEntryHandler_InvalidUTF8(t *testing.T) {
+	entry := newTestEntry(uuid.New(), "Go\xff言語\xc3", 100)
+	entry.Excerpt = "bad\xfe\xffbytes"
+	entry.Subject = "\xe3\x81tech"
+
+	mockRepo := &mockEntryRepository{entries: []*domainEntry.Entry{entry}, total: 1}
+	ts := newTestServer(RouterConfig{
+		EntryHandler: NewEntryHandler(newTestEntryService(mockRepo), testAPIBasePath),
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/entries/new?date=20240101"))
+	defer resp.Body.Close()
+	assertStatus(t, resp, http.StatusOK)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	if !utf8.Valid(body) {
+		t.Fatalf("response is not valid UTF-8: %q", body)
+	}
+	if bytes.Contains(body, []byte("\ufffd")) || bytes.Contains(body, []byte(`\ufffd`)) {
+		t.Errorf("response carries replacement characters: %s", body)
+	}
+
+	var result entryListResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	got := result.Entries[0]
+	if got.Title != "Go言語" {
+		t.Errorf("Title = %q, want %q", got.Title, "Go言語")
+	}
+	if got.Excerpt == nil || *got.Excerpt != "badbytes" {
+		t.Errorf("Excerpt = %v, want %q", got.Excerpt, "badbytes")
+	}
+	if got.Subject == nil || *got.Subject != "tech" {
+		t.Errorf("Subject = %v, want %q", got.Subject, "tech")
+	}
+}
+
+func TestEntryHandler_RelaxMinUsers(t *testing.T) {
+	mockRepo := &mockEntryRepository{entries: []*domainEntry.Entry{
+		newTestEntry(uuid.New(), "Popular", 120),
+		newTestEntry(uuid.New(), "Quiet", 12),
+		newTestEntry(uuid.New(), "Fresh", 2),
+	}}
+	service := newTestEntryService(mockRepo).WithMinResults(2)
+	ts := newTestServer(RouterConfig{
+		EntryHandler: NewEntryHandler(service, testAPIBasePath),
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/entries/hot?date=20240101&min_users=100"))
+	defer resp.Body.Close()
+	result := assertEntryListResponse(t, resp)
+	if result.Relaxed || result.MinUsers != nil || result.Total != 1 {
+		t.Errorf("without relax: relaxed=%v min_users=%v total=%d", result.Relaxed, result.MinUsers, result.Total)
+	}
+
+	resp = ts.get(t, apiPath("/entries/hot?date=20240101&min_users=100&relax=true"))
+	defer resp.Body.Close()
+	result = assertEntryListResponse(t, resp)
+	if !result.Relaxed {
+		t.Errorf("relaxed = false, want true")
+	}
+	if result.MinUsers == nil || *result.MinUsers != 10 {
+		t.Errorf("min_users = %v, want 10", result.MinUsers)
+	}
+	if result.Total != 2 || len(result.Entries) != 2 {
+		t.Errorf("total = %d, entries = %d, want 2", result.Total, len(result.Entries))
+	}
+
+	resp = ts.get(t, apiPath("/entries/hot?date=20240101&relax=maybe"))
+	defer resp.Body.Close()
+	assertErrorResponse(t, resp, http.StatusBadRequest)
+}
+
+func TestEntryHandler_PreferPageSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		prefer      string
+		wantLimit   int
+		wantApplied string
+	}{
+		{name: "header only", prefer: "page-size=5", wantLimit: 5, wantApplied: "page-size=5"},
+		{name: "among other preferences", prefer: "return=minimal, page-size=\"7\"; strict", wantLimit: 7, wantApplied: "page-size=7"},
+		{name: "clamped to max", prefer: "page-size=5000", wantLimit: domainEntry.MaxLimit, wantApplied: fmt.Sprintf("page-size=%d", domainEntry.MaxLimit)},
+		{name: "query takes precedence", query: "&limit=3", prefer: "page-size=50", wantLimit: 3},
+		{name: "malformed header ignored", prefer: "page-size=many", wantLimit: defaultLimit},
+		{name: "zero ignored", prefer: "page-size=0", wantLimit: defaultLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(RouterConfig{
+				EntryHandler: NewEntryHandler(newTestEntryService(&mockEntryRepository{}), testAPIBasePath),
+			})
+			defer ts.Close()
+
+			resp := ts.getWithHeader(t, apiPath("/entries/new?date=20240101"+tt.query), "Prefer", tt.prefer)
+			defer resp.Body.Close()
+			if got := resp.Header.Get("Preference-Applied"); got != tt.wantApplied {
+				t.Errorf("Preference-Applied = %q, want %q", got, tt.wantApplied)
+			}
+			result := assertEntryListResponse(t, resp)
+			if result.Limit != tt.wantLimit {
+				t.Errorf("limit = %d, want %d", result.Limit, tt.wantLimit)
+			}
+		})
+	}
+
+	t.Run("search", func(t *testing.T) {
+		var gotLimit int
+		mockRepo := &mockEntryRepository{
+			listFunc: func(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
+				gotLimit = query.Limit
+				return nil, nil
+			},
+		}
+		ts := newTestServer(RouterConfig{
+			SearchHandler: NewSearchHandler(newTestSearchService(mockRepo, &mockSearchHistoryRepository{}), testAPIBasePath),
+		})
+		defer ts.Close()
+
+		resp := ts.getWithHeader(t, apiPath("/search?q=go"), "Prefer", "page-size=40")
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusOK)
+		if got := resp.Header.Get("Preference-Applied"); got != "page-size=40" {
+			t.Errorf("Preference-Applied = %q, want page-size=40", got)
+		}
+		if vary := resp.Header.Values("Vary"); !slices.Contains(vary, "Prefer") {
+			t.Errorf("Vary = %v, want Prefer", vary)
+		}
+		if gotLimit != 40 {
+			t.Errorf("repository limit = %d, want 40", gotLimit)
+		}
+	})
+}
+
 func TestEntryHandler_BoundaryValues(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -470,3 +794,537 @@ func TestEntryHandler_BoundaryValues(t *testing.T) {
 		})
 	}
 }
+
+// headerAuth accepts requests carrying X-API-Key: ok, standing in for API key auth.
+func headerAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "ok" {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func getWithAPIKey(t *testing.T, ts *testServer, path string) *http.Response {
+	t.Helper()
+	return doWithAPIKey(t, ts, http.MethodGet, path, "")
+}
+
+func doWithAPIKey(t *testing.T, ts *testServer, method, path, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("X-API-Key", "ok")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	return resp
+}
+
+func TestEntryHandler_EntriesByIDs(t *testing.T) {
+	first := newTestEntry(uuid.New(), "First", 10)
+	second := newTestEntry(uuid.New(), "Second", 20)
+	missing := uuid.New()
+	mockRepo := &mockEntryRepository{entries: []*domainEntry.Entry{first, second}}
+	handler := NewEntryHandler(newTestEntryService(mockRepo), testAPIBasePath)
+	ts := newTestServer(RouterConfig{EntryHandler: handler})
+	defer ts.Close()
+
+	t.Run("returns present entries in request order", func(t *testing.T) {
+		resp := ts.get(t, apiPath(fmt.Sprintf("/entries?ids=%s,%s,%s", second.ID, missing, first.ID)))
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusOK)
+
+		var result entryBatchResponse
+		decodeJSON(t, resp, &result)
+		if len(result.Entries) != 2 {
+			t.Fatalf("entries count = %d, want 2", len(result.Entries))
+		}
+		if result.Entries[0].ID != second.ID || result.Entries[1].ID != first.ID {
+			t.Errorf("ids = [%s %s], want [%s %s]", result.Entries[0].ID, result.Entries[1].ID, second.ID, first.ID)
+		}
+	})
+
+	t.Run("all ids missing returns an empty array", func(t *testing.T) {
+		resp := ts.get(t, apiPath("/entries?ids="+missing.String()))
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusOK)
+
+		var raw map[string]any
+		decodeJSON(t, resp, &raw)
+		if entries, ok := raw["entries"].([]any); !ok || len(entries) != 0 {
+			t.Errorf("entries = %v, want []", raw["entries"])
+		}
+	})
+
+	t.Run("accepts ids up to the cap", func(t *testing.T) {
+		ids := make([]string, domainEntry.MaxBatchIDs)
+		for i := range ids {
+			ids[i] = uuid.NewString()
+		}
+		resp := ts.get(t, apiPath("/entries?ids="+strings.Join(ids, ",")))
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusOK)
+	})
+
+	t.Run("rejects ids over the cap", func(t *testing.T) {
+		ids := make([]string, domainEntry.MaxBatchIDs+1)
+		for i := range ids {
+			ids[i] = uuid.NewString()
+		}
+		resp := ts.get(t, apiPath("/entries?ids="+strings.Join(ids, ",")))
+		defer resp.Body.Close()
+		body := assertErrorResponse(t, resp, http.StatusBadRequest)
+		if want := fmt.Sprintf("ids must have at most %d items", domainEntry.MaxBatchIDs); body["error"] != want {
+			t.Errorf("error = %q, want %q", body["error"], want)
+		}
+	})
+
+	for name, query := range map[string]string{
+		"missing ids":  "",
+		"empty ids":    "?ids=,,",
+		"invalid uuid": "?ids=" + first.ID.String() + ",not-a-uuid",
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := ts.get(t, apiPath("/entries"+query))
+			defer resp.Body.Close()
+			assertErrorResponse(t, resp, http.StatusBadRequest)
+		})
+	}
+}
+
+func TestEntryHandler_EntriesByIDs_ServiceError(t *testing.T) {
+	mockRepo := &mockEntryRepository{
+		getByIDsFunc: func(ctx context.Context, ids []domainEntry.ID) ([]*domainEntry.Entry, error) {
+			return nil, fmt.Errorf("database error")
+		},
+	}
+	handler := NewEntryHandler(newTestEntryService(mockRepo), testAPIBasePath)
+	ts := newTestServer(RouterConfig{EntryHandler: handler})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/entries?ids="+uuid.NewString()))
+	defer resp.Body.Close()
+	assertErrorResponse(t, resp, http.StatusInternalServerError)
+}
+
+func TestEntryHandler_UntaggedEntries(t *testing.T) {
+	var gotLimit, gotOffset int
+	mockRepo := &mockEntryRepository{
+		listUntaggedFunc: func(ctx context.Context, limit, offset int) ([]*domainEntry.Entry, error) {
+			gotLimit, gotOffset = limit, offset
+			ent := newTestEntry(uuid.New(), "Untagged", 10)
+			ent.Tags = nil
+			return []*domainEntry.Entry{ent}, nil
+		},
+		countUntaggedFunc: func(ctx context.Context) (int64, error) {
+			return 42, nil
+		},
+	}
+	handler := NewEntryHandler(newTestEntryService(mockRepo), testAPIBasePath).WithCurationAuth(headerAuth)
+	ts := newTestServer(RouterConfig{EntryHandler: handler})
+	defer ts.Close()
+
+	t.Run("success with pagination", func(t *testing.T) {
+		resp := getWithAPIKey(t, ts, apiPath("/entries/untagged?limit=10&offset=20"))
+		result := assertEntryListResponse(t, resp)
+
+		if gotLimit != 10 || gotOffset != 20 {
+			t.Errorf("repository called with limit=%d offset=%d, want 10, 20", gotLimit, gotOffset)
+		}
+		if len(result.Entries) != 1 {
+			t.Fatalf("entries count = %d, want 1", len(result.Entries))
+		}
+		if len(result.Entries[0].Tags) != 0 {
+			t.Errorf("tags = %v, want empty", result.Entries[0].Tags)
+		}
+		if result.Total != 42 || result.Limit != 10 || result.Offset != 20 {
+			t.Errorf("pagination = (total=%d, limit=%d, offset=%d), want (42, 10, 20)", result.Total, result.Limit, result.Offset)
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		resp := getWithAPIKey(t, ts, apiPath("/entries/untagged"))
+		result := assertEntryListResponse(t, resp)
+		if result.Limit != defaultLimit || result.Offset != 0 {
+			t.Errorf("limit/offset = %d/%d, want %d/0", result.Limit, result.Offset, defaultLimit)
+		}
+	})
+
+	t.Run("requires API key", func(t *testing.T) {
+		resp := ts.get(t, apiPath("/entries/untagged"))
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusUnauthorized)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		resp := getWithAPIKey(t, ts, apiPath("/entries/untagged?limit=101"))
+		defer resp.Body.Close()
+		assertErrorResponse(t, resp, http.StatusBadRequest)
+	})
+
+	t.Run("invalid offset", func(t *testing.T) {
+		resp := getWithAPIKey(t, ts, apiPath("/entries/untagged?offset=-1"))
+		defer resp.Body.Close()
+		assertErrorResponse(t, resp, http.StatusBadRequest)
+	})
+}
+
+func TestEntryHandler_UntaggedEntries_ServiceError(t *testing.T) {
+	mockRepo := &mockEntryRepository{
+		listUntaggedFunc: func(ctx context.Context, limit, offset int) ([]*domainEntry.Entry, error) {
+			return nil, fmt.Errorf("database error")
+		},
+	}
+	handler := NewEntryHandler(newTestEntryService(mockRepo), testAPIBasePath).WithCurationAuth(headerAuth)
+	ts := newTestServer(RouterConfig{EntryHandler: handler})
+	defer ts.Close()
+
+	resp := getWithAPIKey(t, ts, apiPath("/entries/untagged"))
+	defer resp.Body.Close()
+	assertErrorResponse(t, resp, http.StatusInternalServerError)
+}
+
+func TestEntryHandler_UntaggedEntries_NotRegisteredWithoutAuth(t *testing.T) {
+	handler := NewEntryHandler(newTestEntryService(&mockEntryRepository{}), testAPIBasePath)
+	ts := newTestServer(RouterConfig{EntryHandler: handler})
+	defer ts.Close()
+
+	resp := getWithAPIKey(t, ts, apiPath("/entries/untagged"))
+	defer resp.Body.Close()
+	assertStatus(t, resp, http.StatusNotFound)
+}
+
+func TestEntryHandler_AddEntryTags(t *testing.T) {
+	entryID := uuid.New()
+	var attached map[domainTag.ID]int
+	mockRepo := &mockEntryRepository{
+		getFunc: func(ctx context.Context, id domainEntry.ID) (*domainEntry.Entry, error) {
+			if id != entryID {
+				return nil, fmt.Errorf("%w: no rows", domainEntry.ErrNotFound)
+			}
+			return newTestEntry(id, "Entry", 10), nil
+		},
+	}
+	linker := &mockTagLinker{
+		attachEntryFunc: func(ctx context.Context, _ domainEntry.ID, tagID domainTag.ID, score int) error {
+			attached[tagID] = score
+			return nil
+		},
+	}
+	service := newTestEntryService(mockRepo).WithTagLinker(linker)
+	handler := NewEntryHandler(service, testAPIBasePath).WithCurationAuth(headerAuth)
+	ts := newTestServer(RouterConfig{EntryHandler: handler})
+	defer ts.Close()
+
+	tagID := func(name string) domainTag.ID { return uuid.NewSHA1(uuid.Nil, []byte(name)) }
+	path := apiPath("/entries/" + entryID.String() + "/tags")
+
+	tests := []struct {
+		name         string
+		path         string
+		body         string
+		wantStatus   int
+		wantAttached map[domainTag.ID]int
+	}{
+		{
+			name:         "default score",
+			path:         path,
+			body:         `{"tags":["Go"," go ","Rust"]}`,
+			wantStatus:   http.StatusOK,
+			wantAttached: map[domainTag.ID]int{tagID("go"): usecaseEntry.DefaultManualTagScore, tagID("rust"): usecaseEntry.DefaultManualTagScore},
+		},
+		{
+			name:         "explicit score",
+			path:         path,
+			body:         `{"tags":["go"],"score":40}`,
+			wantStatus:   http.StatusOK,
+			wantAttached: map[domainTag.ID]int{tagID("go"): 40},
+		},
+		{name: "invalid entry id", path: apiPath("/entries/not-a-uuid/tags"), body: `{"tags":["go"]}`, wantStatus: http.StatusBadRequest},
+		{name: "malformed body", path: path, body: `{"tags":`, wantStatus: http.StatusBadRequest},
+		{name: "no tags", path: path, body: `{"tags":[]}`, wantStatus: http.StatusBadRequest},
+		{name: "blank tag", path: path, body: `{"tags":["  "]}`, wantStatus: http.StatusBadRequest},
+		{name: "score out of range", path: path, body: `{"tags":["go"],"score":101}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown entry", path: apiPath("/entries/" + uuid.New().String() + "/tags"), body: `{"tags":["go"]}`, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attached = map[domainTag.ID]int{}
+			resp := doWithAPIKey(t, ts, http.MethodPost, tt.path, tt.body)
+			if tt.wantStatus != http.StatusOK {
+				assertErrorResponse(t, resp, tt.wantStatus)
+				if len(attached) != 0 {
+					t.Errorf("attached = %v, want none", attached)
+				}
+				return
+			}
+			defer resp.Body.Close()
+			assertStatus(t, resp, http.StatusOK)
+			var got entryResponse
+			decodeJSON(t, resp, &got)
+			if got.ID != entryID {
+				t.Errorf("entry id = %s, want %s", got.ID, entryID)
+			}
+			if fmt.Sprint(attached) != fmt.Sprint(tt.wantAttached) {
+				t.Errorf("attached = %v, want %v", attached, tt.wantAttached)
+			}
+		})
+	}
+
+	t.Run("requires API key", func(t *testing.T) {
+		resp, err := http.Post(ts.URL+path, "application/json", strings.NewReader(`{"tags":["go"]}`))
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusUnauthorized)
+	})
+}
+
+func TestEntryHandler_AddEntryTags_MaxTags(t *testing.T) {
+	const maxTags = 3
+	entryID := uuid.New()
+	mockRepo := &mockEntryRepository{
+		getFunc: func(ctx context.Context, id domainEntry.ID) (*domainEntry.Entry, error) {
+			return newTestEntry(id, "Entry", 10), nil
+		},
+	}
+	var attached int
+	linker := &mockTagLinker{
+		attachEntryFunc: func(ctx context.Context, _ domainEntry.ID, _ domainTag.ID, _ int) error {
+			attached++
+			return nil
+		},
+	}
+	service := newTestEntryService(mockRepo).WithTagLinker(linker).WithMaxTags(maxTags)
+	handler := NewEntryHandler(service, testAPIBasePath).WithCurationAuth(headerAuth).WithMaxTags(maxTags)
+	ts := newTestServer(RouterConfig{EntryHandler: handler})
+	defer ts.Close()
+
+	path := apiPath("/entries/" + entryID.String() + "/tags")
+
+	t.Run("accepts the limit", func(t *testing.T) {
+		attached = 0
+		resp := doWithAPIKey(t, ts, http.MethodPost, path, `{"tags":["a","b","c"]}`)
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusOK)
+		if attached != maxTags {
+			t.Errorf("attached = %d, want %d", attached, maxTags)
+		}
+	})
+
+	t.Run("rejects one more", func(t *testing.T) {
+		attached = 0
+		resp := doWithAPIKey(t, ts, http.MethodPost, path, `{"tags":["a","b","c","d"]}`)
+		assertErrorResponse(t, resp, http.StatusBadRequest)
+		if attached != 0 {
+			t.Errorf("attached = %d, want 0", attached)
+		}
+	})
+}
+
+func TestEntryHandler_RemoveEntryTag(t *testing.T) {
+	entryID := uuid.New()
+	var detached []string
+	mockRepo := &mockEntryRepository{
+		getFunc: func(ctx context.Context, id domainEntry.ID) (*domainEntry.Entry, error) {
+			if id != entryID {
+				return nil, fmt.Errorf("%w: no rows", domainEntry.ErrNotFound)
+			}
+			return newTestEntry(id, "Entry", 10), nil
+		},
+	}
+	linker := &mockTagLinker{
+		detachEntryFunc: func(ctx context.Context, _ domainEntry.ID, tagName string) (bool, error) {
+			detached = append(detached, tagName)
+			return tagName == "go", nil
+		},
+	}
+	service := newTestEntryService(mockRepo).WithTagLinker(linker)
+	handler := NewEntryHandler(service, testAPIBasePath).WithCurationAuth(headerAuth)
+	ts := newTestServer(RouterConfig{EntryHandler: handler})
+	defer ts.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		wantStatus   int
+		wantError    string
+		wantDetached []string
+	}{
+		{name: "success", path: "/entries/" + entryID.String() + "/tags/Go", wantStatus: http.StatusNoContent, wantDetached: []string{"go"}},
+		{name: "tag not attached", path: "/entries/" + entryID.String() + "/tags/rust", wantStatus: http.StatusNotFound, wantError: "tag is not attached to the entry", wantDetached: []string{"rust"}},
+		{name: "blank tag", path: "/entries/" + entryID.String() + "/tags/%20", wantStatus: http.StatusBadRequest},
+		{name: "invalid entry id", path: "/entries/not-a-uuid/tags/go", wantStatus: http.StatusBadRequest},
+		{name: "unknown entry", path: "/entries/" + uuid.New().String() + "/tags/go", wantStatus: http.StatusNotFound, wantError: "entry not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detached = nil
+			resp := doWithAPIKey(t, ts, http.MethodDelete, apiPath(tt.path), "")
+			if tt.wantStatus == http.StatusNoContent {
+				defer resp.Body.Close()
+				assertStatus(t, resp, tt.wantStatus)
+			} else {
+				errResp := assertErrorResponse(t, resp, tt.wantStatus)
+				if tt.wantError != "" && errResp["error"] != tt.wantError {
+					t.Errorf("error = %q, want %q", errResp["error"], tt.wantError)
+				}
+			}
+			if fmt.Sprint(detached) != fmt.Sprint(tt.wantDetached) {
+				t.Errorf("detached = %v, want %v", detached, tt.wantDetached)
+			}
+		})
+	}
+}
+
+func TestEntryHandler_UpdateEntryTagScore(t *testing.T) {
+	entryID := uuid.New()
+	scores := map[string]int{}
+	mockRepo := &mockEntryRepository{
+		getFunc: func(ctx context.Context, id domainEntry.ID) (*domainEntry.Entry, error) {
+			if id != entryID {
+				return nil, fmt.Errorf("%w: no rows", domainEntry.ErrNotFound)
+			}
+			return newTestEntry(id, "Entry", 10), nil
+		},
+	}
+	linker := &mockTagLinker{
+		updateScoreFunc: func(ctx context.Context, _ domainEntry.ID, tagName string, score int) (bool, error) {
+			if tagName != "go" {
+				return false, nil
+			}
+			scores[tagName] = score
+			return true, nil
+		},
+	}
+	service := newTestEntryService(mockRepo).WithTagLinker(linker)
+	handler := NewEntryHandler(service, testAPIBasePath).WithAdminAuth(headerAuth)
+	ts := newTestServer(RouterConfig{EntryHandler: handler})
+	defer ts.Close()
+
+	path := "/entries/" + entryID.String() + "/tags/"
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+		wantScores map[string]int
+	}{
+		{name: "valid update", path: path + "Go", body: `{"score":35}`, wantStatus: http.StatusOK, wantScores: map[string]int{"go": 35}},
+		{name: "score above range", path: path + "go", body: `{"score":101}`, wantStatus: http.StatusBadRequest},
+		{name: "negative score", path: path + "go", body: `{"score":-1}`, wantStatus: http.StatusBadRequest},
+		{name: "missing score", path: path + "go", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "malformed body", path: path + "go", body: `{"score":`, wantStatus: http.StatusBadRequest},
+		{name: "tag not attached", path: path + "rust", body: `{"score":10}`, wantStatus: http.StatusNotFound, wantError: "tag is not attached to the entry"},
+		{name: "unknown entry", path: "/entries/" + uuid.New().String() + "/tags/go", body: `{"score":10}`, wantStatus: http.StatusNotFound, wantError: "entry not found"},
+		{name: "invalid entry id", path: "/entries/not-a-uuid/tags/go", body: `{"score":10}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clear(scores)
+			resp := doWithAPIKey(t, ts, http.MethodPut, apiPath(tt.path), tt.body)
+			if tt.wantStatus != http.StatusOK {
+				errResp := assertErrorResponse(t, resp, tt.wantStatus)
+				if tt.wantError != "" && errResp["error"] != tt.wantError {
+					t.Errorf("error = %q, want %q", errResp["error"], tt.wantError)
+				}
+				if len(scores) != 0 {
+					t.Errorf("scores = %v, want none", scores)
+				}
+				return
+			}
+			defer resp.Body.Close()
+			assertStatus(t, resp, http.StatusOK)
+			var got entryResponse
+			decodeJSON(t, resp, &got)
+			if got.ID != entryID {
+				t.Errorf("entry id = %s, want %s", got.ID, entryID)
+			}
+			if fmt.Sprint(scores) != fmt.Sprint(tt.wantScores) {
+				t.Errorf("scores = %v, want %v", scores, tt.wantScores)
+			}
+		})
+	}
+
+	t.Run("requires API key", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPut, ts.URL+apiPath(path+"go"), strings.NewReader(`{"score":10}`))
+		if err != nil {
+			t.Fatalf("new request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("PUT failed: %v", err)
+		}
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusUnauthorized)
+	})
+
+	t.Run("not registered without admin auth", func(t *testing.T) {
+		plain := newTestServer(RouterConfig{EntryHandler: NewEntryHandler(service, testAPIBasePath).WithCurationAuth(headerAuth)})
+		defer plain.Close()
+		resp := doWithAPIKey(t, plain, http.MethodPut, apiPath(path+"go"), `{"score":10}`)
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Errorf("status = %d, want the route to be absent", resp.StatusCode)
+		}
+	})
+}
+
+func TestBuildFaviconURL(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/a":          "/api/v1/favicons?domain=example.com",
+		"https://WWW.Example.com:8443/b": "/api/v1/favicons?domain=example.com",
+		"https://www2.example.com/":      "/api/v1/favicons?domain=www2.example.com",
+		"not a url":                      "",
+	}
+	for raw, want := range tests {
+		if got := buildFaviconURL(raw, "/api/v1"); got != want {
+			t.Errorf("buildFaviconURL(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestEntryHandler_MaxTags(t *testing.T) {
+	ent := newTestEntry(uuid.New(), "Tagged", 10)
+	ent.Tags = []domainEntry.Tagging{
+		{TagID: uuid.New(), Name: "low", Score: 10},
+		{TagID: uuid.New(), Name: "high", Score: 90},
+		{TagID: uuid.New(), Name: "mid", Score: 50},
+	}
+	ts := newTestServer(RouterConfig{
+		EntryHandler: NewEntryHandler(newTestEntryService(&mockEntryRepository{entries: []*domainEntry.Entry{ent}, total: 1}), testAPIBasePath),
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/entries/new?date=20240101&max_tags=2"))
+	defer resp.Body.Close()
+	result := assertEntryListResponse(t, resp)
+	if len(result.Entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(result.Entries))
+	}
+	got := result.Entries[0]
+	if got.TotalTags == nil || *got.TotalTags != 3 {
+		t.Errorf("total_tags = %v, want 3", got.TotalTags)
+	}
+	if len(got.Tags) != 2 || got.Tags[0].Name != "high" || got.Tags[1].Name != "mid" {
+		t.Errorf("tags = %+v, want high and mid", got.Tags)
+	}
+
+	resp = ts.get(t, apiPath("/entries/new?date=20240101"))
+	defer resp.Body.Close()
+	result = assertEntryListResponse(t, resp)
+	if got := result.Entries[0]; got.TotalTags != nil || len(got.Tags) != 3 {
+		t.Errorf("without max_tags: total_tags = %v, tags = %d", got.TotalTags, len(got.Tags))
+	}
+
+	resp = ts.get(t, apiPath("/entries/new?date=20240101&max_tags=-1"))
+	defer resp.Body.Close()
+	assertErrorResponse(t, resp, http.StatusBadRequest)
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// setFeedLinkHeader advertises the RSS feed matching a JSON list endpoint so feed
+// readers can auto-discover it. Feeds mirror the API route under feedBaseURL.
+// Nothing is emitted when feedBaseURL is empty.
+func setFeedLinkHeader(w http.ResponseWriter, feedBaseURL, route string, query url.Values) {
+	if feedBaseURL == "" {
+		return
+	}
+	target := strings.TrimRight(feedBaseURL, "/") + route
+	if encoded := query.Encode(); encoded != "" {
+		target += "?" + encoded
+	}
+	w.Header().Add("Link", "<"+target+`>; rel="alternate"; type="application/rss+xml"`)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -13,7 +14,8 @@ import (
 
 // MetricsHandler handles /metrics endpoints.
 type MetricsHandler struct {
-	service *usecaseMetrics.Service
+	service     *usecaseMetrics.Service
+	summaryAuth func(http.Handler) http.Handler
 }
 
 // NewMetricsHandler creates a MetricsHandler.
@@ -21,9 +23,19 @@ func NewMetricsHandler(service *usecaseMetrics.Service) *MetricsHandler {
 	return &MetricsHandler{service: service}
 }
 
+// WithSummaryAuth enables GET /metrics/summary behind auth. The route is registered only
+// when the service has a summary configured.
+func (h *MetricsHandler) WithSummaryAuth(auth func(http.Handler) http.Handler) *MetricsHandler {
+	h.summaryAuth = auth
+	return h
+}
+
 // RegisterRoutes wires metrics routes.
 func (h *MetricsHandler) RegisterRoutes(r chiRouter) {
 	r.Post("/metrics/clicks", h.handleRecordClick)
+	if h.summaryAuth != nil && h.service.SummaryEnabled() {
+		r.Get("/metrics/summary", h.summaryAuth(http.HandlerFunc(h.handleSummary)).ServeHTTP)
+	}
 }
 
 func (h *MetricsHandler) handleRecordClick(w http.ResponseWriter, r *http.Request) {
@@ -51,6 +63,37 @@ func (h *MetricsHandler) handleRecordClick(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+func (h *MetricsHandler) handleSummary(w http.ResponseWriter, r *http.Request) {
+	summary, cacheHit, err := h.service.Summary(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := metricsSummaryResponse{
+		Clicks: metricsSummaryCounts{
+			Today:     summary.ClicksToday,
+			Last7Days: summary.Clicks7Days,
+		},
+		Searches: metricsSummaryCounts{
+			Today:     summary.SearchesToday,
+			Last7Days: summary.Searches7Days,
+		},
+		TopEntries:  make([]metricsTopEntryResponse, 0, len(summary.TopEntries)),
+		GeneratedAt: summary.GeneratedAt,
+	}
+	for _, e := range summary.TopEntries {
+		resp.TopEntries = append(resp.TopEntries, metricsTopEntryResponse{
+			EntryID:    e.ID,
+			Title:      e.Title,
+			URL:        e.URL,
+			ClickCount: e.ClickCount,
+		})
+	}
+	setCacheStatusHeader(w, cacheHit)
+	writeJSON(w, http.StatusOK, resp)
+}
+
 type clickMetricsRequest struct {
 	EntryID   uuid.UUID `json:"entry_id"`
 	Referrer  *string   `json:"referrer"`
@@ -61,3 +104,23 @@ type metricsResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 }
+
+// metricsSummaryResponse matches MetricsSummaryResponse schema.
+type metricsSummaryResponse struct {
+	Clicks      metricsSummaryCounts      `json:"clicks"`
+	Searches    metricsSummaryCounts      `json:"searches"`
+	TopEntries  []metricsTopEntryResponse `json:"top_entries"`
+	GeneratedAt time.Time                 `json:"generated_at"`
+}
+
+type metricsSummaryCounts struct {
+	Today     int64 `json:"today"`
+	Last7Days int64 `json:"last_7_days"`
+}
+
+type metricsTopEntryResponse struct {
+	EntryID    domainEntry.ID `json:"entry_id"`
+	Title      string         `json:"title"`
+	URL        string         `json:"url"`
+	ClickCount int64          `json:"click_count"`
+}
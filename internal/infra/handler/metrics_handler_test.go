@@ -240,6 +240,85 @@ func (m *mockClickMetricsRepository) Increment(ctx context.Context, entryID doma
 	return nil
 }
 
+// mockMetricsStats implements the summary aggregate sources for testing.
+type mockMetricsStats struct {
+	sums map[time.Time]int64
+	top  []domainEntry.ClickedEntry
+	err  error
+}
+
+func (m *mockMetricsStats) SumSince(ctx context.Context, from time.Time) (int64, error) {
+	return m.sums[from], m.err
+}
+
+func (m *mockMetricsStats) TopEntriesSince(ctx context.Context, from time.Time, limit int) ([]domainEntry.ClickedEntry, error) {
+	return m.top, m.err
+}
+
+func TestMetricsHandler_Summary(t *testing.T) {
+	entryID := uuid.New()
+	clicks := &mockMetricsStats{
+		top: []domainEntry.ClickedEntry{{ID: entryID, Title: "Popular", URL: "https://example.com/p", ClickCount: 12}},
+	}
+	service := usecaseMetrics.NewService(&mockEntryRepository{}, &mockClickMetricsRepository{}).
+		WithSummary(clicks, &mockMetricsStats{}, nil)
+	ts := newTestServer(RouterConfig{MetricsHandler: NewMetricsHandler(service).WithSummaryAuth(headerAuth)})
+	defer ts.Close()
+
+	t.Run("success", func(t *testing.T) {
+		resp := getWithAPIKey(t, ts, apiPath("/metrics/summary"))
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusOK)
+
+		var result metricsSummaryResponse
+		decodeJSON(t, resp, &result)
+		if len(result.TopEntries) != 1 || result.TopEntries[0].EntryID != entryID || result.TopEntries[0].ClickCount != 12 {
+			t.Errorf("top_entries = %+v, want one entry %s with 12 clicks", result.TopEntries, entryID)
+		}
+		if result.GeneratedAt.IsZero() {
+			t.Error("generated_at should be set")
+		}
+	})
+
+	t.Run("requires API key", func(t *testing.T) {
+		resp := ts.get(t, apiPath("/metrics/summary"))
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusUnauthorized)
+	})
+}
+
+func TestMetricsHandler_Summary_ServiceError(t *testing.T) {
+	stats := &mockMetricsStats{err: fmt.Errorf("database error")}
+	service := usecaseMetrics.NewService(&mockEntryRepository{}, &mockClickMetricsRepository{}).
+		WithSummary(stats, stats, nil)
+	ts := newTestServer(RouterConfig{MetricsHandler: NewMetricsHandler(service).WithSummaryAuth(headerAuth)})
+	defer ts.Close()
+
+	resp := getWithAPIKey(t, ts, apiPath("/metrics/summary"))
+	defer resp.Body.Close()
+	assertErrorResponse(t, resp, http.StatusInternalServerError)
+}
+
+func TestMetricsHandler_Summary_NotRegistered(t *testing.T) {
+	withoutAuth := usecaseMetrics.NewService(&mockEntryRepository{}, &mockClickMetricsRepository{}).
+		WithSummary(&mockMetricsStats{}, &mockMetricsStats{}, nil)
+	withoutSummary := usecaseMetrics.NewService(&mockEntryRepository{}, &mockClickMetricsRepository{})
+
+	for name, handler := range map[string]*MetricsHandler{
+		"without auth":    NewMetricsHandler(withoutAuth),
+		"without summary": NewMetricsHandler(withoutSummary).WithSummaryAuth(headerAuth),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ts := newTestServer(RouterConfig{MetricsHandler: handler})
+			defer ts.Close()
+
+			resp := getWithAPIKey(t, ts, apiPath("/metrics/summary"))
+			defer resp.Body.Close()
+			assertStatus(t, resp, http.StatusNotFound)
+		})
+	}
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/vmihailenco/msgpack/v5"
+
+	domainEntry "hateblog/internal/domain/entry"
+)
+
+func TestAcceptsMsgpack(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept []string
+		want   bool
+	}{
+		{name: "no header"},
+		{name: "json", accept: []string{"application/json"}},
+		{name: "msgpack", accept: []string{mediaTypeMsgpack}, want: true},
+		{name: "x-msgpack", accept: []string{"application/x-msgpack"}, want: true},
+		{name: "among others", accept: []string{"application/json;q=0.5, application/msgpack"}, want: true},
+		{name: "refused", accept: []string{"application/msgpack;q=0"}},
+		{name: "malformed", accept: []string{";;"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := acceptsMsgpack(tt.accept); got != tt.want {
+				t.Errorf("acceptsMsgpack(%q) = %v, want %v", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+// readBody reads and closes the body of resp.
+func readBody(t *testing.T, resp *http.Response) []byte {
+	t.Helper()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return body
+}
+
+// msgpackAsJSON decodes a MessagePack body and re-encodes it as JSON, so that it can be compared
+// with a JSON response without caring about integer widths.
+func msgpackAsJSON(t *testing.T, body []byte) any {
+	t.Helper()
+	var decoded any
+	if err := msgpack.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("decode msgpack: %v", err)
+	}
+	raw, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("re-encode msgpack as json: %v", err)
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		t.Fatalf("decode re-encoded json: %v", err)
+	}
+	return v
+}
+
+func TestMsgpack_ResponsesMatchJSON(t *testing.T) {
+	entries := []*domainEntry.Entry{
+		newTestEntry(uuid.New(), "Go Programming Tutorial", 100),
+		newTestEntry(uuid.New(), "Advanced Go Patterns", 50),
+	}
+	mockRepo := &mockEntryRepository{entries: entries, total: 2}
+	ts := newTestServer(RouterConfig{
+		EntryHandler:  NewEntryHandler(newTestEntryService(mockRepo), testAPIBasePath),
+		SearchHandler: NewSearchHandler(newTestSearchService(mockRepo, &mockSearchHistoryRepository{}), testAPIBasePath),
+		Msgpack:       true,
+	})
+	defer ts.Close()
+
+	for _, path := range []string{
+		apiPath("/entries/new?date=20240101&limit=10"),
+		apiPath("/search?q=go&limit=10"),
+	} {
+		for _, version := range []string{"", mediaTypeV2} {
+			accept := mediaTypeMsgpack
+			if version != "" {
+				accept = version + ", " + mediaTypeMsgpack
+			}
+			t.Run(path+" "+accept, func(t *testing.T) {
+				jsonResp := ts.getAccept(t, path, version)
+				assertStatus(t, jsonResp, http.StatusOK)
+				var want any
+				if err := json.Unmarshal(readBody(t, jsonResp), &want); err != nil {
+					t.Fatalf("decode json: %v", err)
+				}
+
+				resp := ts.getAccept(t, path, accept)
+				assertStatus(t, resp, http.StatusOK)
+				assertContentType(t, resp, mediaTypeMsgpack)
+				if got := msgpackAsJSON(t, readBody(t, resp)); !reflect.DeepEqual(got, want) {
+					t.Errorf("msgpack response = %v, want JSON shape %v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestMsgpack_RoundTripsResponseStruct(t *testing.T) {
+	entries := []*domainEntry.Entry{newTestEntry(uuid.New(), "Go Programming Tutorial", 100)}
+	mockRepo := &mockEntryRepository{entries: entries, total: 1}
+	ts := newTestServer(RouterConfig{
+		SearchHandler: NewSearchHandler(newTestSearchService(mockRepo, &mockSearchHistoryRepository{}), testAPIBasePath),
+		Msgpack:       true,
+	})
+	defer ts.Close()
+	path := apiPath("/search?q=go")
+
+	var want searchResponse
+	if err := json.Unmarshal(readBody(t, ts.getAccept(t, path, "")), &want); err != nil {
+		t.Fatalf("decode json: %v", err)
+	}
+	var got searchResponse
+	dec := msgpack.NewDecoder(bytes.NewReader(readBody(t, ts.getAccept(t, path, mediaTypeMsgpack))))
+	dec.SetCustomStructTag("json")
+	if err := dec.Decode(&got); err != nil {
+		t.Fatalf("decode msgpack: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("msgpack = %+v, want %+v", got, want)
+	}
+}
+
+func TestMsgpack_DisabledServesJSON(t *testing.T) {
+	mockRepo := &mockEntryRepository{entries: []*domainEntry.Entry{newTestEntry(uuid.New(), "Entry", 10)}, total: 1}
+	ts := newTestServer(RouterConfig{
+		EntryHandler: NewEntryHandler(newTestEntryService(mockRepo), testAPIBasePath),
+	})
+	defer ts.Close()
+
+	resp := ts.getAccept(t, apiPath("/entries/new?date=20240101"), mediaTypeMsgpack)
+	defer resp.Body.Close()
+	assertStatus(t, resp, http.StatusOK)
+	assertContentType(t, resp, "application/json")
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	domainEntry "hateblog/internal/domain/entry"
 )
@@ -17,6 +18,78 @@ func readQueryInt(r *http.Request, key string, min, max, def int) (int, error) {
 	return parseInt(key, raw, min, max)
 }
 
+// readQueryLimit parses the limit parameter. Without it, a "Prefer: page-size=N" request
+// header sets the page size (see preferredLimit).
+func readQueryLimit(w http.ResponseWriter, r *http.Request, min, max, def int) (int, error) {
+	if r.URL.Query().Get("limit") != "" {
+		return readQueryInt(r, "limit", min, max, def)
+	}
+	return preferredLimit(w, r, min, max, def), nil
+}
+
+// preferredLimit returns the page size requested by a "Prefer: page-size=N" header, clamped
+// to max, and echoes it in Preference-Applied. Like any preference it is a hint: a missing or
+// malformed value yields def rather than an error.
+func preferredLimit(w http.ResponseWriter, r *http.Request, min, max, def int) int {
+	w.Header().Add("Vary", "Prefer")
+	size, ok := preferredPageSize(r.Header.Values("Prefer"))
+	if !ok || size < min {
+		return def
+	}
+	if max > 0 && size > max {
+		size = max
+	}
+	w.Header().Set("Preference-Applied", "page-size="+strconv.Itoa(size))
+	return size
+}
+
+// preferredPageSize finds the page-size preference in Prefer header values
+// (RFC 7240: comma-separated preferences, each optionally followed by ";" parameters).
+func preferredPageSize(prefer []string) (int, bool) {
+	for _, header := range prefer {
+		for _, pref := range strings.Split(header, ",") {
+			token, _, _ := strings.Cut(pref, ";")
+			name, value, ok := strings.Cut(token, "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "page-size") {
+				continue
+			}
+			size, err := strconv.Atoi(strings.Trim(strings.TrimSpace(value), `"`))
+			if err != nil {
+				return 0, false
+			}
+			return size, true
+		}
+	}
+	return 0, false
+}
+
+// readQueryMaxTags parses the max_tags parameter, which caps the taggings returned per entry
+// (0, the default, returns all).
+func readQueryMaxTags(r *http.Request) (int, error) {
+	return readQueryInt(r, "max_tags", 0, 0, 0)
+}
+
+// readQueryOffset parses the offset parameter and rejects values above maxOffset
+// (0 means no limit).
+func readQueryOffset(r *http.Request, maxOffset int) (int, error) {
+	offset, err := readQueryInt(r, "offset", 0, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkOffset(offset, maxOffset); err != nil {
+		return 0, err
+	}
+	return offset, nil
+}
+
+// checkOffset rejects deep pages, which force the database to scan every skipped row.
+func checkOffset(offset, maxOffset int) error {
+	if maxOffset > 0 && offset > maxOffset {
+		return fmt.Errorf("offset must be <= %d; narrow the query or use cursor pagination for deeper pages", maxOffset)
+	}
+	return nil
+}
+
 func requireQueryInt(r *http.Request, key string, min, max int) (int, error) {
 	raw := r.URL.Query().Get(key)
 	if raw == "" {
@@ -39,6 +112,21 @@ func parseInt(name, value string, min, max int) (int, error) {
 	return v, nil
 }
 
+// readQueryBool parses an optional boolean parameter ("true" or "false").
+func readQueryBool(r *http.Request, key string, def bool) (bool, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(key))
+	switch raw {
+	case "":
+		return def, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	default:
+		return false, fmt.Errorf("%s must be true or false", key)
+	}
+}
+
 func readQuerySort(r *http.Request, key string, def domainEntry.SortType) (domainEntry.SortType, error) {
 	raw := strings.TrimSpace(r.URL.Query().Get(key))
 	if raw == "" {
@@ -51,3 +139,52 @@ func readQuerySort(r *http.Request, key string, def domainEntry.SortType) (domai
 		return "", fmt.Errorf("%s must be one of new, hot", key)
 	}
 }
+
+// readQueryHotSort parses the sort parameter of the hot list: hot (the default), or trending
+// when allowTrending is set.
+func readQueryHotSort(r *http.Request, key string, allowTrending bool) (domainEntry.SortType, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(key))
+	switch domainEntry.SortType(raw) {
+	case "", domainEntry.SortHot:
+		return domainEntry.SortHot, nil
+	case domainEntry.SortTrending:
+		if allowTrending {
+			return domainEntry.SortTrending, nil
+		}
+	}
+	if allowTrending {
+		return "", fmt.Errorf("%s must be one of hot, trending", key)
+	}
+	return "", fmt.Errorf("%s must be hot", key)
+}
+
+// readQueryNewSort parses the sort of the new list: discovered (created_at, the default) or
+// posted. new is accepted as an alias of discovered.
+func readQueryNewSort(r *http.Request, key string) (domainEntry.SortType, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(key))
+	switch domainEntry.SortType(raw) {
+	case "", domainEntry.SortNew, domainEntry.SortDiscovered:
+		return domainEntry.SortDiscovered, nil
+	case domainEntry.SortPosted:
+		return domainEntry.SortPosted, nil
+	}
+	return "", fmt.Errorf("%s must be one of discovered, posted", key)
+}
+
+// readQueryLocation parses an optional IANA time zone name.
+// It returns nil when the parameter is absent so callers fall back to the app zone.
+func readQueryLocation(r *http.Request, key string) (*time.Location, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(key))
+	if raw == "" {
+		return nil, nil
+	}
+	// "Local" would silently resolve to the server zone; only explicit names are accepted.
+	if raw == "Local" || len(raw) > 64 {
+		return nil, fmt.Errorf("%s must be a valid IANA time zone name", key)
+	}
+	loc, err := time.LoadLocation(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a valid IANA time zone name", key)
+	}
+	return loc, nil
+}
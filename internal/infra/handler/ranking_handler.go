@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 
+	"hateblog/internal/pkg/apptime"
 	usecaseRanking "hateblog/internal/usecase/ranking"
 )
 
@@ -12,12 +15,17 @@ const (
 	maxMonthlyRankingLimit    = 100
 	maxWeeklyRankingLimit     = 100
 	defaultRankingMinBookmark = 5
+	maxRankingOffset          = 100000
+
+	rankingModeBookmarks  = "bookmarks"
+	rankingModeEngagement = "engagement"
 )
 
 // RankingHandler serves ranking endpoints.
 type RankingHandler struct {
 	service     *usecaseRanking.Service
 	apiBasePath string
+	maxOffset   int
 }
 
 // NewRankingHandler builds a RankingHandler.
@@ -25,7 +33,17 @@ func NewRankingHandler(service *usecaseRanking.Service, apiBasePath string) *Ran
 	return &RankingHandler{
 		service:     service,
 		apiBasePath: normalizeAPIBasePath(apiBasePath),
+		maxOffset:   maxRankingOffset,
+	}
+}
+
+// WithMaxOffset lowers the offset limit below the built-in maximum. Zero or larger values
+// keep the built-in maximum.
+func (h *RankingHandler) WithMaxOffset(maxOffset int) *RankingHandler {
+	if maxOffset > 0 && maxOffset < maxRankingOffset {
+		h.maxOffset = maxOffset
 	}
+	return h
 }
 
 // RegisterRoutes registers ranking endpoints.
@@ -41,12 +59,12 @@ func (h *RankingHandler) handleYearly(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
-	limit, err := readQueryInt(r, "limit", 1, maxYearlyRankingLimit, defaultRankingLimit)
+	limit, err := readQueryLimit(w, r, 1, maxYearlyRankingLimit, defaultRankingLimit)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
-	offset, err := readQueryInt(r, "offset", 0, 100000, 0)
+	offset, err := readQueryOffset(r, h.maxOffset)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
@@ -57,14 +75,33 @@ func (h *RankingHandler) handleYearly(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, cacheHit, err := h.service.YearlyWithCacheStatus(r.Context(), year, limit, offset, minUsers)
+	engagement, err := h.readEngagement(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	maxTags, err := readQueryMaxTags(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var (
+		result   usecaseRanking.Result
+		cacheHit bool
+	)
+	if engagement {
+		result, cacheHit, err = h.service.EngagementWithCacheStatus(r.Context(), usecaseRanking.Period{Kind: usecaseRanking.PeriodYearly, Year: year}, limit, offset, minUsers)
+	} else {
+		result, cacheHit, err = h.service.YearlyWithCacheStatus(r.Context(), year, limit, offset, minUsers)
+	}
 	if err != nil {
 		writeError(w, r, http.StatusInternalServerError, err)
 		return
 	}
 
 	setCacheStatusHeader(w, cacheHit)
-	writeJSON(w, http.StatusOK, buildRankingResponse("yearly", year, nil, nil, result, limit, offset, h.apiBasePath))
+	writeList(w, r, http.StatusOK, buildRankingResponse("yearly", year, nil, nil, result, limit, offset, h.apiBasePath, maxTags))
 }
 
 func (h *RankingHandler) handleMonthly(w http.ResponseWriter, r *http.Request) {
@@ -78,12 +115,12 @@ func (h *RankingHandler) handleMonthly(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
-	limit, err := readQueryInt(r, "limit", 1, maxMonthlyRankingLimit, defaultRankingLimit)
+	limit, err := readQueryLimit(w, r, 1, maxMonthlyRankingLimit, defaultRankingLimit)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
-	offset, err := readQueryInt(r, "offset", 0, 100000, 0)
+	offset, err := readQueryOffset(r, h.maxOffset)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
@@ -94,14 +131,33 @@ func (h *RankingHandler) handleMonthly(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, cacheHit, err := h.service.MonthlyWithCacheStatus(r.Context(), year, month, limit, offset, minUsers)
+	engagement, err := h.readEngagement(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	maxTags, err := readQueryMaxTags(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var (
+		result   usecaseRanking.Result
+		cacheHit bool
+	)
+	if engagement {
+		result, cacheHit, err = h.service.EngagementWithCacheStatus(r.Context(), usecaseRanking.Period{Kind: usecaseRanking.PeriodMonthly, Year: year, Month: month}, limit, offset, minUsers)
+	} else {
+		result, cacheHit, err = h.service.MonthlyWithCacheStatus(r.Context(), year, month, limit, offset, minUsers)
+	}
 	if err != nil {
 		writeError(w, r, http.StatusInternalServerError, err)
 		return
 	}
 
 	setCacheStatusHeader(w, cacheHit)
-	writeJSON(w, http.StatusOK, buildRankingResponse("monthly", year, &month, nil, result, limit, offset, h.apiBasePath))
+	writeList(w, r, http.StatusOK, buildRankingResponse("monthly", year, &month, nil, result, limit, offset, h.apiBasePath, maxTags))
 }
 
 func (h *RankingHandler) handleWeekly(w http.ResponseWriter, r *http.Request) {
@@ -115,12 +171,18 @@ func (h *RankingHandler) handleWeekly(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
-	limit, err := readQueryInt(r, "limit", 1, maxWeeklyRankingLimit, defaultRankingLimit)
+	// Only some ISO years have a week 53; in the others its days belong to week 1 of the
+	// following year.
+	if _, _, err := apptime.ISOWeekRange(year, week); err != nil {
+		writeError(w, r, http.StatusBadRequest, fmt.Errorf("week %d does not exist in %d", week, year))
+		return
+	}
+	limit, err := readQueryLimit(w, r, 1, maxWeeklyRankingLimit, defaultRankingLimit)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
-	offset, err := readQueryInt(r, "offset", 0, 100000, 0)
+	offset, err := readQueryOffset(r, h.maxOffset)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
@@ -131,17 +193,52 @@ func (h *RankingHandler) handleWeekly(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, cacheHit, err := h.service.WeeklyWithCacheStatus(r.Context(), year, week, limit, offset, minUsers)
+	engagement, err := h.readEngagement(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	maxTags, err := readQueryMaxTags(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var (
+		result   usecaseRanking.Result
+		cacheHit bool
+	)
+	if engagement {
+		result, cacheHit, err = h.service.EngagementWithCacheStatus(r.Context(), usecaseRanking.Period{Kind: usecaseRanking.PeriodWeekly, Year: year, Week: week}, limit, offset, minUsers)
+	} else {
+		result, cacheHit, err = h.service.WeeklyWithCacheStatus(r.Context(), year, week, limit, offset, minUsers)
+	}
 	if err != nil {
 		writeError(w, r, http.StatusInternalServerError, err)
 		return
 	}
 
 	setCacheStatusHeader(w, cacheHit)
-	writeJSON(w, http.StatusOK, buildRankingResponse("weekly", year, nil, &week, result, limit, offset, h.apiBasePath))
+	writeList(w, r, http.StatusOK, buildRankingResponse("weekly", year, nil, &week, result, limit, offset, h.apiBasePath, maxTags))
 }
 
-func buildRankingResponse(periodType string, year int, month, week *int, result usecaseRanking.Result, limit, offset int, apiBasePath string) rankingResponse {
+// readEngagement reads the ranking parameter: "bookmarks" (the default) orders by bookmark
+// count and "engagement" blends in click counts when the service supports it.
+func (h *RankingHandler) readEngagement(r *http.Request) (bool, error) {
+	switch r.URL.Query().Get("ranking") {
+	case "", rankingModeBookmarks:
+		return false, nil
+	case rankingModeEngagement:
+		if !h.service.EngagementEnabled() {
+			return false, errors.New("ranking=engagement is not available")
+		}
+		return true, nil
+	default:
+		return false, errors.New("ranking must be bookmarks or engagement")
+	}
+}
+
+func buildRankingResponse(periodType string, year int, month, week *int, result usecaseRanking.Result, limit, offset int, apiBasePath string, maxTags int) rankingResponse {
 	resp := rankingResponse{
 		PeriodType: periodType,
 		Year:       year,
@@ -156,17 +253,26 @@ func buildRankingResponse(periodType string, year int, month, week *int, result
 	if week != nil {
 		resp.Week = week
 	}
+	if result.ClickCounts != nil {
+		resp.Ranking = rankingModeEngagement
+	}
 	for i, ent := range result.Entries {
-		resp.Entries = append(resp.Entries, rankingEntryResponse{
+		item := rankingEntryResponse{
 			Rank:  offset + i + 1,
-			Entry: toEntryResponse(ent, apiBasePath),
-		})
+			Entry: toEntryResponse(ent, apiBasePath, maxTags),
+		}
+		if result.ClickCounts != nil {
+			clicks := result.ClickCounts[ent.ID]
+			item.ClickCount = &clicks
+		}
+		resp.Entries = append(resp.Entries, item)
 	}
 	return resp
 }
 
 type rankingResponse struct {
 	PeriodType string                 `json:"period_type"`
+	Ranking    string                 `json:"ranking,omitempty"`
 	Year       int                    `json:"year"`
 	Month      *int                   `json:"month,omitempty"`
 	Week       *int                   `json:"week,omitempty"`
@@ -177,6 +283,35 @@ type rankingResponse struct {
 }
 
 type rankingEntryResponse struct {
-	Rank  int           `json:"rank"`
-	Entry entryResponse `json:"entry"`
+	Rank       int           `json:"rank"`
+	Entry      entryResponse `json:"entry"`
+	ClickCount *int64        `json:"click_count,omitempty"`
+}
+
+func (r rankingResponse) resultCount() int {
+	return len(r.Entries)
+}
+
+func (r rankingResponse) envelope() listEnvelope {
+	return listEnvelope{
+		Data: r.Entries,
+		Meta: rankingMeta{
+			PeriodType: r.PeriodType,
+			Ranking:    r.Ranking,
+			Year:       r.Year,
+			Month:      r.Month,
+			Week:       r.Week,
+			listMeta:   listMeta{Total: r.Total, Limit: r.Limit, Offset: r.Offset},
+		},
+	}
+}
+
+// rankingMeta is the v2 meta of rankings.
+type rankingMeta struct {
+	PeriodType string `json:"period_type"`
+	Ranking    string `json:"ranking,omitempty"`
+	Year       int    `json:"year"`
+	Month      *int   `json:"month,omitempty"`
+	Week       *int   `json:"week,omitempty"`
+	listMeta
 }
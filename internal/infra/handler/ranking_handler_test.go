@@ -344,6 +344,19 @@ func TestRankingHandler_Weekly(t *testing.T) {
 			wantWeek:       52,
 			wantTotal:      1,
 		},
+		{
+			name:        "success with week 53 in a 53-week year",
+			queryParams: "?year=2020&week=53",
+			mockResult: usecaseRanking.Result{
+				Entries: []*domainEntry.Entry{entry1},
+				Total:   1,
+			},
+			wantStatus:     http.StatusOK,
+			wantEntryCount: 1,
+			wantYear:       2020,
+			wantWeek:       53,
+			wantTotal:      1,
+		},
 		{
 			name:        "success with custom limit",
 			queryParams: "?year=2025&week=10&limit=20&min_users=50",
@@ -382,6 +395,11 @@ func TestRankingHandler_Weekly(t *testing.T) {
 			queryParams: "?year=2025&week=abc",
 			wantStatus:  http.StatusBadRequest,
 		},
+		{
+			name:        "error: week 53 in a 52-week year",
+			queryParams: "?year=2024&week=53",
+			wantStatus:  http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -450,3 +468,78 @@ func (m *mockRankingRepository) Count(ctx context.Context, query domainEntry.Lis
 	}
 	return m.result.Total, nil
 }
+
+type stubClickCounter map[domainEntry.ID]int64
+
+func (c stubClickCounter) CountByEntries(ctx context.Context, ids []domainEntry.ID) (map[domainEntry.ID]int64, error) {
+	return c, nil
+}
+
+func TestRankingHandler_Engagement(t *testing.T) {
+	bookmarked := newTestEntry(uuid.New(), "Bookmarked Entry", 100)
+	clicked := newTestEntry(uuid.New(), "Clicked Entry", 50)
+	mockRepo := &mockRankingRepository{
+		result: usecaseRanking.Result{Entries: []*domainEntry.Entry{bookmarked, clicked}, Total: 2},
+	}
+	service := usecaseRanking.NewService(mockRepo, nil, nil, nil).
+		WithEngagement(stubClickCounter{clicked.ID: 10}, usecaseRanking.Weights{Bookmarks: 1, Clicks: 10}, nil)
+	ts := newTestServer(RouterConfig{
+		RankingHandler: NewRankingHandler(service, testAPIBasePath),
+	})
+	defer ts.Close()
+
+	for _, path := range []string{
+		"/rankings/yearly?year=2024&ranking=engagement",
+		"/rankings/monthly?year=2024&month=1&ranking=engagement",
+		"/rankings/weekly?year=2024&week=1&ranking=engagement",
+	} {
+		t.Run(path, func(t *testing.T) {
+			resp := ts.get(t, apiPath(path))
+			defer resp.Body.Close()
+			assertStatus(t, resp, http.StatusOK)
+
+			var result rankingResponse
+			decodeJSON(t, resp, &result)
+			if result.Ranking != rankingModeEngagement {
+				t.Errorf("ranking = %q, want %q", result.Ranking, rankingModeEngagement)
+			}
+			if len(result.Entries) != 2 {
+				t.Fatalf("got %d entries, want 2", len(result.Entries))
+			}
+			if result.Entries[0].Entry.Title != "Clicked Entry" {
+				t.Errorf("first entry = %q, want %q", result.Entries[0].Entry.Title, "Clicked Entry")
+			}
+			if got := result.Entries[0].ClickCount; got == nil || *got != 10 {
+				t.Errorf("first click_count = %v, want 10", got)
+			}
+			if got := result.Entries[1].ClickCount; got == nil || *got != 0 {
+				t.Errorf("second click_count = %v, want 0", got)
+			}
+		})
+	}
+
+	resp := ts.get(t, apiPath("/rankings/yearly?year=2024&ranking=bookmarks"))
+	defer resp.Body.Close()
+	assertStatus(t, resp, http.StatusOK)
+	var result rankingResponse
+	decodeJSON(t, resp, &result)
+	if result.Ranking != "" || result.Entries[0].ClickCount != nil {
+		t.Errorf("bookmarks ranking must not carry engagement fields: %+v", result)
+	}
+
+	resp = ts.get(t, apiPath("/rankings/yearly?year=2024&ranking=bogus"))
+	defer resp.Body.Close()
+	assertErrorResponse(t, resp, http.StatusBadRequest)
+}
+
+func TestRankingHandler_EngagementUnavailable(t *testing.T) {
+	service := usecaseRanking.NewService(&mockRankingRepository{}, nil, nil, nil)
+	ts := newTestServer(RouterConfig{
+		RankingHandler: NewRankingHandler(service, testAPIBasePath),
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/rankings/monthly?year=2024&month=1&ranking=engagement"))
+	defer resp.Body.Close()
+	assertErrorResponse(t, resp, http.StatusBadRequest)
+}
@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// resultCountHeader carries the number of items in a list response, so that clients can tell
+// a valid query with no results ("0") from a failed one without parsing the body. It is
+// exposed to cross-origin scripts by the server's CORS middleware.
+const resultCountHeader = "X-Result-Count"
+
+func setResultCountHeader(w http.ResponseWriter, n int) {
+	w.Header().Set(resultCountHeader, strconv.Itoa(n))
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	domainEntry "hateblog/internal/domain/entry"
+	domainTag "hateblog/internal/domain/tag"
+	usecaseRanking "hateblog/internal/usecase/ranking"
+)
+
+func TestResultCountHeader(t *testing.T) {
+	entries := []*domainEntry.Entry{
+		newTestEntry(uuid.New(), "Go Programming Tutorial", 100),
+		newTestEntry(uuid.New(), "Advanced Go Patterns", 50),
+	}
+	newServer := func(entries []*domainEntry.Entry, tags []domainTag.Tag) *testServer {
+		entryRepo := &mockEntryRepository{entries: entries, total: int64(len(entries))}
+		return newTestServer(RouterConfig{
+			EntryHandler:   NewEntryHandler(newTestEntryService(entryRepo), testAPIBasePath),
+			SearchHandler:  NewSearchHandler(newTestSearchService(entryRepo, &mockSearchHistoryRepository{}), testAPIBasePath),
+			RankingHandler: NewRankingHandler(usecaseRanking.NewService(&mockRankingRepository{result: usecaseRanking.Result{Entries: entries, Total: int64(len(entries))}}, nil, nil, nil), testAPIBasePath),
+			TagHandler:     NewTagHandler(newTestTagService(&mockTagRepository{tags: tags}), nil, testAPIBasePath),
+		})
+	}
+	full := newServer(entries, []domainTag.Tag{*newTestTag(uuid.New(), "go")})
+	defer full.Close()
+	empty := newServer(nil, nil)
+	defer empty.Close()
+
+	tests := []struct {
+		name   string
+		ts     *testServer
+		path   string
+		status int
+		want   string
+	}{
+		{name: "entries", ts: full, path: "/entries/new?date=20240101", status: http.StatusOK, want: "2"},
+		{name: "no entries", ts: empty, path: "/entries/new?date=20240101", status: http.StatusOK, want: "0"},
+		{name: "search", ts: full, path: "/search?q=go", status: http.StatusOK, want: "2"},
+		{name: "search without results", ts: empty, path: "/search?q=go", status: http.StatusOK, want: "0"},
+		{name: "ranking without results", ts: empty, path: "/rankings/yearly?year=2024", status: http.StatusOK, want: "0"},
+		{name: "tags", ts: full, path: "/tags", status: http.StatusOK, want: "1"},
+		{name: "no tags", ts: empty, path: "/tags", status: http.StatusOK, want: "0"},
+		{name: "invalid input", ts: empty, path: "/entries/new?date=invalid", status: http.StatusBadRequest, want: ""},
+		{name: "invalid search", ts: empty, path: "/search", status: http.StatusBadRequest, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := tt.ts.get(t, apiPath(tt.path))
+			defer resp.Body.Close()
+			assertStatus(t, resp, tt.status)
+			if got := resp.Header.Get(resultCountHeader); got != tt.want {
+				t.Errorf("%s = %q, want %q", resultCountHeader, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -15,13 +16,25 @@ type RouterConfig struct {
 	TagHandler     *TagHandler
 	SearchHandler  *SearchHandler
 	MetricsHandler *MetricsHandler
+	SourceHandler  *SourceHandler
 	APIKeyHandler  *APIKeyHandler
 	FaviconHandler *FaviconHandler
 	HealthHandler  *HealthHandler
 
-	APIBasePath       string
+	APIBasePath string
+	// DefaultAPIVersion is the response envelope version used when the Accept header does not
+	// name one (1 or 2; other values mean 1).
+	DefaultAPIVersion int
 	Middlewares       []func(http.Handler) http.Handler
 	PrometheusHandler http.Handler
+	// RequestLogger replaces chi's request logger, e.g. with one that redacts query parameters.
+	RequestLogger func(http.Handler) http.Handler
+	// ReadOnly answers every POST/PUT/PATCH/DELETE under the API base path with 405, keeping
+	// reads available.
+	ReadOnly bool
+	// Msgpack lets list and search requests with Accept: application/msgpack receive
+	// MessagePack instead of JSON.
+	Msgpack bool
 }
 
 // NewRouter wires handlers and middlewares.
@@ -29,7 +42,11 @@ func NewRouter(cfg RouterConfig) http.Handler {
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	if cfg.RequestLogger != nil {
+		r.Use(cfg.RequestLogger)
+	} else {
+		r.Use(middleware.Logger)
+	}
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Compress(1))
 
@@ -45,6 +62,13 @@ func NewRouter(cfg RouterConfig) http.Handler {
 		apiBasePath = "/"
 	}
 	r.Route(apiBasePath, func(api chi.Router) {
+		api.Use(apiVersionMiddleware(cfg.DefaultAPIVersion))
+		if cfg.ReadOnly {
+			api.Use(readOnlyMiddleware)
+		}
+		if cfg.Msgpack {
+			api.Use(msgpackMiddleware)
+		}
 		if cfg.EntryHandler != nil {
 			cfg.EntryHandler.RegisterRoutes(api)
 		}
@@ -63,6 +87,9 @@ func NewRouter(cfg RouterConfig) http.Handler {
 		if cfg.MetricsHandler != nil {
 			cfg.MetricsHandler.RegisterRoutes(api)
 		}
+		if cfg.SourceHandler != nil {
+			cfg.SourceHandler.RegisterRoutes(api)
+		}
 		if cfg.APIKeyHandler != nil {
 			cfg.APIKeyHandler.RegisterRoutes(api)
 		}
@@ -75,3 +102,19 @@ func NewRouter(cfg RouterConfig) http.Handler {
 	})
 	return r
 }
+
+// errReadOnly is returned for write requests in read-only mode.
+var errReadOnly = errors.New("the API is read-only")
+
+// readOnlyMiddleware rejects requests with methods that may write with 405.
+func readOnlyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+			writeError(w, r, http.StatusMethodNotAllowed, errReadOnly)
+		}
+	})
+}
@@ -11,7 +11,11 @@ import (
 
 	domainEntry "hateblog/internal/domain/entry"
 	"hateblog/internal/domain/repository"
+	domainTag "hateblog/internal/domain/tag"
+	usecaseArchive "hateblog/internal/usecase/archive"
 	usecaseEntry "hateblog/internal/usecase/entry"
+	usecaseRanking "hateblog/internal/usecase/ranking"
+	usecaseSource "hateblog/internal/usecase/source"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
@@ -70,6 +74,10 @@ func (f *fakeRepo) Get(ctx context.Context, id domainEntry.ID) (*domainEntry.Ent
 	return nil, nil
 }
 
+func (f *fakeRepo) GetByIDs(ctx context.Context, ids []domainEntry.ID) ([]*domainEntry.Entry, error) {
+	return []*domainEntry.Entry{}, nil
+}
+
 func (f *fakeRepo) List(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
 	return f.list, nil
 }
@@ -84,9 +92,189 @@ func (f *fakeRepo) Delete(ctx context.Context, id domainEntry.ID) error        {
 func (f *fakeRepo) ListArchiveCounts(ctx context.Context, minBookmarkCount int) ([]repository.ArchiveCount, error) {
 	return nil, nil
 }
+func (f *fakeRepo) ListUntagged(ctx context.Context, limit, offset int) ([]*domainEntry.Entry, error) {
+	return nil, nil
+}
+func (f *fakeRepo) CountUntagged(ctx context.Context) (int64, error) { return 0, nil }
 
 type fakeHealthChecker struct{}
 
 func (f *fakeHealthChecker) HealthCheck(ctx context.Context) error {
 	return nil
 }
+
+func TestRouter_MaxOffsetAcrossEndpoints(t *testing.T) {
+	const maxOffset = 100
+	entryRepo := &mockEntryRepository{}
+	tagRepo := &mockTagRepository{
+		getByNameFunc: func(ctx context.Context, name string) (*domainTag.Tag, error) {
+			return newTestTag(uuid.New(), name), nil
+		},
+	}
+	entryService := newTestEntryService(entryRepo)
+	rankingService := usecaseRanking.NewService(&mockRankingRepository{}, nil, nil, nil)
+
+	ts := newTestServer(RouterConfig{
+		EntryHandler:   NewEntryHandler(entryService, testAPIBasePath).WithMaxOffset(maxOffset).WithCurationAuth(headerAuth),
+		TagHandler:     NewTagHandler(newTestTagService(tagRepo), entryService, testAPIBasePath).WithMaxOffset(maxOffset),
+		RankingHandler: NewRankingHandler(rankingService, testAPIBasePath).WithMaxOffset(maxOffset),
+		SearchHandler:  NewSearchHandler(newTestSearchService(entryRepo, &mockSearchHistoryRepository{}), testAPIBasePath).WithMaxOffset(maxOffset),
+	})
+	defer ts.Close()
+
+	paths := []string{
+		"/entries/new?date=20250105",
+		"/entries/hot?date=20250105",
+		"/entries/untagged?limit=10",
+		"/tags?limit=10",
+		"/tags/entries/go?limit=10",
+		"/rankings/yearly?year=2024",
+		"/rankings/monthly?year=2024&month=1",
+		"/rankings/weekly?year=2024&week=1",
+		"/search?q=go",
+	}
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			resp := getWithAPIKey(t, ts, apiPath(path+"&offset=100"))
+			resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode, "offset at the limit")
+
+			resp = getWithAPIKey(t, ts, apiPath(path+"&offset=101"))
+			body := assertErrorResponse(t, resp, http.StatusBadRequest)
+			require.Contains(t, body["error"], "offset must be <= 100")
+		})
+	}
+}
+
+func TestRouter_EmptyListsSerializeAsArrays(t *testing.T) {
+	entryRepo := &mockEntryRepository{}
+	tagRepo := &mockTagRepository{
+		getByNameFunc: func(ctx context.Context, name string) (*domainTag.Tag, error) {
+			return newTestTag(uuid.New(), name), nil
+		},
+	}
+	entryService := newTestEntryService(entryRepo)
+
+	ts := newTestServer(RouterConfig{
+		EntryHandler:   NewEntryHandler(entryService, testAPIBasePath).WithCurationAuth(headerAuth),
+		TagHandler:     NewTagHandler(newTestTagService(tagRepo), entryService, testAPIBasePath),
+		RankingHandler: NewRankingHandler(usecaseRanking.NewService(&mockRankingRepository{}, nil, nil, nil), testAPIBasePath),
+		SearchHandler:  NewSearchHandler(newTestSearchService(entryRepo, &mockSearchHistoryRepository{}), testAPIBasePath),
+		ArchiveHandler: NewArchiveHandler(usecaseArchive.NewService(&mockArchiveRepository{}, nil)),
+		SourceHandler:  NewSourceHandler(usecaseSource.NewService(&mockSourceRepository{})),
+	})
+	defer ts.Close()
+
+	tests := []struct {
+		path     string
+		key      string
+		hasTotal bool
+	}{
+		{"/entries/new?date=20250105", "entries", true},
+		{"/entries/hot?date=20250105", "entries", true},
+		{"/entries/untagged", "entries", true},
+		{"/tags/entries/go", "entries", true},
+		{"/rankings/yearly?year=2024", "entries", true},
+		{"/rankings/monthly?year=2024&month=1", "entries", true},
+		{"/rankings/weekly?year=2024&week=1", "entries", true},
+		{"/search?q=go", "entries", true},
+		{"/tags", "tags", false},
+		{"/tags/trending", "tags", true},
+		{"/tags/clicked", "tags", true},
+		{"/archive", "items", false},
+		{"/sources", "sources", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp := getWithAPIKey(t, ts, apiPath(tt.path))
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var body map[string]json.RawMessage
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			require.Equal(t, "[]", string(body[tt.key]), "%s must be an empty array", tt.key)
+			if tt.hasTotal {
+				require.Equal(t, "0", string(body["total"]))
+			}
+		})
+	}
+}
+
+func TestRouter_ReadOnly(t *testing.T) {
+	entryRepo := &mockEntryRepository{
+		getFunc: func(ctx context.Context, id domainEntry.ID) (*domainEntry.Entry, error) {
+			return newTestEntry(id, "Entry", 10), nil
+		},
+	}
+	var writes int
+	linker := &mockTagLinker{
+		attachEntryFunc: func(context.Context, domainEntry.ID, domainTag.ID, int) error {
+			writes++
+			return nil
+		},
+		detachEntryFunc: func(context.Context, domainEntry.ID, string) (bool, error) {
+			writes++
+			return true, nil
+		},
+		updateScoreFunc: func(context.Context, domainEntry.ID, string, int) (bool, error) {
+			writes++
+			return true, nil
+		},
+	}
+	entryService := newTestEntryService(entryRepo).WithTagLinker(linker)
+	newServer := func(readOnly bool) *testServer {
+		return newTestServer(RouterConfig{
+			EntryHandler:  NewEntryHandler(entryService, testAPIBasePath).WithCurationAuth(headerAuth).WithAdminAuth(headerAuth),
+			SourceHandler: NewSourceHandler(usecaseSource.NewService(&mockSourceRepository{})),
+			ReadOnly:      readOnly,
+		})
+	}
+	entryPath := "/entries/" + uuid.New().String() + "/tags"
+	writeRequests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, entryPath, `{"tags":["go"]}`},
+		{http.MethodPut, entryPath + "/go", `{"score":10}`},
+		{http.MethodDelete, entryPath + "/go", ""},
+	}
+
+	ts := newServer(true)
+	defer ts.Close()
+	for _, req := range writeRequests {
+		t.Run(req.method+" is rejected", func(t *testing.T) {
+			resp := doWithAPIKey(t, ts, req.method, apiPath(req.path), req.body)
+			require.Equal(t, "GET, HEAD, OPTIONS", resp.Header.Get("Allow"))
+			body := assertErrorResponse(t, resp, http.StatusMethodNotAllowed)
+			require.Contains(t, body["error"], "read-only")
+		})
+	}
+	require.Zero(t, writes)
+
+	for _, path := range []string{"/entries/new?date=20250105", "/entries/untagged", "/sources"} {
+		t.Run("GET "+path+" works", func(t *testing.T) {
+			resp := getWithAPIKey(t, ts, apiPath(path))
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+
+	t.Run("writes work when not read-only", func(t *testing.T) {
+		rw := newServer(false)
+		defer rw.Close()
+		for _, req := range writeRequests {
+			resp := doWithAPIKey(t, rw, req.method, apiPath(req.path), req.body)
+			resp.Body.Close()
+			require.Less(t, resp.StatusCode, 300, "%s %s", req.method, req.path)
+		}
+		require.Equal(t, len(writeRequests), writes)
+	})
+}
+
+func TestRankingHandler_WithMaxOffsetKeepsBuiltInCap(t *testing.T) {
+	h := NewRankingHandler(nil, testAPIBasePath).WithMaxOffset(0)
+	require.Equal(t, maxRankingOffset, h.maxOffset)
+	h = NewRankingHandler(nil, testAPIBasePath).WithMaxOffset(maxRankingOffset * 2)
+	require.Equal(t, maxRankingOffset, h.maxOffset)
+}
@@ -2,17 +2,29 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	domainEntry "hateblog/internal/domain/entry"
+	"hateblog/internal/domain/repository"
+	"hateblog/internal/pkg/apptime"
 	usecaseSearch "hateblog/internal/usecase/search"
 )
 
+// defaultHistoryDays is the range of GET /admin/search/history without from.
+const defaultHistoryDays = 7
+
 // SearchHandler serves /search endpoint.
 type SearchHandler struct {
 	service     *usecaseSearch.Service
 	apiBasePath string
+	maxOffset   int
+	// substringDefault makes English terms match as substrings when word_boundary is omitted.
+	substringDefault bool
+	historyAuth      func(http.Handler) http.Handler
+	now              func() time.Time
 }
 
 // NewSearchHandler builds a SearchHandler.
@@ -20,12 +32,36 @@ func NewSearchHandler(service *usecaseSearch.Service, apiBasePath string) *Searc
 	return &SearchHandler{
 		service:     service,
 		apiBasePath: normalizeAPIBasePath(apiBasePath),
+		now:         time.Now,
 	}
 }
 
+// WithWordBoundaryDefault sets the word_boundary value used when a request omits it.
+func (h *SearchHandler) WithWordBoundaryDefault(on bool) *SearchHandler {
+	h.substringDefault = !on
+	return h
+}
+
+// WithMaxOffset rejects requests with an offset above maxOffset (0 means no limit).
+func (h *SearchHandler) WithMaxOffset(maxOffset int) *SearchHandler {
+	h.maxOffset = maxOffset
+	return h
+}
+
+// WithHistoryAuth enables GET /admin/search/history and /admin/search/top behind auth. The route is registered only
+// when the service can list the search history.
+func (h *SearchHandler) WithHistoryAuth(auth func(http.Handler) http.Handler) *SearchHandler {
+	h.historyAuth = auth
+	return h
+}
+
 // RegisterRoutes adds search routes.
 func (h *SearchHandler) RegisterRoutes(r chiRouter) {
 	r.Get("/search", h.handleSearch)
+	if h.historyAuth != nil && h.service.HistoryListEnabled() {
+		r.Get("/admin/search/history", h.historyAuth(http.HandlerFunc(h.handleSearchHistory)).ServeHTTP)
+		r.Get("/admin/search/top", h.historyAuth(http.HandlerFunc(h.handleSearchTop)).ServeHTTP)
+	}
 }
 
 func (h *SearchHandler) handleSearch(w http.ResponseWriter, r *http.Request) {
@@ -39,12 +75,12 @@ func (h *SearchHandler) handleSearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	limit, err := readQueryInt(r, "limit", 1, 100, defaultLimit)
+	limit, err := readQueryLimit(w, r, 1, 100, defaultLimit)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
-	offset, err := readQueryInt(r, "offset", 0, -1, 0)
+	offset, err := readQueryOffset(r, h.maxOffset)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
@@ -59,12 +95,35 @@ func (h *SearchHandler) handleSearch(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
+	matchTags, err := readQueryBool(r, "match_tags", false)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	wordBoundary, err := readQueryBool(r, "word_boundary", !h.substringDefault)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	relax, err := readQueryBool(r, "relax", false)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	maxTags, err := readQueryMaxTags(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
 
 	result, cacheHit, err := h.service.SearchWithCacheStatus(r.Context(), q, usecaseSearch.Params{
 		MinBookmarkCount: minUsers,
 		Limit:            limit,
 		Offset:           offset,
 		Sort:             sortType,
+		MatchTags:        matchTags,
+		EnglishSubstring: !wordBoundary,
+		Relax:            relax,
 	})
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
@@ -78,12 +137,16 @@ func (h *SearchHandler) handleSearch(w http.ResponseWriter, r *http.Request) {
 		Limit:   result.Limit,
 		Offset:  result.Offset,
 	}
+	if result.Relaxed {
+		resp.Relaxed = true
+		resp.MinUsers = &result.MinBookmarkCount
+	}
 	for _, ent := range result.Entries {
-		resp.Entries = append(resp.Entries, toEntryResponse(ent, h.apiBasePath))
+		resp.Entries = append(resp.Entries, toEntryResponse(ent, h.apiBasePath, maxTags))
 	}
 
 	setCacheStatusHeader(w, cacheHit)
-	writeJSON(w, http.StatusOK, resp)
+	writeList(w, r, http.StatusOK, resp)
 }
 
 type searchResponse struct {
@@ -92,4 +155,131 @@ type searchResponse struct {
 	Total   int64           `json:"total"`
 	Limit   int             `json:"limit"`
 	Offset  int             `json:"offset"`
+	// Relaxed and MinUsers report the lowered min_users of a relax=true request.
+	Relaxed  bool `json:"relaxed,omitempty"`
+	MinUsers *int `json:"min_users,omitempty"`
+}
+
+func (r searchResponse) resultCount() int {
+	return len(r.Entries)
+}
+
+func (r searchResponse) envelope() listEnvelope {
+	return listEnvelope{
+		Data: r.Entries,
+		Meta: searchMeta{
+			Query:    r.Query,
+			listMeta: listMeta{Total: r.Total, Limit: r.Limit, Offset: r.Offset, Relaxed: r.Relaxed, MinUsers: r.MinUsers},
+		},
+	}
+}
+
+// searchMeta is the v2 meta of search results.
+type searchMeta struct {
+	Query string `json:"query"`
+	listMeta
+}
+
+func (h *SearchHandler) handleSearchHistory(w http.ResponseWriter, r *http.Request) {
+	to, err := readQueryDate(r, "to", apptime.TruncateToDay(h.now()))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	from, err := readQueryDate(r, "from", to.AddDate(0, 0, -(defaultHistoryDays-1)))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if from.After(to) {
+		writeError(w, r, http.StatusBadRequest, errors.New("from must not be after to"))
+		return
+	}
+	limit, err := readQueryInt(r, "limit", 1, usecaseSearch.MaxHistoryLimit, usecaseSearch.DefaultHistoryLimit)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	// to is inclusive; the service takes a half-open range.
+	queries, err := h.service.ListHistory(r.Context(), from, to.AddDate(0, 0, 1), limit)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	resp := searchHistoryResponse{
+		From:    from.Format("2006-01-02"),
+		To:      to.Format("2006-01-02"),
+		Queries: toSearchHistoryItems(queries),
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *SearchHandler) handleSearchTop(w http.ResponseWriter, r *http.Request) {
+	days, err := readQueryInt(r, "days", 1, usecaseSearch.MaxTopDays, usecaseSearch.DefaultTopDays)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	limit, err := readQueryInt(r, "limit", 1, usecaseSearch.MaxTopLimit, usecaseSearch.DefaultTopLimit)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	queries, err := h.service.TopQueries(r.Context(), days, limit)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	resp := searchTopResponse{
+		Days:    days,
+		Queries: toSearchHistoryItems(queries),
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// readQueryDate parses an optional YYYYMMDD date in the application timezone.
+func readQueryDate(r *http.Request, key string, def time.Time) (time.Time, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(key))
+	if raw == "" {
+		return def, nil
+	}
+	if !isValidDate(raw) {
+		return time.Time{}, fmt.Errorf("%s must be YYYYMMDD", key)
+	}
+	return apptime.ParseDate(raw)
+}
+
+// searchHistoryResponse matches SearchHistoryResponse schema.
+type searchHistoryResponse struct {
+	From    string              `json:"from"`
+	To      string              `json:"to"`
+	Queries []searchHistoryItem `json:"queries"`
+}
+
+// searchTopResponse matches SearchTopResponse schema.
+type searchTopResponse struct {
+	Days    int                 `json:"days"`
+	Queries []searchHistoryItem `json:"queries"`
+}
+
+type searchHistoryItem struct {
+	Query           string `json:"query"`
+	Count           int64  `json:"count"`
+	FirstSearchedAt string `json:"first_searched_at"`
+	LastSearchedAt  string `json:"last_searched_at"`
+}
+
+func toSearchHistoryItems(queries []repository.SearchQueryCount) []searchHistoryItem {
+	items := make([]searchHistoryItem, 0, len(queries))
+	for _, q := range queries {
+		items = append(items, searchHistoryItem{
+			Query:           q.Query,
+			Count:           q.Count,
+			FirstSearchedAt: q.FirstSearchedAt.Format("2006-01-02"),
+			LastSearchedAt:  q.LastSearchedAt.Format("2006-01-02"),
+		})
+	}
+	return items
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -12,6 +13,8 @@ import (
 	"github.com/google/uuid"
 
 	domainEntry "hateblog/internal/domain/entry"
+	"hateblog/internal/domain/repository"
+	usecaseSearch "hateblog/internal/usecase/search"
 )
 
 func TestSearchHandler_SearchEntries(t *testing.T) {
@@ -155,6 +158,11 @@ func TestSearchHandler_SearchEntries(t *testing.T) {
 			queryParams: "?q=test&sort=popular",
 			wantStatus:  http.StatusBadRequest,
 		},
+		{
+			name:        "error: invalid match_tags",
+			queryParams: "?q=test&match_tags=yes",
+			wantStatus:  http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -218,6 +226,112 @@ func TestSearchHandler_SearchEntries(t *testing.T) {
 	}
 }
 
+func TestSearchHandler_SearchEntries_MatchTags(t *testing.T) {
+	tests := []struct {
+		queryParams string
+		want        bool
+	}{
+		{"?q=golang", false},
+		{"?q=golang&match_tags=false", false},
+		{"?q=golang&match_tags=true", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.queryParams, func(t *testing.T) {
+			var got domainEntry.ListQuery
+			mockEntryRepo := &mockEntryRepository{}
+			mockEntryRepo.listFunc = func(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
+				got = query
+				return nil, nil
+			}
+			service := newTestSearchService(mockEntryRepo, &mockSearchHistoryRepository{})
+			ts := newTestServer(RouterConfig{
+				SearchHandler: NewSearchHandler(service, testAPIBasePath),
+			})
+			defer ts.Close()
+
+			resp := ts.get(t, apiPath("/search"+tt.queryParams))
+			defer resp.Body.Close()
+
+			assertStatus(t, resp, http.StatusOK)
+			if got.MatchTags != tt.want {
+				t.Errorf("MatchTags = %v, want %v", got.MatchTags, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchHandler_SearchEntries_WordBoundary(t *testing.T) {
+	tests := []struct {
+		name          string
+		queryParams   string
+		boundaryOff   bool
+		wantSubstring bool
+		wantStatus    int
+	}{
+		{"default", "?q=go", false, false, http.StatusOK},
+		{"disabled by param", "?q=go&word_boundary=false", false, true, http.StatusOK},
+		{"config default off", "?q=go", true, true, http.StatusOK},
+		{"param overrides config", "?q=go&word_boundary=true", true, false, http.StatusOK},
+		{"invalid", "?q=go&word_boundary=maybe", false, false, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got domainEntry.ListQuery
+			mockEntryRepo := &mockEntryRepository{}
+			mockEntryRepo.listFunc = func(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
+				got = query
+				return nil, nil
+			}
+			service := newTestSearchService(mockEntryRepo, &mockSearchHistoryRepository{})
+			ts := newTestServer(RouterConfig{
+				SearchHandler: NewSearchHandler(service, testAPIBasePath).WithWordBoundaryDefault(!tt.boundaryOff),
+			})
+			defer ts.Close()
+
+			resp := ts.get(t, apiPath("/search"+tt.queryParams))
+			defer resp.Body.Close()
+
+			assertStatus(t, resp, tt.wantStatus)
+			if got.EnglishSubstring != tt.wantSubstring {
+				t.Errorf("EnglishSubstring = %v, want %v", got.EnglishSubstring, tt.wantSubstring)
+			}
+		})
+	}
+}
+
+func TestSearchHandler_SearchEntries_Relax(t *testing.T) {
+	var minUsers []int
+	mockEntryRepo := &mockEntryRepository{
+		listFunc: func(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
+			minUsers = append(minUsers, query.MinBookmarkCount)
+			return []*domainEntry.Entry{newTestEntry(uuid.New(), "Go", 3)}, nil
+		},
+		countFunc: func(ctx context.Context, query domainEntry.ListQuery) (int64, error) {
+			if query.MinBookmarkCount > 0 {
+				return 0, nil
+			}
+			return 1, nil
+		},
+	}
+	service := newTestSearchService(mockEntryRepo, &mockSearchHistoryRepository{}).WithMinResults(1)
+	ts := newTestServer(RouterConfig{
+		SearchHandler: NewSearchHandler(service, testAPIBasePath),
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/search?q=go&min_users=10&relax=true"))
+	defer resp.Body.Close()
+	assertStatus(t, resp, http.StatusOK)
+	var result searchResponse
+	decodeJSON(t, resp, &result)
+	if !result.Relaxed || result.MinUsers == nil || *result.MinUsers != 0 {
+		t.Errorf("relaxed = %v, min_users = %v, want true and 0", result.Relaxed, result.MinUsers)
+	}
+	if want := []int{10, 5, 0}; !slices.Equal(minUsers, want) {
+		t.Errorf("searched min_users = %v, want %v", minUsers, want)
+	}
+}
+
 func TestSearchHandler_SearchEntries_ServiceError(t *testing.T) {
 	mockEntryRepo := &mockEntryRepository{
 		listFunc: func(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
@@ -474,13 +588,26 @@ func TestSearchHandler_SpecialCharacters(t *testing.T) {
 			query:      "c++",
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:       "punctuation only",
+			query:      "!!! ?",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "like wildcard only",
+			query:      "%",
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			listed := false
 			mockEntryRepo := &mockEntryRepository{
-				entries: []*domainEntry.Entry{},
-				total:   0,
+				listFunc: func(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
+					listed = true
+					return []*domainEntry.Entry{}, nil
+				},
 			}
 
 			mockHistoryRepo := &mockSearchHistoryRepository{}
@@ -496,7 +623,168 @@ func TestSearchHandler_SpecialCharacters(t *testing.T) {
 			resp := ts.get(t, path)
 			defer resp.Body.Close()
 
+			if tt.wantStatus != http.StatusOK {
+				assertErrorResponse(t, resp, tt.wantStatus)
+				if listed {
+					t.Errorf("query %q must not list entries", tt.query)
+				}
+				return
+			}
 			assertStatus(t, resp, tt.wantStatus)
 		})
 	}
 }
+
+// mockSearchHistoryLister records the range it is asked for and returns fixed rows.
+type mockSearchHistoryLister struct {
+	rows     []repository.SearchQueryCount
+	err      error
+	from, to time.Time
+	days     int
+	limit    int
+}
+
+func (m *mockSearchHistoryLister) List(ctx context.Context, from, to time.Time, limit int) ([]repository.SearchQueryCount, error) {
+	m.from, m.to, m.limit = from, to, limit
+	return m.rows, m.err
+}
+
+func (m *mockSearchHistoryLister) Top(ctx context.Context, days int, limit int) ([]repository.SearchQueryCount, error) {
+	m.days, m.limit = days, limit
+	return m.rows, m.err
+}
+
+func TestSearchHandler_History(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.Local) }
+	lister := &mockSearchHistoryLister{rows: []repository.SearchQueryCount{
+		{Query: "rust", Count: 3, FirstSearchedAt: day(5), LastSearchedAt: day(7)},
+		{Query: "go", Count: 12, FirstSearchedAt: day(1), LastSearchedAt: day(6)},
+	}}
+	service := newTestSearchService(&mockEntryRepository{}, &mockSearchHistoryRepository{}).WithHistoryList(lister)
+	handler := NewSearchHandler(service, testAPIBasePath).WithHistoryAuth(headerAuth)
+	handler.now = func() time.Time { return day(10).Add(15 * time.Hour) }
+	ts := newTestServer(RouterConfig{SearchHandler: handler})
+	defer ts.Close()
+
+	t.Run("range", func(t *testing.T) {
+		resp := getWithAPIKey(t, ts, apiPath("/admin/search/history?from=20250101&to=20250107&limit=10"))
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusOK)
+		var result searchHistoryResponse
+		decodeJSON(t, resp, &result)
+
+		if !lister.from.Equal(day(1)) || !lister.to.Equal(day(8)) || lister.limit != 10 {
+			t.Errorf("List(%v, %v, %d), want [2025-01-01, 2025-01-08) limit 10", lister.from, lister.to, lister.limit)
+		}
+		want := searchHistoryResponse{
+			From: "2025-01-01",
+			To:   "2025-01-07",
+			Queries: []searchHistoryItem{
+				{Query: "rust", Count: 3, FirstSearchedAt: "2025-01-05", LastSearchedAt: "2025-01-07"},
+				{Query: "go", Count: 12, FirstSearchedAt: "2025-01-01", LastSearchedAt: "2025-01-06"},
+			},
+		}
+		if !slices.Equal(result.Queries, want.Queries) || result.From != want.From || result.To != want.To {
+			t.Errorf("response = %+v, want %+v", result, want)
+		}
+	})
+
+	t.Run("defaults to the last 7 days", func(t *testing.T) {
+		resp := getWithAPIKey(t, ts, apiPath("/admin/search/history"))
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusOK)
+		if !lister.from.Equal(day(4)) || !lister.to.Equal(day(11)) || lister.limit != usecaseSearch.DefaultHistoryLimit {
+			t.Errorf("List(%v, %v, %d), want [2025-01-04, 2025-01-11) limit %d", lister.from, lister.to, lister.limit, usecaseSearch.DefaultHistoryLimit)
+		}
+	})
+
+	for name, query := range map[string]string{
+		"invalid from":    "?from=2025-01-01",
+		"from after to":   "?from=20250108&to=20250107",
+		"limit too large": "?limit=5000",
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := getWithAPIKey(t, ts, apiPath("/admin/search/history"+query))
+			defer resp.Body.Close()
+			assertErrorResponse(t, resp, http.StatusBadRequest)
+		})
+	}
+
+	t.Run("requires auth", func(t *testing.T) {
+		resp := ts.get(t, apiPath("/admin/search/history"))
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusUnauthorized)
+	})
+}
+
+func TestSearchHandler_History_NotRegistered(t *testing.T) {
+	withoutAuth := newTestSearchService(&mockEntryRepository{}, &mockSearchHistoryRepository{}).WithHistoryList(&mockSearchHistoryLister{})
+	withoutLister := newTestSearchService(&mockEntryRepository{}, &mockSearchHistoryRepository{})
+
+	for name, handler := range map[string]*SearchHandler{
+		"without auth":   NewSearchHandler(withoutAuth, testAPIBasePath),
+		"without lister": NewSearchHandler(withoutLister, testAPIBasePath).WithHistoryAuth(headerAuth),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ts := newTestServer(RouterConfig{SearchHandler: handler})
+			defer ts.Close()
+
+			resp := getWithAPIKey(t, ts, apiPath("/admin/search/history"))
+			defer resp.Body.Close()
+			assertStatus(t, resp, http.StatusNotFound)
+		})
+	}
+}
+
+func TestSearchHandler_TopQueries(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.Local) }
+	lister := &mockSearchHistoryLister{rows: []repository.SearchQueryCount{
+		{Query: "go", Count: 12, FirstSearchedAt: day(1), LastSearchedAt: day(6)},
+		{Query: "rust", Count: 3, FirstSearchedAt: day(5), LastSearchedAt: day(7)},
+	}}
+	service := newTestSearchService(&mockEntryRepository{}, &mockSearchHistoryRepository{}).WithHistoryList(lister)
+	ts := newTestServer(RouterConfig{SearchHandler: NewSearchHandler(service, testAPIBasePath).WithHistoryAuth(headerAuth)})
+	defer ts.Close()
+
+	t.Run("success", func(t *testing.T) {
+		resp := getWithAPIKey(t, ts, apiPath("/admin/search/top?days=30&limit=5"))
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusOK)
+		var result searchTopResponse
+		decodeJSON(t, resp, &result)
+
+		if lister.days != 30 || lister.limit != 5 {
+			t.Errorf("Top(%d, %d), want (30, 5)", lister.days, lister.limit)
+		}
+		want := []searchHistoryItem{
+			{Query: "go", Count: 12, FirstSearchedAt: "2025-01-01", LastSearchedAt: "2025-01-06"},
+			{Query: "rust", Count: 3, FirstSearchedAt: "2025-01-05", LastSearchedAt: "2025-01-07"},
+		}
+		if result.Days != 30 || !slices.Equal(result.Queries, want) {
+			t.Errorf("response = %+v, want days 30 and %+v", result, want)
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		resp := getWithAPIKey(t, ts, apiPath("/admin/search/top"))
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusOK)
+		if lister.days != usecaseSearch.DefaultTopDays || lister.limit != usecaseSearch.DefaultTopLimit {
+			t.Errorf("Top(%d, %d), want defaults", lister.days, lister.limit)
+		}
+	})
+
+	for _, query := range []string{"?days=0", "?days=366", "?limit=101"} {
+		t.Run(query, func(t *testing.T) {
+			resp := getWithAPIKey(t, ts, apiPath("/admin/search/top"+query))
+			defer resp.Body.Close()
+			assertErrorResponse(t, resp, http.StatusBadRequest)
+		})
+	}
+
+	t.Run("requires auth", func(t *testing.T) {
+		resp := ts.get(t, apiPath("/admin/search/top"))
+		defer resp.Body.Close()
+		assertStatus(t, resp, http.StatusUnauthorized)
+	})
+}
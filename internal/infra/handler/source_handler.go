@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	usecaseSource "hateblog/internal/usecase/source"
+)
+
+// SourceHandler exposes per-host entry statistics.
+type SourceHandler struct {
+	service *usecaseSource.Service
+}
+
+// NewSourceHandler builds a SourceHandler.
+func NewSourceHandler(service *usecaseSource.Service) *SourceHandler {
+	return &SourceHandler{service: service}
+}
+
+// RegisterRoutes wires source endpoints.
+func (h *SourceHandler) RegisterRoutes(r chiRouter) {
+	r.Get("/sources", h.handleListSources)
+}
+
+func (h *SourceHandler) handleListSources(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		writeError(w, r, http.StatusInternalServerError, errServiceUnavailable)
+		return
+	}
+	limit, err := readQueryLimit(w, r, 1, usecaseSource.MaxLimit, usecaseSource.DefaultLimit)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	sources, err := h.service.List(r.Context(), limit)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := sourcesResponse{
+		Sources: make([]sourceItemResponse, 0, len(sources)),
+		Limit:   limit,
+	}
+	for _, src := range sources {
+		resp.Sources = append(resp.Sources, sourceItemResponse{
+			Host:       src.Host,
+			EntryCount: src.EntryCount,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type sourcesResponse struct {
+	Sources []sourceItemResponse `json:"sources"`
+	Limit   int                  `json:"limit"`
+}
+
+type sourceItemResponse struct {
+	Host       string `json:"host"`
+	EntryCount int64  `json:"entry_count"`
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	domainEntry "hateblog/internal/domain/entry"
+	usecaseSource "hateblog/internal/usecase/source"
+)
+
+type mockSourceRepository struct {
+	sources []domainEntry.Source
+	err     error
+	limit   int
+}
+
+func (m *mockSourceRepository) ListSources(ctx context.Context, limit int) ([]domainEntry.Source, error) {
+	m.limit = limit
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.sources, nil
+}
+
+func TestSourceHandler_List(t *testing.T) {
+	tests := []struct {
+		name        string
+		queryParams string
+		wantStatus  int
+		wantLimit   int
+	}{
+		{name: "default limit", wantStatus: http.StatusOK, wantLimit: usecaseSource.DefaultLimit},
+		{name: "explicit limit", queryParams: "?limit=5", wantStatus: http.StatusOK, wantLimit: 5},
+		{name: "error: limit too large", queryParams: "?limit=101", wantStatus: http.StatusBadRequest},
+		{name: "error: limit zero", queryParams: "?limit=0", wantStatus: http.StatusBadRequest},
+		{name: "error: invalid limit", queryParams: "?limit=abc", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockSourceRepository{sources: []domainEntry.Source{
+				{Host: "example.com", EntryCount: 12},
+				{Host: "blog.example.com", EntryCount: 3},
+			}}
+			ts := newTestServer(RouterConfig{
+				SourceHandler: NewSourceHandler(usecaseSource.NewService(mockRepo)),
+			})
+			defer ts.Close()
+
+			resp := ts.get(t, apiPath("/sources"+tt.queryParams))
+			defer resp.Body.Close()
+
+			if tt.wantStatus != http.StatusOK {
+				assertErrorResponse(t, resp, tt.wantStatus)
+				return
+			}
+
+			assertStatus(t, resp, http.StatusOK)
+			assertContentType(t, resp, "application/json")
+
+			var result sourcesResponse
+			decodeJSON(t, resp, &result)
+
+			if mockRepo.limit != tt.wantLimit || result.Limit != tt.wantLimit {
+				t.Errorf("limit = %d (response %d), want %d", mockRepo.limit, result.Limit, tt.wantLimit)
+			}
+			if len(result.Sources) != 2 {
+				t.Fatalf("got %d sources, want 2", len(result.Sources))
+			}
+			if result.Sources[0] != (sourceItemResponse{Host: "example.com", EntryCount: 12}) {
+				t.Errorf("first source = %+v", result.Sources[0])
+			}
+		})
+	}
+}
+
+func TestSourceHandler_ServiceError(t *testing.T) {
+	mockRepo := &mockSourceRepository{err: fmt.Errorf("database error")}
+	ts := newTestServer(RouterConfig{
+		SourceHandler: NewSourceHandler(usecaseSource.NewService(mockRepo)),
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/sources"))
+	defer resp.Body.Close()
+	assertErrorResponse(t, resp, http.StatusInternalServerError)
+}
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -15,10 +18,8 @@ import (
 )
 
 const (
-	defaultTagLimit     = 25
-	maxTagLimit         = 100
-	defaultTagListLimit = 50
-	maxTagListLimit     = 200
+	defaultTagLimit = 25
+	maxTagLimit     = 100
 )
 
 // TagHandler exposes tag endpoints.
@@ -26,6 +27,8 @@ type TagHandler struct {
 	tagService   *usecaseTag.Service
 	entryService *usecaseEntry.Service
 	apiBasePath  string
+	feedBaseURL  string
+	maxOffset    int
 }
 
 // NewTagHandler builds a TagHandler.
@@ -37,6 +40,18 @@ func NewTagHandler(tagService *usecaseTag.Service, entryService *usecaseEntry.Se
 	}
 }
 
+// WithFeedBaseURL enables RSS discovery Link headers pointing under baseURL.
+func (h *TagHandler) WithFeedBaseURL(baseURL string) *TagHandler {
+	h.feedBaseURL = strings.TrimSpace(baseURL)
+	return h
+}
+
+// WithMaxOffset rejects list requests with an offset above maxOffset (0 means no limit).
+func (h *TagHandler) WithMaxOffset(maxOffset int) *TagHandler {
+	h.maxOffset = maxOffset
+	return h
+}
+
 // RegisterRoutes wires tag endpoints.
 func (h *TagHandler) RegisterRoutes(r chiRouter) {
 	r.Get("/tags", h.handleListTags)
@@ -45,6 +60,49 @@ func (h *TagHandler) RegisterRoutes(r chiRouter) {
 	r.Get("/tags/entries", h.handleTagEntries)
 	r.Get("/tags/entries/", h.handleTagEntries)
 	r.Get("/tags/entries/{tag}", h.handleTagEntries)
+	r.Get("/tags/{tag}", h.handleGetTag)
+}
+
+func (h *TagHandler) handleGetTag(w http.ResponseWriter, r *http.Request) {
+	if h.tagService == nil {
+		writeError(w, r, http.StatusInternalServerError, errServiceUnavailable)
+		return
+	}
+	rawTag := chi.URLParam(r, "tag")
+	if rawTag == "" {
+		writeError(w, r, http.StatusBadRequest, errInvalidTag)
+		return
+	}
+
+	tagEntity, err := h.tagService.GetByName(r.Context(), rawTag)
+	if err != nil {
+		writeError(w, r, tagLookupErrorStatus(err), err)
+		return
+	}
+	usage, err := h.tagService.GetUsage(r.Context(), tagEntity.ID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tagDetailResponse{
+		ID:         tagEntity.ID,
+		Name:       tagEntity.Name,
+		EntryCount: usage.EntryCount,
+		ViewCount:  usage.ViewCount,
+	})
+}
+
+// tagLookupErrorStatus maps GetByName errors to HTTP status codes.
+func tagLookupErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domainTag.ErrInvalidTag):
+		return http.StatusBadRequest
+	case errors.Is(err, domainTag.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
 }
 
 func (h *TagHandler) handleListTags(w http.ResponseWriter, r *http.Request) {
@@ -52,18 +110,19 @@ func (h *TagHandler) handleListTags(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, http.StatusInternalServerError, errServiceUnavailable)
 		return
 	}
-	limit, err := readQueryInt(r, "limit", 1, maxTagListLimit, defaultTagListLimit)
+	def, max := h.tagService.ListLimits()
+	limit, err := readQueryLimit(w, r, 1, max, def)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
-	offset, err := readQueryInt(r, "offset", 0, 0, 0)
+	offset, err := readQueryOffset(r, h.maxOffset)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
-	tags, cacheHit, err := h.tagService.ListWithCacheStatus(r.Context(), limit, offset)
+	tags, cacheHit, err := h.tagService.ListWithEntryCounts(r.Context(), limit, offset)
 	if err != nil {
 		writeError(w, r, http.StatusInternalServerError, err)
 		return
@@ -76,12 +135,14 @@ func (h *TagHandler) handleListTags(w http.ResponseWriter, r *http.Request) {
 	}
 	for _, t := range tags {
 		resp.Tags = append(resp.Tags, tagItemResponse{
-			ID:   t.ID,
-			Name: t.Name,
+			ID:         t.ID,
+			Name:       t.Name,
+			EntryCount: t.EntryCount,
 		})
 	}
 
 	setCacheStatusHeader(w, cacheHit)
+	setResultCountHeader(w, len(resp.Tags))
 	writeJSON(w, http.StatusOK, resp)
 }
 
@@ -98,16 +159,16 @@ func (h *TagHandler) handleTagEntries(w http.ResponseWriter, r *http.Request) {
 
 	tagEntity, err := h.tagService.GetByName(r.Context(), rawTag)
 	if err != nil {
-		writeError(w, r, http.StatusNotFound, err)
+		writeError(w, r, tagLookupErrorStatus(err), err)
 		return
 	}
 
-	limit, err := readQueryInt(r, "limit", 1, maxTagLimit, defaultTagLimit)
+	limit, err := readQueryLimit(w, r, 1, maxTagLimit, defaultTagLimit)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
-	offset, err := readQueryInt(r, "offset", 0, 0, 0)
+	offset, err := readQueryOffset(r, h.maxOffset)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
@@ -122,6 +183,11 @@ func (h *TagHandler) handleTagEntries(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
+	maxTags, err := readQueryMaxTags(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
 
 	result, cacheHit, err := h.entryService.ListTagEntriesWithCacheStatus(r.Context(), tagEntity.Name, usecaseEntry.TagListParams{
 		MinBookmarkCount: minUsers,
@@ -138,8 +204,12 @@ func (h *TagHandler) handleTagEntries(w http.ResponseWriter, r *http.Request) {
 		slog.Default().Warn("failed to record tag view", "tag", tagEntity.Name, "error", err)
 	}
 
+	setFeedLinkHeader(w, h.feedBaseURL, "/tags/entries/"+url.PathEscape(tagEntity.Name), url.Values{
+		"min_users": []string{strconv.Itoa(minUsers)},
+		"sort":      []string{string(sortType)},
+	})
 	setCacheStatusHeader(w, cacheHit)
-	writeJSON(w, http.StatusOK, buildEntryListResponse(result, limit, offset, h.apiBasePath))
+	writeList(w, r, http.StatusOK, buildEntryListResponse(result, limit, offset, h.apiBasePath, maxTags))
 }
 
 func (h *TagHandler) handleTrendingTags(w http.ResponseWriter, r *http.Request) {
@@ -164,7 +234,7 @@ func (h *TagHandler) handleTrendingTags(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	limit, err := readQueryInt(r, "limit", 1, 100, 20)
+	limit, err := readQueryLimit(w, r, 1, 100, 20)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
@@ -209,7 +279,7 @@ func (h *TagHandler) handleClickedTags(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	limit, err := readQueryInt(r, "limit", 1, 100, 20)
+	limit, err := readQueryLimit(w, r, 1, 100, 20)
 	if err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
 		return
@@ -241,6 +311,7 @@ func (h *TagHandler) handleClickedTags(w http.ResponseWriter, r *http.Request) {
 var (
 	errServiceUnavailable = errors.New("service unavailable")
 	errInvalidTag         = errors.New("tag is required")
+	errInvalidEntryID     = errors.New("invalid entry id")
 )
 
 type tagsResponse struct {
@@ -250,8 +321,16 @@ type tagsResponse struct {
 }
 
 type tagItemResponse struct {
-	ID   domainTag.ID `json:"id"`
-	Name string       `json:"name"`
+	ID         domainTag.ID `json:"id"`
+	Name       string       `json:"name"`
+	EntryCount int          `json:"entry_count"`
+}
+
+type tagDetailResponse struct {
+	ID         domainTag.ID `json:"id"`
+	Name       string       `json:"name"`
+	EntryCount int          `json:"entry_count"`
+	ViewCount  int          `json:"view_count"`
 }
 
 type trendingTagsResponse struct {
@@ -11,6 +11,7 @@ import (
 
 	domainEntry "hateblog/internal/domain/entry"
 	domainTag "hateblog/internal/domain/tag"
+	usecaseTag "hateblog/internal/usecase/tag"
 )
 
 func TestTagHandler_GetEntriesByTag(t *testing.T) {
@@ -29,6 +30,7 @@ func TestTagHandler_GetEntriesByTag(t *testing.T) {
 		mockEntries    []*domainEntry.Entry
 		mockTotal      int64
 		wantStatus     int
+		wantError      string
 		wantEntryCount int
 		wantTotal      int64
 		wantLimit      int
@@ -106,8 +108,16 @@ func TestTagHandler_GetEntriesByTag(t *testing.T) {
 			name:         "error: tag not found",
 			tagPath:      "nonexistent",
 			queryParams:  "",
-			mockTagError: fmt.Errorf("tag not found"),
+			mockTagError: fmt.Errorf("%w: no rows", domainTag.ErrNotFound),
 			wantStatus:   http.StatusNotFound,
+			wantError:    "tag not found",
+		},
+		{
+			name:         "error: tag lookup fails",
+			tagPath:      tagName,
+			queryParams:  "",
+			mockTagError: fmt.Errorf("get tag by name: connection refused"),
+			wantStatus:   http.StatusInternalServerError,
 		},
 		{
 			name:        "error: empty tag",
@@ -203,7 +213,10 @@ func TestTagHandler_GetEntriesByTag(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.wantStatus != http.StatusOK {
-				assertErrorResponse(t, resp, tt.wantStatus)
+				errResp := assertErrorResponse(t, resp, tt.wantStatus)
+				if tt.wantError != "" && errResp["error"] != tt.wantError {
+					t.Errorf("error = %q, want %q", errResp["error"], tt.wantError)
+				}
 				return
 			}
 
@@ -293,6 +306,35 @@ func TestTagHandler_GetEntriesByTag_RecordView(t *testing.T) {
 	}
 }
 
+func TestTagHandler_GetEntriesByTag_FeedLink(t *testing.T) {
+	tagID := uuid.New()
+	mockTagRepo := &mockTagRepository{
+		getByNameFunc: func(ctx context.Context, name string) (*domainTag.Tag, error) {
+			return newTestTag(tagID, "c++"), nil
+		},
+	}
+	mockEntryRepo := &mockEntryRepository{
+		entries: []*domainEntry.Entry{newTestEntry(uuid.New(), "Entry", 100)},
+		total:   1,
+	}
+
+	handler := NewTagHandler(newTestTagService(mockTagRepo), newTestEntryService(mockEntryRepo), testAPIBasePath).
+		WithFeedBaseURL("https://feeds.example.com/rss/")
+	ts := newTestServer(RouterConfig{
+		TagHandler: handler,
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/tags/entries/c++?min_users=50&sort=hot&limit=10"))
+	defer resp.Body.Close()
+
+	assertStatus(t, resp, http.StatusOK)
+	want := `<https://feeds.example.com/rss/tags/entries/c++?min_users=50&sort=hot>; rel="alternate"; type="application/rss+xml"`
+	if got := resp.Header.Get("Link"); got != want {
+		t.Errorf("Link = %q, want %q", got, want)
+	}
+}
+
 func TestTagHandler_GetEntriesByTag_RecordViewError(t *testing.T) {
 	tagID := uuid.New()
 	tagName := "programming"
@@ -393,6 +435,29 @@ func TestTagHandler_BoundaryValues(t *testing.T) {
 	}
 }
 
+func TestTagHandler_ListTagsConfiguredMaxLimit(t *testing.T) {
+	tagService := newTestTagService(&mockTagRepository{}).WithMaxListLimit(20)
+	ts := newTestServer(RouterConfig{
+		TagHandler: NewTagHandler(tagService, nil, testAPIBasePath),
+	})
+	defer ts.Close()
+
+	for query, want := range map[string]int{"": 20, "?limit=20": 20} {
+		resp := ts.get(t, apiPath("/tags"+query))
+		assertStatus(t, resp, http.StatusOK)
+		var result tagsResponse
+		decodeJSON(t, resp, &result)
+		resp.Body.Close()
+		if result.Limit != want {
+			t.Errorf("/tags%s limit = %d, want %d", query, result.Limit, want)
+		}
+	}
+
+	resp := ts.get(t, apiPath("/tags?limit=21"))
+	defer resp.Body.Close()
+	assertErrorResponse(t, resp, http.StatusBadRequest)
+}
+
 func TestTagHandler_ListTags(t *testing.T) {
 	tag1 := newTestTag(uuid.New(), "programming")
 	tag2 := newTestTag(uuid.New(), "golang")
@@ -416,7 +481,7 @@ func TestTagHandler_ListTags(t *testing.T) {
 			},
 			wantStatus: http.StatusOK,
 			wantCount:  3,
-			wantLimit:  defaultTagListLimit,
+			wantLimit:  usecaseTag.DefaultListLimit,
 			wantOffset: 0,
 		},
 		{
@@ -447,7 +512,7 @@ func TestTagHandler_ListTags(t *testing.T) {
 			mockTags:    []domainTag.Tag{},
 			wantStatus:  http.StatusOK,
 			wantCount:   0,
-			wantLimit:   defaultTagListLimit,
+			wantLimit:   usecaseTag.DefaultListLimit,
 			wantOffset:  0,
 		},
 		{
@@ -460,6 +525,14 @@ func TestTagHandler_ListTags(t *testing.T) {
 			queryParams: "?limit=0",
 			wantStatus:  http.StatusBadRequest,
 		},
+		{
+			name:        "success with limit at max",
+			queryParams: "?limit=200",
+			mockTags:    []domainTag.Tag{*tag1},
+			wantStatus:  http.StatusOK,
+			wantCount:   1,
+			wantLimit:   usecaseTag.MaxListLimit,
+		},
 		{
 			name:        "error: limit too large",
 			queryParams: "?limit=201",
@@ -555,6 +628,63 @@ func TestTagHandler_ListTags_ServiceError(t *testing.T) {
 	assertErrorResponse(t, resp, http.StatusInternalServerError)
 }
 
+func TestTagHandler_ListTags_EntryCounts(t *testing.T) {
+	tag1 := newTestTag(uuid.New(), "golang")
+	tag2 := newTestTag(uuid.New(), "rust")
+	var countedIDs []domainTag.ID
+	mockRepo := &mockTagRepository{
+		tags: []domainTag.Tag{*tag1, *tag2},
+		entryCountsFunc: func(ctx context.Context, tagIDs []domainTag.ID) (map[domainTag.ID]int, error) {
+			countedIDs = tagIDs
+			return map[domainTag.ID]int{tag1.ID: 12}, nil
+		},
+	}
+
+	ts := newTestServer(RouterConfig{
+		TagHandler: NewTagHandler(newTestTagService(mockRepo), nil, testAPIBasePath),
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/tags"))
+	defer resp.Body.Close()
+	assertStatus(t, resp, http.StatusOK)
+
+	var result tagsResponse
+	decodeJSON(t, resp, &result)
+	if len(result.Tags) != 2 {
+		t.Fatalf("got %d tags, want 2", len(result.Tags))
+	}
+	if result.Tags[0].EntryCount != 12 {
+		t.Errorf("%s entry_count = %d, want 12", result.Tags[0].Name, result.Tags[0].EntryCount)
+	}
+	// Tags without entries are absent from the counts and reported as zero.
+	if result.Tags[1].EntryCount != 0 {
+		t.Errorf("%s entry_count = %d, want 0", result.Tags[1].Name, result.Tags[1].EntryCount)
+	}
+	if len(countedIDs) != 2 {
+		t.Errorf("counted %d tags in one query, want 2", len(countedIDs))
+	}
+}
+
+func TestTagHandler_ListTags_EntryCountsError(t *testing.T) {
+	mockRepo := &mockTagRepository{
+		tags: []domainTag.Tag{*newTestTag(uuid.New(), "golang")},
+		entryCountsFunc: func(ctx context.Context, tagIDs []domainTag.ID) (map[domainTag.ID]int, error) {
+			return nil, fmt.Errorf("database error")
+		},
+	}
+
+	ts := newTestServer(RouterConfig{
+		TagHandler: NewTagHandler(newTestTagService(mockRepo), nil, testAPIBasePath),
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/tags"))
+	defer resp.Body.Close()
+
+	assertErrorResponse(t, resp, http.StatusInternalServerError)
+}
+
 func TestTagHandler_ListTags_NilService(t *testing.T) {
 	handler := NewTagHandler(nil, nil, testAPIBasePath)
 
@@ -568,3 +698,107 @@ func TestTagHandler_ListTags_NilService(t *testing.T) {
 
 	assertErrorResponse(t, resp, http.StatusInternalServerError)
 }
+
+func TestTagHandler_GetTag(t *testing.T) {
+	tagID := uuid.New()
+	var requestedName string
+	var usageTagID domainTag.ID
+	mockTagRepo := &mockTagRepository{
+		getByNameFunc: func(ctx context.Context, name string) (*domainTag.Tag, error) {
+			requestedName = name
+			return newTestTag(tagID, name), nil
+		},
+		getUsageFunc: func(ctx context.Context, id domainTag.ID) (domainTag.Usage, error) {
+			usageTagID = id
+			return domainTag.Usage{EntryCount: 42, ViewCount: 1234}, nil
+		},
+	}
+
+	ts := newTestServer(RouterConfig{
+		TagHandler: NewTagHandler(newTestTagService(mockTagRepo), nil, testAPIBasePath),
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/tags/Go%20"))
+	defer resp.Body.Close()
+
+	assertStatus(t, resp, http.StatusOK)
+	var body tagDetailResponse
+	decodeJSON(t, resp, &body)
+
+	if requestedName != "go" {
+		t.Errorf("looked up name = %q, want normalized %q", requestedName, "go")
+	}
+	if usageTagID != tagID {
+		t.Errorf("usage looked up for %v, want %v", usageTagID, tagID)
+	}
+	want := tagDetailResponse{ID: tagID, Name: "go", EntryCount: 42, ViewCount: 1234}
+	if body != want {
+		t.Errorf("body = %+v, want %+v", body, want)
+	}
+}
+
+func TestTagHandler_GetTag_NotFound(t *testing.T) {
+	usageCalled := false
+	mockTagRepo := &mockTagRepository{
+		getUsageFunc: func(ctx context.Context, id domainTag.ID) (domainTag.Usage, error) {
+			usageCalled = true
+			return domainTag.Usage{}, nil
+		},
+	}
+
+	ts := newTestServer(RouterConfig{
+		TagHandler: NewTagHandler(newTestTagService(mockTagRepo), nil, testAPIBasePath),
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/tags/unknown"))
+	defer resp.Body.Close()
+
+	errResp := assertErrorResponse(t, resp, http.StatusNotFound)
+	if errResp["error"] != "tag not found" {
+		t.Errorf("error = %q, want %q", errResp["error"], "tag not found")
+	}
+	if usageCalled {
+		t.Error("usage should not be looked up for a missing tag")
+	}
+}
+
+func TestTagHandler_GetTag_LookupError(t *testing.T) {
+	mockTagRepo := &mockTagRepository{
+		getByNameFunc: func(ctx context.Context, name string) (*domainTag.Tag, error) {
+			return nil, fmt.Errorf("get tag by name: connection refused")
+		},
+	}
+
+	ts := newTestServer(RouterConfig{
+		TagHandler: NewTagHandler(newTestTagService(mockTagRepo), nil, testAPIBasePath),
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/tags/go"))
+	defer resp.Body.Close()
+
+	assertErrorResponse(t, resp, http.StatusInternalServerError)
+}
+
+func TestTagHandler_GetTag_UsageError(t *testing.T) {
+	mockTagRepo := &mockTagRepository{
+		getByNameFunc: func(ctx context.Context, name string) (*domainTag.Tag, error) {
+			return newTestTag(uuid.New(), name), nil
+		},
+		getUsageFunc: func(ctx context.Context, id domainTag.ID) (domainTag.Usage, error) {
+			return domainTag.Usage{}, fmt.Errorf("database error")
+		},
+	}
+
+	ts := newTestServer(RouterConfig{
+		TagHandler: NewTagHandler(newTestTagService(mockTagRepo), nil, testAPIBasePath),
+	})
+	defer ts.Close()
+
+	resp := ts.get(t, apiPath("/tags/go"))
+	defer resp.Body.Close()
+
+	assertErrorResponse(t, resp, http.StatusInternalServerError)
+}
@@ -62,6 +62,21 @@ func (ts *testServer) get(t *testing.T, path string) *http.Response {
 	return resp
 }
 
+// getWithHeader performs a GET request to the test server with one extra request header.
+func (ts *testServer) getWithHeader(t *testing.T, path, key, value string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	req.Header.Set(key, value)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	return resp
+}
+
 // decodeJSON decodes response body as JSON.
 func decodeJSON(t *testing.T, resp *http.Response, dest interface{}) {
 	t.Helper()
@@ -92,11 +107,14 @@ func assertContentType(t *testing.T, resp *http.Response, want string) {
 
 // mockEntryRepository is a mock implementation of entry repository.
 type mockEntryRepository struct {
-	listFunc  func(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error)
-	countFunc func(ctx context.Context, query domainEntry.ListQuery) (int64, error)
-	getFunc   func(ctx context.Context, id domainEntry.ID) (*domainEntry.Entry, error)
-	entries   []*domainEntry.Entry
-	total     int64
+	listFunc          func(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error)
+	countFunc         func(ctx context.Context, query domainEntry.ListQuery) (int64, error)
+	getFunc           func(ctx context.Context, id domainEntry.ID) (*domainEntry.Entry, error)
+	getByIDsFunc      func(ctx context.Context, ids []domainEntry.ID) ([]*domainEntry.Entry, error)
+	listUntaggedFunc  func(ctx context.Context, limit, offset int) ([]*domainEntry.Entry, error)
+	countUntaggedFunc func(ctx context.Context) (int64, error)
+	entries           []*domainEntry.Entry
+	total             int64
 }
 
 func (m *mockEntryRepository) Get(ctx context.Context, id domainEntry.ID) (*domainEntry.Entry, error) {
@@ -112,6 +130,22 @@ func (m *mockEntryRepository) Get(ctx context.Context, id domainEntry.ID) (*doma
 	return nil, fmt.Errorf("entry not found")
 }
 
+func (m *mockEntryRepository) GetByIDs(ctx context.Context, ids []domainEntry.ID) ([]*domainEntry.Entry, error) {
+	if m.getByIDsFunc != nil {
+		return m.getByIDsFunc(ctx, ids)
+	}
+	entries := make([]*domainEntry.Entry, 0, len(ids))
+	for _, id := range ids {
+		for _, entry := range m.entries {
+			if entry.ID == id {
+				entries = append(entries, entry)
+				break
+			}
+		}
+	}
+	return entries, nil
+}
+
 func (m *mockEntryRepository) List(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
 	if m.listFunc != nil {
 		return m.listFunc(ctx, query)
@@ -152,6 +186,20 @@ func (m *mockEntryRepository) ListArchiveCounts(ctx context.Context, minBookmark
 	return nil, nil
 }
 
+func (m *mockEntryRepository) ListUntagged(ctx context.Context, limit, offset int) ([]*domainEntry.Entry, error) {
+	if m.listUntaggedFunc != nil {
+		return m.listUntaggedFunc(ctx, limit, offset)
+	}
+	return m.List(ctx, domainEntry.ListQuery{Limit: limit, Offset: offset})
+}
+
+func (m *mockEntryRepository) CountUntagged(ctx context.Context) (int64, error) {
+	if m.countUntaggedFunc != nil {
+		return m.countUntaggedFunc(ctx)
+	}
+	return m.total, nil
+}
+
 // mockTagRepository is a mock implementation of tag repository.
 type mockTagRepository struct {
 	getByNameFunc            func(ctx context.Context, name string) (*domainTag.Tag, error)
@@ -159,6 +207,8 @@ type mockTagRepository struct {
 	incrementViewHistoryFunc func(ctx context.Context, tagID domainTag.ID, viewedAt time.Time) error
 	getTrendingFunc          func(ctx context.Context, hours int, minBookmarkCount int, limit int) ([]domainTag.TrendingTag, error)
 	getClickedFunc           func(ctx context.Context, days int, limit int) ([]domainTag.ClickedTag, error)
+	getUsageFunc             func(ctx context.Context, tagID domainTag.ID) (domainTag.Usage, error)
+	entryCountsFunc          func(ctx context.Context, tagIDs []domainTag.ID) (map[domainTag.ID]int, error)
 	tags                     []domainTag.Tag
 	err                      error
 }
@@ -167,7 +217,7 @@ func (m *mockTagRepository) GetByName(ctx context.Context, name string) (*domain
 	if m.getByNameFunc != nil {
 		return m.getByNameFunc(ctx, name)
 	}
-	return nil, fmt.Errorf("tag not found")
+	return nil, fmt.Errorf("%w: no rows", domainTag.ErrNotFound)
 }
 
 func (m *mockTagRepository) List(ctx context.Context, limit, offset int) ([]domainTag.Tag, error) {
@@ -187,6 +237,20 @@ func (m *mockTagRepository) IncrementViewHistory(ctx context.Context, tagID doma
 	return nil
 }
 
+func (m *mockTagRepository) GetUsage(ctx context.Context, tagID domainTag.ID) (domainTag.Usage, error) {
+	if m.getUsageFunc != nil {
+		return m.getUsageFunc(ctx, tagID)
+	}
+	return domainTag.Usage{}, nil
+}
+
+func (m *mockTagRepository) EntryCounts(ctx context.Context, tagIDs []domainTag.ID) (map[domainTag.ID]int, error) {
+	if m.entryCountsFunc != nil {
+		return m.entryCountsFunc(ctx, tagIDs)
+	}
+	return map[domainTag.ID]int{}, nil
+}
+
 func (m *mockTagRepository) GetTrending(ctx context.Context, hours int, minBookmarkCount int, limit int) ([]domainTag.TrendingTag, error) {
 	if m.getTrendingFunc != nil {
 		return m.getTrendingFunc(ctx, hours, minBookmarkCount, limit)
@@ -201,6 +265,39 @@ func (m *mockTagRepository) GetClicked(ctx context.Context, days int, limit int)
 	return nil, nil
 }
 
+// mockTagLinker is a mock implementation of usecaseEntry.TagLinker.
+type mockTagLinker struct {
+	attachEntryFunc func(ctx context.Context, entryID domainEntry.ID, tagID domainTag.ID, score int) error
+	detachEntryFunc func(ctx context.Context, entryID domainEntry.ID, tagName string) (bool, error)
+	updateScoreFunc func(ctx context.Context, entryID domainEntry.ID, tagName string, score int) (bool, error)
+}
+
+func (m *mockTagLinker) Upsert(ctx context.Context, t *domainTag.Tag) error {
+	t.ID = uuid.NewSHA1(uuid.Nil, []byte(t.Name))
+	return nil
+}
+
+func (m *mockTagLinker) AttachEntry(ctx context.Context, entryID domainEntry.ID, tagID domainTag.ID, score int) error {
+	if m.attachEntryFunc != nil {
+		return m.attachEntryFunc(ctx, entryID, tagID, score)
+	}
+	return nil
+}
+
+func (m *mockTagLinker) DetachEntry(ctx context.Context, entryID domainEntry.ID, tagName string) (bool, error) {
+	if m.detachEntryFunc != nil {
+		return m.detachEntryFunc(ctx, entryID, tagName)
+	}
+	return true, nil
+}
+
+func (m *mockTagLinker) UpdateEntryScore(ctx context.Context, entryID domainEntry.ID, tagName string, score int) (bool, error) {
+	if m.updateScoreFunc != nil {
+		return m.updateScoreFunc(ctx, entryID, tagName, score)
+	}
+	return true, nil
+}
+
 // mockSearchHistoryRepository is a mock implementation of search history repository.
 type mockSearchHistoryRepository struct {
 	recordFunc func(ctx context.Context, query string, searchedAt time.Time) error
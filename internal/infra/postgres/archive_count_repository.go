@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hateblog/internal/pkg/batchutil"
+)
+
+// ArchiveCountsLock names the advisory lock held by every transaction writing archive_counts.
+// The fetcher's per-day refresh and the admin rebuild would otherwise race: two refreshes of
+// the same day both delete, then the second insert fails on the primary key.
+const ArchiveCountsLock = "archive_counts"
+
+// ArchiveCountDiff is a (day, threshold) row whose stored archive_counts value differs from
+// the count recomputed from entries. A zero Stored means the row is missing; a zero Actual
+// means the row should no longer exist.
+type ArchiveCountDiff struct {
+	Day       time.Time
+	Threshold int
+	Stored    int
+	Actual    int
+}
+
+// ArchiveCountRepository maintains the archive_counts aggregate table.
+type ArchiveCountRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewArchiveCountRepository creates a new repository.
+func NewArchiveCountRepository(pool *pgxpool.Pool) *ArchiveCountRepository {
+	return &ArchiveCountRepository{pool: pool}
+}
+
+// Diff recomputes the per-day counts from entries and returns only the rows that drifted,
+// ordered by day and threshold.
+func (r *ArchiveCountRepository) Diff(ctx context.Context) ([]ArchiveCountDiff, error) {
+	const query = `
+WITH actual AS (
+	SELECT DATE(created_at) AS day, t.threshold, COUNT(1)::int AS count
+	FROM entries
+	CROSS JOIN (VALUES (5), (10), (50), (100), (500), (1000)) AS t(threshold)
+	WHERE entries.bookmark_count >= t.threshold
+	GROUP BY day, t.threshold
+)
+SELECT
+	COALESCE(a.day, s.day) AS day,
+	COALESCE(a.threshold, s.threshold) AS threshold,
+	COALESCE(s.count, 0) AS stored,
+	COALESCE(a.count, 0) AS actual
+FROM actual a
+FULL OUTER JOIN archive_counts s ON s.day = a.day AND s.threshold = a.threshold
+WHERE a.count IS DISTINCT FROM s.count
+ORDER BY day, threshold`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("archive count diff: %w", err)
+	}
+	defer rows.Close()
+
+	var diffs []ArchiveCountDiff
+	for rows.Next() {
+		var d ArchiveCountDiff
+		if err := rows.Scan(&d.Day, &d.Threshold, &d.Stored, &d.Actual); err != nil {
+			return nil, fmt.Errorf("scan archive count diff: %w", err)
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs, rows.Err()
+}
+
+// ApplyDiff writes the Actual values of diffs in one transaction and returns the number of
+// rows changed. Rows whose Actual count is zero are deleted.
+func (r *ArchiveCountRepository) ApplyDiff(ctx context.Context, diffs []ArchiveCountDiff) (int64, error) {
+	if len(diffs) == 0 {
+		return 0, nil
+	}
+	const (
+		upsertQuery = `
+INSERT INTO archive_counts (day, threshold, count)
+VALUES ($1, $2, $3)
+ON CONFLICT (day, threshold) DO UPDATE SET count = EXCLUDED.count`
+		deleteQuery = `DELETE FROM archive_counts WHERE day = $1 AND threshold = $2`
+	)
+
+	var changed int64
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if err := batchutil.AdvisoryXactLock(ctx, tx, ArchiveCountsLock); err != nil {
+			return err
+		}
+		for _, d := range diffs {
+			var (
+				ct  pgconn.CommandTag
+				err error
+			)
+			if d.Actual == 0 {
+				ct, err = tx.Exec(ctx, deleteQuery, d.Day, d.Threshold)
+			} else {
+				ct, err = tx.Exec(ctx, upsertQuery, d.Day, d.Threshold, d.Actual)
+			}
+			if err != nil {
+				return fmt.Errorf("apply archive count diff day=%s threshold=%d: %w",
+					d.Day.Format("2006-01-02"), d.Threshold, err)
+			}
+			changed += ct.RowsAffected()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+// RefreshDay recomputes the counts of a single day in its own transaction and returns the
+// number of rows written.
+func (r *ArchiveCountRepository) RefreshDay(ctx context.Context, day time.Time) (int64, error) {
+	const (
+		deleteQuery = `
+DELETE FROM archive_counts
+WHERE day = DATE($1)`
+		insertQuery = `
+INSERT INTO archive_counts (day, threshold, count)
+SELECT DATE($1) AS day, t.threshold, COUNT(1)
+FROM entries
+CROSS JOIN (VALUES (5), (10), (50), (100), (500), (1000)) AS t(threshold)
+WHERE DATE(entries.created_at) = DATE($1)
+  AND entries.bookmark_count >= t.threshold
+GROUP BY t.threshold`
+	)
+
+	var written int64
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if err := batchutil.AdvisoryXactLock(ctx, tx, ArchiveCountsLock); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, deleteQuery, day); err != nil {
+			return err
+		}
+		ct, err := tx.Exec(ctx, insertQuery, day)
+		if err != nil {
+			return err
+		}
+		written = ct.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("refresh archive counts day=%s: %w", day.Format("2006-01-02"), err)
+	}
+	return written, nil
+}
+
+// RefreshDays refreshes each day separately so that no transaction spans the whole window.
+func (r *ArchiveCountRepository) RefreshDays(ctx context.Context, days []time.Time) (int64, error) {
+	var written int64
+	for _, day := range days {
+		n, err := r.RefreshDay(ctx, day)
+		if err != nil {
+			return written, err
+		}
+		written += n
+	}
+	return written, nil
+}
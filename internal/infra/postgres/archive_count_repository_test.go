@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainEntry "hateblog/internal/domain/entry"
+)
+
+func TestArchiveCountRepository_Diff(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+
+	repo := NewArchiveCountRepository(pool)
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	yesterday := today.Add(-24 * time.Hour)
+
+	seed := func(t *testing.T) {
+		t.Helper()
+		cleanupTables(t, pool)
+		insertEntry(t, pool, testEntry(func(e *domainEntry.Entry) {
+			e.BookmarkCount = 10
+			e.CreatedAt = today
+		}))
+		insertEntry(t, pool, testEntry(func(e *domainEntry.Entry) {
+			e.BookmarkCount = 5
+			e.CreatedAt = yesterday
+		}))
+		refreshArchiveCounts(t, pool)
+	}
+
+	t.Run("returns nothing when in sync", func(t *testing.T) {
+		seed(t)
+
+		diffs, err := repo.Diff(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, diffs)
+	})
+
+	t.Run("reports changed, missing and stale rows", func(t *testing.T) {
+		seed(t)
+		// Drift: a new entry today, a stale row for a day without entries,
+		// and a lost row for yesterday.
+		insertEntry(t, pool, testEntry(func(e *domainEntry.Entry) {
+			e.BookmarkCount = 50
+			e.CreatedAt = today.Add(time.Hour)
+		}))
+		stale := today.Add(-48 * time.Hour)
+		_, err := pool.Exec(ctx, `INSERT INTO archive_counts (day, threshold, count) VALUES ($1, 5, 3)`, stale)
+		require.NoError(t, err)
+		_, err = pool.Exec(ctx, `DELETE FROM archive_counts WHERE day = $1`, yesterday)
+		require.NoError(t, err)
+
+		diffs, err := repo.Diff(ctx)
+		require.NoError(t, err)
+		require.Len(t, diffs, 5)
+
+		type key struct {
+			day       time.Time
+			threshold int
+		}
+		got := make(map[key][2]int, len(diffs))
+		for _, d := range diffs {
+			got[key{d.Day.UTC(), d.Threshold}] = [2]int{d.Stored, d.Actual}
+		}
+		assert.Equal(t, map[key][2]int{
+			{stale, 5}:     {3, 0},
+			{yesterday, 5}: {0, 1},
+			{today, 5}:     {1, 2},
+			{today, 10}:    {1, 2},
+			{today, 50}:    {0, 1},
+		}, got)
+
+		changed, err := repo.ApplyDiff(ctx, diffs)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), changed)
+
+		diffs, err = repo.Diff(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, diffs)
+	})
+
+	t.Run("apply with no diffs is a no-op", func(t *testing.T) {
+		changed, err := repo.ApplyDiff(ctx, nil)
+		require.NoError(t, err)
+		assert.Zero(t, changed)
+	})
+}
+
+func TestArchiveCountRepository_RefreshDays(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+	cleanupTables(t, pool)
+
+	repo := NewArchiveCountRepository(pool)
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	days := []time.Time{today.AddDate(0, 0, -2), today.AddDate(0, 0, -1), today}
+	outside := today.AddDate(0, 0, -3)
+
+	for _, day := range append([]time.Time{outside}, days...) {
+		insertEntry(t, pool, testEntry(func(e *domainEntry.Entry) {
+			e.BookmarkCount = 10
+			e.CreatedAt = day
+		}))
+	}
+	refreshArchiveCounts(t, pool)
+
+	// New entries land inside and outside the window after the last refresh.
+	for _, day := range []time.Time{outside, days[0], days[2]} {
+		insertEntry(t, pool, testEntry(func(e *domainEntry.Entry) {
+			e.BookmarkCount = 5
+			e.CreatedAt = day.Add(time.Hour)
+		}))
+	}
+
+	written, err := repo.RefreshDays(ctx, days)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), written)
+
+	diffs, err := repo.Diff(ctx)
+	require.NoError(t, err)
+	require.Len(t, diffs, 1, "only the day outside the window should remain stale")
+	assert.True(t, diffs[0].Day.UTC().Equal(outside))
+	assert.Equal(t, 5, diffs[0].Threshold)
+	assert.Equal(t, 1, diffs[0].Stored)
+	assert.Equal(t, 2, diffs[0].Actual)
+}
+
+func TestArchiveCountRepository_ConcurrentRefresh(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+	cleanupTables(t, pool)
+
+	repo := NewArchiveCountRepository(pool)
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	days := []time.Time{today.AddDate(0, 0, -1), today}
+	for _, day := range days {
+		for _, count := range []int{5, 10, 50} {
+			insertEntry(t, pool, testEntry(func(e *domainEntry.Entry) {
+				e.BookmarkCount = count
+				e.CreatedAt = day.Add(time.Hour)
+			}))
+		}
+	}
+
+	// Two refreshers of the same days, as the fetcher and admin archive refresh-recent may run.
+	// Without the lock, the second delete misses the rows the first inserts and its insert
+	// fails on the primary key.
+	const rounds = 20
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			for j := 0; j < rounds; j++ {
+				if _, err := repo.RefreshDays(ctx, days); err != nil {
+					errs <- err
+					return
+				}
+			}
+			errs <- nil
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	diffs, err := repo.Diff(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+}
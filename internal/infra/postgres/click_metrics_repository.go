@@ -41,3 +41,78 @@ SET count = click_metrics.count + 1`
 	}
 	return nil
 }
+
+// SumSince returns the total number of clicks on or after the day of from.
+func (r *ClickMetricsRepository) SumSince(ctx context.Context, from time.Time) (int64, error) {
+	const query = `
+SELECT COALESCE(SUM(count), 0)
+FROM click_metrics
+WHERE clicked_at >= $1`
+	var total int64
+	if err := r.pool.QueryRow(ctx, query, apptime.TruncateToDay(from)).Scan(&total); err != nil {
+		return 0, fmt.Errorf("sum click metrics: %w", err)
+	}
+	return total, nil
+}
+
+// TopEntriesSince returns the most clicked entries on or after the day of from, ordered by
+// clicks and then by entry ID.
+func (r *ClickMetricsRepository) TopEntriesSince(ctx context.Context, from time.Time, limit int) ([]entry.ClickedEntry, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	const query = `
+SELECT e.id, e.title, e.url, SUM(cm.count) AS clicks
+FROM click_metrics cm
+INNER JOIN entries e ON e.id = cm.entry_id
+WHERE cm.clicked_at >= $1
+GROUP BY e.id, e.title, e.url
+ORDER BY clicks DESC, e.id
+LIMIT $2`
+	rows, err := r.pool.Query(ctx, query, apptime.TruncateToDay(from), limit)
+	if err != nil {
+		return nil, fmt.Errorf("top clicked entries: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]entry.ClickedEntry, 0, limit)
+	for rows.Next() {
+		var e entry.ClickedEntry
+		if err := rows.Scan(&e.ID, &e.Title, &e.URL, &e.ClickCount); err != nil {
+			return nil, fmt.Errorf("scan top clicked entry: %w", err)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// CountByEntries returns the all-time click totals of the given entries. Entries that were
+// never clicked are absent from the map.
+func (r *ClickMetricsRepository) CountByEntries(ctx context.Context, ids []entry.ID) (map[entry.ID]int64, error) {
+	out := make(map[entry.ID]int64, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	const query = `
+SELECT entry_id, SUM(count)
+FROM click_metrics
+WHERE entry_id = ANY($1::uuid[])
+GROUP BY entry_id`
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("count clicks by entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id     entry.ID
+			clicks int64
+		)
+		if err := rows.Scan(&id, &clicks); err != nil {
+			return nil, fmt.Errorf("scan entry clicks: %w", err)
+		}
+		out[id] = clicks
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainEntry "hateblog/internal/domain/entry"
+	"hateblog/internal/pkg/apptime"
+	usecaseMetrics "hateblog/internal/usecase/metrics"
+)
+
+func TestMetricsSummary_Rollup(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+	cleanupTables(t, pool)
+	_, err := pool.Exec(ctx, "TRUNCATE TABLE search_history")
+	require.NoError(t, err)
+
+	today := apptime.TruncateToDay(time.Now())
+	popular := testEntry(func(e *domainEntry.Entry) { e.Title = "popular" })
+	quiet := testEntry(func(e *domainEntry.Entry) { e.Title = "quiet" })
+	stale := testEntry(func(e *domainEntry.Entry) { e.Title = "stale" })
+	for _, e := range []*domainEntry.Entry{popular, quiet, stale} {
+		insertEntry(t, pool, e)
+	}
+
+	clicks := NewClickMetricsRepository(pool)
+	searches := NewSearchHistoryRepository(pool)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, clicks.Increment(ctx, popular.ID, today))
+	}
+	require.NoError(t, clicks.Increment(ctx, quiet.ID, today))
+	require.NoError(t, searches.Record(ctx, "go", today))
+	require.NoError(t, searches.Record(ctx, "Go", today))
+
+	seed := func(query string, args ...any) {
+		t.Helper()
+		_, err := pool.Exec(ctx, query, args...)
+		require.NoError(t, err)
+	}
+	const insertClicks = `INSERT INTO click_metrics (entry_id, clicked_at, count) VALUES ($1, $2, $3)`
+	const insertSearches = `INSERT INTO search_history (query, searched_at, count) VALUES ($1, $2, $3)`
+	seed(insertClicks, popular.ID, today.AddDate(0, 0, -6), 4)
+	seed(insertClicks, quiet.ID, today.AddDate(0, 0, -3), 1)
+	seed(insertClicks, stale.ID, today.AddDate(0, 0, -7), 100)
+	seed(insertSearches, "rust", today.AddDate(0, 0, -2), 5)
+	seed(insertSearches, "rust", today.AddDate(0, 0, -10), 50)
+
+	svc := usecaseMetrics.NewService(NewEntryRepository(pool), clicks).WithSummary(clicks, searches, nil)
+	summary, hit, err := svc.Summary(ctx)
+	require.NoError(t, err)
+	assert.False(t, hit)
+
+	assert.Equal(t, int64(4), summary.ClicksToday)
+	assert.Equal(t, int64(9), summary.Clicks7Days)
+	assert.Equal(t, int64(2), summary.SearchesToday)
+	assert.Equal(t, int64(7), summary.Searches7Days)
+
+	require.Len(t, summary.TopEntries, 2)
+	assert.Equal(t, popular.ID, summary.TopEntries[0].ID)
+	assert.Equal(t, "popular", summary.TopEntries[0].Title)
+	assert.Equal(t, int64(7), summary.TopEntries[0].ClickCount)
+	assert.Equal(t, quiet.ID, summary.TopEntries[1].ID)
+	assert.Equal(t, int64(2), summary.TopEntries[1].ClickCount)
+}
+
+func TestClickMetricsRepository_CountByEntries(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+	cleanupTables(t, pool)
+
+	today := apptime.TruncateToDay(time.Now())
+	clicked := testEntry(func(e *domainEntry.Entry) { e.Title = "clicked" })
+	unclicked := testEntry(func(e *domainEntry.Entry) { e.Title = "unclicked" })
+	other := testEntry(func(e *domainEntry.Entry) { e.Title = "other" })
+	for _, e := range []*domainEntry.Entry{clicked, unclicked, other} {
+		insertEntry(t, pool, e)
+	}
+
+	repo := NewClickMetricsRepository(pool)
+	require.NoError(t, repo.Increment(ctx, clicked.ID, today))
+	require.NoError(t, repo.Increment(ctx, clicked.ID, today.AddDate(0, 0, -30)))
+	require.NoError(t, repo.Increment(ctx, clicked.ID, today.AddDate(-1, 0, 0)))
+	require.NoError(t, repo.Increment(ctx, other.ID, today))
+
+	counts, err := repo.CountByEntries(ctx, []domainEntry.ID{clicked.ID, unclicked.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[domainEntry.ID]int64{clicked.ID: 3}, counts)
+
+	counts, err = repo.CountByEntries(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+}
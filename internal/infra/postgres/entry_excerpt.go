@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"hateblog/internal/domain/entry"
+	"hateblog/internal/pkg/apptime"
+)
+
+// missingExcerptCondition matches entries without a usable excerpt.
+const missingExcerptCondition = `(excerpt IS NULL OR btrim(excerpt) = '')`
+
+// CountMissingExcerpt returns the number of entries without an excerpt.
+func (r *EntryRepository) CountMissingExcerpt(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM entries WHERE `+missingExcerptCondition).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count entries missing excerpt: %w", err)
+	}
+	return count, nil
+}
+
+// ListMissingExcerpt returns up to limit entries without an excerpt whose IDs come after
+// after, in ID order, so that a backfill walks them once even when some cannot be filled.
+func (r *EntryRepository) ListMissingExcerpt(ctx context.Context, after uuid.UUID, limit int) ([]*entry.Entry, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	query := `
+SELECT id, title, url, posted_at, bookmark_count, excerpt, subject, created_at, updated_at
+FROM entries
+WHERE id > $1 AND ` + missingExcerptCondition + `
+ORDER BY id
+LIMIT $2`
+	rows, err := r.pool.Query(ctx, query, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list entries missing excerpt: %w", err)
+	}
+	defer rows.Close()
+	return scanEntries(rows)
+}
+
+// FillExcerpt stores excerpt on e and recomputes its search_text. It reports false without
+// writing when the entry is gone or got an excerpt in the meantime.
+func (r *EntryRepository) FillExcerpt(ctx context.Context, e *entry.Entry, excerpt string) (bool, error) {
+	if e == nil || e.ID == uuid.Nil {
+		return false, fmt.Errorf("entry id is required")
+	}
+	excerpt = strings.TrimSpace(excerpt)
+	if excerpt == "" {
+		return false, fmt.Errorf("excerpt is required")
+	}
+	query := `
+UPDATE entries
+SET excerpt = $2,
+	search_text = $3,
+	updated_at = $4
+WHERE id = $1 AND ` + missingExcerptCondition
+	ct, err := r.pool.Exec(ctx, query,
+		e.ID,
+		excerpt,
+		nullableString(entry.BuildSearchText(e.Title, excerpt, e.URL)),
+		apptime.Now(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("fill excerpt: %w", err)
+	}
+	return ct.RowsAffected() == 1, nil
+}
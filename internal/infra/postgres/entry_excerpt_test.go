@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainEntry "hateblog/internal/domain/entry"
+)
+
+func TestEntryRepository_MissingExcerpt(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+	cleanupTables(t, pool)
+
+	null := testEntry(func(e *domainEntry.Entry) { e.Title = "Null Excerpt"; e.Excerpt = "" })
+	blank := testEntry(func(e *domainEntry.Entry) { e.Title = "Blank Excerpt" })
+	filled := testEntry(func(e *domainEntry.Entry) { e.Excerpt = "already here" })
+	for _, e := range []*domainEntry.Entry{null, blank, filled} {
+		insertEntry(t, pool, e)
+	}
+	_, err := pool.Exec(ctx, `UPDATE entries SET excerpt = '  ' WHERE id = $1`, blank.ID)
+	require.NoError(t, err)
+
+	repo := NewEntryRepository(pool)
+	count, err := repo.CountMissingExcerpt(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	first, err := repo.ListMissingExcerpt(ctx, uuid.Nil, 1)
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	rest, err := repo.ListMissingExcerpt(ctx, first[0].ID, 10)
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.ElementsMatch(t, []uuid.UUID{null.ID, blank.ID}, []uuid.UUID{first[0].ID, rest[0].ID})
+
+	ok, err := repo.FillExcerpt(ctx, null, "Backfilled description")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	got, err := repo.Get(ctx, null.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Backfilled description", got.Excerpt)
+	var searchText string
+	require.NoError(t, pool.QueryRow(ctx, `SELECT search_text FROM entries WHERE id = $1`, null.ID).Scan(&searchText))
+	assert.Equal(t, domainEntry.BuildSearchText(null.Title, "Backfilled description", null.URL), searchText)
+
+	// An entry that has an excerpt is left alone.
+	ok, err = repo.FillExcerpt(ctx, filled, "overwrite")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	count, err = repo.CountMissingExcerpt(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"hateblog/internal/domain/entry"
+)
+
+// hostExpression is the generation expression of entries.host (migration 000017). Keep them in
+// sync; ReindexBatch compares the stored value against a fresh evaluation of it.
+const hostExpression = `lower(substring(url from '^[A-Za-z][A-Za-z0-9+.-]*://(?:[^@/?#]*@)?([^/:?#]+)'))`
+
+// ReindexFields selects the derived entry columns that ReindexBatch recomputes.
+type ReindexFields struct {
+	// Host rewrites rows whose stored host differs from its generation expression, e.g. after
+	// a collation or locale upgrade changed lower(). Rewriting a row recomputes the column.
+	Host bool
+	// SearchText rewrites search_text where it differs from entry.BuildSearchText.
+	SearchText bool
+}
+
+// ReindexResult reports one ReindexBatch call.
+type ReindexResult struct {
+	// Scanned is the number of entries read; fewer than the limit means the walk is done.
+	Scanned int
+	// Last is the ID of the last entry read, to pass as after to the next batch.
+	Last            uuid.UUID
+	HostFixed       int64
+	SearchTextFixed int64
+}
+
+// CountAll returns the number of stored entries.
+func (r *EntryRepository) CountAll(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM entries`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count entries: %w", err)
+	}
+	return count, nil
+}
+
+// ReindexBatch reads up to limit entries with IDs after after, in ID order, and rewrites the
+// selected derived columns of those that are stale, in one transaction.
+func (r *EntryRepository) ReindexBatch(ctx context.Context, after uuid.UUID, limit int, fields ReindexFields) (ReindexResult, error) {
+	if limit <= 0 {
+		return ReindexResult{}, fmt.Errorf("limit must be positive")
+	}
+	var res ReindexResult
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		const selectBatch = `
+SELECT id, title, COALESCE(excerpt, ''), url, search_text
+FROM entries
+WHERE id > $1
+ORDER BY id
+LIMIT $2`
+		rows, err := tx.Query(ctx, selectBatch, after, limit)
+		if err != nil {
+			return fmt.Errorf("select entries: %w", err)
+		}
+		var (
+			ids       []uuid.UUID
+			staleIDs  []uuid.UUID
+			newValues []*string
+		)
+		for rows.Next() {
+			var (
+				id                  uuid.UUID
+				title, excerpt, url string
+				stored              *string
+			)
+			if err := rows.Scan(&id, &title, &excerpt, &url, &stored); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan entry: %w", err)
+			}
+			ids = append(ids, id)
+			if !fields.SearchText {
+				continue
+			}
+			// Stored as NULL when empty, as Create and Update do.
+			var want *string
+			if text, ok := nullableString(entry.BuildSearchText(title, excerpt, url)).(string); ok {
+				want = &text
+			}
+			if (stored == nil) != (want == nil) || (stored != nil && *stored != *want) {
+				staleIDs = append(staleIDs, id)
+				newValues = append(newValues, want)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("select entries: %w", err)
+		}
+		res.Scanned = len(ids)
+		if len(ids) == 0 {
+			return nil
+		}
+		res.Last = ids[len(ids)-1]
+
+		if len(staleIDs) > 0 {
+			const updateSearchText = `
+UPDATE entries AS e
+SET search_text = v.search_text
+FROM unnest($1::uuid[], $2::text[]) AS v(id, search_text)
+WHERE e.id = v.id`
+			ct, err := tx.Exec(ctx, updateSearchText, staleIDs, newValues)
+			if err != nil {
+				return fmt.Errorf("update search_text: %w", err)
+			}
+			res.SearchTextFixed = ct.RowsAffected()
+		}
+		if fields.Host {
+			updateHost := `
+UPDATE entries
+SET url = url
+WHERE id = ANY($1)
+  AND host IS DISTINCT FROM ` + hostExpression
+			ct, err := tx.Exec(ctx, updateHost, ids)
+			if err != nil {
+				return fmt.Errorf("update host: %w", err)
+			}
+			res.HostFixed = ct.RowsAffected()
+		}
+		return nil
+	})
+	if err != nil {
+		return ReindexResult{}, fmt.Errorf("reindex entries: %w", err)
+	}
+	return res, nil
+}
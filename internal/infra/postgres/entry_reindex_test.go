@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainEntry "hateblog/internal/domain/entry"
+)
+
+func TestEntryRepository_ReindexBatch(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+	cleanupTables(t, pool)
+
+	stale := testEntry(func(e *domainEntry.Entry) {
+		e.URL = "https://User@WWW.Example.COM:8080/stale"
+		e.Title = "Stale Title"
+	})
+	missing := testEntry(func(e *domainEntry.Entry) { e.Title = "Missing Search Text" })
+	fresh := testEntry()
+	for _, e := range []*domainEntry.Entry{stale, missing, fresh} {
+		insertEntry(t, pool, e)
+	}
+	_, err := pool.Exec(ctx, `UPDATE entries SET search_text = 'outdated' WHERE id = $1`, stale.ID)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `UPDATE entries SET search_text = NULL WHERE id = $1`, missing.ID)
+	require.NoError(t, err)
+
+	repo := NewEntryRepository(pool)
+	total, err := repo.CountAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+
+	fields := ReindexFields{Host: true, SearchText: true}
+	var (
+		after   uuid.UUID
+		scanned int
+		fixed   int64
+	)
+	for {
+		res, err := repo.ReindexBatch(ctx, after, 2, fields)
+		require.NoError(t, err)
+		scanned += res.Scanned
+		fixed += res.SearchTextFixed
+		// host is generated, so it can only be stale after the expression's behavior changed.
+		assert.Zero(t, res.HostFixed)
+		if res.Scanned < 2 {
+			break
+		}
+		after = res.Last
+	}
+	assert.Equal(t, 3, scanned)
+	assert.Equal(t, int64(2), fixed)
+
+	for _, e := range []*domainEntry.Entry{stale, missing, fresh} {
+		var searchText, host string
+		require.NoError(t, pool.QueryRow(ctx, `SELECT search_text, host FROM entries WHERE id = $1`, e.ID).Scan(&searchText, &host))
+		assert.Equal(t, domainEntry.BuildSearchText(e.Title, e.Excerpt, e.URL), searchText)
+		assert.NotEmpty(t, host)
+	}
+	var host string
+	require.NoError(t, pool.QueryRow(ctx, `SELECT host FROM entries WHERE id = $1`, stale.ID).Scan(&host))
+	assert.Equal(t, "www.example.com", host)
+
+	// A second pass finds nothing to fix.
+	res, err := repo.ReindexBatch(ctx, uuid.Nil, 10, fields)
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Scanned)
+	assert.Zero(t, res.SearchTextFixed)
+	assert.Zero(t, res.HostFixed)
+}
@@ -17,13 +17,16 @@ import (
 	"hateblog/internal/domain/entry"
 	"hateblog/internal/domain/repository"
 	"hateblog/internal/pkg/apptime"
+	"hateblog/internal/pkg/hostname"
 )
 
 var _ repository.EntryRepository = (*EntryRepository)(nil)
 
 // EntryRepository implements repository.EntryRepository backed by PostgreSQL.
 type EntryRepository struct {
-	pool *pgxpool.Pool
+	pool          *pgxpool.Pool
+	excludedHosts []string
+	hotTiebreak   entry.HotTiebreak
 }
 
 // NewEntryRepository creates a new EntryRepository.
@@ -31,6 +34,47 @@ func NewEntryRepository(pool *pgxpool.Pool) *EntryRepository {
 	return &EntryRepository{pool: pool}
 }
 
+// WithExcludedHosts returns a repository that hides entries from the given hosts
+// in every List/Count query, in addition to any ListQuery.ExcludeHosts. URL-form values are
+// reduced to their host so that they match the stored host column.
+func (r *EntryRepository) WithExcludedHosts(hosts []string) *EntryRepository {
+	clone := *r
+	clone.excludedHosts = make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		if host, err := hostname.Normalize(h); err == nil {
+			h = host
+		}
+		clone.excludedHosts = append(clone.excludedHosts, h)
+	}
+	return &clone
+}
+
+// WithHotTiebreak returns a repository that breaks SortHot ties with t unless the query sets
+// its own ListQuery.HotTiebreak.
+func (r *EntryRepository) WithHotTiebreak(t entry.HotTiebreak) *EntryRepository {
+	clone := *r
+	clone.hotTiebreak = t
+	return &clone
+}
+
+// prepareListQuery merges repository-level exclusions and normalizes the query.
+func (r *EntryRepository) prepareListQuery(q entry.ListQuery) (entry.ListQuery, error) {
+	query := q
+	if len(r.excludedHosts) > 0 {
+		hosts := make([]string, 0, len(q.ExcludeHosts)+len(r.excludedHosts))
+		hosts = append(hosts, q.ExcludeHosts...)
+		hosts = append(hosts, r.excludedHosts...)
+		query.ExcludeHosts = hosts
+	}
+	if query.HotTiebreak == "" {
+		query.HotTiebreak = r.hotTiebreak
+	}
+	if err := query.Normalize(); err != nil {
+		return entry.ListQuery{}, err
+	}
+	return query, nil
+}
+
 // Create inserts a new entry.
 func (r *EntryRepository) Create(ctx context.Context, e *entry.Entry) error {
 	if e == nil {
@@ -136,7 +180,7 @@ WHERE id = $1`
 	ent, err := scanEntry(row)
 	if err != nil {
 		if errorsIsNoRows(err) {
-			return nil, fmt.Errorf("entry not found: %w", err)
+			return nil, fmt.Errorf("%w: %w", entry.ErrNotFound, err)
 		}
 		return nil, err
 	}
@@ -146,10 +190,46 @@ WHERE id = $1`
 	return ent, nil
 }
 
+// GetByIDs returns the entries with the given IDs in the order of ids. IDs that do not exist
+// or belong to excluded hosts are omitted; ids is expected to hold no duplicates.
+func (r *EntryRepository) GetByIDs(ctx context.Context, ids []entry.ID) ([]*entry.Entry, error) {
+	if len(ids) == 0 {
+		return []*entry.Entry{}, nil
+	}
+	query := `
+SELECT e.id, e.title, e.url, e.posted_at, e.bookmark_count, e.excerpt, e.subject, e.created_at, e.updated_at
+FROM unnest($1::uuid[]) WITH ORDINALITY AS req(id, ord)
+INNER JOIN entries e ON e.id = req.id`
+	args := []any{ids}
+	if len(r.excludedHosts) > 0 {
+		query += "\nWHERE " + excludedHostsCondition("e.host", 2)
+		args = append(args, r.excludedHosts)
+	}
+	query += "\nORDER BY req.ord"
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get entries by ids: %w", err)
+	}
+	defer rows.Close()
+
+	entries, err := scanEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return entries, nil
+	}
+	if err := r.loadTags(ctx, entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
 // List returns entries that match the query.
 func (r *EntryRepository) List(ctx context.Context, q entry.ListQuery) ([]*entry.Entry, error) {
-	query := q
-	if err := query.Normalize(); err != nil {
+	query, err := r.prepareListQuery(q)
+	if err != nil {
 		return nil, err
 	}
 	sql, args := buildListEntriesSQL(query, false)
@@ -176,8 +256,8 @@ func (r *EntryRepository) List(ctx context.Context, q entry.ListQuery) ([]*entry
 
 // Count returns the number of entries matching the query.
 func (r *EntryRepository) Count(ctx context.Context, q entry.ListQuery) (int64, error) {
-	query := q
-	if err := query.Normalize(); err != nil {
+	query, err := r.prepareListQuery(q)
+	if err != nil {
 		return 0, err
 	}
 	sql, args := buildListEntriesSQL(query, true)
@@ -190,8 +270,8 @@ func (r *EntryRepository) Count(ctx context.Context, q entry.ListQuery) (int64,
 
 // ListAndCount returns entries and the total count. When the page has no rows, it falls back to Count.
 func (r *EntryRepository) ListAndCount(ctx context.Context, q entry.ListQuery) ([]*entry.Entry, int64, error) {
-	query := q
-	if err := query.Normalize(); err != nil {
+	query, err := r.prepareListQuery(q)
+	if err != nil {
 		return nil, 0, err
 	}
 	sql, args := buildListEntriesWithTotalSQL(query)
@@ -201,10 +281,9 @@ func (r *EntryRepository) ListAndCount(ctx context.Context, q entry.ListQuery) (
 	}
 	defer rows.Close()
 
-	var (
-		entries []*entry.Entry
-		total   int64
-	)
+	// Empty pages are returned as an empty slice so cached results encode as [] rather than null.
+	entries := make([]*entry.Entry, 0)
+	var total int64
 	for rows.Next() {
 		ent := &entry.Entry{}
 		var excerpt, subject *string
@@ -282,6 +361,198 @@ ORDER BY day DESC`
 	return items, rows.Err()
 }
 
+// ListUntagged returns entries that have no tags, newest posted first.
+// Repository-level excluded hosts are applied as for List.
+func (r *EntryRepository) ListUntagged(ctx context.Context, limit, offset int) ([]*entry.Entry, error) {
+	sql, args, err := r.buildUntaggedSQL(false)
+	if err != nil {
+		return nil, err
+	}
+	sql += fmt.Sprintf(" ORDER BY e.posted_at DESC, e.id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list untagged entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries, err := scanEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, ent := range entries {
+		ent.Tags = []entry.Tagging{}
+	}
+	return entries, nil
+}
+
+// CountUntagged returns the number of entries that have no tags.
+func (r *EntryRepository) CountUntagged(ctx context.Context) (int64, error) {
+	sql, args, err := r.buildUntaggedSQL(true)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	if err := r.pool.QueryRow(ctx, sql, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count untagged entries: %w", err)
+	}
+	return count, nil
+}
+
+// ListSources returns entry hosts ordered by entry count, then host.
+// Repository-level excluded hosts are left out.
+func (r *EntryRepository) ListSources(ctx context.Context, limit int) ([]entry.Source, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	// prepareListQuery normalizes the repository-level excluded hosts.
+	query, err := r.prepareListQuery(entry.ListQuery{})
+	if err != nil {
+		return nil, err
+	}
+	excluded := query.ExcludeHosts
+	if excluded == nil {
+		excluded = []string{}
+	}
+	const sql = `
+SELECT host, COUNT(*)
+FROM entries
+WHERE host IS NOT NULL
+  AND host <> ALL($2::text[])
+GROUP BY host
+ORDER BY COUNT(*) DESC, host
+LIMIT $1`
+
+	rows, err := r.pool.Query(ctx, sql, limit, excluded)
+	if err != nil {
+		return nil, fmt.Errorf("list sources: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []entry.Source
+	for rows.Next() {
+		var src entry.Source
+		if err := rows.Scan(&src.Host, &src.EntryCount); err != nil {
+			return nil, fmt.Errorf("scan source: %w", err)
+		}
+		sources = append(sources, src)
+	}
+	return sources, rows.Err()
+}
+
+// ListHosts returns up to limit distinct entry hosts that sort after the given host, in
+// ascending order, so that every host can be walked in pages. Excluded hosts are included.
+func (r *EntryRepository) ListHosts(ctx context.Context, after string, limit int) ([]string, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	const sql = `
+SELECT DISTINCT host
+FROM entries
+WHERE host IS NOT NULL
+  AND host > $1
+ORDER BY host
+LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, sql, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list hosts: %w", err)
+	}
+	defer rows.Close()
+
+	var hosts []string
+	for rows.Next() {
+		var host string
+		if err := rows.Scan(&host); err != nil {
+			return nil, fmt.Errorf("scan host: %w", err)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, rows.Err()
+}
+
+func (r *EntryRepository) buildUntaggedSQL(countOnly bool) (string, []any, error) {
+	// prepareListQuery normalizes the repository-level excluded hosts.
+	query, err := r.prepareListQuery(entry.ListQuery{})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var builder strings.Builder
+	if countOnly {
+		builder.WriteString("SELECT COUNT(*) FROM entries e")
+	} else {
+		builder.WriteString(`SELECT e.id, e.title, e.url, e.posted_at, e.bookmark_count, e.excerpt, e.subject, e.created_at, e.updated_at
+FROM entries e`)
+	}
+	builder.WriteString(" WHERE NOT EXISTS (SELECT 1 FROM entry_tags et WHERE et.entry_id = e.id)")
+
+	var args []any
+	if len(query.ExcludeHosts) > 0 {
+		builder.WriteString(" AND ")
+		builder.WriteString(excludedHostsCondition("e.host", 1))
+		args = append(args, query.ExcludeHosts)
+	}
+	return builder.String(), args, nil
+}
+
+// NewestCreatedAt returns the created_at of the most recently ingested entry.
+// It returns the zero time when no entries exist.
+func (r *EntryRepository) NewestCreatedAt(ctx context.Context) (time.Time, error) {
+	const query = `SELECT max(created_at) FROM entries`
+
+	var newest *time.Time
+	if err := r.pool.QueryRow(ctx, query).Scan(&newest); err != nil {
+		return time.Time{}, fmt.Errorf("newest created_at: %w", err)
+	}
+	if newest == nil {
+		return time.Time{}, nil
+	}
+	return *newest, nil
+}
+
+// CountPrunable returns the number of entries created before cutoff with at most maxBookmarks
+// bookmarks.
+func (r *EntryRepository) CountPrunable(ctx context.Context, cutoff time.Time, maxBookmarks int) (int64, error) {
+	const query = `
+SELECT COUNT(*)
+FROM entries
+WHERE created_at < $1
+  AND bookmark_count <= $2`
+
+	var count int64
+	if err := r.pool.QueryRow(ctx, query, cutoff, maxBookmarks).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count prunable entries: %w", err)
+	}
+	return count, nil
+}
+
+// PruneBatch deletes up to limit of the oldest entries created before cutoff with at most
+// maxBookmarks bookmarks and returns how many were deleted. Their entry_tags and click_metrics
+// rows are removed by ON DELETE CASCADE.
+func (r *EntryRepository) PruneBatch(ctx context.Context, cutoff time.Time, maxBookmarks, limit int) (int64, error) {
+	if limit <= 0 {
+		return 0, fmt.Errorf("limit must be positive")
+	}
+	const query = `
+DELETE FROM entries
+WHERE id IN (
+	SELECT id
+	FROM entries
+	WHERE created_at < $1
+	  AND bookmark_count <= $2
+	ORDER BY created_at
+	LIMIT $3
+)`
+
+	ct, err := r.pool.Exec(ctx, query, cutoff, maxBookmarks, limit)
+	if err != nil {
+		return 0, fmt.Errorf("prune entries: %w", err)
+	}
+	return ct.RowsAffected(), nil
+}
+
 func (r *EntryRepository) loadTags(ctx context.Context, entries []*entry.Entry) error {
 	ids := make([]uuid.UUID, 0, len(entries))
 	entryByID := make(map[uuid.UUID]*entry.Entry, len(entries))
@@ -370,6 +641,12 @@ func buildListEntriesSQL(q entry.ListQuery, countOnly bool) (string, []any) {
 		argPos++
 	}
 
+	if len(q.ExcludeHosts) > 0 {
+		conditions = append(conditions, excludedHostsCondition("host", argPos))
+		args = append(args, q.ExcludeHosts)
+		argPos++
+	}
+
 	if len(conditions) > 0 {
 		builder.WriteString(" WHERE ")
 		builder.WriteString(strings.Join(conditions, " AND "))
@@ -381,7 +658,8 @@ func buildListEntriesSQL(q entry.ListQuery, countOnly bool) (string, []any) {
 
 	switch q.Sort {
 	case entry.SortHot:
-		builder.WriteString(" ORDER BY bookmark_count DESC, created_at DESC")
+		builder.WriteString(" ORDER BY ")
+		builder.WriteString(hotOrderBy("", q.HotTiebreak))
 	default:
 		builder.WriteString(" ORDER BY created_at DESC")
 	}
@@ -435,6 +713,12 @@ func buildListEntriesWithTotalSQL(q entry.ListQuery) (string, []any) {
 		argPos++
 	}
 
+	if len(q.ExcludeHosts) > 0 {
+		conditions = append(conditions, excludedHostsCondition("host", argPos))
+		args = append(args, q.ExcludeHosts)
+		argPos++
+	}
+
 	if len(conditions) > 0 {
 		builder.WriteString(" WHERE ")
 		builder.WriteString(strings.Join(conditions, " AND "))
@@ -442,7 +726,8 @@ func buildListEntriesWithTotalSQL(q entry.ListQuery) (string, []any) {
 
 	switch q.Sort {
 	case entry.SortHot:
-		builder.WriteString(" ORDER BY bookmark_count DESC, created_at DESC")
+		builder.WriteString(" ORDER BY ")
+		builder.WriteString(hotOrderBy("", q.HotTiebreak))
 	default:
 		builder.WriteString(" ORDER BY created_at DESC")
 	}
@@ -453,8 +738,9 @@ func buildListEntriesWithTotalSQL(q entry.ListQuery) (string, []any) {
 	return builder.String(), args
 }
 
+// scanEntries returns an empty, non-nil slice when there are no rows.
 func scanEntries(rows pgx.Rows) ([]*entry.Entry, error) {
-	var entries []*entry.Entry
+	entries := make([]*entry.Entry, 0)
 	for rows.Next() {
 		ent, err := scanEntry(rows)
 		if err != nil {
@@ -522,7 +808,7 @@ func buildKeywordSearchSQL(q entry.ListQuery, countOnly bool, withTotal bool) (s
 		}
 		normalized := strings.ToLower(term)
 		termsAny = append(termsAny, normalized)
-		if isASCIIWord(normalized) {
+		if !q.EnglishSubstring && isASCIIWord(normalized) {
 			enWords = append(enWords, normalized)
 		}
 	}
@@ -558,7 +844,15 @@ func buildKeywordSearchSQL(q entry.ListQuery, countOnly bool, withTotal bool) (s
 	argPos++
 
 	builder.WriteString(" , candidates AS (SELECT e.* FROM entries e, params p WHERE ")
-	builder.WriteString(" (SELECT bool_and(e.search_text LIKE '%' || t || '%') FROM unnest(p.terms_any) t)")
+	if q.MatchTags {
+		builder.WriteString(` (SELECT bool_and(e.search_text LIKE '%' || t || '%' OR EXISTS (
+			SELECT 1 FROM entry_tags et
+			INNER JOIN tags tg ON tg.id = et.tag_id
+			WHERE et.entry_id = e.id AND tg.name LIKE '%' || t || '%'
+		)) FROM unnest(p.terms_any) t)`)
+	} else {
+		builder.WriteString(" (SELECT bool_and(e.search_text LIKE '%' || t || '%') FROM unnest(p.terms_any) t)")
+	}
 
 	if q.MinBookmarkCount > 0 {
 		builder.WriteString(fmt.Sprintf(" AND e.bookmark_count >= $%d", argPos))
@@ -588,12 +882,33 @@ func buildKeywordSearchSQL(q entry.ListQuery, countOnly bool, withTotal bool) (s
 		argPos++
 	}
 
+	if len(q.ExcludeHosts) > 0 {
+		builder.WriteString(" AND ")
+		builder.WriteString(excludedHostsCondition("e.host", argPos))
+		args = append(args, q.ExcludeHosts)
+		argPos++
+	}
+
 	builder.WriteString(fmt.Sprintf(" LIMIT %d)", candidateLimit))
 
 	builder.WriteString(" SELECT ")
 	builder.WriteString(columns)
-	builder.WriteString(" FROM candidates c, params p WHERE ")
-	builder.WriteString(" (cardinality(p.en_words) = 0 OR (SELECT COUNT(DISTINCT m[1]) FROM regexp_matches(c.search_text, p.en_regex, 'g') m) = cardinality(p.en_words))")
+	builder.WriteString(" FROM candidates c, params p")
+	// English words must match whole words unless EnglishSubstring is set, in which case the
+	// candidate LIKE filter already decides. With MatchTags the tag names count as text too.
+	if !q.EnglishSubstring {
+		matchText := "c.search_text"
+		if q.MatchTags {
+			matchText = `c.search_text || ' ' || COALESCE((
+			SELECT string_agg(tg.name, ' ') FROM entry_tags et
+			INNER JOIN tags tg ON tg.id = et.tag_id
+			WHERE et.entry_id = c.id
+		), '')`
+		}
+		builder.WriteString(" WHERE  (cardinality(p.en_words) = 0 OR (SELECT COUNT(DISTINCT m[1]) FROM regexp_matches(")
+		builder.WriteString(matchText)
+		builder.WriteString(", p.en_regex, 'g') m) = cardinality(p.en_words))")
+	}
 
 	if countOnly {
 		return builder.String(), args
@@ -601,7 +916,8 @@ func buildKeywordSearchSQL(q entry.ListQuery, countOnly bool, withTotal bool) (s
 
 	switch q.Sort {
 	case entry.SortHot:
-		builder.WriteString(" ORDER BY c.bookmark_count DESC, c.created_at DESC")
+		builder.WriteString(" ORDER BY ")
+		builder.WriteString(hotOrderBy("c.", q.HotTiebreak))
 	default:
 		builder.WriteString(" ORDER BY c.created_at DESC")
 	}
@@ -612,6 +928,24 @@ func buildKeywordSearchSQL(q entry.ListQuery, countOnly bool, withTotal bool) (s
 	return builder.String(), args
 }
 
+// hotOrderBy returns the SortHot ORDER BY list for columns qualified by prefix.
+func hotOrderBy(prefix string, tiebreak entry.HotTiebreak) string {
+	switch tiebreak {
+	case entry.HotTiebreakOldest:
+		return fmt.Sprintf("%[1]sbookmark_count DESC, %[1]screated_at ASC", prefix)
+	case entry.HotTiebreakTitle:
+		return fmt.Sprintf("%[1]sbookmark_count DESC, %[1]stitle ASC, %[1]screated_at DESC", prefix)
+	default:
+		return fmt.Sprintf("%[1]sbookmark_count DESC, %[1]screated_at DESC", prefix)
+	}
+}
+
+// excludedHostsCondition builds a predicate rejecting entries whose stored host (the generated
+// entries.host column) is in the text[] bound at argPos. URLs without a parsable host are kept.
+func excludedHostsCondition(hostColumn string, argPos int) string {
+	return fmt.Sprintf("COALESCE(%s, '') <> ALL($%d::text[])", hostColumn, argPos)
+}
+
 func splitSearchTerms(input string) []string {
 	return strings.FieldsFunc(input, func(r rune) bool {
 		return unicode.IsSpace(r)
@@ -0,0 +1,299 @@
+package postgres
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainEntry "hateblog/internal/domain/entry"
+)
+
+// updateGolden rewrites the golden files instead of comparing against them:
+//
+//	go test ./internal/infra/postgres -run TestKeywordSearchSQLGolden -update
+var updateGolden = flag.Bool("update", false, "rewrite golden files under testdata")
+
+// keywordSearchCase is one buildKeywordSearchSQL input with its golden file name.
+type keywordSearchCase struct {
+	name      string
+	query     domainEntry.ListQuery
+	countOnly bool
+	withTotal bool
+}
+
+var (
+	searchFrom = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	searchTo   = time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+)
+
+var keywordSearchCases = []keywordSearchCase{
+	{name: "english_single", query: domainEntry.ListQuery{Keyword: "Go", Limit: 25}},
+	{name: "english_multi", query: domainEntry.ListQuery{Keyword: "go  rust\tc++ v1.2", Limit: 25, Offset: 50}},
+	{name: "cjk", query: domainEntry.ListQuery{Keyword: "機械学習　入門", Limit: 25}},
+	{name: "mixed", query: domainEntry.ListQuery{Keyword: "Go 並行処理", Sort: domainEntry.SortHot, Limit: 25}},
+	{name: "match_tags", query: domainEntry.ListQuery{Keyword: "go", MatchTags: true, Limit: 25}},
+	{name: "english_substring", query: domainEntry.ListQuery{Keyword: "go 入門", EnglishSubstring: true, Limit: 25}},
+	{
+		name: "filters",
+		query: domainEntry.ListQuery{
+			Keyword:          "go",
+			MinBookmarkCount: 5,
+			Tags:             []string{"go", "web"},
+			PostedAtFrom:     searchFrom,
+			PostedAtTo:       searchTo,
+			ExcludeHosts:     []string{"spam.example.net"},
+			Sort:             domainEntry.SortHot,
+			HotTiebreak:      domainEntry.HotTiebreakOldest,
+			Limit:            25,
+		},
+	},
+	{name: "count_only", query: domainEntry.ListQuery{Keyword: "go 入門", MinBookmarkCount: 5, Limit: 25}, countOnly: true},
+	{name: "with_total", query: domainEntry.ListQuery{Keyword: "go", PostedAtFrom: searchFrom, Limit: 25}, withTotal: true},
+	{name: "blank_keyword", query: domainEntry.ListQuery{Keyword: " \t", MinBookmarkCount: 5, Limit: 25}},
+}
+
+// formatKeywordSearch renders the SQL and its bound arguments for a golden file.
+func formatKeywordSearch(sql string, args []any) string {
+	var b strings.Builder
+	b.WriteString(sql)
+	b.WriteString("\n-- args\n")
+	for i, arg := range args {
+		fmt.Fprintf(&b, "$%d %T %v\n", i+1, arg, arg)
+	}
+	return b.String()
+}
+
+func TestKeywordSearchSQLGolden(t *testing.T) {
+	for _, tc := range keywordSearchCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sql, args := buildKeywordSearchSQL(tc.query, tc.countOnly, tc.withTotal)
+			got := formatKeywordSearch(sql, args)
+			path := filepath.Join("testdata", "keyword_search", tc.name+".golden")
+			if *updateGolden {
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, []byte(got), 0o644))
+			}
+			want, err := os.ReadFile(path)
+			require.NoError(t, err, "run with -update to create the golden file")
+			assert.Equal(t, string(want), got)
+		})
+	}
+}
+
+func TestSplitSearchTerms(t *testing.T) {
+	assert.Equal(t, []string{"go", "rust", "入門"}, splitSearchTerms(" go\trust　入門\n"))
+	assert.Empty(t, splitSearchTerms(" \t　"))
+}
+
+func TestEnglishWordRegex(t *testing.T) {
+	assert.Equal(t, `\m(go|c\+\+|v1\.2)\M`, englishWordRegex([]string{"go", "c++", " ", "v1.2"}))
+	assert.Equal(t, "a^", englishWordRegex(nil))
+	assert.Equal(t, "a^", englishWordRegex([]string{" "}))
+}
+
+// benchmarkKeywords builds a keyword of n terms drawn from words.
+func benchmarkKeywords(words []string, n int) string {
+	terms := make([]string, n)
+	for i := range terms {
+		terms[i] = words[i%len(words)]
+	}
+	return strings.Join(terms, " ")
+}
+
+var (
+	benchEnglishWords = []string{"go", "rust", "kubernetes", "postgres", "redis", "react"}
+	benchCJKWords     = []string{"機械学習", "入門", "並行処理", "データベース", "設計", "東京"}
+)
+
+func BenchmarkBuildKeywordSearchSQL(b *testing.B) {
+	for _, n := range []int{1, 4, 16} {
+		for _, lang := range []struct {
+			name  string
+			words []string
+		}{
+			{"english", benchEnglishWords},
+			{"cjk", benchCJKWords},
+		} {
+			q := domainEntry.ListQuery{Keyword: benchmarkKeywords(lang.words, n), Limit: 25}
+			b.Run(fmt.Sprintf("%s/terms=%d", lang.name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				for b.Loop() {
+					buildKeywordSearchSQL(q, false, true)
+				}
+			})
+		}
+	}
+
+	filtered := domainEntry.ListQuery{
+		Keyword:          benchmarkKeywords(slices.Concat(benchEnglishWords, benchCJKWords), 4),
+		MinBookmarkCount: 5,
+		Tags:             []string{"go", "web"},
+		PostedAtFrom:     searchFrom,
+		PostedAtTo:       searchTo,
+		MatchTags:        true,
+		Limit:            25,
+	}
+	b.Run("filters", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			buildKeywordSearchSQL(filtered, false, true)
+		}
+	})
+}
+
+func BenchmarkSplitSearchTerms(b *testing.B) {
+	input := benchmarkKeywords(slices.Concat(benchEnglishWords, benchCJKWords), 16)
+	b.ReportAllocs()
+	for b.Loop() {
+		splitSearchTerms(input)
+	}
+}
+
+func BenchmarkEnglishWordRegex(b *testing.B) {
+	words := strings.Fields(benchmarkKeywords(benchEnglishWords, 16))
+	b.ReportAllocs()
+	for b.Loop() {
+		englishWordRegex(words)
+	}
+}
+
+func TestKeywordSearch_Integration(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+	cleanupTables(t, pool)
+
+	// URLs avoid every search term so that only titles and excerpts decide the matches.
+	corpus := []struct {
+		title   string
+		excerpt string
+		tags    []string
+	}{
+		{title: "Learn Go in a weekend", excerpt: "入門ガイド", tags: []string{"golang"}},
+		{title: "Google announces new phones"},
+		{title: "Why I stopped using golang generics"},
+		{title: "go-lang style guide", tags: []string{"golang"}},
+		{title: "Cargo workspaces explained", excerpt: "Rust のビルド"},
+		{title: "東京都の天気予報"},
+		{title: "京都の紅葉スポット"},
+		{title: "RUST 入門", tags: []string{"rust"}},
+	}
+	tagIDs := map[string]domainEntry.ID{}
+	for i, c := range corpus {
+		e := testEntry(func(e *domainEntry.Entry) {
+			e.Title = c.title
+			e.Excerpt = c.excerpt
+			e.URL = fmt.Sprintf("https://example.com/a%d", i)
+		})
+		insertEntry(t, pool, e)
+		for _, name := range c.tags {
+			id, ok := tagIDs[name]
+			if !ok {
+				tg := testTag(name)
+				insertTag(t, pool, tg)
+				id = tg.ID
+				tagIDs[name] = id
+			}
+			insertEntryTag(t, pool, e.ID, id, 80)
+		}
+	}
+
+	repo := NewEntryRepository(pool)
+	tests := []struct {
+		name string
+		q    domainEntry.ListQuery
+		want []string
+	}{
+		{
+			name: "english term matches whole words only",
+			q:    domainEntry.ListQuery{Keyword: "go"},
+			want: []string{"Learn Go in a weekend", "go-lang style guide"},
+		},
+		{
+			name: "english term is case-insensitive",
+			q:    domainEntry.ListQuery{Keyword: "rust"},
+			want: []string{"Cargo workspaces explained", "RUST 入門"},
+		},
+		{
+			name: "english substring when word boundary is off",
+			q:    domainEntry.ListQuery{Keyword: "go", EnglishSubstring: true},
+			want: []string{"Learn Go in a weekend", "Google announces new phones", "Why I stopped using golang generics", "go-lang style guide", "Cargo workspaces explained"},
+		},
+		{
+			name: "cjk term matches substrings",
+			q:    domainEntry.ListQuery{Keyword: "京都"},
+			want: []string{"東京都の天気予報", "京都の紅葉スポット"},
+		},
+		{
+			name: "longer cjk term narrows the substring",
+			q:    domainEntry.ListQuery{Keyword: "東京"},
+			want: []string{"東京都の天気予報"},
+		},
+		{
+			name: "terms are ANDed",
+			q:    domainEntry.ListQuery{Keyword: "go 入門"},
+			want: []string{"Learn Go in a weekend"},
+		},
+		{
+			name: "english and cjk terms are ANDed",
+			q:    domainEntry.ListQuery{Keyword: "rust 入門"},
+			want: []string{"RUST 入門"},
+		},
+		{
+			name: "no match",
+			q:    domainEntry.ListQuery{Keyword: "python"},
+			want: []string{},
+		},
+		{
+			name: "tag filter",
+			q:    domainEntry.ListQuery{Keyword: "go", Tags: []string{"golang"}},
+			want: []string{"Learn Go in a weekend", "go-lang style guide"},
+		},
+		{
+			name: "tag filter excludes untagged matches",
+			q:    domainEntry.ListQuery{Keyword: "golang", Tags: []string{"golang"}},
+			want: []string{},
+		},
+		{
+			name: "tag filter with cjk term",
+			q:    domainEntry.ListQuery{Keyword: "入門", Tags: []string{"rust"}},
+			want: []string{"RUST 入門"},
+		},
+		{
+			name: "tag names match as text when requested",
+			q:    domainEntry.ListQuery{Keyword: "golang", MatchTags: true},
+			want: []string{"Learn Go in a weekend", "Why I stopped using golang generics", "go-lang style guide"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := repo.List(ctx, tt.q)
+			require.NoError(t, err)
+			titles := make([]string, 0, len(entries))
+			for _, e := range entries {
+				titles = append(titles, e.Title)
+			}
+			assert.ElementsMatch(t, tt.want, titles)
+
+			count, err := repo.Count(ctx, tt.q)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.want)), count)
+
+			listed, total, err := repo.ListAndCount(ctx, tt.q)
+			require.NoError(t, err)
+			assert.Len(t, listed, len(tt.want))
+			assert.Equal(t, int64(len(tt.want)), total)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domainEntry "hateblog/internal/domain/entry"
+	usecaseRanking "hateblog/internal/usecase/ranking"
+)
+
+func TestWeeklyRanking_ISOWeekAcrossYearBoundary(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+	cleanupTables(t, pool)
+
+	// 2025-W01 runs from Monday 2024-12-30 to Sunday 2025-01-05.
+	posted := map[string]time.Time{
+		"2024-W52 sunday":  time.Date(2024, time.December, 29, 23, 59, 59, 0, time.Local),
+		"2025-W01 monday":  time.Date(2024, time.December, 30, 0, 0, 0, 0, time.Local),
+		"2025-W01 dec 31":  time.Date(2024, time.December, 31, 12, 0, 0, 0, time.Local),
+		"2025-W01 jan 1":   time.Date(2025, time.January, 1, 9, 0, 0, 0, time.Local),
+		"2025-W01 sunday":  time.Date(2025, time.January, 5, 23, 59, 59, 0, time.Local),
+		"2025-W02 monday":  time.Date(2025, time.January, 6, 0, 0, 0, 0, time.Local),
+		"2020-W53 jan 3rd": time.Date(2021, time.January, 3, 12, 0, 0, 0, time.Local),
+	}
+	for title, at := range posted {
+		insertEntry(t, pool, testEntry(func(e *domainEntry.Entry) {
+			e.Title = title
+			e.PostedAt = at
+		}))
+	}
+
+	svc := usecaseRanking.NewService(NewEntryRepository(pool), nil, nil, nil)
+	titles := func(year, week int) []string {
+		t.Helper()
+		result, err := svc.Weekly(ctx, year, week, 100, 0, 0)
+		require.NoError(t, err)
+		require.Equal(t, int64(len(result.Entries)), result.Total)
+		out := make([]string, 0, len(result.Entries))
+		for _, e := range result.Entries {
+			out = append(out, e.Title)
+		}
+		return out
+	}
+
+	require.ElementsMatch(t, []string{"2025-W01 monday", "2025-W01 dec 31", "2025-W01 jan 1", "2025-W01 sunday"}, titles(2025, 1))
+	require.ElementsMatch(t, []string{"2024-W52 sunday"}, titles(2024, 52))
+	require.ElementsMatch(t, []string{"2025-W02 monday"}, titles(2025, 2))
+	require.ElementsMatch(t, []string{"2020-W53 jan 3rd"}, titles(2020, 53))
+
+	_, err := svc.Weekly(ctx, 2024, 53, 100, 0, 0)
+	require.Error(t, err, "2024 has no ISO week 53")
+}
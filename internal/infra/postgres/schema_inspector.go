@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrNoSchemaVersion is returned by SchemaInspector.Version when no migration has been recorded.
+var ErrNoSchemaVersion = errors.New("no schema version recorded")
+
+// SchemaInspector reads the migration state of the database.
+type SchemaInspector struct {
+	pool *pgxpool.Pool
+}
+
+// NewSchemaInspector creates a new inspector.
+func NewSchemaInspector(pool *pgxpool.Pool) *SchemaInspector {
+	return &SchemaInspector{pool: pool}
+}
+
+// Version returns the version and dirty flag that golang-migrate records in schema_migrations.
+func (s *SchemaInspector) Version(ctx context.Context) (int64, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := s.pool.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, ErrNoSchemaVersion
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read schema version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// MissingTables returns the names in tables that do not resolve to a relation on the search path.
+func (s *SchemaInspector) MissingTables(ctx context.Context, tables []string) ([]string, error) {
+	const query = `
+SELECT name
+FROM unnest($1::text[]) WITH ORDINALITY AS t(name, pos)
+WHERE to_regclass(name) IS NULL
+ORDER BY pos`
+	rows, err := s.pool.Query(ctx, query, tables)
+	if err != nil {
+		return nil, fmt.Errorf("check tables: %w", err)
+	}
+	defer rows.Close()
+
+	var missing []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		missing = append(missing, name)
+	}
+	return missing, rows.Err()
+}
@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaInspector(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+
+	inspector := NewSchemaInspector(pool)
+	version, dirty, err := inspector.Version(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, version)
+	assert.False(t, dirty)
+
+	missing, err := inspector.MissingTables(ctx, []string{"entries", "no_such_table", "tags", "another_missing"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"no_such_table", "another_missing"}, missing)
+}
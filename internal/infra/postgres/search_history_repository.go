@@ -41,3 +41,80 @@ SET count = search_history.count + 1`
 	}
 	return nil
 }
+
+// SumSince returns the total number of searches on or after the day of from.
+func (r *SearchHistoryRepository) SumSince(ctx context.Context, from time.Time) (int64, error) {
+	const query = `
+SELECT COALESCE(SUM(count), 0)
+FROM search_history
+WHERE searched_at >= $1`
+	var total int64
+	if err := r.pool.QueryRow(ctx, query, apptime.TruncateToDay(from)).Scan(&total); err != nil {
+		return 0, fmt.Errorf("sum search history: %w", err)
+	}
+	return total, nil
+}
+
+// List aggregates the searches recorded on days in [from, to) per query, most recently searched
+// first, then by count and query.
+func (r *SearchHistoryRepository) List(ctx context.Context, from, to time.Time, limit int) ([]repository.SearchQueryCount, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	const query = `
+SELECT query, SUM(count) AS total, MIN(searched_at), MAX(searched_at) AS last_searched_at
+FROM search_history
+WHERE searched_at >= $1 AND searched_at < $2
+GROUP BY query
+ORDER BY last_searched_at DESC, total DESC, query
+LIMIT $3`
+	out, err := r.queryCounts(ctx, query, apptime.TruncateToDay(from), apptime.TruncateToDay(to), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list search history: %w", err)
+	}
+	return out, nil
+}
+
+// Top returns the most searched queries of the last days days, today included, ordered by
+// count, then by the last day searched and query.
+func (r *SearchHistoryRepository) Top(ctx context.Context, days int, limit int) ([]repository.SearchQueryCount, error) {
+	if days <= 0 {
+		return nil, fmt.Errorf("days must be positive")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	const query = `
+SELECT query, SUM(count) AS total, MIN(searched_at), MAX(searched_at) AS last_searched_at
+FROM search_history
+WHERE searched_at >= $1
+GROUP BY query
+ORDER BY total DESC, last_searched_at DESC, query
+LIMIT $2`
+	since := apptime.TruncateToDay(apptime.Now()).AddDate(0, 0, -(days - 1))
+	out, err := r.queryCounts(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("top search queries: %w", err)
+	}
+	return out, nil
+}
+
+// queryCounts runs a search_history aggregation that selects the query, its count and the
+// first and last days searched.
+func (r *SearchHistoryRepository) queryCounts(ctx context.Context, query string, args ...any) ([]repository.SearchQueryCount, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []repository.SearchQueryCount
+	for rows.Next() {
+		var c repository.SearchQueryCount
+		if err := rows.Scan(&c.Query, &c.Count, &c.FirstSearchedAt, &c.LastSearchedAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
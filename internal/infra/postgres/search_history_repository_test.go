@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"hateblog/internal/pkg/apptime"
+)
+
+func TestSearchHistoryRepository_List(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+	_, err := pool.Exec(ctx, "TRUNCATE TABLE search_history")
+	require.NoError(t, err)
+
+	day := apptime.TruncateToDay(time.Date(2025, 1, 10, 12, 0, 0, 0, time.Local))
+	const insert = `INSERT INTO search_history (query, searched_at, count) VALUES ($1, $2, $3)`
+	for _, row := range []struct {
+		query string
+		ago   int
+		count int
+	}{
+		{"go", 0, 2},
+		{"go", 3, 5},
+		{"rust", 1, 40},
+		{"python", 0, 9},
+		{"java", 8, 100}, // before the range
+		{"zig", -1, 7},   // after the range
+	} {
+		_, err := pool.Exec(ctx, insert, row.query, day.AddDate(0, 0, -row.ago), row.count)
+		require.NoError(t, err)
+	}
+
+	repo := NewSearchHistoryRepository(pool)
+	got, err := repo.List(ctx, day.AddDate(0, 0, -7), day.AddDate(0, 0, 1), 10)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+
+	// Most recently searched first; ties on the last day go to the larger count.
+	assert.Equal(t, "python", got[0].Query)
+	assert.Equal(t, int64(9), got[0].Count)
+	assert.Equal(t, "go", got[1].Query)
+	assert.Equal(t, int64(7), got[1].Count)
+	assert.Equal(t, day.AddDate(0, 0, -3).Format("2006-01-02"), got[1].FirstSearchedAt.Format("2006-01-02"))
+	assert.Equal(t, day.Format("2006-01-02"), got[1].LastSearchedAt.Format("2006-01-02"))
+	assert.Equal(t, "rust", got[2].Query)
+
+	got, err = repo.List(ctx, day.AddDate(0, 0, -7), day.AddDate(0, 0, 1), 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "python", got[0].Query)
+}
+
+func TestSearchHistoryRepository_Top(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+	_, err := pool.Exec(ctx, "TRUNCATE TABLE search_history")
+	require.NoError(t, err)
+
+	repo := NewSearchHistoryRepository(pool)
+	today := apptime.TruncateToDay(time.Now())
+	record := func(query string, times int, day time.Time) {
+		t.Helper()
+		for i := 0; i < times; i++ {
+			require.NoError(t, repo.Record(ctx, query, day))
+		}
+	}
+	record("Go", 3, today)
+	record("go", 2, today.AddDate(0, 0, -6))
+	record("rust", 4, today.AddDate(0, 0, -1))
+	record("python", 4, today.AddDate(0, 0, -2))
+	record("zig", 1, today)
+	record("java", 20, today.AddDate(0, 0, -7)) // outside a 7-day window
+
+	got, err := repo.Top(ctx, 7, 10)
+	require.NoError(t, err)
+	queries := make([]string, 0, len(got))
+	for _, c := range got {
+		queries = append(queries, c.Query)
+	}
+	// Counts first; rust and python tie and the more recently searched one wins.
+	assert.Equal(t, []string{"go", "rust", "python", "zig"}, queries)
+	assert.Equal(t, int64(5), got[0].Count)
+	assert.Equal(t, today.AddDate(0, 0, -6).Format("2006-01-02"), got[0].FirstSearchedAt.Format("2006-01-02"))
+	assert.Equal(t, today.Format("2006-01-02"), got[0].LastSearchedAt.Format("2006-01-02"))
+
+	got, err = repo.Top(ctx, 8, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "java", got[0].Query)
+}
@@ -3,11 +3,13 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"hateblog/internal/domain/entry"
 	"hateblog/internal/domain/repository"
 	"hateblog/internal/domain/tag"
 	"hateblog/internal/pkg/apptime"
@@ -43,12 +45,15 @@ func (r *TagRepository) Get(ctx context.Context, id tag.ID) (*tag.Tag, error) {
 func (r *TagRepository) GetByName(ctx context.Context, name string) (*tag.Tag, error) {
 	norm := tag.NormalizeName(name)
 	if norm == "" {
-		return nil, fmt.Errorf("tag name is required")
+		return nil, fmt.Errorf("%w: tag name is required", tag.ErrInvalidTag)
 	}
 	const query = `SELECT id, name FROM tags WHERE name = $1`
 	row := r.pool.QueryRow(ctx, query, norm)
 	var result tag.Tag
 	if err := row.Scan(&result.ID, &result.Name); err != nil {
+		if errorsIsNoRows(err) {
+			return nil, fmt.Errorf("%w: %w", tag.ErrNotFound, err)
+		}
 		return nil, fmt.Errorf("get tag by name: %w", err)
 	}
 	return &result, nil
@@ -105,6 +110,96 @@ RETURNING id, name`
 	return nil
 }
 
+// AttachEntry links a tag to an entry. An existing link takes the new score.
+func (r *TagRepository) AttachEntry(ctx context.Context, entryID entry.ID, tagID tag.ID, score int) error {
+	if entryID == uuid.Nil || tagID == uuid.Nil {
+		return fmt.Errorf("entry id and tag id are required")
+	}
+	const query = `
+INSERT INTO entry_tags (entry_id, tag_id, score)
+VALUES ($1, $2, $3)
+ON CONFLICT (entry_id, tag_id) DO UPDATE SET score = EXCLUDED.score`
+
+	if _, err := r.pool.Exec(ctx, query, entryID, tagID, score); err != nil {
+		return fmt.Errorf("attach tag: %w", err)
+	}
+	return nil
+}
+
+// AttachEntryTags links tags to an entry in a single multi-row INSERT and returns the number of
+// links added. Existing links, and repeats of a tag within taggings, keep their first score.
+func (r *TagRepository) AttachEntryTags(ctx context.Context, entryID entry.ID, taggings []entry.Tagging) (int64, error) {
+	if entryID == uuid.Nil {
+		return 0, fmt.Errorf("entry id is required")
+	}
+	if len(taggings) == 0 {
+		return 0, nil
+	}
+	var b strings.Builder
+	b.WriteString("INSERT INTO entry_tags (entry_id, tag_id, score)\nVALUES ")
+	args := make([]any, 0, 1+2*len(taggings))
+	args = append(args, entryID)
+	for i, t := range taggings {
+		if t.TagID == uuid.Nil {
+			return 0, fmt.Errorf("tag id is required")
+		}
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "($1, $%d, $%d)", len(args)+1, len(args)+2)
+		args = append(args, t.TagID, t.Score)
+	}
+	b.WriteString("\nON CONFLICT (entry_id, tag_id) DO NOTHING")
+
+	res, err := r.pool.Exec(ctx, b.String(), args...)
+	if err != nil {
+		return 0, fmt.Errorf("attach tags: %w", err)
+	}
+	return res.RowsAffected(), nil
+}
+
+// DetachEntry unlinks the named tag from an entry and reports whether a link was removed.
+func (r *TagRepository) DetachEntry(ctx context.Context, entryID entry.ID, tagName string) (bool, error) {
+	norm := tag.NormalizeName(tagName)
+	if entryID == uuid.Nil || norm == "" {
+		return false, fmt.Errorf("entry id and tag name are required")
+	}
+	const query = `
+DELETE FROM entry_tags et
+USING tags t
+WHERE et.tag_id = t.id
+  AND et.entry_id = $1
+  AND t.name = $2`
+
+	ct, err := r.pool.Exec(ctx, query, entryID, norm)
+	if err != nil {
+		return false, fmt.Errorf("detach tag: %w", err)
+	}
+	return ct.RowsAffected() > 0, nil
+}
+
+// UpdateEntryScore sets the score of the named tag's link to an entry and reports whether the
+// link exists.
+func (r *TagRepository) UpdateEntryScore(ctx context.Context, entryID entry.ID, tagName string, score int) (bool, error) {
+	norm := tag.NormalizeName(tagName)
+	if entryID == uuid.Nil || norm == "" {
+		return false, fmt.Errorf("entry id and tag name are required")
+	}
+	const query = `
+UPDATE entry_tags et
+SET score = $3
+FROM tags t
+WHERE et.tag_id = t.id
+  AND et.entry_id = $1
+  AND t.name = $2`
+
+	ct, err := r.pool.Exec(ctx, query, entryID, norm, score)
+	if err != nil {
+		return false, fmt.Errorf("update tag score: %w", err)
+	}
+	return ct.RowsAffected() > 0, nil
+}
+
 // Delete removes a tag.
 func (r *TagRepository) Delete(ctx context.Context, id tag.ID) error {
 	if id == uuid.Nil {
@@ -194,6 +289,58 @@ ORDER BY tt.weighted_score DESC, tt.occurrence_count DESC, tt.name ASC`
 	return tags, rows.Err()
 }
 
+// GetUsage returns how many entries carry the tag and how often its entry list was viewed.
+func (r *TagRepository) GetUsage(ctx context.Context, tagID tag.ID) (tag.Usage, error) {
+	if tagID == uuid.Nil {
+		return tag.Usage{}, fmt.Errorf("tag id is required")
+	}
+	const query = `
+SELECT
+  (SELECT COUNT(*) FROM entry_tags WHERE tag_id = $1) AS entry_count,
+  (SELECT COALESCE(SUM(count), 0) FROM tag_view_history WHERE tag_id = $1) AS view_count`
+
+	var usage tag.Usage
+	if err := r.pool.QueryRow(ctx, query, tagID).Scan(&usage.EntryCount, &usage.ViewCount); err != nil {
+		return tag.Usage{}, fmt.Errorf("get tag usage: %w", err)
+	}
+	return usage, nil
+}
+
+// EntryCounts returns how many entries carry each of the tags in a single grouped query.
+// Every requested tag is in the result; tags without entries count 0.
+func (r *TagRepository) EntryCounts(ctx context.Context, tagIDs []tag.ID) (map[tag.ID]int, error) {
+	counts := make(map[tag.ID]int, len(tagIDs))
+	if len(tagIDs) == 0 {
+		return counts, nil
+	}
+	for _, id := range tagIDs {
+		counts[id] = 0
+	}
+	const query = `
+SELECT tag_id, COUNT(*) AS entry_count
+FROM entry_tags
+WHERE tag_id = ANY($1)
+GROUP BY tag_id`
+
+	rows, err := r.pool.Query(ctx, query, tagIDs)
+	if err != nil {
+		return nil, fmt.Errorf("count tag entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id    tag.ID
+			count int
+		)
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, fmt.Errorf("scan tag entry count: %w", err)
+		}
+		counts[id] = count
+	}
+	return counts, rows.Err()
+}
+
 // GetClicked returns tags from recently clicked entries, ordered by click count.
 func (r *TagRepository) GetClicked(ctx context.Context, days int, limit int) ([]tag.ClickedTag, error) {
 	if days <= 0 {
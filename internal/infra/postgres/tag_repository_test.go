@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"hateblog/internal/domain/entry"
 	"hateblog/internal/domain/tag"
 	"hateblog/internal/pkg/apptime"
 )
@@ -160,19 +161,27 @@ func TestTagRepository_GetByName(t *testing.T) {
 		assertTagEqual(t, tg, got)
 	})
 
-	t.Run("returns error for non-existent tag", func(t *testing.T) {
+	t.Run("returns ErrNotFound for non-existent tag", func(t *testing.T) {
 		cleanupTables(t, pool)
 
 		_, err := repo.GetByName(ctx, "nonexistent")
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "get tag by name")
+		require.ErrorIs(t, err, tag.ErrNotFound)
 	})
 
 	t.Run("returns error for empty name", func(t *testing.T) {
 		_, err := repo.GetByName(ctx, "")
-		require.Error(t, err)
+		require.ErrorIs(t, err, tag.ErrInvalidTag)
 		require.Contains(t, err.Error(), "tag name is required")
 	})
+
+	t.Run("does not report query failures as not found", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := repo.GetByName(canceled, "golang")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, tag.ErrNotFound)
+	})
 }
 
 func TestTagRepository_List(t *testing.T) {
@@ -466,3 +475,196 @@ func TestTagRepository_IncrementViewHistory(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestTagRepository_GetUsage(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+
+	repo := NewTagRepository(pool)
+
+	t.Run("counts entries and views", func(t *testing.T) {
+		cleanupTables(t, pool)
+
+		tg := testTag("golang")
+		insertTag(t, pool, tg)
+		for i := 0; i < 2; i++ {
+			e := testEntry()
+			insertEntry(t, pool, e)
+			insertEntryTag(t, pool, e.ID, tg.ID, 80)
+		}
+		now := time.Now()
+		require.NoError(t, repo.IncrementViewHistory(ctx, tg.ID, now))
+		require.NoError(t, repo.IncrementViewHistory(ctx, tg.ID, now))
+		require.NoError(t, repo.IncrementViewHistory(ctx, tg.ID, now.AddDate(0, 0, -1)))
+
+		usage, err := repo.GetUsage(ctx, tg.ID)
+		require.NoError(t, err)
+		assert.Equal(t, tag.Usage{EntryCount: 2, ViewCount: 3}, usage)
+	})
+
+	t.Run("returns zero counts for unused tag", func(t *testing.T) {
+		cleanupTables(t, pool)
+
+		tg := testTag("unused")
+		insertTag(t, pool, tg)
+
+		usage, err := repo.GetUsage(ctx, tg.ID)
+		require.NoError(t, err)
+		assert.Equal(t, tag.Usage{}, usage)
+	})
+
+	t.Run("rejects nil id", func(t *testing.T) {
+		_, err := repo.GetUsage(ctx, uuid.Nil)
+		require.Error(t, err)
+	})
+}
+
+func TestTagRepository_EntryCounts(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+
+	repo := NewTagRepository(pool)
+
+	t.Run("counts several tags in one call", func(t *testing.T) {
+		cleanupTables(t, pool)
+
+		golang := testTag("golang")
+		rust := testTag("rust")
+		unused := testTag("unused")
+		other := testTag("other")
+		for _, tg := range []*tag.Tag{golang, rust, unused, other} {
+			insertTag(t, pool, tg)
+		}
+		for i := 0; i < 3; i++ {
+			e := testEntry()
+			insertEntry(t, pool, e)
+			insertEntryTag(t, pool, e.ID, golang.ID, 80)
+			if i == 0 {
+				insertEntryTag(t, pool, e.ID, rust.ID, 50)
+				insertEntryTag(t, pool, e.ID, other.ID, 50)
+			}
+		}
+
+		counts, err := repo.EntryCounts(ctx, []tag.ID{golang.ID, rust.ID, unused.ID})
+		require.NoError(t, err)
+		assert.Equal(t, map[tag.ID]int{golang.ID: 3, rust.ID: 1, unused.ID: 0}, counts)
+	})
+
+	t.Run("returns empty map for no tags", func(t *testing.T) {
+		counts, err := repo.EntryCounts(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, counts)
+	})
+}
+
+func TestTagRepository_AttachDetachEntry(t *testing.T) {
+	pool, terminate := setupPostgres(t)
+	defer terminate()
+
+	ctx := context.Background()
+	require.NoError(t, applyTestMigrations(ctx, pool))
+
+	repo := NewTagRepository(pool)
+	entryRepo := NewEntryRepository(pool)
+
+	t.Run("attach inserts and updates score", func(t *testing.T) {
+		cleanupTables(t, pool)
+
+		e := testEntry()
+		insertEntry(t, pool, e)
+		tg := testTag("golang")
+		insertTag(t, pool, tg)
+
+		require.NoError(t, repo.AttachEntry(ctx, e.ID, tg.ID, 60))
+		require.NoError(t, repo.AttachEntry(ctx, e.ID, tg.ID, 90))
+
+		got, err := entryRepo.Get(ctx, e.ID)
+		require.NoError(t, err)
+		require.Len(t, got.Tags, 1)
+		assert.Equal(t, 90, got.Tags[0].Score)
+	})
+
+	t.Run("attach entry tags inserts in one batch and skips duplicates", func(t *testing.T) {
+		cleanupTables(t, pool)
+
+		e := testEntry()
+		insertEntry(t, pool, e)
+		golang, web, db := testTag("golang"), testTag("web"), testTag("database")
+		for _, tg := range []*tag.Tag{golang, web, db} {
+			insertTag(t, pool, tg)
+		}
+		insertEntryTag(t, pool, e.ID, golang.ID, 80)
+
+		added, err := repo.AttachEntryTags(ctx, e.ID, []entry.Tagging{
+			{TagID: golang.ID, Score: 10},
+			{TagID: web.ID, Score: 70},
+			{TagID: db.ID, Score: 50},
+			{TagID: web.ID, Score: 20},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), added)
+
+		got, err := entryRepo.Get(ctx, e.ID)
+		require.NoError(t, err)
+		scores := make(map[string]int, len(got.Tags))
+		for _, tg := range got.Tags {
+			scores[tg.Name] = tg.Score
+		}
+		assert.Equal(t, map[string]int{"golang": 80, "web": 70, "database": 50}, scores)
+
+		added, err = repo.AttachEntryTags(ctx, e.ID, nil)
+		require.NoError(t, err)
+		assert.Zero(t, added)
+	})
+
+	t.Run("detach removes link by name", func(t *testing.T) {
+		cleanupTables(t, pool)
+
+		e := testEntry()
+		insertEntry(t, pool, e)
+		tg := testTag("golang")
+		insertTag(t, pool, tg)
+		insertEntryTag(t, pool, e.ID, tg.ID, 80)
+
+		removed, err := repo.DetachEntry(ctx, e.ID, " GoLang ")
+		require.NoError(t, err)
+		assert.True(t, removed)
+
+		removed, err = repo.DetachEntry(ctx, e.ID, "golang")
+		require.NoError(t, err)
+		assert.False(t, removed)
+
+		// The tag itself is kept.
+		_, err = repo.Get(ctx, tg.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("update score by name", func(t *testing.T) {
+		cleanupTables(t, pool)
+
+		e := testEntry()
+		insertEntry(t, pool, e)
+		tg := testTag("golang")
+		insertTag(t, pool, tg)
+		insertEntryTag(t, pool, e.ID, tg.ID, 80)
+
+		updated, err := repo.UpdateEntryScore(ctx, e.ID, " GoLang ", 25)
+		require.NoError(t, err)
+		assert.True(t, updated)
+
+		got, err := entryRepo.Get(ctx, e.ID)
+		require.NoError(t, err)
+		require.Len(t, got.Tags, 1)
+		assert.Equal(t, 25, got.Tags[0].Score)
+
+		updated, err = repo.UpdateEntryScore(ctx, e.ID, "rust", 25)
+		require.NoError(t, err)
+		assert.False(t, updated)
+	})
+}
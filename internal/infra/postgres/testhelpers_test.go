@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -98,13 +99,34 @@ func applyTestMigrations(ctx context.Context, pool *pgxpool.Pool, connStr ...str
 		return fmt.Errorf("grant schema: %w", err)
 	}
 
-	// Find the migrations directory from current working directory
+	absMigrationsDir, err := findMigrationsDir()
+	if err != nil {
+		return err
+	}
+
+	m, err := migrate.New("file://"+absMigrationsDir, migrationConnStr)
+	if err != nil {
+		return fmt.Errorf("create migrate instance: %w", err)
+	}
+	defer func() {
+		_, _ = m.Close()
+	}()
+
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("migrate up: %w", err)
+	}
+
+	return nil
+}
+
+// findMigrationsDir locates the migrations directory starting from the working directory
+// and going up.
+func findMigrationsDir() (string, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
-		return fmt.Errorf("get working directory: %w", err)
+		return "", fmt.Errorf("get working directory: %w", err)
 	}
 
-	// Try to find migrations directory starting from cwd and going up
 	migrationsDir := filepath.Join(cwd, "migrations")
 	for i := 0; i < 5; i++ {
 		if _, err := os.Stat(migrationsDir); err == nil {
@@ -114,27 +136,25 @@ func applyTestMigrations(ctx context.Context, pool *pgxpool.Pool, connStr ...str
 		migrationsDir = filepath.Join(cwd, "migrations")
 	}
 	if _, err := os.Stat(migrationsDir); err != nil {
-		return fmt.Errorf("migrations directory not found: %w", err)
+		return "", fmt.Errorf("migrations directory not found: %w", err)
 	}
 
 	absMigrationsDir, err := filepath.Abs(migrationsDir)
 	if err != nil {
-		return fmt.Errorf("resolve migrations directory: %w", err)
+		return "", fmt.Errorf("resolve migrations directory: %w", err)
 	}
+	return absMigrationsDir, nil
+}
 
-	m, err := migrate.New("file://"+absMigrationsDir, migrationConnStr)
-	if err != nil {
-		return fmt.Errorf("create migrate instance: %w", err)
-	}
-	defer func() {
-		_, _ = m.Close()
-	}()
-
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("migrate up: %w", err)
-	}
+// readMigration returns the SQL of a single migration file.
+func readMigration(t *testing.T, name string) string {
+	t.Helper()
 
-	return nil
+	dir, err := findMigrationsDir()
+	require.NoError(t, err)
+	body, err := os.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+	return string(body)
 }
 
 // cleanupTables removes all data from test tables.
@@ -179,6 +199,32 @@ GROUP BY day, t.threshold`
 	}
 }
 
+// explainPlan returns the EXPLAIN output for sql with sequential scans disabled. Test tables
+// are small enough that a sequential scan would win, so this makes the planner pick between the
+// indexes; a Seq Scan left in the plan means no usable index exists.
+func explainPlan(t *testing.T, ctx context.Context, pool *pgxpool.Pool, sql string, args ...any) string {
+	t.Helper()
+
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback(ctx) }()
+	_, err = tx.Exec(ctx, "SET LOCAL enable_seqscan = off")
+	require.NoError(t, err)
+
+	rows, err := tx.Query(ctx, "EXPLAIN "+sql, args...)
+	require.NoError(t, err)
+	defer rows.Close()
+	var plan strings.Builder
+	for rows.Next() {
+		var line string
+		require.NoError(t, rows.Scan(&line))
+		plan.WriteString(line)
+		plan.WriteString("\n")
+	}
+	require.NoError(t, rows.Err())
+	return plan.String()
+}
+
 // testEntry creates a test entry with default values.
 func testEntry(overrides ...func(*entry.Entry)) *entry.Entry {
 	e := &entry.Entry{
@@ -28,6 +28,7 @@ type CacheTTLConfig struct {
 }
 
 // DayEntriesCache caches all entries for a given JST date (YYYYMMDD).
+// Dates resolved in another timezone carry an "@<zone>" suffix, keeping keys distinct.
 type DayEntriesCache struct {
 	cache *snappyJSONCache
 }
@@ -53,7 +54,19 @@ func (c *DayEntriesCache) Set(ctx context.Context, date string, entries []*domai
 	return c.cache.Set(ctx, c.key(date), entries)
 }
 
-// TagEntriesCache caches the first page of tag entries for a given tag.
+// Delete drops cached day entries for the given date, including the variants cached per timezone.
+func (c *DayEntriesCache) Delete(ctx context.Context, date string) error {
+	if err := c.cache.Delete(ctx, c.key(date)); err != nil {
+		return err
+	}
+	if strings.Contains(date, "@") {
+		return nil
+	}
+	_, err := c.cache.client.DeleteByPattern(ctx, c.key(date+"@*"), 0)
+	return err
+}
+
+// TagEntriesCache caches the first page of tag entries for a given tag and min_users segment.
 type TagEntriesCache struct {
 	cache *snappyJSONCache
 }
@@ -78,6 +91,11 @@ func (c *TagEntriesCache) Set(ctx context.Context, tagName string, sort domainEn
 	return c.cache.Set(ctx, c.key(tagName, sort, minUsers), value)
 }
 
+// Delete drops cached tag entries for the given tag name and segment.
+func (c *TagEntriesCache) Delete(ctx context.Context, tagName string, sort domainEntry.SortType, minUsers int) error {
+	return c.cache.Delete(ctx, c.key(tagName, sort, minUsers))
+}
+
 // SearchCache caches full search responses for a given query+params.
 type SearchCache struct {
 	cache *snappyJSONCache
@@ -103,7 +121,7 @@ func (c *SearchCache) Set(ctx context.Context, query string, sort domainEntry.So
 	return c.cache.Set(ctx, c.key(query, sort, minUsers, limit, offset), value)
 }
 
-// TagsListCache caches tag list responses.
+// TagsListCache caches tag list responses per view (sort mode and window) and page.
 type TagsListCache struct {
 	cache *snappyJSONCache
 }
@@ -113,18 +131,44 @@ func NewTagsListCache(client bytesCacheClient, ttl time.Duration) *TagsListCache
 	return &TagsListCache{cache: newSnappyJSONCache(client, ttl)}
 }
 
-func (c *TagsListCache) key(limit, offset int) string {
-	return fmt.Sprintf("hateblog:tags:list:%d:%d", limit, offset)
+func (c *TagsListCache) key(view domainTag.ListView, limit, offset int) string {
+	sort := view.Sort
+	if sort == "" {
+		sort = domainTag.ListSortName
+	}
+	return fmt.Sprintf("hateblog:tags:list:%s:%d:%d:%d", sort, view.WindowHours, limit, offset)
 }
 
 // Get returns cached tag list responses.
-func (c *TagsListCache) Get(ctx context.Context, limit, offset int, out any) (bool, error) {
-	return c.cache.Get(ctx, c.key(limit, offset), out)
+func (c *TagsListCache) Get(ctx context.Context, view domainTag.ListView, limit, offset int, out any) (bool, error) {
+	return c.cache.Get(ctx, c.key(view, limit, offset), out)
 }
 
 // Set stores tag list responses.
-func (c *TagsListCache) Set(ctx context.Context, limit, offset int, value any) error {
-	return c.cache.Set(ctx, c.key(limit, offset), value)
+func (c *TagsListCache) Set(ctx context.Context, view domainTag.ListView, limit, offset int, value any) error {
+	return c.cache.Set(ctx, c.key(view, limit, offset), value)
+}
+
+// MetricsSummaryCache caches the admin metrics summary for a short time.
+type MetricsSummaryCache struct {
+	cache *snappyJSONCache
+}
+
+// NewMetricsSummaryCache builds a metrics summary cache.
+func NewMetricsSummaryCache(client bytesCacheClient, ttl time.Duration) *MetricsSummaryCache {
+	return &MetricsSummaryCache{cache: newSnappyJSONCache(client, ttl)}
+}
+
+const metricsSummaryKey = "hateblog:metrics:summary"
+
+// Get returns the cached summary.
+func (c *MetricsSummaryCache) Get(ctx context.Context, out any) (bool, error) {
+	return c.cache.Get(ctx, metricsSummaryKey, out)
+}
+
+// Set stores the summary.
+func (c *MetricsSummaryCache) Set(ctx context.Context, value any) error {
+	return c.cache.Set(ctx, metricsSummaryKey, value)
 }
 
 // ArchiveCache caches archive responses with separate TTLs for today and past data.
@@ -267,3 +311,29 @@ func (c *WeeklyRankingCache) Get(ctx context.Context, year, week, minUsers int,
 func (c *WeeklyRankingCache) Set(ctx context.Context, year, week, minUsers int, value any) error {
 	return newSnappyJSONCache(c.client, c.ttl(year, week, time.Now())).Set(ctx, c.key(year, week, minUsers), value)
 }
+
+// EngagementRankingCache caches engagement ranking entries (up to max) per period key
+// (e.g. "monthly:2025:1") and min_users. Click counts keep changing, so one short TTL is used for every period.
+type EngagementRankingCache struct {
+	client bytesCacheClient
+	ttl    time.Duration
+}
+
+// NewEngagementRankingCache builds an engagement ranking cache.
+func NewEngagementRankingCache(client bytesCacheClient, ttl time.Duration) *EngagementRankingCache {
+	return &EngagementRankingCache{client: client, ttl: ttl}
+}
+
+func (c *EngagementRankingCache) key(period string, minUsers int) string {
+	return fmt.Sprintf("hateblog:rankings:engagement:%s:%d", period, minUsers)
+}
+
+// Get returns cached engagement rankings.
+func (c *EngagementRankingCache) Get(ctx context.Context, period string, minUsers int, out any) (bool, error) {
+	return newSnappyJSONCache(c.client, c.ttl).Get(ctx, c.key(period, minUsers), out)
+}
+
+// Set stores engagement rankings.
+func (c *EngagementRankingCache) Set(ctx context.Context, period string, minUsers int, value any) error {
+	return newSnappyJSONCache(c.client, c.ttl).Set(ctx, c.key(period, minUsers), value)
+}
@@ -0,0 +1,89 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	domainEntry "hateblog/internal/domain/entry"
+	domainTag "hateblog/internal/domain/tag"
+)
+
+func TestDayEntriesCacheDeleteClearsZonedKeys(t *testing.T) {
+	ctx := context.Background()
+	client := &flakyBytesClient{}
+	c := NewDayEntriesCache(client, time.Minute)
+	entries := []*domainEntry.Entry{{Title: "a"}}
+
+	require.NoError(t, c.Set(ctx, "20250101", entries))
+	require.NoError(t, c.Set(ctx, "20250101@UTC", entries))
+	require.NoError(t, c.Set(ctx, "20250101@America/New_York", entries))
+	require.NoError(t, c.Set(ctx, "20250102@UTC", entries))
+
+	require.NoError(t, c.Delete(ctx, "20250101"))
+
+	for _, date := range []string{"20250101", "20250101@UTC", "20250101@America/New_York"} {
+		_, ok, err := c.Get(ctx, date)
+		require.NoError(t, err)
+		require.False(t, ok, date)
+	}
+	_, ok, err := c.Get(ctx, "20250102@UTC")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestTagsListCacheKeyPerView(t *testing.T) {
+	c := NewTagsListCache(&flakyBytesClient{}, time.Minute)
+
+	byName := domainTag.ListView{Sort: domainTag.ListSortName}
+	byCount := domainTag.ListView{Sort: "entry_count"}
+	trending24h := domainTag.ListView{Sort: "trending", WindowHours: 24}
+	trending168h := domainTag.ListView{Sort: "trending", WindowHours: 168}
+
+	require.Equal(t, "hateblog:tags:list:name:0:50:0", c.key(byName, 50, 0))
+	require.Equal(t, c.key(byName, 50, 0), c.key(domainTag.ListView{}, 50, 0))
+
+	keys := map[string]struct{}{}
+	for _, view := range []domainTag.ListView{byName, byCount, trending24h, trending168h} {
+		keys[c.key(view, 50, 0)] = struct{}{}
+	}
+	require.Len(t, keys, 4)
+}
+
+func TestTagsListCacheViewsDoNotOverwriteEachOther(t *testing.T) {
+	ctx := context.Background()
+	c := NewTagsListCache(&flakyBytesClient{}, time.Minute)
+	byName := domainTag.ListView{Sort: domainTag.ListSortName}
+	trending := domainTag.ListView{Sort: "trending", WindowHours: 24}
+
+	require.NoError(t, c.Set(ctx, byName, 50, 0, []string{"go", "rust"}))
+	require.NoError(t, c.Set(ctx, trending, 50, 0, []string{"rust"}))
+
+	var got []string
+	ok, err := c.Get(ctx, byName, 50, 0, &got)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []string{"go", "rust"}, got)
+
+	ok, err = c.Get(ctx, domainTag.ListView{Sort: "trending", WindowHours: 168}, 50, 0, &got)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestEngagementRankingCacheKey(t *testing.T) {
+	ctx := context.Background()
+	c := NewEngagementRankingCache(&flakyBytesClient{}, time.Minute)
+	require.Equal(t, "hateblog:rankings:engagement:monthly:2025:1:5", c.key("monthly:2025:1", 5))
+
+	require.NoError(t, c.Set(ctx, "yearly:2025", 5, []string{"a"}))
+	var got []string
+	ok, err := c.Get(ctx, "yearly:2025", 10, &got)
+	require.NoError(t, err)
+	require.False(t, ok)
+	ok, err = c.Get(ctx, "yearly:2025", 5, &got)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []string{"a"}, got)
+}
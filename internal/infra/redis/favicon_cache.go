@@ -20,8 +20,11 @@ type FaviconCache struct {
 type cacheClient interface {
 	Get(ctx context.Context, key string) (string, error)
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
 }
 
+const faviconKeyPrefix = "favicon:"
+
 // NewFaviconCache constructs a cache wrapper.
 func NewFaviconCache(client cacheClient, ttl time.Duration) *FaviconCache {
 	return &FaviconCache{
@@ -30,13 +33,29 @@ func NewFaviconCache(client cacheClient, ttl time.Duration) *FaviconCache {
 	}
 }
 
-// BuildKey normalizes the domain and returns cache key.
+// BuildKey returns the cache key of the domain's favicon host (see hostname.Favicon).
 func (c *FaviconCache) BuildKey(domain string) (string, error) {
-	host, err := hostname.Normalize(domain)
+	host, err := hostname.Favicon(domain)
 	if err != nil {
 		return "", err
 	}
-	return "favicon:" + host, nil
+	return faviconKeyPrefix + host, nil
+}
+
+// StaleKey returns the key the domain was cached under when keys used the plain normalized
+// host, and whether it differs from BuildKey. A differing key is no longer read and can be
+// purged.
+func (c *FaviconCache) StaleKey(domain string) (string, bool, error) {
+	host, err := hostname.Normalize(domain)
+	if err != nil {
+		return "", false, err
+	}
+	current, err := c.BuildKey(host)
+	if err != nil {
+		return "", false, err
+	}
+	stale := faviconKeyPrefix + host
+	return stale, stale != current, nil
 }
 
 // Get retrieves favicon from cache.
@@ -81,6 +100,11 @@ func (c *FaviconCache) SetNegative(ctx context.Context, key string) error {
 	return c.client.Set(ctx, key+":neg", "1", c.ttl)
 }
 
+// Delete removes the favicon and the negative entry stored under key.
+func (c *FaviconCache) Delete(ctx context.Context, key string) error {
+	return c.client.Delete(ctx, key, key+":neg")
+}
+
 // IsNegative checks if a negative cache entry exists.
 func (c *FaviconCache) IsNegative(ctx context.Context, key string) (bool, error) {
 	_, err := c.client.Get(ctx, key+":neg")
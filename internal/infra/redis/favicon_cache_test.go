@@ -43,6 +43,44 @@ func TestFaviconCacheInvalidDomain(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestFaviconCacheKeyIgnoresWWW(t *testing.T) {
+	client := &mockCache{store: make(map[string]string)}
+	fc := NewFaviconCache(client, time.Minute)
+
+	bare, err := fc.BuildKey("example.com")
+	require.NoError(t, err)
+	www, err := fc.BuildKey("https://WWW.example.com/entry")
+	require.NoError(t, err)
+	require.Equal(t, "favicon:example.com", bare)
+	require.Equal(t, bare, www)
+}
+
+func TestFaviconCacheStaleKey(t *testing.T) {
+	client := &mockCache{store: make(map[string]string)}
+	fc := NewFaviconCache(client, time.Minute)
+
+	key, stale, err := fc.StaleKey("www.example.com")
+	require.NoError(t, err)
+	require.True(t, stale)
+	require.Equal(t, "favicon:www.example.com", key)
+
+	key, stale, err = fc.StaleKey("example.com")
+	require.NoError(t, err)
+	require.False(t, stale)
+	require.Equal(t, "favicon:example.com", key)
+}
+
+func TestFaviconCacheDelete(t *testing.T) {
+	client := &mockCache{store: make(map[string]string)}
+	fc := NewFaviconCache(client, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, fc.Set(ctx, "favicon:www.example.com", []byte{1}, "image/png"))
+	require.NoError(t, fc.SetNegative(ctx, "favicon:www.example.com"))
+	require.NoError(t, fc.Delete(ctx, "favicon:www.example.com"))
+	require.Empty(t, client.store)
+}
+
 type mockCache struct {
 	store map[string]string
 }
@@ -65,3 +103,10 @@ func (m *mockCache) Set(ctx context.Context, key string, value interface{}, ttl
 	}
 	return nil
 }
+
+func (m *mockCache) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(m.store, key)
+	}
+	return nil
+}
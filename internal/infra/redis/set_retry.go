@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SetRetryClient retries failed cache writes a bounded number of times.
+// A brief Redis blip would otherwise drop the write and send the next request to the database.
+type SetRetryClient struct {
+	client      bytesCacheClient
+	maxAttempts int
+	backoff     time.Duration
+	wait        func(ctx context.Context, d time.Duration) error
+}
+
+// NewSetRetryClient wraps client so that Set is attempted up to maxAttempts times.
+// The delay starts at backoff and doubles after each failed attempt.
+func NewSetRetryClient(client bytesCacheClient, maxAttempts int, backoff time.Duration) *SetRetryClient {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &SetRetryClient{
+		client:      client,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		wait:        waitContext,
+	}
+}
+
+// GetBytes reads from the underlying client without retrying.
+func (c *SetRetryClient) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	return c.client.GetBytes(ctx, key)
+}
+
+// Set writes the value and retries on failure. The last error is returned once attempts are exhausted.
+func (c *SetRetryClient) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	delay := c.backoff
+	var err error
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		err = c.client.Set(ctx, key, value, ttl)
+		if err == nil || isContextDone(err) || attempt == c.maxAttempts {
+			return err
+		}
+		if waitErr := c.wait(ctx, delay); waitErr != nil {
+			return err
+		}
+		delay *= 2
+	}
+	return err
+}
+
+// Delete removes keys from the underlying client without retrying.
+func (c *SetRetryClient) Delete(ctx context.Context, keys ...string) error {
+	return c.client.Delete(ctx, keys...)
+}
+
+// DeleteByPattern removes matching keys through the underlying client without retrying.
+func (c *SetRetryClient) DeleteByPattern(ctx context.Context, pattern string, batchSize int64) (int64, error) {
+	return c.client.DeleteByPattern(ctx, pattern, batchSize)
+}
+
+func waitContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func isContextDone(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
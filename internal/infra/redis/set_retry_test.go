@@ -0,0 +1,121 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"hateblog/internal/platform/cache"
+)
+
+type flakyBytesClient struct {
+	failures int
+	setCalls int
+	store    map[string][]byte
+	err      error
+}
+
+func (c *flakyBytesClient) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	v, ok := c.store[key]
+	if !ok {
+		return nil, cache.ErrCacheMiss
+	}
+	return v, nil
+}
+
+func (c *flakyBytesClient) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	c.setCalls++
+	if c.setCalls <= c.failures {
+		if c.err != nil {
+			return c.err
+		}
+		return errors.New("connection reset")
+	}
+	if c.store == nil {
+		c.store = make(map[string][]byte)
+	}
+	c.store[key] = value.([]byte)
+	return nil
+}
+
+func (c *flakyBytesClient) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(c.store, key)
+	}
+	return nil
+}
+
+func (c *flakyBytesClient) DeleteByPattern(ctx context.Context, pattern string, batchSize int64) (int64, error) {
+	// Redis globs let * span any characters, including the "/" of zone names.
+	re := regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$")
+	var deleted int64
+	for key := range c.store {
+		if re.MatchString(key) {
+			delete(c.store, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func newTestSetRetryClient(inner bytesCacheClient, maxAttempts int, waits *[]time.Duration) *SetRetryClient {
+	c := NewSetRetryClient(inner, maxAttempts, 10*time.Millisecond)
+	c.wait = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	return c
+}
+
+func TestSetRetryClientSucceedsOnSecondAttempt(t *testing.T) {
+	inner := &flakyBytesClient{failures: 1}
+	var waits []time.Duration
+	c := newSnappyJSONCache(newTestSetRetryClient(inner, 3, &waits), time.Minute)
+
+	require.NoError(t, c.Set(context.Background(), "hateblog:test", []string{"a", "b"}))
+	require.Equal(t, 2, inner.setCalls)
+	require.Equal(t, []time.Duration{10 * time.Millisecond}, waits)
+
+	var got []string
+	ok, err := c.Get(context.Background(), "hateblog:test", &got)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []string{"a", "b"}, got)
+}
+
+func TestSetRetryClientGivesUpAfterMaxAttempts(t *testing.T) {
+	inner := &flakyBytesClient{failures: 5}
+	var waits []time.Duration
+	c := newTestSetRetryClient(inner, 3, &waits)
+
+	err := c.Set(context.Background(), "hateblog:test", []byte("x"), time.Minute)
+	require.EqualError(t, err, "connection reset")
+	require.Equal(t, 3, inner.setCalls)
+	require.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, waits)
+}
+
+func TestSetRetryClientDoesNotRetryCanceledContext(t *testing.T) {
+	inner := &flakyBytesClient{failures: 5, err: fmt.Errorf("failed to set cache: %w", context.Canceled)}
+	var waits []time.Duration
+	c := newTestSetRetryClient(inner, 3, &waits)
+
+	err := c.Set(context.Background(), "hateblog:test", []byte("x"), time.Minute)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, inner.setCalls)
+	require.Empty(t, waits)
+}
+
+func TestSetRetryClientSingleAttempt(t *testing.T) {
+	inner := &flakyBytesClient{failures: 1}
+	var waits []time.Duration
+	c := newTestSetRetryClient(inner, 0, &waits)
+
+	require.Error(t, c.Set(context.Background(), "hateblog:test", []byte("x"), time.Minute))
+	require.Equal(t, 1, inner.setCalls)
+}
@@ -17,6 +17,8 @@ import (
 type bytesCacheClient interface {
 	GetBytes(ctx context.Context, key string) ([]byte, error)
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	DeleteByPattern(ctx context.Context, pattern string, batchSize int64) (int64, error)
 }
 
 type snappyJSONCache struct {
@@ -55,6 +57,10 @@ func (c *snappyJSONCache) Set(ctx context.Context, key string, value any) error
 	return c.client.Set(ctx, key, payload, c.ttl)
 }
 
+func (c *snappyJSONCache) Delete(ctx context.Context, keys ...string) error {
+	return c.client.Delete(ctx, keys...)
+}
+
 func sha256Hex(value string) string {
 	sum := sha256.Sum256([]byte(value))
 	return hex.EncodeToString(sum[:])
@@ -31,7 +31,16 @@ func ResolveCreatedAt(now, postedAt time.Time) time.Time {
 
 // ParseDate parses a "YYYYMMDD" date string in the application timezone.
 func ParseDate(date string) (time.Time, error) {
-	t, err := time.ParseInLocation("20060102", date, time.Local)
+	return ParseDateIn(date, time.Local)
+}
+
+// ParseDateIn parses a "YYYYMMDD" date string in the given location.
+// A nil location falls back to the application timezone.
+func ParseDateIn(date string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	t, err := time.ParseInLocation("20060102", date, loc)
 	if err != nil {
 		return time.Time{}, fmt.Errorf("invalid date: %s", date)
 	}
@@ -41,7 +50,12 @@ func ParseDate(date string) (time.Time, error) {
 // DayRange returns the start and end of a day parsed from a "YYYYMMDD" string.
 // start is midnight, end is midnight of the following day.
 func DayRange(date string) (start, end time.Time, err error) {
-	start, err = ParseDate(date)
+	return DayRangeIn(date, time.Local)
+}
+
+// DayRangeIn is like DayRange but interprets the date in the given location.
+func DayRangeIn(date string, loc *time.Location) (start, end time.Time, err error) {
+	start, err = ParseDateIn(date, loc)
 	if err != nil {
 		return time.Time{}, time.Time{}, err
 	}
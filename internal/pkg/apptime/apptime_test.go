@@ -98,6 +98,24 @@ func TestDayRange(t *testing.T) {
 	})
 }
 
+func TestDayRangeIn(t *testing.T) {
+	t.Run("other location", func(t *testing.T) {
+		ny, err := time.LoadLocation("America/New_York")
+		require.NoError(t, err)
+		start, end, err := DayRangeIn("20240315", ny)
+		require.NoError(t, err)
+		require.Equal(t, ny, start.Location())
+		require.Equal(t, time.Date(2024, 3, 15, 4, 0, 0, 0, time.UTC), start.UTC())
+		require.Equal(t, time.Date(2024, 3, 16, 4, 0, 0, 0, time.UTC), end.UTC())
+	})
+
+	t.Run("nil location uses local", func(t *testing.T) {
+		start, _, err := DayRangeIn("20240315", nil)
+		require.NoError(t, err)
+		require.Equal(t, time.Local, start.Location())
+	})
+}
+
 func TestYearRange(t *testing.T) {
 	t.Run("normal year", func(t *testing.T) {
 		start, end, err := YearRange(2024)
@@ -163,6 +181,25 @@ func TestISOWeekRange(t *testing.T) {
 		require.Equal(t, 1, isoWeek)
 	})
 
+	t.Run("week 1 of 2025 starts in 2024", func(t *testing.T) {
+		start, end, err := ISOWeekRange(2025, 1)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2024, time.December, 30, 0, 0, 0, 0, time.Local), start)
+		require.Equal(t, time.Date(2025, time.January, 6, 0, 0, 0, 0, time.Local), end)
+	})
+
+	t.Run("week 53 of 2020 ends in 2021", func(t *testing.T) {
+		start, end, err := ISOWeekRange(2020, 53)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2020, time.December, 28, 0, 0, 0, 0, time.Local), start)
+		require.Equal(t, time.Date(2021, time.January, 4, 0, 0, 0, 0, time.Local), end)
+	})
+
+	t.Run("week 53 of a 52-week year", func(t *testing.T) {
+		_, _, err := ISOWeekRange(2024, 53)
+		require.Error(t, err)
+	})
+
 	t.Run("week zero", func(t *testing.T) {
 		_, _, err := ISOWeekRange(2024, 0)
 		require.Error(t, err)
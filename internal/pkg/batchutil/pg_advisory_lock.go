@@ -6,6 +6,7 @@ import (
 	"hash/fnv"
 	"math"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -43,3 +44,17 @@ func TryAdvisoryLock(ctx context.Context, pool *pgxpool.Pool, name string) (lock
 		return nil
 	}, nil
 }
+
+// Execer runs a statement, as pgx.Tx does.
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// AdvisoryXactLock waits for the transaction-level advisory lock name in tx. It is released
+// when tx commits or rolls back, so writers of the same data serialize instead of skipping.
+func AdvisoryXactLock(ctx context.Context, tx Execer, name string) error {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, LockID(name)); err != nil {
+		return fmt.Errorf("advisory xact lock %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Package cachefallback counts database loads forced by cache errors.
+// A plain cache miss is expected and is not counted; only lookups that failed
+// (for example because Redis is unreachable) are, so alerts can catch Redis degradation.
+package cachefallback
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Reasons reported in the reason label.
+const (
+	ReasonTimeout = "timeout"
+	ReasonError   = "error"
+)
+
+// Total is the cache_fallback_total counter. It is registered by the HTTP metrics registry.
+var Total = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "cache_fallback_total",
+	Help: "Number of database loads caused by cache lookup errors (plain misses are excluded).",
+}, []string{"reason"})
+
+// Record increments the counter for a failed cache lookup. A nil error is ignored.
+func Record(err error) {
+	if err == nil {
+		return
+	}
+	Total.WithLabelValues(Reason(err)).Inc()
+}
+
+// Reason classifies a cache lookup error.
+func Reason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ReasonTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ReasonTimeout
+	}
+	return ReasonError
+}
@@ -0,0 +1,22 @@
+package cachefallback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	before := testutil.ToFloat64(Total.WithLabelValues(ReasonError))
+	Record(errors.New("connection refused"))
+	Record(nil)
+	require.Equal(t, before+1, testutil.ToFloat64(Total.WithLabelValues(ReasonError)))
+
+	beforeTimeout := testutil.ToFloat64(Total.WithLabelValues(ReasonTimeout))
+	Record(fmt.Errorf("failed to get cache: %w", context.DeadlineExceeded))
+	require.Equal(t, beforeTimeout+1, testutil.ToFloat64(Total.WithLabelValues(ReasonTimeout)))
+}
@@ -0,0 +1,19 @@
+// Package daycap counts day loads that reached the row cap.
+// A day list is loaded in full (up to the cap) and cached; a load that returns exactly the cap
+// has probably been truncated, so alerts can catch pathologically large days.
+package daycap
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Total is the day_entries_load_capped_total counter. It is registered by the HTTP metrics registry.
+var Total = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "day_entries_load_capped_total",
+	Help: "Number of day entry loads that returned as many rows as the cap and may be truncated.",
+})
+
+// Record increments the counter.
+func Record() {
+	Total.Inc()
+}
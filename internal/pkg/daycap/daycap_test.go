@@ -0,0 +1,14 @@
+package daycap
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	before := testutil.ToFloat64(Total)
+	Record()
+	require.Equal(t, before+1, testutil.ToFloat64(Total))
+}
@@ -34,3 +34,17 @@ func Normalize(raw string) (string, error) {
 	}
 	return value, nil
 }
+
+// Favicon returns the host whose favicon represents raw (a hostname or URL): the normalized host
+// without a leading "www." label, so that both spellings share one cached icon. A bare "www.tld"
+// is kept as is.
+func Favicon(raw string) (string, error) {
+	host, err := Normalize(raw)
+	if err != nil {
+		return "", err
+	}
+	if rest, ok := strings.CutPrefix(host, "www."); ok && strings.Contains(rest, ".") {
+		return rest, nil
+	}
+	return host, nil
+}
@@ -25,3 +25,22 @@ func TestNormalizeInvalid(t *testing.T) {
 		require.Error(t, err, input)
 	}
 }
+
+func TestFavicon(t *testing.T) {
+	tests := map[string]string{
+		"example.com":                    "example.com",
+		"WWW.Example.com":                "example.com",
+		"https://www.example.co.jp/path": "example.co.jp",
+		"www2.example.com":               "www2.example.com",
+		"blog.www.example.com":           "blog.www.example.com",
+		"www.com":                        "www.com",
+	}
+	for input, expected := range tests {
+		got, err := Favicon(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, got, input)
+	}
+
+	_, err := Favicon("bad host")
+	require.Error(t, err)
+}
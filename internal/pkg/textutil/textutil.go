@@ -0,0 +1,14 @@
+package textutil
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SanitizeUTF8 removes invalid UTF-8 sequences from s. Valid strings are returned as is.
+func SanitizeUTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	return strings.ToValidUTF8(s, "")
+}
@@ -0,0 +1,40 @@
+package textutil
+
+import "testing"
+
+func TestSanitizeUTF8(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "valid ascii",
+			input: "hello",
+			want:  "hello",
+		},
+		{
+			name:  "valid utf8",
+			input: "こんにちは",
+			want:  "こんにちは",
+		},
+		{
+			name:  "invalid utf8",
+			input: "hello\xffworld",
+			want:  "helloworld",
+		},
+		{
+			name:  "mixed valid and invalid",
+			input: string([]byte{0xe3, 0x81, 0x82, 0xff, 0xe3, 0x81, 0x84}), // "あ" + invalid + "い"
+			want:  "あい",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeUTF8(tt.input); got != tt.want {
+				t.Errorf("SanitizeUTF8(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
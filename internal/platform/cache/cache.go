@@ -139,7 +139,8 @@ func (c *Cache) Delete(ctx context.Context, keys ...string) error {
 	return nil
 }
 
-// DeleteByPattern deletes keys that match the pattern using SCAN.
+// DeleteByPattern deletes keys that match the pattern using SCAN. When ctx is cancelled it
+// stops between batches and returns the number of keys deleted so far with ctx.Err().
 func (c *Cache) DeleteByPattern(ctx context.Context, pattern string, batchSize int64) (int64, error) {
 	if batchSize <= 0 {
 		batchSize = 500
@@ -147,6 +148,9 @@ func (c *Cache) DeleteByPattern(ctx context.Context, pattern string, batchSize i
 	var cursor uint64
 	var deleted int64
 	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
 		keys, next, err := c.client.Scan(ctx, cursor, pattern, batchSize).Result()
 		if err != nil {
 			c.logger.Error("failed to scan keys", "pattern", pattern, "error", err)
@@ -187,6 +191,17 @@ func (c *Cache) Expire(ctx context.Context, key string, ttl time.Duration) error
 	return nil
 }
 
+// TTL returns the remaining time to live of a key.
+// A non-positive duration means the key does not exist or has no expiration.
+func (c *Cache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.client.PTTL(ctx, key).Result()
+	if err != nil {
+		c.logger.Error("failed to get ttl", "key", key, "error", err)
+		return 0, fmt.Errorf("failed to get ttl: %w", err)
+	}
+	return ttl, nil
+}
+
 // Increment increments a counter in cache
 func (c *Cache) Increment(ctx context.Context, key string) (int64, error) {
 	val, err := c.client.Incr(ctx, key).Result()
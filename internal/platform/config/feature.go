@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// featurePrefix starts the environment variables that set feature flags.
+const featurePrefix = "FEATURE_"
+
+// Feature names consumed by the application.
+const (
+	// FeatureTrending enables sort=trending on the hot list.
+	FeatureTrending = "trending"
+)
+
+// Feature reports whether the named feature flag is on. Names are case-insensitive and "-"
+// matches "_", so Feature("fuzzy-search") reads FEATURE_FUZZY_SEARCH. Unset flags are off.
+func (c *Config) Feature(name string) bool {
+	if c == nil {
+		return false
+	}
+	return c.Features[featureKey(name)]
+}
+
+func featureKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "-", "_"))
+}
+
+// parseFeatures collects the FEATURE_<NAME>=<bool> variables of environ, given as KEY=value
+// pairs like os.Environ. Empty values leave the flag unset.
+func parseFeatures(environ []string) (map[string]bool, error) {
+	features := make(map[string]bool)
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, featurePrefix) {
+			continue
+		}
+		name := featureKey(strings.TrimPrefix(key, featurePrefix))
+		value = strings.TrimSpace(value)
+		if name == "" || value == "" {
+			continue
+		}
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %q (must be true or false)", key, value)
+		}
+		features[name] = on
+	}
+	return features, nil
+}
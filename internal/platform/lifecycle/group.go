@@ -0,0 +1,146 @@
+// Package lifecycle runs the app's background workers and stops them on shutdown, flushing
+// buffered writes last.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// RunFunc is the body of a background worker. It must return once ctx is canceled, after
+// flushing any in-flight work. Returning context.Canceled is not treated as a failure.
+type RunFunc func(ctx context.Context) error
+
+// FlushFunc writes buffered in-memory state to durable storage. It runs once during Stop with
+// Stop's context, so it is bounded by the shutdown timeout.
+type FlushFunc func(ctx context.Context) error
+
+type flusher struct {
+	name  string
+	flush FlushFunc
+}
+
+type worker struct {
+	name   string
+	run    RunFunc
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// Group runs registered workers until Stop. Workers are stopped in reverse registration order,
+// so a worker may depend on any worker registered before it.
+type Group struct {
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	workers  []*worker
+	flushers []flusher
+	started  bool
+	stopped  bool
+}
+
+// NewGroup creates an empty Group. logger may be nil.
+func NewGroup(logger *slog.Logger) *Group {
+	return &Group{logger: logger}
+}
+
+// Add registers a worker. It must be called before Start.
+func (g *Group) Add(name string, run RunFunc) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.started {
+		panic(fmt.Sprintf("lifecycle: worker %q added after Start", name))
+	}
+	g.workers = append(g.workers, &worker{name: name, run: run, done: make(chan struct{})})
+}
+
+// AddFlush registers a flush that Stop runs after every worker has stopped, so it also sees
+// what the workers buffered while stopping. Flushes run in reverse registration order.
+func (g *Group) AddFlush(name string, flush FlushFunc) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.flushers = append(g.flushers, flusher{name: name, flush: flush})
+}
+
+// Start runs every registered worker in its own goroutine. A worker that fails before Stop is
+// logged; the others keep running. Calling Start again has no effect.
+func (g *Group) Start() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.started {
+		return
+	}
+	g.started = true
+	for _, w := range g.workers {
+		ctx, cancel := context.WithCancel(context.Background())
+		w.cancel = cancel
+		go func() {
+			defer close(w.done)
+			w.err = w.run(ctx)
+			if w.err != nil && !errors.Is(w.err, context.Canceled) && ctx.Err() == nil && g.logger != nil {
+				g.logger.Error("background worker failed", "worker", w.name, "error", w.err)
+			}
+		}()
+	}
+}
+
+// Stop signals each worker to stop, in reverse registration order, and waits for it to return
+// before stopping the next. It stops waiting with ctx's error when ctx ends first; workers not yet
+// stopped are still signaled. The registered flushes run afterwards, even without Start, and
+// even when ctx has ended so that they can report what they could not write. Worker and flush
+// errors are joined into the result.
+func (g *Group) Stop(ctx context.Context) error {
+	g.mu.Lock()
+	if g.stopped {
+		g.mu.Unlock()
+		return nil
+	}
+	var workers []*worker
+	if g.started {
+		g.stopped = true
+		workers = g.workers
+	}
+	flushers := g.flushers
+	g.flushers = nil
+	g.mu.Unlock()
+
+	errs := g.stopWorkers(ctx, workers)
+	for i := len(flushers) - 1; i >= 0; i-- {
+		f := flushers[i]
+		if err := f.flush(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("flush %s: %w", f.name, err))
+			continue
+		}
+		if g.logger != nil {
+			g.logger.Info("buffered writes flushed", "buffer", f.name)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (g *Group) stopWorkers(ctx context.Context, workers []*worker) []error {
+	var errs []error
+	for i := len(workers) - 1; i >= 0; i-- {
+		w := workers[i]
+		w.cancel()
+		select {
+		case <-w.done:
+			if w.err != nil && !errors.Is(w.err, context.Canceled) {
+				errs = append(errs, fmt.Errorf("worker %s: %w", w.name, w.err))
+			}
+		case <-ctx.Done():
+			for _, rest := range workers[:i] {
+				rest.cancel()
+			}
+			return append(errs, fmt.Errorf("worker %s did not stop: %w", w.name, ctx.Err()))
+		}
+		if g.logger != nil {
+			g.logger.Info("background worker stopped", "worker", w.name)
+		}
+	}
+	return errs
+}
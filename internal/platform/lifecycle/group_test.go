@@ -0,0 +1,184 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupStopsWorkersInReverseOrder(t *testing.T) {
+	g := NewGroup(nil)
+	var (
+		mu      sync.Mutex
+		stopped []string
+		flushed = make(map[string]bool)
+	)
+	started := make(chan struct{}, 2)
+	worker := func(name string) RunFunc {
+		return func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			// Simulate flushing in-flight work after the stop signal.
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			flushed[name] = true
+			stopped = append(stopped, name)
+			return ctx.Err()
+		}
+	}
+	g.Add("recorder", worker("recorder"))
+	g.Add("sender", worker("sender"))
+	g.Start()
+	<-started
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, g.Stop(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"sender", "recorder"}, stopped)
+	assert.True(t, flushed["recorder"])
+	assert.True(t, flushed["sender"])
+}
+
+func TestGroupStopReturnsWorkerErrors(t *testing.T) {
+	g := NewGroup(nil)
+	boom := errors.New("flush failed")
+	g.Add("failing", func(ctx context.Context) error {
+		<-ctx.Done()
+		return boom
+	})
+	g.Start()
+
+	err := g.Stop(context.Background())
+	require.ErrorIs(t, err, boom)
+	require.NoError(t, g.Stop(context.Background()), "second Stop is a no-op")
+}
+
+func TestGroupStopTimesOut(t *testing.T) {
+	g := NewGroup(nil)
+	release := make(chan struct{})
+	defer close(release)
+	var signaled sync.WaitGroup
+	signaled.Add(1)
+	g.Add("first", func(ctx context.Context) error {
+		<-ctx.Done()
+		signaled.Done()
+		return nil
+	})
+	g.Add("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	g.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := g.Stop(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	// Workers behind the stuck one are still signaled.
+	signaled.Wait()
+}
+
+func TestGroupStopWithoutStart(t *testing.T) {
+	g := NewGroup(nil)
+	g.Add("idle", func(ctx context.Context) error { return nil })
+	require.NoError(t, g.Stop(context.Background()))
+}
+
+// bufferedCounter buffers increments in memory until Flush writes them to store.
+type bufferedCounter struct {
+	mu      sync.Mutex
+	pending map[string]int
+	store   map[string]int
+}
+
+func (c *bufferedCounter) Increment(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending[key]++
+}
+
+func (c *bufferedCounter) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for k, v := range c.pending {
+		c.store[k] += v
+	}
+	c.pending = map[string]int{}
+	return nil
+}
+
+func TestGroupStopFlushesBufferedIncrements(t *testing.T) {
+	g := NewGroup(nil)
+	counter := &bufferedCounter{pending: map[string]int{}, store: map[string]int{}}
+	started := make(chan struct{})
+	g.Add("clicks", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		// A worker stopping may still buffer its last increments.
+		counter.Increment("entry-2")
+		return nil
+	})
+	g.AddFlush("clicks", counter.Flush)
+	g.Start()
+	<-started
+
+	counter.Increment("entry-1")
+	counter.Increment("entry-1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, g.Stop(ctx))
+
+	assert.Equal(t, map[string]int{"entry-1": 2, "entry-2": 1}, counter.store)
+	assert.Empty(t, counter.pending)
+}
+
+func TestGroupStopFlushesInReverseOrder(t *testing.T) {
+	g := NewGroup(nil)
+	var flushed []string
+	flush := func(name string) FlushFunc {
+		return func(ctx context.Context) error {
+			flushed = append(flushed, name)
+			return nil
+		}
+	}
+	g.AddFlush("tag views", flush("tag views"))
+	g.AddFlush("clicks", flush("clicks"))
+
+	// Flushes run even when no worker was started.
+	require.NoError(t, g.Stop(context.Background()))
+	assert.Equal(t, []string{"clicks", "tag views"}, flushed)
+}
+
+func TestGroupStopFlushIsBoundedByContext(t *testing.T) {
+	g := NewGroup(nil)
+	release := make(chan struct{})
+	defer close(release)
+	g.Add("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	counter := &bufferedCounter{pending: map[string]int{"entry-1": 1}, store: map[string]int{}}
+	g.AddFlush("clicks", counter.Flush)
+	g.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := g.Stop(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "flush clicks")
+	assert.Empty(t, counter.store)
+}
@@ -7,6 +7,9 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"hateblog/internal/pkg/cachefallback"
+	"hateblog/internal/pkg/daycap"
 )
 
 // HTTPMetrics collects basic HTTP request metrics.
@@ -32,7 +35,7 @@ func NewHTTPMetrics() *HTTPMetrics {
 		Buckets: prometheus.DefBuckets,
 	}, []string{"method", "path", "status"})
 
-	reg.MustRegister(requests, latency)
+	reg.MustRegister(requests, latency, cachefallback.Total, daycap.Total)
 
 	return &HTTPMetrics{
 		registry: reg,
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+const newestEntryAgeLookupTimeout = 2 * time.Second
+
+// FetcherMetrics collects ingestion metrics for the fetcher batch.
+// The fetcher is short-lived, so the values are pushed to a Pushgateway instead of being scraped.
+type FetcherMetrics struct {
+	registry *prometheus.Registry
+
+	inserted prometheus.Counter
+	skipped  *prometheus.CounterVec
+}
+
+// NewFetcherMetrics creates a new FetcherMetrics with its own registry.
+func NewFetcherMetrics() *FetcherMetrics {
+	reg := prometheus.NewRegistry()
+
+	inserted := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "hateblog_fetcher_entries_inserted_total",
+		Help: "Number of entries inserted by the fetcher run.",
+	})
+
+	skipped := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "hateblog_fetcher_skipped_total",
+		Help: "Number of feed entries skipped by the fetcher run, by reason.",
+	}, []string{"reason"})
+
+	reg.MustRegister(inserted, skipped)
+
+	return &FetcherMetrics{
+		registry: reg,
+		inserted: inserted,
+		skipped:  skipped,
+	}
+}
+
+// AddInserted increments the inserted entries counter.
+func (m *FetcherMetrics) AddInserted(n int) {
+	if m == nil || n <= 0 {
+		return
+	}
+	m.inserted.Add(float64(n))
+}
+
+// AddSkipped increments the skipped entries counter for reason.
+func (m *FetcherMetrics) AddSkipped(reason string, n int) {
+	if m == nil || n <= 0 {
+		return
+	}
+	m.skipped.WithLabelValues(reason).Add(float64(n))
+}
+
+// Push sends the collected metrics to the Pushgateway at gatewayURL under the given job name.
+func (m *FetcherMetrics) Push(ctx context.Context, gatewayURL, job string) error {
+	if m == nil || gatewayURL == "" {
+		return nil
+	}
+	if err := push.New(gatewayURL, job).Gatherer(m.registry).PushContext(ctx); err != nil {
+		return fmt.Errorf("push metrics: %w", err)
+	}
+	return nil
+}
+
+// NewestEntryFunc returns the created_at of the most recently ingested entry.
+// A zero time means no entries exist yet.
+type NewestEntryFunc func(ctx context.Context) (time.Time, error)
+
+// RegisterNewestEntryAge exposes the age of the newest entry, computed at scrape time.
+func (m *HTTPMetrics) RegisterNewestEntryAge(lookup NewestEntryFunc) {
+	if m == nil || m.registry == nil || lookup == nil {
+		return
+	}
+	m.registry.MustRegister(newNewestEntryAgeCollector(lookup, time.Now))
+}
+
+// newestEntryAgeCollector queries the newest entry on every scrape.
+// Lookup failures skip the sample so that a stale value is never reported.
+type newestEntryAgeCollector struct {
+	desc   *prometheus.Desc
+	lookup NewestEntryFunc
+	now    func() time.Time
+}
+
+func newNewestEntryAgeCollector(lookup NewestEntryFunc, now func() time.Time) *newestEntryAgeCollector {
+	return &newestEntryAgeCollector{
+		desc: prometheus.NewDesc(
+			"hateblog_newest_entry_age_seconds",
+			"Seconds since the newest entry was ingested.",
+			nil, nil,
+		),
+		lookup: lookup,
+		now:    now,
+	}
+}
+
+func (c *newestEntryAgeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *newestEntryAgeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), newestEntryAgeLookupTimeout)
+	defer cancel()
+
+	newest, err := c.lookup(ctx)
+	if err != nil || newest.IsZero() {
+		return
+	}
+	age := c.now().Sub(newest).Seconds()
+	if age < 0 {
+		age = 0
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, age)
+}
@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetcherMetrics_AddInsertedAndPush(t *testing.T) {
+	m := NewFetcherMetrics()
+	m.AddInserted(3)
+	m.AddInserted(2)
+	m.AddInserted(0)
+
+	var (
+		method string
+		path   string
+		body   string
+	)
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		path = r.URL.Path
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gateway.Close()
+
+	require.NoError(t, m.Push(context.Background(), gateway.URL, "hateblog_fetcher"))
+	require.Equal(t, http.MethodPut, method)
+	require.Equal(t, "/metrics/job/hateblog_fetcher", path)
+	require.NotEmpty(t, body)
+
+	families, err := m.registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	require.Equal(t, "hateblog_fetcher_entries_inserted_total", families[0].GetName())
+	require.Equal(t, float64(5), families[0].GetMetric()[0].GetCounter().GetValue())
+}
+
+func TestFetcherMetrics_AddSkipped(t *testing.T) {
+	m := NewFetcherMetrics()
+	m.AddSkipped("already_present", 4)
+	m.AddSkipped("blocked_domain", 1)
+	m.AddSkipped("already_present", 2)
+	m.AddSkipped("empty_url", 0)
+
+	families, err := m.registry.Gather()
+	require.NoError(t, err)
+
+	got := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != "hateblog_fetcher_skipped_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			got[metric.GetLabel()[0].GetValue()] = metric.GetCounter().GetValue()
+		}
+	}
+	require.Equal(t, map[string]float64{"already_present": 6, "blocked_domain": 1}, got)
+}
+
+func TestFetcherMetrics_PushWithoutGateway(t *testing.T) {
+	m := NewFetcherMetrics()
+	require.NoError(t, m.Push(context.Background(), "", "hateblog_fetcher"))
+}
+
+func TestNewestEntryAgeCollector(t *testing.T) {
+	now := time.Date(2026, 2, 3, 12, 0, 0, 0, time.UTC)
+	fixedNow := func() time.Time { return now }
+
+	t.Run("reports seconds since newest entry", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(newNewestEntryAgeCollector(func(ctx context.Context) (time.Time, error) {
+			return now.Add(-90 * time.Second), nil
+		}, fixedNow))
+
+		families, err := reg.Gather()
+		require.NoError(t, err)
+		require.Len(t, families, 1)
+		require.Equal(t, "hateblog_newest_entry_age_seconds", families[0].GetName())
+		require.Equal(t, float64(90), families[0].GetMetric()[0].GetGauge().GetValue())
+	})
+
+	t.Run("clamps future timestamps to zero", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(newNewestEntryAgeCollector(func(ctx context.Context) (time.Time, error) {
+			return now.Add(time.Minute), nil
+		}, fixedNow))
+
+		families, err := reg.Gather()
+		require.NoError(t, err)
+		require.Len(t, families, 1)
+		require.Equal(t, float64(0), families[0].GetMetric()[0].GetGauge().GetValue())
+	})
+
+	t.Run("skips sample on lookup error", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(newNewestEntryAgeCollector(func(ctx context.Context) (time.Time, error) {
+			return time.Time{}, errors.New("db down")
+		}, fixedNow))
+
+		families, err := reg.Gather()
+		require.NoError(t, err)
+		require.Empty(t, families)
+	})
+
+	t.Run("skips sample when no entries exist", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(newNewestEntryAgeCollector(func(ctx context.Context) (time.Time, error) {
+			return time.Time{}, nil
+		}, fixedNow))
+
+		families, err := reg.Gather()
+		require.NoError(t, err)
+		require.Empty(t, families)
+	})
+}
+
+func TestHTTPMetrics_RegisterNewestEntryAge(t *testing.T) {
+	m := NewHTTPMetrics()
+	m.RegisterNewestEntryAge(func(ctx context.Context) (time.Time, error) {
+		return time.Now().Add(-time.Minute), nil
+	})
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.com/observability/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "hateblog_newest_entry_age_seconds")
+}
@@ -1,13 +1,21 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -18,8 +26,35 @@ import (
 	"hateblog/internal/platform/cache"
 )
 
-// RequestLogger returns a middleware that logs HTTP requests
+// DefaultRedactedQueryParams lists the query parameters RequestLogger masks by default.
+var DefaultRedactedQueryParams = []string{"api_key", "apikey", "key", "token", "access_token"}
+
+// redactedValue replaces masked query values and headers in logs.
+const redactedValue = "[REDACTED]"
+
+// RequestLoggerConfig configures RequestLoggerWithConfig.
+type RequestLoggerConfig struct {
+	Logger *slog.Logger
+	// RedactParams lists query parameters whose values are masked, matched case-insensitively.
+	RedactParams []string
+	// RedactPaths lists path prefixes whose whole query string is masked, e.g. search endpoints
+	// whose terms should not reach the logs.
+	RedactPaths []string
+}
+
+// RequestLogger returns a middleware that logs HTTP requests, masking DefaultRedactedQueryParams.
 func RequestLogger(logger *slog.Logger) func(next http.Handler) http.Handler {
+	return RequestLoggerWithConfig(RequestLoggerConfig{Logger: logger, RedactParams: DefaultRedactedQueryParams})
+}
+
+// RequestLoggerWithConfig returns a middleware that logs HTTP requests with configured query
+// redaction. Credential headers such as X-API-Key are never logged.
+func RequestLoggerWithConfig(cfg RequestLoggerConfig) func(next http.Handler) http.Handler {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	redact := newQueryRedactor(cfg.RedactParams, cfg.RedactPaths)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
@@ -35,7 +70,7 @@ func RequestLogger(logger *slog.Logger) func(next http.Handler) http.Handler {
 			logger.Info("http request",
 				"method", r.Method,
 				"path", r.URL.Path,
-				"query", r.URL.RawQuery,
+				"query", redact.query(r.URL.Path, r.URL.RawQuery),
 				"status", ww.Status(),
 				"bytes", ww.BytesWritten(),
 				"duration_ms", duration.Milliseconds(),
@@ -46,6 +81,55 @@ func RequestLogger(logger *slog.Logger) func(next http.Handler) http.Handler {
 	}
 }
 
+// queryRedactor masks sensitive parts of raw query strings.
+type queryRedactor struct {
+	params map[string]bool
+	paths  []string
+}
+
+func newQueryRedactor(params, paths []string) queryRedactor {
+	q := queryRedactor{params: make(map[string]bool, len(params))}
+	for _, p := range params {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			q.params[p] = true
+		}
+	}
+	for _, p := range paths {
+		if p = strings.TrimSpace(p); p != "" {
+			q.paths = append(q.paths, p)
+		}
+	}
+	return q
+}
+
+// query returns rawQuery with the values of redacted params masked, or masked entirely when
+// path falls under a redacted prefix. Parameter order and encoding are kept otherwise.
+func (q queryRedactor) query(path, rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	for _, prefix := range q.paths {
+		if strings.HasPrefix(path, prefix) {
+			return redactedValue
+		}
+	}
+	if len(q.params) == 0 {
+		return rawQuery
+	}
+	parts := strings.Split(rawQuery, "&")
+	for i, part := range parts {
+		rawName, _, hasValue := strings.Cut(part, "=")
+		name := rawName
+		if unescaped, err := url.QueryUnescape(rawName); err == nil {
+			name = unescaped
+		}
+		if hasValue && q.params[strings.ToLower(name)] {
+			parts[i] = rawName + "=" + redactedValue
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
 // Recoverer returns a middleware that recovers from panics
 func Recoverer(logger *slog.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -70,14 +154,47 @@ func Recoverer(logger *slog.Logger) func(next http.Handler) http.Handler {
 	}
 }
 
+// DefaultCORSMaxAge is the preflight cache duration used by CORS.
+const DefaultCORSMaxAge = time.Hour
+
+// corsExposedHeaders lists the response headers browsers let cross-origin scripts read: the
+// cache status (X-Cache) and the result count set by the API handlers.
+const corsExposedHeaders = "X-Cache, X-Result-Count"
+
+// CORSConfig configures CORSWithConfig.
+type CORSConfig struct {
+	AllowedOrigins []string
+	// CredentialedOrigins may send credentialed requests: their origin is echoed with
+	// Access-Control-Allow-Credentials even when AllowedOrigins is "*", which browsers reject
+	// for credentialed requests. "*" is ignored here.
+	CredentialedOrigins []string
+	// MaxAge is sent as Access-Control-Max-Age so browsers can reuse preflight results.
+	// Zero asks browsers not to cache them.
+	MaxAge time.Duration
+}
+
 // CORS returns a middleware that handles CORS
 func CORS(allowedOrigins []string) func(next http.Handler) http.Handler {
+	return CORSWithConfig(CORSConfig{AllowedOrigins: allowedOrigins, MaxAge: DefaultCORSMaxAge})
+}
+
+// CORSWithConfig returns a CORS middleware with a configurable preflight max-age.
+func CORSWithConfig(cfg CORSConfig) func(next http.Handler) http.Handler {
+	allowedOrigins := cfg.AllowedOrigins
+	maxAge := strconv.Itoa(int(cfg.MaxAge / time.Second))
+	// With "*" every origin gets the same static header; otherwise the request origin is
+	// echoed back.
+	wildcard := slices.Contains(allowedOrigins, "*")
+	credentialedOrigins := slices.DeleteFunc(slices.Clone(cfg.CredentialedOrigins), func(o string) bool {
+		return o == "*"
+	})
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			origin := r.Header.Get("Origin")
+			credentialed := origin != "" && slices.Contains(credentialedOrigins, origin)
 
 			// Check if origin is allowed
-			allowed := false
+			allowed := credentialed
 			for _, allowedOrigin := range allowedOrigins {
 				if allowedOrigin == "*" || allowedOrigin == origin {
 					allowed = true
@@ -85,15 +202,30 @@ func CORS(allowedOrigins []string) func(next http.Handler) http.Handler {
 				}
 			}
 
-			if allowed {
-				if origin != "" {
+			switch {
+			case credentialed:
+				w.Header().Add("Vary", "Origin")
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			case wildcard:
+				// Credentialed origins get their own header, so the static one varies too.
+				if len(credentialedOrigins) > 0 {
+					w.Header().Add("Vary", "Origin")
+				}
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			default:
+				// The response depends on the request origin, so shared caches must key on it
+				// even when the origin is rejected.
+				w.Header().Add("Vary", "Origin")
+				if allowed && origin != "" {
 					w.Header().Set("Access-Control-Allow-Origin", origin)
-				} else if len(allowedOrigins) > 0 {
-					w.Header().Set("Access-Control-Allow-Origin", allowedOrigins[0])
 				}
+			}
+			if allowed {
 				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 				w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Authorization, X-API-Key")
-				w.Header().Set("Access-Control-Max-Age", "3600")
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+				w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
 			}
 
 			// Handle preflight requests
@@ -107,37 +239,146 @@ func CORS(allowedOrigins []string) func(next http.Handler) http.Handler {
 	}
 }
 
-// APIKeyAuth returns a middleware that validates API keys
+// APIKeyAuth returns a middleware that accepts only validAPIKey.
 func APIKeyAuth(validAPIKey string, logger *slog.Logger) func(next http.Handler) http.Handler {
+	return APIKeyAuthWithConfig(APIKeyAuthConfig{MasterKey: validAPIKey, Logger: logger})
+}
+
+// APIKeyLookup resolves stored API keys by ID.
+type APIKeyLookup interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*api_key.APIKey, error)
+}
+
+// APIKeyAuthConfig configures APIKeyAuthWithConfig.
+type APIKeyAuthConfig struct {
+	// MasterKey is accepted in X-API-Key on its own, for bootstrapping and superuser access.
+	// Empty disables it.
+	MasterKey string
+	// Keys verifies stored keys presented as X-API-Key-ID plus X-API-Key. nil accepts only MasterKey.
+	Keys APIKeyLookup
+	// CacheTTL keeps keys found in Keys in memory so that not every request hits the store.
+	// Revocation takes effect after at most CacheTTL. Zero disables the cache.
+	CacheTTL time.Duration
+	Logger   *slog.Logger
+
+	now func() time.Time
+}
+
+// APIKeyAuthWithConfig returns a middleware that accepts the master key or any stored key
+// that is neither expired nor revoked.
+func APIKeyAuthWithConfig(cfg APIKeyAuthConfig) func(next http.Handler) http.Handler {
+	now := cfg.now
+	if now == nil {
+		now = time.Now
+	}
+	keys := newAPIKeyCache(cfg.Keys, cfg.CacheTTL, now)
+	warn := func(msg string, args ...any) {
+		if cfg.Logger != nil {
+			cfg.Logger.Warn(msg, args...)
+		}
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			apiKey := r.Header.Get("X-API-Key")
+			apiKeyStr := r.Header.Get("X-API-Key")
+			if apiKeyStr == "" {
+				warn("missing API key", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+				writeUnauthorizedJSON(w, "Missing API key")
+				return
+			}
+			if cfg.MasterKey != "" && subtle.ConstantTimeCompare([]byte(apiKeyStr), []byte(cfg.MasterKey)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
 
-			if apiKey == "" {
-				logger.Warn("missing API key", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				if _, err := w.Write([]byte(`{"error":"UNAUTHORIZED","message":"Missing API key"}`)); err != nil && logger != nil {
-					logger.Debug("failed to write error response", "error", err)
-				}
+			keyIDStr := r.Header.Get("X-API-Key-ID")
+			if keys == nil || keyIDStr == "" {
+				warn("invalid API key", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+				writeUnauthorizedJSON(w, "Invalid API key")
+				return
+			}
+			keyID, err := uuid.Parse(keyIDStr)
+			if err != nil {
+				warn("invalid key ID format", "key_id", keyIDStr, "path", r.URL.Path, "error", err)
+				writeUnauthorizedJSON(w, "Invalid key ID format")
 				return
 			}
 
-			if apiKey != validAPIKey {
-				logger.Warn("invalid API key", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				if _, err := w.Write([]byte(`{"error":"UNAUTHORIZED","message":"Invalid API key"}`)); err != nil && logger != nil {
-					logger.Debug("failed to write error response", "error", err)
-				}
+			storedKey, err := keys.get(r.Context(), keyID)
+			if err != nil {
+				warn("API key not found", "key_id", keyIDStr, "path", r.URL.Path, "error", err)
+				writeUnauthorizedJSON(w, "Invalid API key")
+				return
+			}
+			if !apikeyhash.Verify(storedKey.KeyHash, apiKeyStr) {
+				warn("API key verification failed", "key_id", keyIDStr, "path", r.URL.Path)
+				writeUnauthorizedJSON(w, "Invalid API key")
+				return
+			}
+			if storedKey.IsRevoked() {
+				warn("API key revoked", "key_id", keyIDStr, "path", r.URL.Path)
+				writeUnauthorizedJSON(w, "API key revoked")
+				return
+			}
+			if storedKey.IsExpired(now()) {
+				warn("API key expired", "key_id", keyIDStr, "path", r.URL.Path)
+				writeUnauthorizedJSON(w, "API key expired")
 				return
 			}
 
+			if cfg.Logger != nil {
+				cfg.Logger.Debug("API key authenticated", "key_id", keyIDStr, "path", r.URL.Path)
+			}
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// apiKeyCache memoizes successful lookups for a short time. Failed lookups are not cached,
+// so unknown IDs cannot grow the map.
+type apiKeyCache struct {
+	keys APIKeyLookup
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]cachedAPIKey
+}
+
+type cachedAPIKey struct {
+	key       *api_key.APIKey
+	expiresAt time.Time
+}
+
+func newAPIKeyCache(keys APIKeyLookup, ttl time.Duration, now func() time.Time) *apiKeyCache {
+	if keys == nil {
+		return nil
+	}
+	return &apiKeyCache{keys: keys, ttl: ttl, now: now, entries: make(map[uuid.UUID]cachedAPIKey)}
+}
+
+func (c *apiKeyCache) get(ctx context.Context, id uuid.UUID) (*api_key.APIKey, error) {
+	if c.ttl <= 0 {
+		return c.keys.GetByID(ctx, id)
+	}
+	now := c.now()
+	c.mu.Lock()
+	cached, ok := c.entries[id]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.key, nil
+	}
+
+	key, err := c.keys.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.entries[id] = cachedAPIKey{key: key, expiresAt: now.Add(c.ttl)}
+	c.mu.Unlock()
+	return key, nil
+}
+
 // SecurityHeaders returns a middleware that adds security headers
 func SecurityHeaders() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -153,19 +394,84 @@ func SecurityHeaders() func(next http.Handler) http.Handler {
 	}
 }
 
+// Retry-After strategies for RateLimit.
+const (
+	// RetryAfterFixed always advertises the full window length.
+	RetryAfterFixed = "fixed"
+	// RetryAfterRemaining advertises the time left until the client's window resets.
+	RetryAfterRemaining = "remaining"
+)
+
+// Counting algorithms for RateLimit.
+const (
+	// RateLimitFixedWindow counts requests per aligned window. Cheap, but a client can send up
+	// to twice the limit around a window boundary.
+	RateLimitFixedWindow = "fixed"
+	// RateLimitSlidingWindow weights the previous window's count by how much of it still overlaps
+	// the sliding window, which smooths out boundary bursts.
+	RateLimitSlidingWindow = "sliding"
+)
+
+// RateLimitStore is the counter storage used by RateLimit. *cache.Cache satisfies it.
+type RateLimitStore interface {
+	Get(ctx context.Context, key string) (string, error)
+	IncrementWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// SkipPaths returns a Skip function for RateLimit and ConcurrencyLimit that matches requests
+// for the given paths. A path ending in "/*" matches itself without the suffix and every path
+// below it (e.g. "/api/v1/health/*" matches /api/v1/health and /api/v1/health/live); other
+// paths match exactly. Empty entries are ignored.
+func SkipPaths(paths []string) func(r *http.Request) bool {
+	exact := make(map[string]bool, len(paths))
+	var prefixes []string
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if base, ok := strings.CutSuffix(p, "/*"); ok {
+			exact[base] = true
+			prefixes = append(prefixes, base+"/")
+			continue
+		}
+		exact[p] = true
+	}
+	return func(r *http.Request) bool {
+		if exact[r.URL.Path] {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 // RateLimitConfig configures the Redis-backed rate limiter.
 type RateLimitConfig struct {
-	Cache  *cache.Cache
+	Cache  RateLimitStore
 	Limit  int
 	Window time.Duration
 	Logger *slog.Logger
+	// RetryAfter selects how the Retry-After header is computed: RetryAfterFixed (default)
+	// or RetryAfterRemaining.
+	RetryAfter string
+	// Algorithm selects RateLimitFixedWindow (default) or RateLimitSlidingWindow.
+	Algorithm string
 
 	Prefix string
 	Skip   func(r *http.Request) bool
 	Key    func(r *http.Request) (string, error)
+
+	now func() time.Time
 }
 
-// RateLimit returns a middleware that enforces a simple fixed-window counter per key.
+// RateLimit returns a middleware that enforces a per-key request limit using a fixed-window
+// or sliding-window counter.
 func RateLimit(cfg RateLimitConfig) func(next http.Handler) http.Handler {
 	if cfg.Limit <= 0 || cfg.Window <= 0 || cfg.Cache == nil {
 		return func(next http.Handler) http.Handler { return next }
@@ -175,6 +481,11 @@ func RateLimit(cfg RateLimitConfig) func(next http.Handler) http.Handler {
 		prefix = "ratelimit"
 	}
 	logger := cfg.Logger
+	sliding := cfg.Algorithm == RateLimitSlidingWindow
+	now := cfg.now
+	if now == nil {
+		now = time.Now
+	}
 
 	skip := cfg.Skip
 	if skip == nil {
@@ -212,7 +523,19 @@ func RateLimit(cfg RateLimitConfig) func(next http.Handler) http.Handler {
 			}
 			redisKey := prefix + ":" + key
 
-			count, err := cfg.Cache.IncrementWithTTL(r.Context(), redisKey, cfg.Window)
+			var (
+				exceeded   bool
+				untilReset time.Duration
+			)
+			if sliding {
+				var estimate float64
+				estimate, untilReset, err = slidingWindowCount(r.Context(), cfg.Cache, redisKey, cfg.Window, now())
+				exceeded = estimate > float64(cfg.Limit)
+			} else {
+				var count int64
+				count, err = cfg.Cache.IncrementWithTTL(r.Context(), redisKey, cfg.Window)
+				exceeded = int(count) > cfg.Limit
+			}
 			if err != nil {
 				if logger != nil {
 					logger.Debug("rate limit counter failed", "error", err, "path", r.URL.Path)
@@ -221,9 +544,17 @@ func RateLimit(cfg RateLimitConfig) func(next http.Handler) http.Handler {
 				return
 			}
 
-			if int(count) > cfg.Limit {
+			if exceeded {
+				retryAfter := cfg.Window
+				if cfg.RetryAfter == RetryAfterRemaining {
+					if sliding {
+						retryAfter = untilReset
+					} else {
+						retryAfter = remainingWindow(r.Context(), cfg.Cache, redisKey, cfg.Window, logger)
+					}
+				}
 				w.Header().Set("Content-Type", "application/json")
-				w.Header().Set("Retry-After", fmt.Sprintf("%d", int(cfg.Window.Seconds())))
+				w.Header().Set("Retry-After", fmt.Sprintf("%d", retryAfterSeconds(retryAfter)))
 				w.WriteHeader(http.StatusTooManyRequests)
 				_ = json.NewEncoder(w).Encode(map[string]string{
 					"error": "rate limit exceeded",
@@ -236,103 +567,221 @@ func RateLimit(cfg RateLimitConfig) func(next http.Handler) http.Handler {
 	}
 }
 
-func clientIP(r *http.Request) string {
-	if r == nil {
-		return ""
+// slidingWindowCount increments the counter for the current window and returns the sliding
+// estimate: the current count plus the previous window's count weighted by its overlap with
+// the last Window. Counters live for two windows so the previous one is still readable.
+// The returned duration is the time until the current window ends.
+func slidingWindowCount(ctx context.Context, store RateLimitStore, key string, window time.Duration, now time.Time) (float64, time.Duration, error) {
+	index := now.UnixNano() / int64(window)
+	elapsed := time.Duration(now.UnixNano() % int64(window))
+
+	current, err := store.IncrementWithTTL(ctx, key+":"+strconv.FormatInt(index, 10), 2*window)
+	if err != nil {
+		return 0, 0, err
 	}
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err == nil && host != "" {
-		return host
+	var previous int64
+	raw, err := store.Get(ctx, key+":"+strconv.FormatInt(index-1, 10))
+	switch {
+	case errors.Is(err, cache.ErrCacheMiss):
+	case err != nil:
+		return 0, 0, err
+	default:
+		if previous, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("parse previous window count: %w", err)
+		}
 	}
-	return r.RemoteAddr
-}
 
-// DynamicAPIKeyAuth returns a middleware that validates API keys from database.
-func DynamicAPIKeyAuth(repo interface{}, logger *slog.Logger) func(next http.Handler) http.Handler {
-	// Define the interface that the repository must implement
-	type apiKeyRepo interface {
-		GetByID(ctx context.Context, id uuid.UUID) (*api_key.APIKey, error)
-	}
+	weight := 1 - float64(elapsed)/float64(window)
+	return float64(previous)*weight + float64(current), window - elapsed, nil
+}
 
-	// Type assert the repository once during middleware creation
-	typedRepo, ok := repo.(apiKeyRepo)
-	if !ok && repo != nil {
-		// Log warning if repo doesn't match expected interface
+// remainingWindow returns the TTL left on the counter key, falling back to the full window
+// when it cannot be determined.
+func remainingWindow(ctx context.Context, store RateLimitStore, key string, window time.Duration, logger *slog.Logger) time.Duration {
+	ttl, err := store.TTL(ctx, key)
+	if err != nil {
 		if logger != nil {
-			logger.Warn("API key repository type mismatch", "repo_type", fmt.Sprintf("%T", repo))
+			logger.Debug("rate limit ttl lookup failed", "error", err)
 		}
+		return window
+	}
+	if ttl <= 0 || ttl > window {
+		return window
+	}
+	return ttl
+}
+
+// retryAfterSeconds rounds d up to whole seconds, never advertising less than one second.
+func retryAfterSeconds(d time.Duration) int {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// ConcurrencyLimitConfig configures the global in-flight request limiter.
+type ConcurrencyLimitConfig struct {
+	// MaxInFlight is the number of requests served concurrently. Zero disables the limiter.
+	MaxInFlight int
+	// RetryAfter is advertised to rejected clients. Defaults to 1s.
+	RetryAfter time.Duration
+	Logger     *slog.Logger
+	Skip       func(r *http.Request) bool
+}
+
+// ConcurrencyLimit returns a middleware that sheds load with 503 once MaxInFlight
+// requests are being served, independent of per-client rate limits.
+func ConcurrencyLimit(cfg ConcurrencyLimitConfig) func(next http.Handler) http.Handler {
+	if cfg.MaxInFlight <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	retryAfter := cfg.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = time.Second
 	}
+	retryAfterSeconds := int(retryAfter.Round(time.Second).Seconds())
+	if retryAfterSeconds < 1 {
+		retryAfterSeconds = 1
+	}
+	skip := cfg.Skip
+	if skip == nil {
+		skip = func(*http.Request) bool { return false }
+	}
+	slots := make(chan struct{}, cfg.MaxInFlight)
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			keyIDStr := r.Header.Get("X-API-Key-ID")
-			apiKeyStr := r.Header.Get("X-API-Key")
-
-			if keyIDStr == "" || apiKeyStr == "" {
-				if logger != nil {
-					logger.Warn("missing API key or key ID", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
-				}
-				writeUnauthorizedJSON(w, "Missing API key or key ID")
+			if skip(r) {
+				next.ServeHTTP(w, r)
 				return
 			}
 
-			// Parse UUID
-			keyID, err := uuid.Parse(keyIDStr)
-			if err != nil {
-				if logger != nil {
-					logger.Warn("invalid key ID format", "key_id", keyIDStr, "path", r.URL.Path, "error", err)
+			select {
+			case slots <- struct{}{}:
+			default:
+				if cfg.Logger != nil {
+					cfg.Logger.Warn("concurrency limit reached", "max_in_flight", cfg.MaxInFlight, "path", r.URL.Path)
 				}
-				writeUnauthorizedJSON(w, "Invalid key ID format")
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", fmt.Sprintf("%d", retryAfterSeconds))
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"error": "server busy",
+				})
 				return
 			}
+			defer func() { <-slots }()
 
-			// Check if repo can be used
-			if repo == nil || !ok {
-				if logger != nil {
-					logger.Warn("API key repository not configured", "path", r.URL.Path)
-				}
-				writeUnauthorizedJSON(w, "API key authentication not available")
-				return
-			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-			// Get stored key from repository
-			storedKey, err := typedRepo.GetByID(r.Context(), keyID)
-			if err != nil {
-				if logger != nil {
-					logger.Warn("API key not found", "key_id", keyIDStr, "path", r.URL.Path, "error", err)
-				}
-				writeUnauthorizedJSON(w, "Invalid API key")
-				return
-			}
+// DebugRequestLogConfig configures the request dump middleware used for debugging integrations.
+type DebugRequestLogConfig struct {
+	Logger *slog.Logger
+	// Headers lists the request headers to log. Credentials are always redacted.
+	Headers []string
+	// RedactParams lists query parameters whose values are masked, as in RequestLoggerConfig.
+	// Nil masks DefaultRedactedQueryParams.
+	RedactParams []string
+	// RedactPaths lists path prefixes whose whole query string is masked.
+	RedactPaths []string
+	// MaxBodyBytes caps the logged POST body. Zero disables body logging.
+	MaxBodyBytes int64
+}
 
-			// Verify API key hash.
-			if !apikeyhash.Verify(storedKey.KeyHash, apiKeyStr) {
-				if logger != nil {
-					logger.Warn("API key verification failed", "key_id", keyIDStr, "path", r.URL.Path)
-				}
-				writeUnauthorizedJSON(w, "Invalid API key")
+var redactedHeaders = map[string]bool{
+	"Authorization": true,
+	"X-Api-Key":     true,
+	"Cookie":        true,
+}
+
+// DebugRequestLog returns a middleware that logs selected headers and small POST bodies at debug level.
+// The body is restored before the request is passed on, so handlers still see it in full.
+func DebugRequestLog(cfg DebugRequestLogConfig) func(next http.Handler) http.Handler {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	params := cfg.RedactParams
+	if params == nil {
+		params = DefaultRedactedQueryParams
+	}
+	redact := newQueryRedactor(params, cfg.RedactPaths)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !logger.Enabled(r.Context(), slog.LevelDebug) {
+				next.ServeHTTP(w, r)
 				return
 			}
 
-			// Check expiration
-			if storedKey.ExpiresAt != nil && time.Now().After(*storedKey.ExpiresAt) {
-				if logger != nil {
-					logger.Warn("API key expired", "key_id", keyIDStr, "path", r.URL.Path)
+			headers := make(map[string]string, len(cfg.Headers))
+			for _, name := range cfg.Headers {
+				key := http.CanonicalHeaderKey(strings.TrimSpace(name))
+				value := r.Header.Get(key)
+				if key == "" || value == "" {
+					continue
 				}
-				writeUnauthorizedJSON(w, "API key expired")
-				return
+				if redactedHeaders[key] {
+					value = redactedValue
+				}
+				headers[key] = value
+			}
+			attrs := []any{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"query", redact.query(r.URL.Path, r.URL.RawQuery),
+				"headers", headers,
 			}
 
-			// Authentication successful
-			if logger != nil {
-				logger.Debug("API key authenticated", "key_id", keyIDStr, "path", r.URL.Path)
+			if r.Method == http.MethodPost && cfg.MaxBodyBytes > 0 && r.Body != nil && r.Body != http.NoBody {
+				body, err := io.ReadAll(io.LimitReader(r.Body, cfg.MaxBodyBytes+1))
+				if err != nil {
+					attrs = append(attrs, "body_error", err.Error())
+				}
+				truncated := int64(len(body)) > cfg.MaxBodyBytes
+				logged := body
+				if truncated {
+					logged = body[:cfg.MaxBodyBytes]
+				}
+				attrs = append(attrs, "body", string(logged), "body_truncated", truncated)
+				r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
 			}
 
+			logger.Debug("http request dump", attrs...)
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func clientIP(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err == nil && host != "" {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// DynamicAPIKeyAuth returns a middleware that validates stored API keys presented as
+// X-API-Key-ID plus X-API-Key.
+func DynamicAPIKeyAuth(repo interface{}, logger *slog.Logger) func(next http.Handler) http.Handler {
+	keys, ok := repo.(APIKeyLookup)
+	if !ok && repo != nil && logger != nil {
+		logger.Warn("API key repository type mismatch", "repo_type", fmt.Sprintf("%T", repo))
+	}
+	return APIKeyAuthWithConfig(APIKeyAuthConfig{Keys: keys, Logger: logger})
+}
+
 func writeUnauthorizedJSON(w http.ResponseWriter, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusUnauthorized)
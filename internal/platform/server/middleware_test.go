@@ -1,13 +1,27 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"hateblog/internal/domain/api_key"
+	"hateblog/internal/pkg/apikeyhash"
+	"hateblog/internal/platform/cache"
 )
 
 func TestRequestLogger(t *testing.T) {
@@ -29,6 +43,63 @@ func TestRequestLogger(t *testing.T) {
 	assert.Equal(t, "test", rec.Body.String())
 }
 
+func TestRequestLoggerRedactsQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    RequestLoggerConfig
+		target string
+		want   string
+	}{
+		{
+			name:   "default params",
+			target: "/api/v1/entries?limit=10&api_key=secret&Token=secret2",
+			want:   "limit=10&api_key=[REDACTED]&Token=[REDACTED]",
+		},
+		{
+			name:   "encoded param name",
+			cfg:    RequestLoggerConfig{RedactParams: []string{"access_token"}},
+			target: "/api/v1/entries?access%5Ftoken=secret&q=go",
+			want:   "access%5Ftoken=[REDACTED]&q=go",
+		},
+		{
+			name:   "redacted path",
+			cfg:    RequestLoggerConfig{RedactPaths: []string{"/api/v1/search"}},
+			target: "/api/v1/search?q=secret",
+			want:   "[REDACTED]",
+		},
+		{
+			name:   "nothing configured",
+			cfg:    RequestLoggerConfig{},
+			target: "/api/v1/search?q=go",
+			want:   "q=go",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+			mw := RequestLogger(logger)
+			if tt.name != "default params" {
+				tt.cfg.Logger = logger
+				mw = RequestLoggerWithConfig(tt.cfg)
+			}
+			handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// The handler still sees the real query.
+				assert.NotEmpty(t, r.URL.RawQuery)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("X-API-Key", "header-secret")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			var logged map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &logged))
+			assert.Equal(t, tt.want, logged["query"])
+			assert.NotContains(t, buf.String(), "secret")
+		})
+	}
+}
+
 func TestRecoverer(t *testing.T) {
 	logger := slog.Default()
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -115,6 +186,147 @@ func TestCORS(t *testing.T) {
 	}
 }
 
+func TestCORS_VaryOrigin(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		allowedOrigins []string
+		requestOrigin  string
+		wantAllow      string
+		wantVary       bool
+	}{
+		{"specific origin is echoed", []string{"https://a.example", "https://b.example"}, "https://b.example", "https://b.example", true},
+		{"rejected origin still varies", []string{"https://a.example"}, "https://evil.example", "", true},
+		{"wildcard is static", []string{"*"}, "https://a.example", "*", false},
+		{"wildcard without origin", []string{"*"}, "", "*", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.requestOrigin != "" {
+				req.Header.Set("Origin", tt.requestOrigin)
+			}
+			rec := httptest.NewRecorder()
+			CORS(tt.allowedOrigins)(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantAllow, rec.Header().Get("Access-Control-Allow-Origin"))
+			if tt.wantVary {
+				assert.Contains(t, rec.Header().Values("Vary"), "Origin")
+			} else {
+				assert.Empty(t, rec.Header().Values("Vary"))
+			}
+		})
+	}
+}
+
+func TestCORSWithConfig_CredentialedOrigins(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name            string
+		allowedOrigins  []string
+		credentialed    []string
+		requestOrigin   string
+		wantAllow       string
+		wantCredentials bool
+		wantVary        bool
+	}{
+		{"credentialed origin is echoed over wildcard", []string{"*"}, []string{"https://app.example"}, "https://app.example", "https://app.example", true, true},
+		{"other origin gets wildcard without credentials", []string{"*"}, []string{"https://app.example"}, "https://other.example", "*", false, true},
+		{"wildcard without credentialed origins stays static", []string{"*"}, nil, "https://app.example", "*", false, false},
+		{"credentialed origin also in allowed origins", []string{"https://app.example"}, []string{"https://app.example"}, "https://app.example", "https://app.example", true, true},
+		{"allowed origin without credentials", []string{"https://a.example"}, []string{"https://app.example"}, "https://a.example", "https://a.example", false, true},
+		{"credentialed origins only", nil, []string{"https://app.example"}, "https://app.example", "https://app.example", true, true},
+		{"credentialed origins only rejects others", nil, []string{"https://app.example"}, "https://other.example", "", false, true},
+		{"wildcard credentialed origin is ignored", []string{"https://a.example"}, []string{"*"}, "https://other.example", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Origin", tt.requestOrigin)
+			rec := httptest.NewRecorder()
+			CORSWithConfig(CORSConfig{AllowedOrigins: tt.allowedOrigins, CredentialedOrigins: tt.credentialed})(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantAllow, rec.Header().Get("Access-Control-Allow-Origin"))
+			if tt.wantCredentials {
+				assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+			} else {
+				assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+			}
+			if tt.wantVary {
+				assert.Equal(t, []string{"Origin"}, rec.Header().Values("Vary"))
+			} else {
+				assert.Empty(t, rec.Header().Values("Vary"))
+			}
+			if tt.wantAllow == "" {
+				assert.Empty(t, rec.Header().Get("Access-Control-Allow-Methods"))
+			} else {
+				assert.NotEmpty(t, rec.Header().Get("Access-Control-Allow-Methods"))
+			}
+		})
+	}
+}
+
+func TestCORSWithConfig_MaxAge(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	preflight := func(h http.Handler) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/test", nil)
+		req.Header.Set("Origin", "https://example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		want    string
+	}{
+		{"default", CORS([]string{"https://example.com"})(next), "3600"},
+		{"configured", CORSWithConfig(CORSConfig{AllowedOrigins: []string{"https://example.com"}, MaxAge: 24 * time.Hour})(next), "86400"},
+		{"zero", CORSWithConfig(CORSConfig{AllowedOrigins: []string{"*"}})(next), "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := preflight(tt.handler)
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			assert.Equal(t, tt.want, rec.Header().Get("Access-Control-Max-Age"))
+		})
+	}
+}
+
+func TestCORSWithConfig_ExposeHeaders(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Cache", "HIT")
+		w.Header().Set("X-Result-Count", "3")
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := CORSWithConfig(CORSConfig{AllowedOrigins: []string{"https://example.com"}})(next)
+	send := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send("https://example.com")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	exposed := strings.Split(rec.Header().Get("Access-Control-Expose-Headers"), ", ")
+	assert.ElementsMatch(t, []string{"X-Cache", "X-Result-Count"}, exposed)
+
+	rec = send("https://evil.example")
+	assert.Empty(t, rec.Header().Get("Access-Control-Expose-Headers"))
+}
+
 func TestAPIKeyAuth(t *testing.T) {
 	logger := slog.Default()
 	validAPIKey := "test-api-key"
@@ -163,6 +375,121 @@ func TestAPIKeyAuth(t *testing.T) {
 	}
 }
 
+// fakeAPIKeyStore serves API keys from memory and counts lookups.
+type fakeAPIKeyStore struct {
+	keys    map[uuid.UUID]*api_key.APIKey
+	lookups int
+}
+
+func (s *fakeAPIKeyStore) GetByID(ctx context.Context, id uuid.UUID) (*api_key.APIKey, error) {
+	s.lookups++
+	k, ok := s.keys[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return k, nil
+}
+
+func TestAPIKeyAuthWithConfig(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	validID, expiredID, revokedID, missingID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	store := &fakeAPIKeyStore{keys: map[uuid.UUID]*api_key.APIKey{
+		validID:   {ID: validID, KeyHash: apikeyhash.Hash("valid-secret"), ExpiresAt: &future},
+		expiredID: {ID: expiredID, KeyHash: apikeyhash.Hash("expired-secret"), ExpiresAt: &past},
+		revokedID: {ID: revokedID, KeyHash: apikeyhash.Hash("revoked-secret"), RevokedAt: &past},
+	}}
+
+	handler := APIKeyAuthWithConfig(APIKeyAuthConfig{
+		MasterKey: "master-secret",
+		Keys:      store,
+		CacheTTL:  time.Minute,
+		now:       func() time.Time { return now },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name        string
+		keyID       string
+		key         string
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "stored key accepted", keyID: validID.String(), key: "valid-secret", wantStatus: http.StatusOK},
+		{name: "master key without key ID", key: "master-secret", wantStatus: http.StatusOK},
+		{name: "master key with unknown key ID", keyID: missingID.String(), key: "master-secret", wantStatus: http.StatusOK},
+		{name: "expired key rejected", keyID: expiredID.String(), key: "expired-secret", wantStatus: http.StatusUnauthorized, wantMessage: "API key expired"},
+		{name: "revoked key rejected", keyID: revokedID.String(), key: "revoked-secret", wantStatus: http.StatusUnauthorized, wantMessage: "API key revoked"},
+		{name: "wrong secret", keyID: validID.String(), key: "guess", wantStatus: http.StatusUnauthorized, wantMessage: "Invalid API key"},
+		{name: "unknown key ID", keyID: missingID.String(), key: "valid-secret", wantStatus: http.StatusUnauthorized, wantMessage: "Invalid API key"},
+		{name: "malformed key ID", keyID: "not-a-uuid", key: "valid-secret", wantStatus: http.StatusUnauthorized, wantMessage: "Invalid key ID format"},
+		{name: "non-master key without key ID", key: "valid-secret", wantStatus: http.StatusUnauthorized, wantMessage: "Invalid API key"},
+		{name: "missing key", wantStatus: http.StatusUnauthorized, wantMessage: "Missing API key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.keyID != "" {
+				req.Header.Set("X-API-Key-ID", tt.keyID)
+			}
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantMessage != "" {
+				var body map[string]string
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Equal(t, tt.wantMessage, body["message"])
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthWithConfig_CachesLookups(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	id := uuid.New()
+	store := &fakeAPIKeyStore{keys: map[uuid.UUID]*api_key.APIKey{
+		id: {ID: id, KeyHash: apikeyhash.Hash("secret")},
+	}}
+	handler := APIKeyAuthWithConfig(APIKeyAuthConfig{
+		Keys:     store,
+		CacheTTL: 30 * time.Second,
+		now:      func() time.Time { return now },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("X-API-Key-ID", id.String())
+		req.Header.Set("X-API-Key", "secret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusOK, do())
+	require.Equal(t, http.StatusOK, do())
+	assert.Equal(t, 1, store.lookups)
+
+	// Revocation is picked up once the cached entry expires.
+	revokedAt := now
+	store.keys[id] = &api_key.APIKey{ID: id, KeyHash: apikeyhash.Hash("secret"), RevokedAt: &revokedAt}
+	now = now.Add(10 * time.Second)
+	require.Equal(t, http.StatusOK, do())
+	assert.Equal(t, 1, store.lookups)
+
+	now = now.Add(21 * time.Second)
+	require.Equal(t, http.StatusUnauthorized, do())
+	assert.Equal(t, 2, store.lookups)
+}
+
 func TestSecurityHeaders(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -183,3 +510,387 @@ func TestSecurityHeaders(t *testing.T) {
 	assert.Equal(t, "strict-origin-when-cross-origin", rec.Header().Get("Referrer-Policy"))
 	assert.NotEmpty(t, rec.Header().Get("Content-Security-Policy"))
 }
+
+func TestConcurrencyLimit(t *testing.T) {
+	const maxInFlight = 2
+	entered := make(chan struct{}, 10)
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			entered <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wrapped := ConcurrencyLimit(ConcurrencyLimitConfig{
+		MaxInFlight: maxInFlight,
+		RetryAfter:  2 * time.Second,
+		Skip: func(r *http.Request) bool {
+			return r.URL.Path == "/health"
+		},
+	})(handler)
+
+	const total = 5
+	codes := make(chan int, total)
+	var wg sync.WaitGroup
+	for i := 0; i < maxInFlight; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			wrapped.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/entries/new", nil))
+			codes <- rec.Code
+		}()
+	}
+	for i := 0; i < maxInFlight; i++ {
+		<-entered
+	}
+
+	// Slots are exhausted: further requests are shed immediately.
+	for i := maxInFlight; i < total; i++ {
+		rec := httptest.NewRecorder()
+		wrapped.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/entries/hot", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	}
+
+	// Health checks bypass the limiter.
+	rec := httptest.NewRecorder()
+	wrapped.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	close(release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+
+	// Slots are released after the in-flight requests finish.
+	rec = httptest.NewRecorder()
+	wrapped.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/entries/new", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+// fakeRateLimitStore counts in memory and reports a fixed remaining TTL.
+// When now is set, counters expire like Redis keys.
+type fakeRateLimitStore struct {
+	counts   map[string]int64
+	expireAt map[string]time.Time
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+func (s *fakeRateLimitStore) expired(key string) bool {
+	exp, ok := s.expireAt[key]
+	return ok && s.now != nil && !s.now().Before(exp)
+}
+
+func (s *fakeRateLimitStore) Get(ctx context.Context, key string) (string, error) {
+	v, ok := s.counts[key]
+	if !ok || s.expired(key) {
+		return "", cache.ErrCacheMiss
+	}
+	return strconv.FormatInt(v, 10), nil
+}
+
+func (s *fakeRateLimitStore) IncrementWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	if s.counts == nil {
+		s.counts = make(map[string]int64)
+		s.expireAt = make(map[string]time.Time)
+	}
+	if s.expired(key) {
+		delete(s.counts, key)
+	}
+	s.counts[key]++
+	if s.counts[key] == 1 && s.now != nil {
+		s.expireAt[key] = s.now().Add(ttl)
+	}
+	return s.counts[key], nil
+}
+
+func (s *fakeRateLimitStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return s.ttl, nil
+}
+
+func TestRateLimitRetryAfter(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	cases := []struct {
+		name       string
+		strategy   string
+		ttl        time.Duration
+		wantHeader string
+	}{
+		{name: "fixed sends full window", strategy: RetryAfterFixed, ttl: 12 * time.Second, wantHeader: "60"},
+		{name: "default is fixed", strategy: "", ttl: 12 * time.Second, wantHeader: "60"},
+		{name: "remaining sends ttl rounded up", strategy: RetryAfterRemaining, ttl: 11200 * time.Millisecond, wantHeader: "12"},
+		{name: "remaining falls back to window without ttl", strategy: RetryAfterRemaining, ttl: -1, wantHeader: "60"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			wrapped := RateLimit(RateLimitConfig{
+				Cache:      &fakeRateLimitStore{ttl: tc.ttl},
+				Limit:      1,
+				Window:     time.Minute,
+				RetryAfter: tc.strategy,
+			})(handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/entries/new", nil)
+			rec := httptest.NewRecorder()
+			wrapped.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			rec = httptest.NewRecorder()
+			wrapped.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusTooManyRequests, rec.Code)
+			assert.Equal(t, tc.wantHeader, rec.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestRateLimitBoundaryBurst(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	windowStart := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// burst opens a window with one request, sends the rest of the limit just before the
+	// boundary and a full limit just after it, returning how many requests were allowed.
+	burst := func(algorithm string) int {
+		clock := windowStart
+		now := func() time.Time { return clock }
+		wrapped := RateLimit(RateLimitConfig{
+			Cache:     &fakeRateLimitStore{now: now},
+			Limit:     10,
+			Window:    time.Minute,
+			Algorithm: algorithm,
+			now:       now,
+		})(handler)
+
+		allowed := 0
+		send := func() {
+			rec := httptest.NewRecorder()
+			wrapped.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/entries/hot", nil))
+			if rec.Code == http.StatusOK {
+				allowed++
+			}
+		}
+		send()
+		clock = windowStart.Add(59 * time.Second)
+		for i := 0; i < 9; i++ {
+			send()
+		}
+		clock = windowStart.Add(61 * time.Second)
+		for i := 0; i < 10; i++ {
+			send()
+		}
+		return allowed
+	}
+
+	assert.Equal(t, 20, burst(RateLimitFixedWindow), "fixed window lets twice the limit through at the boundary")
+	assert.Equal(t, 10, burst(RateLimitSlidingWindow), "sliding window keeps the boundary burst within the limit")
+}
+
+func TestRateLimitSlidingWindowRecovers(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	clock := time.Date(2026, 1, 1, 0, 0, 45, 0, time.UTC)
+	now := func() time.Time { return clock }
+	wrapped := RateLimit(RateLimitConfig{
+		Cache:      &fakeRateLimitStore{now: now},
+		Limit:      2,
+		Window:     time.Minute,
+		Algorithm:  RateLimitSlidingWindow,
+		RetryAfter: RetryAfterRemaining,
+		now:        now,
+	})(handler)
+	send := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		wrapped.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/entries/hot", nil))
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, send().Code)
+	require.Equal(t, http.StatusOK, send().Code)
+	rec := send()
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "15", rec.Header().Get("Retry-After"))
+
+	// Three quarters into the next window the previous count (rejections included) weighs 25%.
+	clock = time.Date(2026, 1, 1, 0, 1, 45, 0, time.UTC)
+	assert.Equal(t, http.StatusOK, send().Code)
+	assert.Equal(t, http.StatusTooManyRequests, send().Code)
+
+	// Two windows later nothing is left.
+	clock = time.Date(2026, 1, 1, 0, 3, 0, 0, time.UTC)
+	assert.Equal(t, http.StatusOK, send().Code)
+}
+
+func TestConcurrencyLimitDisabled(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	wrapped := ConcurrencyLimit(ConcurrencyLimitConfig{})(handler)
+
+	rec := httptest.NewRecorder()
+	wrapped.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestDebugRequestLog(t *testing.T) {
+	newLogger := func(buf *bytes.Buffer) *slog.Logger {
+		return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+
+	t.Run("redacts credentials and restores body", func(t *testing.T) {
+		var buf bytes.Buffer
+		var seenBody string
+		handler := DebugRequestLog(DebugRequestLogConfig{
+			Logger:       newLogger(&buf),
+			Headers:      []string{"Content-Type", "X-API-Key", "authorization"},
+			MaxBodyBytes: 1024,
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			seenBody = string(b)
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/api-keys?x=1", strings.NewReader(`{"name":"test"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "hb_live_secret")
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `{"name":"test"}`, seenBody)
+
+		var logged map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &logged))
+		assert.Equal(t, "http request dump", logged["msg"])
+		assert.Equal(t, `{"name":"test"}`, logged["body"])
+		assert.Equal(t, false, logged["body_truncated"])
+		headers := logged["headers"].(map[string]any)
+		assert.Equal(t, "application/json", headers["Content-Type"])
+		assert.Equal(t, "[REDACTED]", headers["X-Api-Key"])
+		assert.Equal(t, "[REDACTED]", headers["Authorization"])
+		assert.NotContains(t, buf.String(), "secret")
+	})
+
+	t.Run("truncates large body but handler sees all of it", func(t *testing.T) {
+		var buf bytes.Buffer
+		var seenBody string
+		handler := DebugRequestLog(DebugRequestLogConfig{
+			Logger:       newLogger(&buf),
+			MaxBodyBytes: 4,
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			seenBody = string(b)
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/click", strings.NewReader("0123456789"))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, "0123456789", seenBody)
+		var logged map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &logged))
+		assert.Equal(t, "0123", logged["body"])
+		assert.Equal(t, true, logged["body_truncated"])
+	})
+
+	t.Run("does not log GET bodies", func(t *testing.T) {
+		var buf bytes.Buffer
+		handler := DebugRequestLog(DebugRequestLogConfig{
+			Logger:       newLogger(&buf),
+			MaxBodyBytes: 1024,
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/entries", nil))
+
+		var logged map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &logged))
+		assert.NotContains(t, logged, "body")
+	})
+
+	t.Run("redacts configured query params and paths", func(t *testing.T) {
+		var buf bytes.Buffer
+		handler := DebugRequestLog(DebugRequestLogConfig{
+			Logger:       newLogger(&buf),
+			RedactParams: []string{"session"},
+			RedactPaths:  []string{"/api/v1/search"},
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/entries?session=abc&page=2", nil))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/search?q=private", nil))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 2)
+		var logged map[string]any
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &logged))
+		assert.Equal(t, "session=[REDACTED]&page=2", logged["query"])
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &logged))
+		assert.Equal(t, "[REDACTED]", logged["query"])
+		assert.NotContains(t, buf.String(), "private")
+	})
+
+	t.Run("skips logging above debug level", func(t *testing.T) {
+		var buf bytes.Buffer
+		handler := DebugRequestLog(DebugRequestLogConfig{
+			Logger:       slog.New(slog.NewJSONHandler(&buf, nil)),
+			MaxBodyBytes: 1024,
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/click", strings.NewReader("x")))
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestSkipPaths(t *testing.T) {
+	skip := SkipPaths([]string{"/api/v1/health/*", "/metrics", " ", "/version"})
+	cases := map[string]bool{
+		"/api/v1/health":         true,
+		"/api/v1/health/live":    true,
+		"/api/v1/health/ready":   true,
+		"/api/v1/healthz":        false,
+		"/metrics":               true,
+		"/metrics/clicks":        false,
+		"/version":               true,
+		"/api/v1/entries/new":    false,
+		"/api/v1/metrics/clicks": false,
+	}
+	for path, want := range cases {
+		assert.Equal(t, want, skip(httptest.NewRequest(http.MethodGet, path, nil)), path)
+	}
+}
+
+func TestRateLimitSkipsExemptPaths(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	wrapped := RateLimit(RateLimitConfig{
+		Cache:  &fakeRateLimitStore{},
+		Limit:  1,
+		Window: time.Minute,
+		Skip:   SkipPaths([]string{"/api/v1/health/*", "/metrics", "/version"}),
+	})(handler)
+	send := func(path string) int {
+		rec := httptest.NewRecorder()
+		wrapped.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusOK, send("/api/v1/entries/new"))
+	require.Equal(t, http.StatusTooManyRequests, send("/api/v1/entries/new"))
+	for _, path := range []string{"/api/v1/health", "/api/v1/health/live", "/api/v1/health/ready", "/metrics", "/version"} {
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusOK, send(path), path)
+		}
+	}
+	assert.Equal(t, http.StatusTooManyRequests, send("/api/v1/search"))
+}
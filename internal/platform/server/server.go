@@ -22,8 +22,9 @@ type Config struct {
 
 // Server wraps http.Server with graceful shutdown support
 type Server struct {
-	httpServer *http.Server
-	logger     *slog.Logger
+	httpServer    *http.Server
+	logger        *slog.Logger
+	shutdownHooks []func(ctx context.Context) error
 }
 
 // New creates a new HTTP server
@@ -40,6 +41,13 @@ func New(cfg Config, handler http.Handler, logger *slog.Logger) *Server {
 	}
 }
 
+// OnShutdown registers fn to run during Shutdown after the HTTP server has stopped accepting
+// requests and drained in-flight ones, e.g. to stop background workers. Hooks run in
+// registration order and share Shutdown's context.
+func (s *Server) OnShutdown(fn func(ctx context.Context) error) {
+	s.shutdownHooks = append(s.shutdownHooks, fn)
+}
+
 // Start starts the HTTP server
 func (s *Server) Start() error {
 	s.logger.Info("starting HTTP server", "address", s.httpServer.Addr)
@@ -55,12 +63,24 @@ func (s *Server) Start() error {
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("shutting down HTTP server")
 
-	if err := s.httpServer.Shutdown(ctx); err != nil {
-		return fmt.Errorf("failed to shutdown server: %w", err)
+	err := s.httpServer.Shutdown(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to shutdown server: %w", err)
+	} else {
+		s.logger.Info("HTTP server stopped")
 	}
+	// Hooks run even when draining timed out, so background work still gets a stop signal.
+	return errors.Join(err, s.runShutdownHooks(ctx))
+}
 
-	s.logger.Info("HTTP server stopped")
-	return nil
+func (s *Server) runShutdownHooks(ctx context.Context) error {
+	var errs []error
+	for _, hook := range s.shutdownHooks {
+		if err := hook(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown hook: %w", err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // ListenAndServeWithGracefulShutdown starts the server and handles graceful shutdown
@@ -80,7 +100,9 @@ func (s *Server) ListenAndServeWithGracefulShutdown() error {
 	// Block until we receive a signal or an error
 	select {
 	case err := <-serverErrors:
-		return fmt.Errorf("server error: %w", err)
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return errors.Join(fmt.Errorf("server error: %w", err), s.runShutdownHooks(ctx))
 	case sig := <-quit:
 		s.logger.Info("received shutdown signal", "signal", sig.String())
 
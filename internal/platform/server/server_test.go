@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"testing"
@@ -73,3 +74,28 @@ func TestConfig(t *testing.T) {
 	assert.Equal(t, 10*time.Second, cfg.WriteTimeout)
 	assert.Equal(t, 60*time.Second, cfg.IdleTimeout)
 }
+
+func TestServer_ShutdownRunsHooks(t *testing.T) {
+	srv := New(Config{Address: "127.0.0.1:0"}, http.NotFoundHandler(), slog.Default())
+	go func() {
+		_ = srv.Start()
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	var calls []string
+	srv.OnShutdown(func(ctx context.Context) error {
+		calls = append(calls, "workers")
+		return nil
+	})
+	hookErr := errors.New("flush failed")
+	srv.OnShutdown(func(ctx context.Context) error {
+		calls = append(calls, "failing")
+		return hookErr
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := srv.Shutdown(ctx)
+	require.ErrorIs(t, err, hookErr)
+	assert.Equal(t, []string{"workers", "failing"}, calls)
+}
@@ -6,6 +6,7 @@ import (
 
 	domainArchive "hateblog/internal/domain/archive"
 	"hateblog/internal/domain/repository"
+	"hateblog/internal/pkg/cachefallback"
 )
 
 // Repository defines entry aggregation operations required by the service.
@@ -56,13 +57,12 @@ func (s *Service) ListWithCacheStatus(ctx context.Context, minBookmarkCount int)
 	// Try to get from cache (today + past)
 	items, cacheHit, err := s.getFromCache(ctx, minBookmarkCount)
 	if err != nil {
-		return nil, false, err
-	}
-	if cacheHit {
+		cachefallback.Record(err)
+	} else if cacheHit {
 		return items, true, nil
 	}
 
-	// Cache miss: fetch from DB and split into today/past
+	// Cache miss or cache error: fetch from DB and split into today/past
 	items, err = s.repo.ListArchiveCounts(ctx, minBookmarkCount)
 	if err != nil {
 		return nil, false, err
@@ -2,11 +2,14 @@ package archive
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"hateblog/internal/domain/repository"
+	"hateblog/internal/pkg/cachefallback"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -27,3 +30,31 @@ func TestServiceListRejectsInvalidMinUsers(t *testing.T) {
 	_, err := svc.List(context.Background(), 7)
 	require.Error(t, err)
 }
+
+type failingCache struct{ err error }
+
+func (c failingCache) GetToday(ctx context.Context, minUsers int, out any) (bool, error) {
+	return false, c.err
+}
+
+func (c failingCache) SetToday(ctx context.Context, minUsers int, value any) error { return c.err }
+
+func (c failingCache) GetPast(ctx context.Context, minUsers int, out any) (bool, error) {
+	return false, c.err
+}
+
+func (c failingCache) SetPast(ctx context.Context, minUsers int, value any) error { return c.err }
+
+func TestServiceListFallsBackOnCacheError(t *testing.T) {
+	items := []repository.ArchiveCount{
+		{Date: time.Now(), Count: 10},
+	}
+	before := testutil.ToFloat64(cachefallback.Total.WithLabelValues(cachefallback.ReasonError))
+	svc := NewService(&stubRepo{items: items}, failingCache{err: errors.New("redis: connection refused")})
+
+	got, hit, err := svc.ListWithCacheStatus(context.Background(), 5)
+	require.NoError(t, err)
+	require.False(t, hit)
+	require.Equal(t, items, got)
+	require.Equal(t, before+1, testutil.ToFloat64(cachefallback.Total.WithLabelValues(cachefallback.ReasonError)))
+}
@@ -0,0 +1,71 @@
+package entry
+
+import (
+	"context"
+	"fmt"
+
+	domainEntry "hateblog/internal/domain/entry"
+)
+
+// DayCacheReport compares the cached entries of a day with the database.
+type DayCacheReport struct {
+	Date string
+	// Cached is false when the day is not in the cache; there is nothing to be stale then.
+	Cached      bool
+	CachedCount int
+	DBCount     int
+	// Missing holds entries in the database but not in the cache, Extra the reverse.
+	Missing []domainEntry.ID
+	Extra   []domainEntry.ID
+}
+
+// Stale reports whether the cached entry IDs differ from the database.
+func (r DayCacheReport) Stale() bool {
+	return len(r.Missing) > 0 || len(r.Extra) > 0
+}
+
+// VerifyDayCache loads the day of date (YYYYMMDD in the application timezone) from the day
+// cache and directly from the repository, and reports how their entry ID sets differ. The
+// cache is only read.
+func (s *Service) VerifyDayCache(ctx context.Context, date string) (DayCacheReport, error) {
+	if s.dayCache == nil {
+		return DayCacheReport{}, fmt.Errorf("day cache is not configured")
+	}
+	query, err := s.dayQuery(date, nil)
+	if err != nil {
+		return DayCacheReport{}, err
+	}
+	report := DayCacheReport{Date: date}
+	cached, ok, err := s.dayCache.Get(ctx, dayCacheKey(date, nil))
+	if err != nil {
+		return report, fmt.Errorf("read day cache: %w", err)
+	}
+	stored, err := s.repo.List(ctx, query)
+	if err != nil {
+		return report, err
+	}
+	report.DBCount = len(stored)
+	if !ok {
+		return report, nil
+	}
+	report.Cached = true
+	report.CachedCount = len(cached)
+	report.Missing = missingIDs(stored, cached)
+	report.Extra = missingIDs(cached, stored)
+	return report, nil
+}
+
+// missingIDs returns the IDs of want that are absent from have, in want's order.
+func missingIDs(want, have []*domainEntry.Entry) []domainEntry.ID {
+	seen := make(map[domainEntry.ID]struct{}, len(have))
+	for _, ent := range have {
+		seen[ent.ID] = struct{}{}
+	}
+	var out []domainEntry.ID
+	for _, ent := range want {
+		if _, ok := seen[ent.ID]; !ok {
+			out = append(out, ent.ID)
+		}
+	}
+	return out
+}
@@ -5,23 +5,32 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sort"
+	"time"
 
+	domainArchive "hateblog/internal/domain/archive"
 	domainEntry "hateblog/internal/domain/entry"
 	"hateblog/internal/domain/repository"
 	"hateblog/internal/pkg/apptime"
+	"hateblog/internal/pkg/cachefallback"
+	"hateblog/internal/pkg/daycap"
 )
 
 // DayEntriesCache stores entries by date.
+// The date key is YYYYMMDD, suffixed with "@<zone>" when the request overrides
+// the application timezone. Delete on a plain date also drops its zoned keys.
 type DayEntriesCache interface {
 	Get(ctx context.Context, date string) ([]*domainEntry.Entry, bool, error)
 	Set(ctx context.Context, date string, entries []*domainEntry.Entry) error
+	Delete(ctx context.Context, date string) error
 }
 
 // TagEntriesCache stores entries by tag.
 type TagEntriesCache interface {
 	Get(ctx context.Context, tagName string, sort domainEntry.SortType, minUsers int, out any) (bool, error)
 	Set(ctx context.Context, tagName string, sort domainEntry.SortType, minUsers int, value any) error
+	Delete(ctx context.Context, tagName string, sort domainEntry.SortType, minUsers int) error
 }
 
 // Service orchestrates entry use cases.
@@ -29,22 +38,40 @@ type Service struct {
 	repo          repository.EntryRepository
 	dayCache      DayEntriesCache
 	tagEntries    TagEntriesCache
+	tagLinker     TagLinker
+	maxTags       int
+	hotTiebreak   domainEntry.HotTiebreak
+	hotMinAge     time.Duration
+	now           func() time.Time
 	logger        *slog.Logger
 	maxAllResults int
+	minResults    int
 }
 
 // ListResult represents query outcome.
 type ListResult struct {
 	Entries []*domainEntry.Entry `json:"entries"`
 	Total   int64                `json:"total"`
+	// Relaxed reports that min_users was lowered to MinBookmarkCount to reach the minimum
+	// result count (see WithMinResults).
+	Relaxed          bool `json:"relaxed,omitempty"`
+	MinBookmarkCount int  `json:"min_bookmark_count,omitempty"`
 }
 
 // DayListParams represents user filters for /entries endpoints.
 type DayListParams struct {
-	Date             string
+	Date string
+	// Location overrides the timezone used to resolve Date. nil means the app zone.
+	Location         *time.Location
 	MinBookmarkCount int
 	Offset           int
 	Limit            int
+	// Relax lowers MinBookmarkCount when fewer entries than the minimum result count match.
+	Relax bool
+	// Trending orders hot lists by Entry.Hotness instead of bookmark count.
+	Trending bool
+	// ByPostedAt orders new lists by domainEntry.PostedLess instead of created_at.
+	ByPostedAt bool
 }
 
 // TagListParams represents user filters for /tags/entries/{tag}.
@@ -55,6 +82,15 @@ type TagListParams struct {
 	Sort             domainEntry.SortType
 }
 
+// UntaggedListParams represents paging for /entries/untagged.
+type UntaggedListParams struct {
+	Offset int
+	Limit  int
+}
+
+// DefaultMaxDayEntries caps the rows loaded (and cached) for one day list.
+const DefaultMaxDayEntries = 100000
+
 // NewService instantiates the service.
 func NewService(repo repository.EntryRepository, dayCache DayEntriesCache, tagEntriesCache TagEntriesCache, logger *slog.Logger) *Service {
 	return &Service{
@@ -62,10 +98,42 @@ func NewService(repo repository.EntryRepository, dayCache DayEntriesCache, tagEn
 		dayCache:      dayCache,
 		tagEntries:    tagEntriesCache,
 		logger:        logger,
-		maxAllResults: 100000,
+		maxAllResults: DefaultMaxDayEntries,
+		maxTags:       MaxManualTags,
+		now:           apptime.Now,
 	}
 }
 
+// WithHotTiebreak orders day hot lists with equal bookmark counts by t. Other lists are
+// ordered by the repository (see postgres.EntryRepository.WithHotTiebreak).
+func (s *Service) WithHotTiebreak(t domainEntry.HotTiebreak) *Service {
+	s.hotTiebreak = t
+	return s
+}
+
+// WithHotMinAge hides entries created less than minAge ago from day hot lists, so that a
+// quick burst of bookmarks cannot put an entry on top before it settles (0 disables).
+func (s *Service) WithHotMinAge(minAge time.Duration) *Service {
+	s.hotMinAge = minAge
+	return s
+}
+
+// WithMaxDayEntries overrides DefaultMaxDayEntries as the SQL LIMIT of a full day load
+// (0 keeps the default).
+func (s *Service) WithMaxDayEntries(n int) *Service {
+	if n > 0 {
+		s.maxAllResults = n
+	}
+	return s
+}
+
+// WithMinResults sets the result count below which day lists requested with Relax lower their
+// min_users through the archive thresholds (0 disables relaxing).
+func (s *Service) WithMinResults(n int) *Service {
+	s.minResults = n
+	return s
+}
+
 // ListNewEntries returns entries ordered by created_at DESC.
 func (s *Service) ListNewEntries(ctx context.Context, params DayListParams) (ListResult, error) {
 	result, _, err := s.listDayEntriesWithCacheStatus(ctx, domainEntry.SortNew, params)
@@ -126,14 +194,21 @@ func (s *Service) ListTagEntriesWithCacheStatus(ctx context.Context, tagName str
 		return ListResult{}, false, fmt.Errorf("unsupported sort %q", sortType)
 	}
 
-	useCache := limit == maxLimit && offset == 0 && s.tagEntries != nil
+	// Common thresholds are cached pre-filtered as a first page of maxLimit entries,
+	// and any page inside that window is sliced from it (like the ranking caches).
+	// Other min_users values always go to the repository.
+	useCache := s.tagEntries != nil && offset+limit <= maxLimit && isSegmentedMinUsers(minUsers)
 	if useCache {
-		var cached ListResult
+		var cached tagEntriesCachePayload
 		ok, err := s.tagEntries.Get(ctx, tagName, sortType, minUsers, &cached)
 		if err != nil {
+			cachefallback.Record(err)
 			s.logDebug("tag entries cache lookup failed", err)
 		} else if ok {
-			return cached, true, nil
+			return ListResult{
+				Entries: paginate(cached.Entries, offset, limit),
+				Total:   cached.Total,
+			}, true, nil
 		}
 	}
 
@@ -145,6 +220,10 @@ func (s *Service) ListTagEntriesWithCacheStatus(ctx context.Context, tagName str
 		MaxLimitOverride: maxLimit,
 		MinBookmarkCount: minUsers,
 	}
+	if useCache {
+		query.Limit = maxLimit
+		query.Offset = 0
+	}
 	entries, err := s.repo.List(ctx, query)
 	if err != nil {
 		return ListResult{}, false, err
@@ -161,33 +240,109 @@ func (s *Service) ListTagEntriesWithCacheStatus(ctx context.Context, tagName str
 		}); err != nil {
 			s.logDebug("tag entries cache set failed", err)
 		}
+		entries = paginate(entries, offset, limit)
 	}
 
 	return ListResult{Entries: entries, Total: total}, false, nil
 }
 
+// ListUntaggedEntries returns entries without tags ordered by posted_at DESC.
+// The result is not cached since editors expect tagged entries to drop out immediately.
+func (s *Service) ListUntaggedEntries(ctx context.Context, params UntaggedListParams) (ListResult, error) {
+	limit := params.Limit
+	if limit <= 0 {
+		limit = domainEntry.DefaultLimit
+	}
+	const maxLimit = 100
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	offset := params.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, err := s.repo.ListUntagged(ctx, limit, offset)
+	if err != nil {
+		return ListResult{}, err
+	}
+	total, err := s.repo.CountUntagged(ctx)
+	if err != nil {
+		return ListResult{}, err
+	}
+	return ListResult{Entries: entries, Total: total}, nil
+}
+
+// GetEntriesByIDs returns the entries with the given IDs in request order. Duplicate IDs are
+// looked up once and IDs that do not exist are omitted. At most domainEntry.MaxBatchIDs
+// distinct IDs are accepted. Results are not cached so that clients always get fresh counts.
+func (s *Service) GetEntriesByIDs(ctx context.Context, ids []domainEntry.ID) ([]*domainEntry.Entry, error) {
+	seen := make(map[domainEntry.ID]struct{}, len(ids))
+	unique := make([]domainEntry.ID, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	if len(unique) == 0 {
+		return []*domainEntry.Entry{}, nil
+	}
+	if len(unique) > domainEntry.MaxBatchIDs {
+		return nil, fmt.Errorf("%w: at most %d ids can be requested at once", domainEntry.ErrInvalidListQuery, domainEntry.MaxBatchIDs)
+	}
+	return s.repo.GetByIDs(ctx, unique)
+}
+
 func (s *Service) listDayEntriesWithCacheStatus(ctx context.Context, sortType domainEntry.SortType, params DayListParams) (ListResult, bool, error) {
 	var empty ListResult
 	if params.Date == "" {
 		return empty, false, fmt.Errorf("date is required")
 	}
-	all, cacheHit, err := s.loadAllDayEntries(ctx, params.Date)
+	all, cacheHit, err := s.loadAllDayEntries(ctx, params.Date, params.Location)
 	if err != nil {
 		return empty, false, err
 	}
-	filtered := filterByMinUsers(all, params.MinBookmarkCount)
-	switch sortType {
-	case domainEntry.SortHot:
-		sort.Slice(filtered, func(i, j int) bool {
-			if filtered[i].BookmarkCount != filtered[j].BookmarkCount {
-				return filtered[i].BookmarkCount > filtered[j].BookmarkCount
+	filter := func(minUsers int) []*domainEntry.Entry {
+		filtered := filterByMinUsers(all, minUsers)
+		if sortType == domainEntry.SortHot {
+			filtered = filterByMinAge(filtered, s.now(), s.hotMinAge)
+		}
+		return filtered
+	}
+	minUsers := params.MinBookmarkCount
+	filtered := filter(minUsers)
+	relaxed := false
+	if params.Relax && s.minResults > 0 {
+		for _, lower := range domainArchive.LowerMinUsers(minUsers) {
+			if len(filtered) >= s.minResults {
+				break
 			}
-			return filtered[i].CreatedAt.After(filtered[j].CreatedAt)
+			minUsers, filtered, relaxed = lower, filter(lower), true
+		}
+	}
+	if sortType == domainEntry.SortHot {
+		if params.Trending {
+			now := s.now()
+			sort.Slice(filtered, func(i, j int) bool {
+				return domainEntry.TrendingLess(filtered[i], filtered[j], now)
+			})
+		} else {
+			sort.Slice(filtered, func(i, j int) bool {
+				return domainEntry.HotLess(filtered[i], filtered[j], s.hotTiebreak)
+			})
+		}
+	} else if params.ByPostedAt {
+		// filtered may be the loaded day list itself; keep that in created_at order.
+		filtered = slices.Clone(filtered)
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return domainEntry.PostedLess(filtered[i], filtered[j])
 		})
 	}
 	total := int64(len(filtered))
 	paged := paginate(filtered, params.Offset, params.Limit)
-	return ListResult{Entries: paged, Total: total}, cacheHit, nil
+	return ListResult{Entries: paged, Total: total, Relaxed: relaxed, MinBookmarkCount: minUsers}, cacheHit, nil
 }
 
 func (s *Service) logDebug(msg string, err error) {
@@ -206,39 +361,87 @@ func (s *Service) logDebug(msg string, err error) {
 	s.logger.Debug(msg, attrs...)
 }
 
-func (s *Service) loadAllDayEntries(ctx context.Context, date string) ([]*domainEntry.Entry, bool, error) {
+func (s *Service) loadAllDayEntries(ctx context.Context, date string, loc *time.Location) ([]*domainEntry.Entry, bool, error) {
+	if loc != nil && loc.String() == time.Local.String() {
+		loc = nil
+	}
+	cacheKey := dayCacheKey(date, loc)
 	if s.dayCache != nil {
-		if cached, ok, err := s.dayCache.Get(ctx, date); err == nil && ok {
+		if cached, ok, err := s.dayCache.Get(ctx, cacheKey); err == nil && ok {
 			return cached, true, nil
 		} else if err != nil {
+			cachefallback.Record(err)
 			s.logDebug("day cache lookup failed", err)
 		}
 	}
-	from, to, err := apptime.DayRange(date)
+	query, err := s.dayQuery(date, loc)
 	if err != nil {
 		return nil, false, err
 	}
-	query := domainEntry.ListQuery{
-		Sort:             domainEntry.SortNew,
-		Limit:            s.maxAllResults,
-		MaxLimitOverride: s.maxAllResults,
-		PostedAtFrom:     from,
-		PostedAtTo:       to,
-	}
 	entries, err := s.repo.List(ctx, query)
 	if err != nil {
 		return nil, false, err
 	}
+	if len(entries) >= query.Limit {
+		// The day may hold more entries than were loaded; the lists and the cache miss the rest.
+		daycap.Record()
+		if s.logger != nil {
+			s.logger.Warn("day entries load reached the cap; the day may be truncated", "date", date, "cap", query.Limit)
+		}
+	}
 	if s.dayCache != nil {
-		if err := s.dayCache.Set(ctx, date, entries); err != nil {
+		if err := s.dayCache.Set(ctx, cacheKey, entries); err != nil {
 			s.logDebug("day cache set failed", err)
 		}
 	}
 	return entries, false, nil
 }
 
+// dayQuery returns the query loading every entry of date, as stored in the day cache.
+func (s *Service) dayQuery(date string, loc *time.Location) (domainEntry.ListQuery, error) {
+	from, to, err := apptime.DayRangeIn(date, loc)
+	if err != nil {
+		return domainEntry.ListQuery{}, err
+	}
+	return domainEntry.ListQuery{
+		Sort:             domainEntry.SortNew,
+		Limit:            s.maxAllResults,
+		MaxLimitOverride: s.maxAllResults,
+		PostedAtFrom:     from,
+		PostedAtTo:       to,
+	}, nil
+}
+
 type tagEntriesCachePayload = ListResult
 
+func dayCacheKey(date string, loc *time.Location) string {
+	if loc == nil {
+		return date
+	}
+	return date + "@" + loc.String()
+}
+
+// isSegmentedMinUsers reports whether min_users has its own tag entries cache segment.
+func isSegmentedMinUsers(minUsers int) bool {
+	return minUsers == 0 || domainArchive.IsAllowedMinUsers(minUsers)
+}
+
+// filterByMinAge drops entries created after now-minAge. It returns entries as is when minAge
+// is not positive.
+func filterByMinAge(entries []*domainEntry.Entry, now time.Time, minAge time.Duration) []*domainEntry.Entry {
+	if minAge <= 0 {
+		return entries
+	}
+	cutoff := now.Add(-minAge)
+	out := make([]*domainEntry.Entry, 0, len(entries))
+	for _, e := range entries {
+		if !e.CreatedAt.After(cutoff) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
 func filterByMinUsers(entries []*domainEntry.Entry, minUsers int) []*domainEntry.Entry {
 	if minUsers < 0 {
 		minUsers = 0
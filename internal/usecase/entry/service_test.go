@@ -1,15 +1,22 @@
 package entry
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"testing"
 	"time"
 
 	domainEntry "hateblog/internal/domain/entry"
 	"hateblog/internal/domain/repository"
+	domainTag "hateblog/internal/domain/tag"
+	"hateblog/internal/pkg/cachefallback"
+	"hateblog/internal/pkg/daycap"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -18,13 +25,27 @@ type stubEntryRepo struct {
 	listErr    error
 
 	listCalls int
+	listQuery domainEntry.ListQuery
+
+	untaggedLimit  int
+	untaggedOffset int
+
+	getResult *domainEntry.Entry
+	getErr    error
+
+	getByIDs []domainEntry.ID
 }
 
 func (s *stubEntryRepo) Get(ctx context.Context, id domainEntry.ID) (*domainEntry.Entry, error) {
-	return nil, nil
+	return s.getResult, s.getErr
+}
+func (s *stubEntryRepo) GetByIDs(ctx context.Context, ids []domainEntry.ID) ([]*domainEntry.Entry, error) {
+	s.getByIDs = ids
+	return s.listResult, s.listErr
 }
 func (s *stubEntryRepo) List(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
 	s.listCalls++
+	s.listQuery = query
 	return s.listResult, s.listErr
 }
 func (s *stubEntryRepo) Count(ctx context.Context, query domainEntry.ListQuery) (int64, error) {
@@ -42,9 +63,17 @@ func (s *stubEntryRepo) Delete(ctx context.Context, id domainEntry.ID) error {
 func (s *stubEntryRepo) ListArchiveCounts(ctx context.Context, minBookmarkCount int) ([]repository.ArchiveCount, error) {
 	return nil, nil
 }
+func (s *stubEntryRepo) ListUntagged(ctx context.Context, limit, offset int) ([]*domainEntry.Entry, error) {
+	s.untaggedLimit, s.untaggedOffset = limit, offset
+	return s.listResult, s.listErr
+}
+func (s *stubEntryRepo) CountUntagged(ctx context.Context) (int64, error) {
+	return int64(len(s.listResult)), nil
+}
 
 type stubDayCache struct {
 	store    map[string][]*domainEntry.Entry
+	getErr   error
 	getCalls int
 	setCalls int
 }
@@ -55,6 +84,9 @@ func newStubDayCache() *stubDayCache {
 
 func (c *stubDayCache) Get(ctx context.Context, date string) ([]*domainEntry.Entry, bool, error) {
 	c.getCalls++
+	if c.getErr != nil {
+		return nil, false, c.getErr
+	}
 	v, ok := c.store[date]
 	return v, ok, nil
 }
@@ -65,6 +97,11 @@ func (c *stubDayCache) Set(ctx context.Context, date string, entries []*domainEn
 	return nil
 }
 
+func (c *stubDayCache) Delete(ctx context.Context, date string) error {
+	delete(c.store, date)
+	return nil
+}
+
 type stubTagCache struct {
 	store map[string]any
 }
@@ -99,6 +136,11 @@ func (c *stubTagCache) Set(ctx context.Context, tag string, sort domainEntry.Sor
 	return nil
 }
 
+func (c *stubTagCache) Delete(ctx context.Context, tag string, sort domainEntry.SortType, minUsers int) error {
+	delete(c.store, c.key(tag, sort, minUsers))
+	return nil
+}
+
 func TestListNewEntriesUsesDayCache(t *testing.T) {
 	dayCache := newStubDayCache()
 	tagCache := &stubTagCache{store: map[string]any{}}
@@ -128,6 +170,72 @@ func TestListNewEntriesUsesDayCache(t *testing.T) {
 	require.Equal(t, 1, dayCache.getCalls)
 }
 
+func TestListNewEntriesCountsCacheFallbackOnlyOnError(t *testing.T) {
+	params := DayListParams{Date: "20250105", Limit: 25}
+
+	t.Run("plain miss is not counted", func(t *testing.T) {
+		before := testutil.ToFloat64(cachefallback.Total.WithLabelValues(cachefallback.ReasonError))
+		repo := &stubEntryRepo{}
+		svc := NewService(repo, newStubDayCache(), nil, nil)
+
+		_, err := svc.ListNewEntries(context.Background(), params)
+		require.NoError(t, err)
+		require.Equal(t, 1, repo.listCalls)
+		require.Equal(t, before, testutil.ToFloat64(cachefallback.Total.WithLabelValues(cachefallback.ReasonError)))
+	})
+
+	t.Run("cache error is counted", func(t *testing.T) {
+		before := testutil.ToFloat64(cachefallback.Total.WithLabelValues(cachefallback.ReasonError))
+		dayCache := newStubDayCache()
+		dayCache.getErr = errors.New("redis: connection refused")
+		repo := &stubEntryRepo{}
+		svc := NewService(repo, dayCache, nil, nil)
+
+		_, err := svc.ListNewEntries(context.Background(), params)
+		require.NoError(t, err)
+		require.Equal(t, 1, repo.listCalls)
+		require.Equal(t, before+1, testutil.ToFloat64(cachefallback.Total.WithLabelValues(cachefallback.ReasonError)))
+	})
+}
+
+func TestListNewEntriesWarnsWhenDayLoadReachesCap(t *testing.T) {
+	params := DayListParams{Date: "20250105", Limit: 25}
+	newEntries := func(n int) []*domainEntry.Entry {
+		out := make([]*domainEntry.Entry, n)
+		for i := range out {
+			out[i] = &domainEntry.Entry{ID: uuid.New()}
+		}
+		return out
+	}
+
+	t.Run("exactly the cap", func(t *testing.T) {
+		var logs bytes.Buffer
+		before := testutil.ToFloat64(daycap.Total)
+		repo := &stubEntryRepo{listResult: newEntries(3)}
+		svc := NewService(repo, nil, nil, slog.New(slog.NewTextHandler(&logs, nil))).WithMaxDayEntries(3)
+
+		out, err := svc.ListNewEntries(context.Background(), params)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), out.Total)
+		require.Equal(t, 3, repo.listQuery.Limit)
+		require.Equal(t, before+1, testutil.ToFloat64(daycap.Total))
+		require.Contains(t, logs.String(), "day entries load reached the cap")
+		require.Contains(t, logs.String(), "cap=3")
+	})
+
+	t.Run("below the cap", func(t *testing.T) {
+		var logs bytes.Buffer
+		before := testutil.ToFloat64(daycap.Total)
+		repo := &stubEntryRepo{listResult: newEntries(2)}
+		svc := NewService(repo, nil, nil, slog.New(slog.NewTextHandler(&logs, nil))).WithMaxDayEntries(3)
+
+		_, err := svc.ListNewEntries(context.Background(), params)
+		require.NoError(t, err)
+		require.Equal(t, before, testutil.ToFloat64(daycap.Total))
+		require.Empty(t, logs.String())
+	})
+}
+
 func TestListHotEntriesStoresDayCacheAndSorts(t *testing.T) {
 	dayCache := newStubDayCache()
 	tagCache := &stubTagCache{store: map[string]any{}}
@@ -165,3 +273,463 @@ func TestListHotEntriesStoresDayCacheAndSorts(t *testing.T) {
 	require.Equal(t, 1, dayCache.setCalls)
 	require.Contains(t, dayCache.store, "20250105")
 }
+
+func TestListHotEntriesAppliesHotTiebreak(t *testing.T) {
+	now := time.Now()
+	entries := []*domainEntry.Entry{
+		{ID: uuid.New(), Title: "newer", BookmarkCount: 10, CreatedAt: now},
+		{ID: uuid.New(), Title: "older", BookmarkCount: 10, CreatedAt: now.Add(-time.Hour)},
+	}
+	params := DayListParams{Date: "20250105", Limit: 25}
+
+	out, err := NewService(&stubEntryRepo{listResult: entries}, nil, nil, nil).ListHotEntries(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, "newer", out.Entries[0].Title)
+
+	svc := NewService(&stubEntryRepo{listResult: entries}, nil, nil, nil).WithHotTiebreak(domainEntry.HotTiebreakOldest)
+	out, err = svc.ListHotEntries(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, "older", out.Entries[0].Title)
+}
+
+func TestListHotEntriesWithHotMinAge(t *testing.T) {
+	now := time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC)
+	entries := []*domainEntry.Entry{
+		{ID: uuid.New(), Title: "settled", BookmarkCount: 10, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: uuid.New(), Title: "boundary", BookmarkCount: 20, CreatedAt: now.Add(-time.Hour)},
+		{ID: uuid.New(), Title: "fresh", BookmarkCount: 500, CreatedAt: now.Add(-10 * time.Minute)},
+	}
+	params := DayListParams{Date: "20250105", Limit: 25}
+
+	svc := NewService(&stubEntryRepo{listResult: entries}, nil, nil, nil).WithHotMinAge(time.Hour)
+	svc.now = func() time.Time { return now }
+
+	out, err := svc.ListHotEntries(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), out.Total)
+	require.Equal(t, "boundary", out.Entries[0].Title)
+	require.Equal(t, "settled", out.Entries[1].Title)
+
+	// The new list is not affected.
+	out, err = svc.ListNewEntries(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), out.Total)
+}
+
+func TestListHotEntriesTrending(t *testing.T) {
+	now := time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC)
+	entries := []*domainEntry.Entry{
+		{ID: uuid.New(), Title: "early", BookmarkCount: 200, PostedAt: now.Add(-10 * time.Hour)},
+		{ID: uuid.New(), Title: "recent", BookmarkCount: 40, PostedAt: now.Add(-time.Hour)},
+	}
+	svc := NewService(&stubEntryRepo{listResult: entries}, nil, nil, nil)
+	svc.now = func() time.Time { return now }
+
+	out, err := svc.ListHotEntries(context.Background(), DayListParams{Date: "20250105", Limit: 25})
+	require.NoError(t, err)
+	require.Equal(t, "early", out.Entries[0].Title)
+
+	out, err = svc.ListHotEntries(context.Background(), DayListParams{Date: "20250105", Limit: 25, Trending: true})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), out.Total)
+	require.Equal(t, "recent", out.Entries[0].Title)
+	require.Equal(t, "early", out.Entries[1].Title)
+}
+
+func TestListNewEntriesByPostedAt(t *testing.T) {
+	base := time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)
+	// Day lists are loaded in created_at DESC order. "late" was posted first but found last.
+	entries := []*domainEntry.Entry{
+		{ID: uuid.New(), Title: "late", PostedAt: base.Add(time.Hour), CreatedAt: base.Add(12 * time.Hour)},
+		{ID: uuid.New(), Title: "second", PostedAt: base.Add(9 * time.Hour), CreatedAt: base.Add(10 * time.Hour)},
+		{ID: uuid.New(), Title: "first", PostedAt: base.Add(8 * time.Hour), CreatedAt: base.Add(8 * time.Hour)},
+	}
+	svc := NewService(&stubEntryRepo{listResult: entries}, nil, nil, nil)
+	titles := func(out ListResult) []string {
+		var got []string
+		for _, e := range out.Entries {
+			got = append(got, e.Title)
+		}
+		return got
+	}
+
+	out, err := svc.ListNewEntries(context.Background(), DayListParams{Date: "20250105", Limit: 25})
+	require.NoError(t, err)
+	require.Equal(t, []string{"late", "second", "first"}, titles(out))
+
+	out, err = svc.ListNewEntries(context.Background(), DayListParams{Date: "20250105", Limit: 25, ByPostedAt: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"second", "first", "late"}, titles(out))
+	require.Equal(t, "late", entries[0].Title, "the loaded day list keeps its order")
+
+	out, err = svc.ListNewEntries(context.Background(), DayListParams{Date: "20250105", Limit: 1, Offset: 2, ByPostedAt: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"late"}, titles(out))
+}
+
+type rangeEntryRepo struct {
+	stubEntryRepo
+	all []*domainEntry.Entry
+}
+
+func (r *rangeEntryRepo) List(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
+	r.listCalls++
+	var out []*domainEntry.Entry
+	for _, e := range r.all {
+		if !e.PostedAt.Before(query.PostedAtFrom) && e.PostedAt.Before(query.PostedAtTo) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func TestListNewEntriesResolvesDateInLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	repo := &rangeEntryRepo{all: []*domainEntry.Entry{
+		{ID: uuid.New(), Title: "tokyo morning", BookmarkCount: 10, PostedAt: time.Date(2025, 1, 5, 8, 0, 0, 0, tokyo)},
+		{ID: uuid.New(), Title: "new york evening", BookmarkCount: 10, PostedAt: time.Date(2025, 1, 5, 20, 0, 0, 0, newYork)},
+	}}
+	dayCache := newStubDayCache()
+	svc := NewService(repo, dayCache, nil, nil)
+
+	inTokyo, err := svc.ListNewEntries(context.Background(), DayListParams{Date: "20250105", Location: tokyo, Limit: 25})
+	require.NoError(t, err)
+	require.Len(t, inTokyo.Entries, 1)
+	require.Equal(t, "tokyo morning", inTokyo.Entries[0].Title)
+
+	inNewYork, err := svc.ListNewEntries(context.Background(), DayListParams{Date: "20250105", Location: newYork, Limit: 25})
+	require.NoError(t, err)
+	require.Len(t, inNewYork.Entries, 1)
+	require.Equal(t, "new york evening", inNewYork.Entries[0].Title)
+
+	require.Equal(t, 2, repo.listCalls)
+	require.Contains(t, dayCache.store, "20250105@America/New_York")
+}
+
+type countingEntryRepo struct {
+	stubEntryRepo
+	queries []domainEntry.ListQuery
+}
+
+func (r *countingEntryRepo) List(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
+	r.listCalls++
+	r.queries = append(r.queries, query)
+	return paginate(r.listResult, query.Offset, query.Limit), nil
+}
+
+func (r *countingEntryRepo) Count(ctx context.Context, query domainEntry.ListQuery) (int64, error) {
+	return int64(len(r.listResult)), nil
+}
+
+func newTagEntries(n int) []*domainEntry.Entry {
+	entries := make([]*domainEntry.Entry, 0, n)
+	for i := 0; i < n; i++ {
+		entries = append(entries, &domainEntry.Entry{
+			ID:            uuid.New(),
+			Title:         fmt.Sprintf("entry-%d", i),
+			BookmarkCount: 100,
+		})
+	}
+	return entries
+}
+
+func TestListTagEntriesServesPagesFromSegmentedCache(t *testing.T) {
+	repo := &countingEntryRepo{stubEntryRepo: stubEntryRepo{listResult: newTagEntries(60)}}
+	tagCache := &stubTagCache{store: map[string]any{}}
+	svc := NewService(repo, nil, tagCache, nil)
+
+	params := TagListParams{MinBookmarkCount: 10, Limit: 25, Offset: 0}
+	first, hit, err := svc.ListTagEntriesWithCacheStatus(context.Background(), "go", params)
+	require.NoError(t, err)
+	require.False(t, hit)
+	require.Len(t, first.Entries, 25)
+	require.Equal(t, int64(60), first.Total)
+	require.Equal(t, 1, repo.listCalls)
+	require.Equal(t, 100, repo.queries[0].Limit)
+	require.Equal(t, 0, repo.queries[0].Offset)
+	require.Contains(t, tagCache.store, tagCache.key("go", domainEntry.SortNew, 10))
+
+	params.Offset = 25
+	second, hit, err := svc.ListTagEntriesWithCacheStatus(context.Background(), "go", params)
+	require.NoError(t, err)
+	require.True(t, hit)
+	require.Len(t, second.Entries, 25)
+	require.Equal(t, "entry-25", second.Entries[0].Title)
+	require.Equal(t, int64(60), second.Total)
+	require.Equal(t, 1, repo.listCalls)
+}
+
+func TestListTagEntriesBypassesCacheForUncommonMinUsers(t *testing.T) {
+	repo := &countingEntryRepo{stubEntryRepo: stubEntryRepo{listResult: newTagEntries(30)}}
+	tagCache := &stubTagCache{store: map[string]any{}}
+	svc := NewService(repo, nil, tagCache, nil)
+
+	out, hit, err := svc.ListTagEntriesWithCacheStatus(context.Background(), "go", TagListParams{
+		MinBookmarkCount: 7,
+		Limit:            25,
+	})
+	require.NoError(t, err)
+	require.False(t, hit)
+	require.Len(t, out.Entries, 25)
+	require.Equal(t, 25, repo.queries[0].Limit)
+	require.Empty(t, tagCache.store)
+}
+
+func TestListUntaggedEntriesClampsPaging(t *testing.T) {
+	repo := &stubEntryRepo{listResult: newTagEntries(3)}
+	svc := NewService(repo, nil, nil, nil)
+
+	out, err := svc.ListUntaggedEntries(context.Background(), UntaggedListParams{Limit: 500, Offset: -1})
+	require.NoError(t, err)
+	require.Len(t, out.Entries, 3)
+	require.Equal(t, int64(3), out.Total)
+	require.Equal(t, 100, repo.untaggedLimit)
+	require.Equal(t, 0, repo.untaggedOffset)
+
+	_, err = svc.ListUntaggedEntries(context.Background(), UntaggedListParams{})
+	require.NoError(t, err)
+	require.Equal(t, domainEntry.DefaultLimit, repo.untaggedLimit)
+}
+
+func TestGetEntriesByIDsDeduplicatesAndCaps(t *testing.T) {
+	repo := &stubEntryRepo{listResult: newTagEntries(2)}
+	svc := NewService(repo, nil, nil, nil)
+
+	a, b := uuid.New(), uuid.New()
+	out, err := svc.GetEntriesByIDs(context.Background(), []domainEntry.ID{a, b, a})
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	require.Equal(t, []domainEntry.ID{a, b}, repo.getByIDs)
+
+	ids := make([]domainEntry.ID, domainEntry.MaxBatchIDs+1)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	_, err = svc.GetEntriesByIDs(context.Background(), ids)
+	require.ErrorIs(t, err, domainEntry.ErrInvalidListQuery)
+
+	repo.getByIDs = nil
+	out, err = svc.GetEntriesByIDs(context.Background(), nil)
+	require.NoError(t, err)
+	require.Empty(t, out)
+	require.Nil(t, repo.getByIDs)
+}
+
+type stubTagLinker struct {
+	attached map[string]int
+	detached []string
+	scored   map[string]int
+	linked   bool
+}
+
+func (l *stubTagLinker) Upsert(ctx context.Context, t *domainTag.Tag) error {
+	t.ID = uuid.NewSHA1(uuid.Nil, []byte(t.Name))
+	return nil
+}
+
+func (l *stubTagLinker) AttachEntry(ctx context.Context, entryID domainEntry.ID, tagID domainTag.ID, score int) error {
+	if l.attached == nil {
+		l.attached = make(map[string]int)
+	}
+	l.attached[tagID.String()] = score
+	return nil
+}
+
+func (l *stubTagLinker) DetachEntry(ctx context.Context, entryID domainEntry.ID, tagName string) (bool, error) {
+	l.detached = append(l.detached, tagName)
+	return l.linked, nil
+}
+
+func (l *stubTagLinker) UpdateEntryScore(ctx context.Context, entryID domainEntry.ID, tagName string, score int) (bool, error) {
+	if !l.linked {
+		return false, nil
+	}
+	if l.scored == nil {
+		l.scored = make(map[string]int)
+	}
+	l.scored[tagName] = score
+	return true, nil
+}
+
+func TestAddTagsNormalizesAndInvalidatesCaches(t *testing.T) {
+	ent := &domainEntry.Entry{ID: uuid.New(), PostedAt: time.Date(2025, 1, 5, 12, 0, 0, 0, time.Local)}
+	repo := &stubEntryRepo{getResult: ent}
+	dayCache := newStubDayCache()
+	dayCache.store["20250105"] = []*domainEntry.Entry{ent}
+	tagCache := &stubTagCache{store: map[string]any{
+		"go|new|0":   tagEntriesCachePayload{},
+		"go|hot|100": tagEntriesCachePayload{},
+		"rust|new|0": tagEntriesCachePayload{},
+	}}
+	linker := &stubTagLinker{}
+	svc := NewService(repo, dayCache, tagCache, nil).WithTagLinker(linker)
+
+	_, err := svc.AddTags(context.Background(), ent.ID, []string{" Go ", "go"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{uuid.NewSHA1(uuid.Nil, []byte("go")).String(): DefaultManualTagScore}, linker.attached)
+	require.NotContains(t, dayCache.store, "20250105")
+	require.Equal(t, map[string]any{"rust|new|0": tagEntriesCachePayload{}}, tagCache.store)
+}
+
+func TestAddTagsRejectsInvalidInput(t *testing.T) {
+	repo := &stubEntryRepo{getResult: &domainEntry.Entry{}}
+	svc := NewService(repo, nil, nil, nil).WithTagLinker(&stubTagLinker{})
+	score := 101
+
+	tests := []struct {
+		name  string
+		tags  []string
+		score *int
+	}{
+		{name: "no tags"},
+		{name: "blank tag", tags: []string{"go", "  "}},
+		{name: "score out of range", tags: []string{"go"}, score: &score},
+		{name: "too many tags", tags: make([]string, MaxManualTags+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.AddTags(context.Background(), uuid.New(), tt.tags, tt.score)
+			require.ErrorIs(t, err, domainTag.ErrInvalidTag)
+		})
+	}
+}
+
+func TestAddTagsWithMaxTags(t *testing.T) {
+	repo := &stubEntryRepo{getResult: &domainEntry.Entry{}}
+	svc := NewService(repo, nil, nil, nil).WithTagLinker(&stubTagLinker{}).WithMaxTags(2)
+
+	_, err := svc.AddTags(context.Background(), uuid.New(), []string{"a", "b"}, nil)
+	require.NoError(t, err)
+	_, err = svc.AddTags(context.Background(), uuid.New(), []string{"a", "b", "c"}, nil)
+	require.ErrorIs(t, err, domainTag.ErrInvalidTag)
+}
+
+func TestRemoveTagReportsMissingLink(t *testing.T) {
+	repo := &stubEntryRepo{getResult: &domainEntry.Entry{}}
+	linker := &stubTagLinker{}
+	svc := NewService(repo, nil, nil, nil).WithTagLinker(linker)
+
+	err := svc.RemoveTag(context.Background(), uuid.New(), "Go")
+	require.ErrorIs(t, err, ErrTagNotAttached)
+	require.Equal(t, []string{"go"}, linker.detached)
+
+	linker.linked = true
+	require.NoError(t, svc.RemoveTag(context.Background(), uuid.New(), "go"))
+}
+
+func TestUpdateTagScore(t *testing.T) {
+	ent := &domainEntry.Entry{ID: uuid.New(), PostedAt: time.Date(2025, 1, 5, 12, 0, 0, 0, time.Local)}
+	repo := &stubEntryRepo{getResult: ent}
+	tagCache := &stubTagCache{store: map[string]any{
+		"go|hot|0":   tagEntriesCachePayload{},
+		"rust|hot|0": tagEntriesCachePayload{},
+	}}
+	linker := &stubTagLinker{linked: true}
+	svc := NewService(repo, nil, tagCache, nil).WithTagLinker(linker)
+
+	_, err := svc.UpdateTagScore(context.Background(), ent.ID, " Go ", 40)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"go": 40}, linker.scored)
+	require.Equal(t, map[string]any{"rust|hot|0": tagEntriesCachePayload{}}, tagCache.store)
+
+	for _, score := range []int{-1, 101} {
+		_, err = svc.UpdateTagScore(context.Background(), ent.ID, "go", score)
+		require.ErrorIs(t, err, domainTag.ErrInvalidTag)
+	}
+	_, err = svc.UpdateTagScore(context.Background(), ent.ID, " ", 10)
+	require.ErrorIs(t, err, domainTag.ErrInvalidTag)
+
+	linker.linked = false
+	_, err = svc.UpdateTagScore(context.Background(), ent.ID, "rust", 10)
+	require.ErrorIs(t, err, ErrTagNotAttached)
+}
+
+func TestVerifyDayCacheDetectsStaleCache(t *testing.T) {
+	kept := &domainEntry.Entry{ID: uuid.New(), Title: "kept"}
+	added := &domainEntry.Entry{ID: uuid.New(), Title: "added after caching"}
+	deleted := &domainEntry.Entry{ID: uuid.New(), Title: "deleted after caching"}
+	dayCache := newStubDayCache()
+	dayCache.store["20250105"] = []*domainEntry.Entry{kept, deleted}
+	repo := &stubEntryRepo{listResult: []*domainEntry.Entry{added, kept}}
+	svc := NewService(repo, dayCache, nil, nil)
+
+	report, err := svc.VerifyDayCache(context.Background(), "20250105")
+	require.NoError(t, err)
+	require.True(t, report.Cached)
+	require.True(t, report.Stale())
+	require.Equal(t, 2, report.CachedCount)
+	require.Equal(t, 2, report.DBCount)
+	require.Equal(t, []domainEntry.ID{added.ID}, report.Missing)
+	require.Equal(t, []domainEntry.ID{deleted.ID}, report.Extra)
+	require.Zero(t, dayCache.setCalls)
+
+	// A fresh cache with a different order is consistent.
+	dayCache.store["20250105"] = []*domainEntry.Entry{kept, added}
+	report, err = svc.VerifyDayCache(context.Background(), "20250105")
+	require.NoError(t, err)
+	require.False(t, report.Stale())
+}
+
+func TestVerifyDayCacheWithoutCachedDay(t *testing.T) {
+	dayCache := newStubDayCache()
+	repo := &stubEntryRepo{listResult: []*domainEntry.Entry{{ID: uuid.New()}}}
+	svc := NewService(repo, dayCache, nil, nil)
+
+	report, err := svc.VerifyDayCache(context.Background(), "20250105")
+	require.NoError(t, err)
+	require.False(t, report.Cached)
+	require.False(t, report.Stale())
+	require.Equal(t, 1, report.DBCount)
+	require.Empty(t, dayCache.store)
+
+	_, err = svc.VerifyDayCache(context.Background(), "2025-01-05")
+	require.Error(t, err)
+
+	_, err = NewService(repo, nil, nil, nil).VerifyDayCache(context.Background(), "20250105")
+	require.Error(t, err)
+}
+
+func TestListDayEntriesRelaxesMinUsers(t *testing.T) {
+	dayCache := newStubDayCache()
+	var all []*domainEntry.Entry
+	for _, count := range []int{600, 60, 8, 3} {
+		all = append(all, &domainEntry.Entry{ID: uuid.New(), BookmarkCount: count})
+	}
+	dayCache.store["20250105"] = all
+	svc := NewService(&stubEntryRepo{}, dayCache, nil, nil).WithMinResults(2)
+	params := DayListParams{Date: "20250105", MinBookmarkCount: 100, Limit: 25}
+
+	out, err := svc.ListHotEntries(context.Background(), params)
+	require.NoError(t, err)
+	require.False(t, out.Relaxed)
+	require.Equal(t, int64(1), out.Total)
+	require.Equal(t, 100, out.MinBookmarkCount)
+
+	params.Relax = true
+	out, err = svc.ListHotEntries(context.Background(), params)
+	require.NoError(t, err)
+	require.True(t, out.Relaxed)
+	require.Equal(t, 50, out.MinBookmarkCount)
+	require.Equal(t, int64(2), out.Total)
+	require.Equal(t, 600, out.Entries[0].BookmarkCount)
+
+	// Enough results: nothing is relaxed even when asked.
+	params.MinBookmarkCount = 5
+	out, err = svc.ListNewEntries(context.Background(), params)
+	require.NoError(t, err)
+	require.False(t, out.Relaxed)
+	require.Equal(t, int64(3), out.Total)
+
+	// The ladder ends at 0 when the floor is never reached.
+	svc.WithMinResults(10)
+	params.MinBookmarkCount = 100
+	out, err = svc.ListNewEntries(context.Background(), params)
+	require.NoError(t, err)
+	require.True(t, out.Relaxed)
+	require.Equal(t, 0, out.MinBookmarkCount)
+	require.Equal(t, int64(4), out.Total)
+}
@@ -0,0 +1,190 @@
+package entry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domainEntry "hateblog/internal/domain/entry"
+	domainTag "hateblog/internal/domain/tag"
+)
+
+const (
+	// DefaultManualTagScore is the score given to manually attached tags when none is specified.
+	DefaultManualTagScore = 100
+	// MaxManualTags is the default cap on the number of tags attached in one request.
+	MaxManualTags = 20
+)
+
+// ErrTagNotAttached signals that the entry does not carry the tag being removed.
+var ErrTagNotAttached = errors.New("tag is not attached to the entry")
+
+// TagLinker upserts tags and links them to entries for manual curation.
+type TagLinker interface {
+	Upsert(ctx context.Context, t *domainTag.Tag) error
+	AttachEntry(ctx context.Context, entryID domainEntry.ID, tagID domainTag.ID, score int) error
+	DetachEntry(ctx context.Context, entryID domainEntry.ID, tagName string) (bool, error)
+	UpdateEntryScore(ctx context.Context, entryID domainEntry.ID, tagName string, score int) (bool, error)
+}
+
+// WithTagLinker enables AddTags, RemoveTag and UpdateTagScore.
+func (s *Service) WithTagLinker(linker TagLinker) *Service {
+	s.tagLinker = linker
+	return s
+}
+
+// WithMaxTags overrides MaxManualTags as the cap on tags attached in one AddTags call
+// (0 means no limit).
+func (s *Service) WithMaxTags(n int) *Service {
+	s.maxTags = n
+	return s
+}
+
+// AddTags links the named tags to the entry, creating missing tags. Tags already on the entry
+// take the new score. A nil score means DefaultManualTagScore. The updated entry is returned.
+func (s *Service) AddTags(ctx context.Context, id domainEntry.ID, names []string, score *int) (*domainEntry.Entry, error) {
+	if s.tagLinker == nil {
+		return nil, fmt.Errorf("tag linker is not configured")
+	}
+	normalized, err := normalizeTagNames(names, s.maxTags)
+	if err != nil {
+		return nil, err
+	}
+	tagScore := DefaultManualTagScore
+	if score != nil {
+		tagScore = *score
+	}
+	if err := validateTagScore(tagScore); err != nil {
+		return nil, err
+	}
+
+	ent, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range normalized {
+		t := &domainTag.Tag{Name: name}
+		if err := s.tagLinker.Upsert(ctx, t); err != nil {
+			return nil, err
+		}
+		if err := s.tagLinker.AttachEntry(ctx, id, t.ID, tagScore); err != nil {
+			return nil, err
+		}
+	}
+	s.invalidateEntryCaches(ctx, ent, normalized)
+
+	return s.repo.Get(ctx, id)
+}
+
+// RemoveTag unlinks the named tag from the entry. The tag itself is kept.
+func (s *Service) RemoveTag(ctx context.Context, id domainEntry.ID, name string) error {
+	if s.tagLinker == nil {
+		return fmt.Errorf("tag linker is not configured")
+	}
+	norm := domainTag.NormalizeName(name)
+	if norm == "" {
+		return fmt.Errorf("%w: tag name is required", domainTag.ErrInvalidTag)
+	}
+
+	ent, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	removed, err := s.tagLinker.DetachEntry(ctx, id, norm)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrTagNotAttached
+	}
+	s.invalidateEntryCaches(ctx, ent, []string{norm})
+	return nil
+}
+
+// UpdateTagScore sets the score of a tag already on the entry and returns the updated entry.
+func (s *Service) UpdateTagScore(ctx context.Context, id domainEntry.ID, name string, score int) (*domainEntry.Entry, error) {
+	if s.tagLinker == nil {
+		return nil, fmt.Errorf("tag linker is not configured")
+	}
+	norm := domainTag.NormalizeName(name)
+	if norm == "" {
+		return nil, fmt.Errorf("%w: tag name is required", domainTag.ErrInvalidTag)
+	}
+	if err := validateTagScore(score); err != nil {
+		return nil, err
+	}
+
+	ent, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.tagLinker.UpdateEntryScore(ctx, id, norm, score)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrTagNotAttached
+	}
+	s.invalidateEntryCaches(ctx, ent, []string{norm})
+
+	return s.repo.Get(ctx, id)
+}
+
+// validateTagScore checks that a manual score is within the entry_tags.score range.
+func validateTagScore(score int) error {
+	if score < 0 || score > 100 {
+		return fmt.Errorf("%w: score must be between 0 and 100", domainTag.ErrInvalidTag)
+	}
+	return nil
+}
+
+// invalidateEntryCaches drops the cached day list holding the entry and the cached tag pages
+// of the given tags. Failures are logged only; the caches expire on their own.
+func (s *Service) invalidateEntryCaches(ctx context.Context, ent *domainEntry.Entry, tagNames []string) {
+	if s.dayCache != nil {
+		date := ent.PostedAt.In(time.Local).Format("20060102")
+		if err := s.dayCache.Delete(ctx, dayCacheKey(date, nil)); err != nil {
+			s.logDebug("day cache delete failed", err)
+		}
+	}
+	if s.tagEntries == nil {
+		return
+	}
+	for _, name := range tagNames {
+		for _, sortType := range []domainEntry.SortType{domainEntry.SortNew, domainEntry.SortHot} {
+			for _, minUsers := range tagEntriesSegments {
+				if err := s.tagEntries.Delete(ctx, name, sortType, minUsers); err != nil {
+					s.logDebug("tag entries cache delete failed", err)
+				}
+			}
+		}
+	}
+}
+
+// tagEntriesSegments lists the min_users values accepted by isSegmentedMinUsers.
+var tagEntriesSegments = []int{0, 5, 10, 50, 100, 500, 1000}
+
+// normalizeTagNames normalizes and de-duplicates names, keeping their order.
+func normalizeTagNames(names []string, maxTags int) ([]string, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: at least one tag is required", domainTag.ErrInvalidTag)
+	}
+	if maxTags > 0 && len(names) > maxTags {
+		return nil, fmt.Errorf("%w: at most %d tags can be added at once", domainTag.ErrInvalidTag, maxTags)
+	}
+	seen := make(map[string]struct{}, len(names))
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		norm := domainTag.NormalizeName(name)
+		if norm == "" {
+			return nil, fmt.Errorf("%w: tag name must not be empty", domainTag.ErrInvalidTag)
+		}
+		if _, ok := seen[norm]; ok {
+			continue
+		}
+		seen[norm] = struct{}{}
+		out = append(out, norm)
+	}
+	return out, nil
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"log/slog"
 
+	"hateblog/internal/pkg/cachefallback"
 	"hateblog/internal/pkg/hostname"
 )
 
@@ -58,16 +59,18 @@ func NewService(fetcher Fetcher, cache Cache, limiter Limiter, logger *slog.Logg
 }
 
 // Fetch returns a favicon for the given domain, using cache when possible.
+// The domain is reduced to its favicon host (see hostname.Favicon) before fetching.
 func (s *Service) Fetch(ctx context.Context, domain string) ([]byte, string, bool, error) {
 	if s.fetcher == nil {
 		return nil, "", false, ErrNotInitialized
 	}
 
-	var (
-		key string
-		err error
-	)
+	domain, err := hostname.Favicon(domain)
+	if err != nil {
+		return nil, "", false, err
+	}
 
+	var key string
 	if s.cache != nil {
 		key, err = s.cache.BuildKey(domain)
 		if err != nil {
@@ -85,12 +88,9 @@ func (s *Service) Fetch(ctx context.Context, domain string) ([]byte, string, boo
 		if data, contentType, ok, err := s.cache.Get(ctx, key); err == nil && ok {
 			return data, contentType, true, nil
 		} else if err != nil {
+			cachefallback.Record(err)
 			s.logDebug("favicon cache get failed", err)
 		}
-	} else {
-		if _, err := hostname.Normalize(domain); err != nil {
-			return nil, "", false, err
-		}
 	}
 
 	if s.limiter != nil {
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	domainEntry "hateblog/internal/domain/entry"
+	"hateblog/internal/pkg/apptime"
 )
 
 // EntryRepository provides entry metadata lookup.
@@ -18,10 +19,15 @@ type ClickRepository interface {
 	Increment(ctx context.Context, entryID domainEntry.ID, clickedAt time.Time) error
 }
 
-// Service records click metrics.
+// Service records click metrics and summarizes click and search activity.
 type Service struct {
 	entries EntryRepository
 	clicks  ClickRepository
+
+	clickStats   ClickStatsRepository
+	searchStats  SearchStatsRepository
+	summaryCache SummaryCache
+	now          func() time.Time
 }
 
 // NewService builds a metrics service.
@@ -29,6 +35,7 @@ func NewService(entries EntryRepository, clicks ClickRepository) *Service {
 	return &Service{
 		entries: entries,
 		clicks:  clicks,
+		now:     apptime.Now,
 	}
 }
 
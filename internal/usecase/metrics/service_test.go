@@ -7,8 +7,10 @@ import (
 	"time"
 
 	domainEntry "hateblog/internal/domain/entry"
+	"hateblog/internal/pkg/cachefallback"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -43,3 +45,89 @@ func TestRecordClickValidatesEntry(t *testing.T) {
 	err = svc.RecordClick(context.Background(), domainEntry.ID(uuid.New()))
 	require.Error(t, err)
 }
+
+type fakeStats struct {
+	sums  map[time.Time]int64
+	froms []time.Time
+	top   []domainEntry.ClickedEntry
+}
+
+func (f *fakeStats) SumSince(ctx context.Context, from time.Time) (int64, error) {
+	f.froms = append(f.froms, from)
+	return f.sums[from], nil
+}
+
+func (f *fakeStats) TopEntriesSince(ctx context.Context, from time.Time, limit int) ([]domainEntry.ClickedEntry, error) {
+	return f.top, nil
+}
+
+type memorySummaryCache struct {
+	value  *Summary
+	getErr error
+}
+
+func (c *memorySummaryCache) Get(ctx context.Context, out any) (bool, error) {
+	if c.getErr != nil {
+		return false, c.getErr
+	}
+	if c.value == nil {
+		return false, nil
+	}
+	*out.(*Summary) = *c.value
+	return true, nil
+}
+
+func (c *memorySummaryCache) Set(ctx context.Context, value any) error {
+	v := value.(Summary)
+	c.value = &v
+	return nil
+}
+
+func TestSummaryRollsUpTodayAndSevenDays(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.Local)
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+	weekStart := time.Date(2026, 3, 4, 0, 0, 0, 0, time.Local)
+
+	clicks := &fakeStats{
+		sums: map[time.Time]int64{today: 5, weekStart: 40},
+		top:  []domainEntry.ClickedEntry{{ID: domainEntry.ID(uuid.New()), ClickCount: 30}},
+	}
+	searches := &fakeStats{sums: map[time.Time]int64{today: 2, weekStart: 9}}
+	cache := &memorySummaryCache{}
+	svc := NewService(&fakeEntryStore{}, &fakeClickRepo{}).WithSummary(clicks, searches, cache)
+	svc.now = func() time.Time { return now }
+
+	got, hit, err := svc.Summary(context.Background())
+	require.NoError(t, err)
+	require.False(t, hit)
+	require.Equal(t, int64(5), got.ClicksToday)
+	require.Equal(t, int64(40), got.Clicks7Days)
+	require.Equal(t, int64(2), got.SearchesToday)
+	require.Equal(t, int64(9), got.Searches7Days)
+	require.Len(t, got.TopEntries, 1)
+	require.Equal(t, now, got.GeneratedAt)
+
+	got, hit, err = svc.Summary(context.Background())
+	require.NoError(t, err)
+	require.True(t, hit)
+	require.Equal(t, int64(40), got.Clicks7Days)
+	require.Len(t, clicks.froms, 2)
+}
+
+func TestSummaryFallsBackOnCacheError(t *testing.T) {
+	before := testutil.ToFloat64(cachefallback.Total.WithLabelValues(cachefallback.ReasonError))
+	cache := &memorySummaryCache{getErr: errors.New("redis: connection refused")}
+	svc := NewService(&fakeEntryStore{}, &fakeClickRepo{}).WithSummary(&fakeStats{}, &fakeStats{}, cache)
+
+	_, hit, err := svc.Summary(context.Background())
+	require.NoError(t, err)
+	require.False(t, hit)
+	require.Equal(t, before+1, testutil.ToFloat64(cachefallback.Total.WithLabelValues(cachefallback.ReasonError)))
+}
+
+func TestSummaryRequiresConfiguration(t *testing.T) {
+	svc := NewService(&fakeEntryStore{}, &fakeClickRepo{})
+	require.False(t, svc.SummaryEnabled())
+	_, _, err := svc.Summary(context.Background())
+	require.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainEntry "hateblog/internal/domain/entry"
+	"hateblog/internal/pkg/apptime"
+	"hateblog/internal/pkg/cachefallback"
+)
+
+const (
+	// summaryDays is the length of the longer summary window, including today.
+	summaryDays = 7
+	// summaryTopEntries is the number of most clicked entries in a summary.
+	summaryTopEntries = 10
+)
+
+// ClickStatsRepository aggregates stored click counts.
+type ClickStatsRepository interface {
+	SumSince(ctx context.Context, from time.Time) (int64, error)
+	TopEntriesSince(ctx context.Context, from time.Time, limit int) ([]domainEntry.ClickedEntry, error)
+}
+
+// SearchStatsRepository aggregates stored search counts.
+type SearchStatsRepository interface {
+	SumSince(ctx context.Context, from time.Time) (int64, error)
+}
+
+// SummaryCache stores the latest summary.
+type SummaryCache interface {
+	Get(ctx context.Context, out any) (bool, error)
+	Set(ctx context.Context, value any) error
+}
+
+// Summary is the rollup served to the admin dashboard. Days are counted in the application
+// timezone; the 7 day window includes today.
+type Summary struct {
+	ClicksToday   int64                      `json:"clicks_today"`
+	Clicks7Days   int64                      `json:"clicks_7d"`
+	TopEntries    []domainEntry.ClickedEntry `json:"top_entries"`
+	SearchesToday int64                      `json:"searches_today"`
+	Searches7Days int64                      `json:"searches_7d"`
+	GeneratedAt   time.Time                  `json:"generated_at"`
+}
+
+// WithSummary enables Summary using the given aggregate sources. cache may be nil.
+func (s *Service) WithSummary(clicks ClickStatsRepository, searches SearchStatsRepository, cache SummaryCache) *Service {
+	s.clickStats = clicks
+	s.searchStats = searches
+	s.summaryCache = cache
+	return s
+}
+
+// SummaryEnabled reports whether WithSummary has been configured.
+func (s *Service) SummaryEnabled() bool {
+	return s != nil && s.clickStats != nil && s.searchStats != nil
+}
+
+// Summary returns click and search totals for today and the last 7 days along with the most
+// clicked entries of the last 7 days. The result is served from the cache when present.
+func (s *Service) Summary(ctx context.Context) (Summary, bool, error) {
+	if !s.SummaryEnabled() {
+		return Summary{}, false, fmt.Errorf("metrics summary not configured")
+	}
+	if s.summaryCache != nil {
+		var cached Summary
+		ok, err := s.summaryCache.Get(ctx, &cached)
+		if err != nil {
+			cachefallback.Record(err)
+		} else if ok {
+			return cached, true, nil
+		}
+	}
+
+	now := s.now()
+	today := apptime.TruncateToDay(now)
+	weekStart := today.AddDate(0, 0, -(summaryDays - 1))
+
+	var (
+		out Summary
+		err error
+	)
+	if out.ClicksToday, err = s.clickStats.SumSince(ctx, today); err != nil {
+		return Summary{}, false, err
+	}
+	if out.Clicks7Days, err = s.clickStats.SumSince(ctx, weekStart); err != nil {
+		return Summary{}, false, err
+	}
+	if out.TopEntries, err = s.clickStats.TopEntriesSince(ctx, weekStart, summaryTopEntries); err != nil {
+		return Summary{}, false, err
+	}
+	if out.SearchesToday, err = s.searchStats.SumSince(ctx, today); err != nil {
+		return Summary{}, false, err
+	}
+	if out.Searches7Days, err = s.searchStats.SumSince(ctx, weekStart); err != nil {
+		return Summary{}, false, err
+	}
+	if out.TopEntries == nil {
+		out.TopEntries = []domainEntry.ClickedEntry{}
+	}
+	out.GeneratedAt = now
+
+	if s.summaryCache != nil {
+		_ = s.summaryCache.Set(ctx, out)
+	}
+	return out, false, nil
+}
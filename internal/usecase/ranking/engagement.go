@@ -0,0 +1,188 @@
+package ranking
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	domainEntry "hateblog/internal/domain/entry"
+	"hateblog/internal/pkg/apptime"
+	"hateblog/internal/pkg/cachefallback"
+)
+
+// engagementCandidates is the number of top bookmarked entries of a period that are re-ranked
+// by engagement. Entries below it are not ranked.
+const engagementCandidates = domainEntry.MaxLimit
+
+// PeriodKind names the span of a ranking.
+type PeriodKind string
+
+const (
+	// PeriodYearly is a calendar year.
+	PeriodYearly PeriodKind = "yearly"
+	// PeriodMonthly is a calendar month.
+	PeriodMonthly PeriodKind = "monthly"
+	// PeriodWeekly is an ISO week.
+	PeriodWeekly PeriodKind = "weekly"
+)
+
+// Period identifies a ranking span. Month is used by PeriodMonthly and Week by PeriodWeekly.
+type Period struct {
+	Kind  PeriodKind
+	Year  int
+	Month int
+	Week  int
+}
+
+// Range returns the half-open time range of the period in the application timezone.
+func (p Period) Range() (time.Time, time.Time, error) {
+	switch p.Kind {
+	case PeriodYearly:
+		return apptime.YearRange(p.Year)
+	case PeriodMonthly:
+		return apptime.MonthRange(p.Year, p.Month)
+	case PeriodWeekly:
+		return apptime.ISOWeekRange(p.Year, p.Week)
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown ranking period: %q", p.Kind)
+	}
+}
+
+// Key identifies the period in cache keys, e.g. "yearly:2025" or "weekly:2025:3".
+func (p Period) Key() string {
+	switch p.Kind {
+	case PeriodMonthly:
+		return fmt.Sprintf("%s:%d:%d", p.Kind, p.Year, p.Month)
+	case PeriodWeekly:
+		return fmt.Sprintf("%s:%d:%d", p.Kind, p.Year, p.Week)
+	default:
+		return fmt.Sprintf("%s:%d", p.Kind, p.Year)
+	}
+}
+
+// ClickCounter returns the stored click totals of entries. Entries without clicks may be absent.
+type ClickCounter interface {
+	CountByEntries(ctx context.Context, ids []domainEntry.ID) (map[domainEntry.ID]int64, error)
+}
+
+// CacheEngagement stores engagement ranking payloads per Period.Key and min_users.
+type CacheEngagement interface {
+	Get(ctx context.Context, period string, minUsers int, out any) (bool, error)
+	Set(ctx context.Context, period string, minUsers int, value any) error
+}
+
+// Weights blends bookmark and click counts into an engagement score.
+type Weights struct {
+	Bookmarks float64
+	Clicks    float64
+}
+
+// score returns the engagement score of an entry.
+func (w Weights) score(bookmarks int, clicks int64) float64 {
+	return w.Bookmarks*float64(bookmarks) + w.Clicks*float64(clicks)
+}
+
+// WithEngagement enables engagement rankings, which blend bookmark counts with the entries'
+// click counts using weights. cache may be nil.
+func (s *Service) WithEngagement(clicks ClickCounter, weights Weights, cache CacheEngagement) *Service {
+	s.clicks = clicks
+	s.weights = weights
+	s.engagementCache = cache
+	return s
+}
+
+// EngagementEnabled reports whether WithEngagement has been configured.
+func (s *Service) EngagementEnabled() bool {
+	return s != nil && s.clicks != nil
+}
+
+// EngagementWithCacheStatus returns the period's entries ordered by engagement score and cache
+// hit info. The top bookmarked entries of the period (up to engagementCandidates) are scored
+// with their all-time click counts; ties keep the bookmark order. Result.ClickCounts holds the
+// click count of every returned entry.
+func (s *Service) EngagementWithCacheStatus(ctx context.Context, period Period, limit, offset, minUsers int) (Result, bool, error) {
+	if !s.EngagementEnabled() {
+		return Result{}, false, fmt.Errorf("engagement ranking not configured")
+	}
+	if limit <= 0 {
+		limit = domainEntry.DefaultLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	minUsers = domainEntry.RankingMinBookmarks(minUsers, s.minUsersFloor)
+	const max = 100
+	if limit > max {
+		limit = max
+	}
+	from, to, err := period.Range()
+	if err != nil {
+		return Result{}, false, err
+	}
+	useCache := limit == max && offset == 0 && s.engagementCache != nil
+	if useCache {
+		var cached rankingCachePayload
+		ok, err := s.engagementCache.Get(ctx, period.Key(), minUsers, &cached)
+		if err != nil {
+			cachefallback.Record(err)
+		} else if ok {
+			return Result{
+				Entries:     sliceWithOffsetAndLimit(cached.Entries, offset, limit),
+				Total:       cached.Total,
+				ClickCounts: cached.ClickCounts,
+			}, true, nil
+		}
+	}
+
+	candidates, err := s.repo.List(ctx, domainEntry.ListQuery{
+		Sort:             domainEntry.SortHot,
+		Limit:            engagementCandidates,
+		MaxLimitOverride: engagementCandidates,
+		PostedAtFrom:     from,
+		PostedAtTo:       to,
+		MinBookmarkCount: minUsers,
+	})
+	if err != nil {
+		return Result{}, false, err
+	}
+	ids := make([]domainEntry.ID, 0, len(candidates))
+	for _, ent := range candidates {
+		ids = append(ids, ent.ID)
+	}
+	var clicks map[domainEntry.ID]int64
+	if len(ids) > 0 {
+		if clicks, err = s.clicks.CountByEntries(ctx, ids); err != nil {
+			return Result{}, false, err
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		return s.weights.score(a.BookmarkCount, clicks[a.ID]) > s.weights.score(b.BookmarkCount, clicks[b.ID])
+	})
+
+	total := int64(len(candidates))
+	if useCache {
+		top := sliceWithOffsetAndLimit(candidates, 0, max)
+		_ = s.engagementCache.Set(ctx, period.Key(), minUsers, rankingCachePayload{
+			Entries:     top,
+			Total:       total,
+			ClickCounts: clickCountsOf(top, clicks),
+		})
+	}
+	entries := sliceWithOffsetAndLimit(candidates, offset, limit)
+	return Result{
+		Entries:     entries,
+		Total:       total,
+		ClickCounts: clickCountsOf(entries, clicks),
+	}, false, nil
+}
+
+// clickCountsOf returns the click counts of entries, including zeros.
+func clickCountsOf(entries []*domainEntry.Entry, clicks map[domainEntry.ID]int64) map[domainEntry.ID]int64 {
+	out := make(map[domainEntry.ID]int64, len(entries))
+	for _, ent := range entries {
+		out[ent.ID] = clicks[ent.ID]
+	}
+	return out
+}
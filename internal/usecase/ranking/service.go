@@ -6,6 +6,7 @@ import (
 
 	domainEntry "hateblog/internal/domain/entry"
 	"hateblog/internal/pkg/apptime"
+	"hateblog/internal/pkg/cachefallback"
 )
 
 // Repository describes entry operations required for ranking computations.
@@ -20,12 +21,20 @@ type Service struct {
 	yearlyCache  CacheYearly
 	monthlyCache CacheMonthly
 	weeklyCache  CacheWeekly
+	// minUsersFloor is the lowest min_users rankings filter on.
+	minUsersFloor int
+
+	clicks          ClickCounter
+	weights         Weights
+	engagementCache CacheEngagement
 }
 
 // Result bundles ranking entries and totals.
 type Result struct {
 	Entries []*domainEntry.Entry
 	Total   int64
+	// ClickCounts holds the click counts of Entries in engagement rankings; nil otherwise.
+	ClickCounts map[domainEntry.ID]int64
 }
 
 // NewService creates a ranking service.
@@ -38,6 +47,16 @@ func NewService(repo Repository, yearly CacheYearly, monthly CacheMonthly, weekl
 	}
 }
 
+// WithMinUsersFloor raises min_users below floor to floor in every ranking, so rankings
+// never list entries with fewer bookmarks. Zero disables the floor.
+func (s *Service) WithMinUsersFloor(floor int) *Service {
+	if floor < 0 {
+		floor = 0
+	}
+	s.minUsersFloor = floor
+	return s
+}
+
 // Yearly returns ranking entries for the given year.
 func (s *Service) Yearly(ctx context.Context, year, limit, offset, minUsers int) (Result, error) {
 	result, _, err := s.YearlyWithCacheStatus(ctx, year, limit, offset, minUsers)
@@ -52,18 +71,15 @@ func (s *Service) YearlyWithCacheStatus(ctx context.Context, year, limit, offset
 	if offset < 0 {
 		offset = 0
 	}
-	if minUsers < 0 {
-		minUsers = 0
-	}
+	minUsers = domainEntry.RankingMinBookmarks(minUsers, s.minUsersFloor)
 	const max = 100
 	useCache := limit == max && offset == 0 && s.yearlyCache != nil
 	if useCache {
 		var cached rankingCachePayload
 		ok, err := s.yearlyCache.Get(ctx, year, minUsers, &cached)
 		if err != nil {
-			return Result{}, false, err
-		}
-		if ok {
+			cachefallback.Record(err)
+		} else if ok {
 			return Result{
 				Entries: sliceWithOffsetAndLimit(cached.Entries, offset, limit),
 				Total:   cached.Total,
@@ -101,18 +117,15 @@ func (s *Service) MonthlyWithCacheStatus(ctx context.Context, year, month, limit
 	if offset < 0 {
 		offset = 0
 	}
-	if minUsers < 0 {
-		minUsers = 0
-	}
+	minUsers = domainEntry.RankingMinBookmarks(minUsers, s.minUsersFloor)
 	const max = 100
 	useCache := limit == max && offset == 0 && s.monthlyCache != nil
 	if useCache {
 		var cached rankingCachePayload
 		ok, err := s.monthlyCache.Get(ctx, year, month, minUsers, &cached)
 		if err != nil {
-			return Result{}, false, err
-		}
-		if ok {
+			cachefallback.Record(err)
+		} else if ok {
 			return Result{
 				Entries: sliceWithOffsetAndLimit(cached.Entries, offset, limit),
 				Total:   cached.Total,
@@ -150,18 +163,15 @@ func (s *Service) WeeklyWithCacheStatus(ctx context.Context, year, week, limit,
 	if offset < 0 {
 		offset = 0
 	}
-	if minUsers < 0 {
-		minUsers = 0
-	}
+	minUsers = domainEntry.RankingMinBookmarks(minUsers, s.minUsersFloor)
 	const max = 100
 	useCache := limit == max && offset == 0 && s.weeklyCache != nil
 	if useCache {
 		var cached rankingCachePayload
 		ok, err := s.weeklyCache.Get(ctx, year, week, minUsers, &cached)
 		if err != nil {
-			return Result{}, false, err
-		}
-		if ok {
+			cachefallback.Record(err)
+		} else if ok {
 			return Result{
 				Entries: sliceWithOffsetAndLimit(cached.Entries, offset, limit),
 				Total:   cached.Total,
@@ -204,8 +214,9 @@ type CacheWeekly interface {
 }
 
 type rankingCachePayload struct {
-	Entries []*domainEntry.Entry `json:"entries"`
-	Total   int64                `json:"total"`
+	Entries     []*domainEntry.Entry     `json:"entries"`
+	Total       int64                    `json:"total"`
+	ClickCounts map[domainEntry.ID]int64 `json:"click_counts,omitempty"`
 }
 
 func (s *Service) listEntriesAndCount(ctx context.Context, from, to time.Time, offset, limit, maxLimit, minUsers int) ([]*domainEntry.Entry, int64, error) {
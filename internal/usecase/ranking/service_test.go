@@ -2,12 +2,17 @@ package ranking
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	domainEntry "hateblog/internal/domain/entry"
+	"hateblog/internal/pkg/cachefallback"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -44,6 +49,114 @@ func TestYearlyRankingClampsLimit(t *testing.T) {
 	require.False(t, repo.lastQuery.PostedAtTo.IsZero())
 }
 
+func TestRankingRaisesMinUsersToFloor(t *testing.T) {
+	repo := &stubEntryRepo{}
+	svc := NewService(repo, nil, nil, nil).WithMinUsersFloor(3)
+	ctx := context.Background()
+
+	_, err := svc.Yearly(ctx, 2024, 10, 0, 1)
+	require.NoError(t, err)
+	require.Equal(t, 3, repo.lastQuery.MinBookmarkCount)
+
+	_, err = svc.Monthly(ctx, 2024, 5, 10, 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, 3, repo.lastQuery.MinBookmarkCount)
+
+	_, err = svc.Weekly(ctx, 2024, 10, 10, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 3, repo.lastQuery.MinBookmarkCount)
+
+	// Requests above the floor are kept.
+	_, err = svc.Weekly(ctx, 2024, 10, 10, 0, 50)
+	require.NoError(t, err)
+	require.Equal(t, 50, repo.lastQuery.MinBookmarkCount)
+}
+
+func TestEngagementRankingRaisesMinUsersToFloor(t *testing.T) {
+	repo := &profileRepo{entries: []*domainEntry.Entry{{ID: uuid.New(), BookmarkCount: 10}}}
+	cache := memoryEngagementCache{}
+	svc := NewService(repo, nil, nil, nil).
+		WithMinUsersFloor(3).
+		WithEngagement(stubClickCounter{}, Weights{Bookmarks: 1}, cache)
+	period := Period{Kind: PeriodYearly, Year: 2024}
+
+	_, _, err := svc.EngagementWithCacheStatus(context.Background(), period, 100, 0, 1)
+	require.NoError(t, err)
+	require.Equal(t, 3, repo.lastQuery.MinBookmarkCount)
+	// The cache is keyed by the raised value, so requests below the floor share it.
+	require.Contains(t, cache, period.Key()+":3")
+
+	_, hit, err := svc.EngagementWithCacheStatus(context.Background(), period, 100, 0, 0)
+	require.NoError(t, err)
+	require.True(t, hit)
+}
+
+type failingYearlyCache struct{ err error }
+
+func (c failingYearlyCache) Get(ctx context.Context, year, minUsers int, out any) (bool, error) {
+	return false, c.err
+}
+
+func (c failingYearlyCache) Set(ctx context.Context, year, minUsers int, value any) error {
+	return c.err
+}
+
+type failingMonthlyCache struct{ err error }
+
+func (c failingMonthlyCache) Get(ctx context.Context, year, month, minUsers int, out any) (bool, error) {
+	return false, c.err
+}
+
+func (c failingMonthlyCache) Set(ctx context.Context, year, month, minUsers int, value any) error {
+	return c.err
+}
+
+type failingWeeklyCache struct{ err error }
+
+func (c failingWeeklyCache) Get(ctx context.Context, year, week, minUsers int, out any) (bool, error) {
+	return false, c.err
+}
+
+func (c failingWeeklyCache) Set(ctx context.Context, year, week, minUsers int, value any) error {
+	return c.err
+}
+
+type failingEngagementCache struct{ err error }
+
+func (c failingEngagementCache) Get(ctx context.Context, period string, minUsers int, out any) (bool, error) {
+	return false, c.err
+}
+
+func (c failingEngagementCache) Set(ctx context.Context, period string, minUsers int, value any) error {
+	return c.err
+}
+
+func TestRankingFallsBackOnCacheError(t *testing.T) {
+	errCache := errors.New("redis: connection refused")
+	repo := &stubEntryRepo{}
+	svc := NewService(repo, failingYearlyCache{err: errCache}, failingMonthlyCache{err: errCache}, failingWeeklyCache{err: errCache}).
+		WithEngagement(stubClickCounter{}, Weights{Bookmarks: 1}, failingEngagementCache{err: errCache})
+	ctx := context.Background()
+	counted := func() float64 {
+		return testutil.ToFloat64(cachefallback.Total.WithLabelValues(cachefallback.ReasonError))
+	}
+
+	before := counted()
+	_, hit, err := svc.YearlyWithCacheStatus(ctx, 2024, 100, 0, 5)
+	require.NoError(t, err)
+	require.False(t, hit)
+	_, hit, err = svc.MonthlyWithCacheStatus(ctx, 2024, 5, 100, 0, 5)
+	require.NoError(t, err)
+	require.False(t, hit)
+	_, hit, err = svc.WeeklyWithCacheStatus(ctx, 2024, 10, 100, 0, 5)
+	require.NoError(t, err)
+	require.False(t, hit)
+	_, hit, err = svc.EngagementWithCacheStatus(ctx, Period{Kind: PeriodYearly, Year: 2024}, 100, 0, 5)
+	require.NoError(t, err)
+	require.False(t, hit)
+	require.Equal(t, before+4, counted())
+}
+
 func TestWeeklyRankingRejectsInvalidWeek(t *testing.T) {
 	repo := &stubEntryRepo{}
 	svc := NewService(repo, nil, nil, nil)
@@ -51,3 +164,93 @@ func TestWeeklyRankingRejectsInvalidWeek(t *testing.T) {
 	_, err := svc.Weekly(context.Background(), 2024, 54, 10, 0, 0)
 	require.Error(t, err)
 }
+
+// profileRepo returns fixed entries in bookmark order.
+type profileRepo struct {
+	entries   []*domainEntry.Entry
+	lastQuery domainEntry.ListQuery
+}
+
+func (r *profileRepo) List(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
+	r.lastQuery = query
+	return append([]*domainEntry.Entry(nil), r.entries...), nil
+}
+
+func (r *profileRepo) Count(ctx context.Context, query domainEntry.ListQuery) (int64, error) {
+	return int64(len(r.entries)), nil
+}
+
+type stubClickCounter map[domainEntry.ID]int64
+
+func (c stubClickCounter) CountByEntries(ctx context.Context, ids []domainEntry.ID) (map[domainEntry.ID]int64, error) {
+	return c, nil
+}
+
+type memoryEngagementCache map[string][]byte
+
+func (c memoryEngagementCache) Get(ctx context.Context, period string, minUsers int, out any) (bool, error) {
+	raw, ok := c[fmt.Sprintf("%s:%d", period, minUsers)]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(raw, out)
+}
+
+func (c memoryEngagementCache) Set(ctx context.Context, period string, minUsers int, value any) error {
+	raw, err := json.Marshal(value)
+	c[fmt.Sprintf("%s:%d", period, minUsers)] = raw
+	return err
+}
+
+func TestEngagementRankingBlendsClicks(t *testing.T) {
+	bookmarked := &domainEntry.Entry{ID: uuid.New(), Title: "bookmarked", BookmarkCount: 100}
+	clicked := &domainEntry.Entry{ID: uuid.New(), Title: "clicked", BookmarkCount: 50}
+	middle := &domainEntry.Entry{ID: uuid.New(), Title: "middle", BookmarkCount: 80}
+	repo := &profileRepo{entries: []*domainEntry.Entry{bookmarked, middle, clicked}}
+	clicks := stubClickCounter{clicked.ID: 10, middle.ID: 1}
+	cache := memoryEngagementCache{}
+	svc := NewService(repo, nil, nil, nil).WithEngagement(clicks, Weights{Bookmarks: 1, Clicks: 10}, cache)
+	period := Period{Kind: PeriodMonthly, Year: 2025, Month: 3}
+
+	result, hit, err := svc.EngagementWithCacheStatus(context.Background(), period, 100, 0, 5)
+	require.NoError(t, err)
+	require.False(t, hit)
+	// clicked: 50+100, bookmarked: 100+0, middle: 80+10.
+	require.Equal(t, []*domainEntry.Entry{clicked, bookmarked, middle}, result.Entries)
+	require.Equal(t, int64(3), result.Total)
+	require.Equal(t, map[domainEntry.ID]int64{clicked.ID: 10, bookmarked.ID: 0, middle.ID: 1}, result.ClickCounts)
+	require.Equal(t, domainEntry.SortHot, repo.lastQuery.Sort)
+	require.Equal(t, 5, repo.lastQuery.MinBookmarkCount)
+	require.Contains(t, cache, "monthly:2025:3:5")
+
+	result, hit, err = svc.EngagementWithCacheStatus(context.Background(), period, 100, 0, 5)
+	require.NoError(t, err)
+	require.True(t, hit)
+	require.Len(t, result.Entries, 3)
+	require.Equal(t, clicked.ID, result.Entries[0].ID)
+	require.Equal(t, int64(10), result.ClickCounts[clicked.ID])
+
+	// Bookmarks only: the click profile no longer matters.
+	svc = NewService(repo, nil, nil, nil).WithEngagement(clicks, Weights{Bookmarks: 1}, nil)
+	result, _, err = svc.EngagementWithCacheStatus(context.Background(), period, 2, 1, 5)
+	require.NoError(t, err)
+	require.Equal(t, []*domainEntry.Entry{middle, clicked}, result.Entries)
+}
+
+func TestEngagementRankingRequiresConfiguration(t *testing.T) {
+	svc := NewService(&stubEntryRepo{}, nil, nil, nil)
+	require.False(t, svc.EngagementEnabled())
+
+	_, _, err := svc.EngagementWithCacheStatus(context.Background(), Period{Kind: PeriodYearly, Year: 2024}, 10, 0, 0)
+	require.Error(t, err)
+
+	svc.WithEngagement(stubClickCounter{}, Weights{Bookmarks: 1}, nil)
+	_, _, err = svc.EngagementWithCacheStatus(context.Background(), Period{Kind: PeriodWeekly, Year: 2024, Week: 54}, 10, 0, 0)
+	require.Error(t, err)
+}
+
+func TestPeriodKey(t *testing.T) {
+	require.Equal(t, "yearly:2025", Period{Kind: PeriodYearly, Year: 2025}.Key())
+	require.Equal(t, "monthly:2025:1", Period{Kind: PeriodMonthly, Year: 2025, Month: 1}.Key())
+	require.Equal(t, "weekly:2025:1", Period{Kind: PeriodWeekly, Year: 2025, Week: 1}.Key())
+}
@@ -0,0 +1,80 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hateblog/internal/domain/repository"
+)
+
+const (
+	// DefaultHistoryLimit is used when ListHistory is called without a positive limit.
+	DefaultHistoryLimit = 50
+	// MaxHistoryLimit caps the number of queries ListHistory returns.
+	MaxHistoryLimit = 1000
+
+	// DefaultTopDays and DefaultTopLimit are used when TopQueries is called without positive values.
+	DefaultTopDays  = 7
+	DefaultTopLimit = 20
+	// MaxTopDays and MaxTopLimit cap the window and the number of queries of TopQueries.
+	MaxTopDays  = 365
+	MaxTopLimit = 100
+)
+
+// HistoryLister aggregates recorded searches per query.
+type HistoryLister interface {
+	List(ctx context.Context, from, to time.Time, limit int) ([]repository.SearchQueryCount, error)
+	Top(ctx context.Context, days int, limit int) ([]repository.SearchQueryCount, error)
+}
+
+// WithHistoryList enables ListHistory and TopQueries.
+func (s *Service) WithHistoryList(lister HistoryLister) *Service {
+	s.historyList = lister
+	return s
+}
+
+// HistoryListEnabled reports whether WithHistoryList has been configured.
+func (s *Service) HistoryListEnabled() bool {
+	return s != nil && s.historyList != nil
+}
+
+// ListHistory returns the queries searched on days in [from, to), most recently searched first.
+// A non-positive limit means DefaultHistoryLimit; larger limits are capped at MaxHistoryLimit.
+func (s *Service) ListHistory(ctx context.Context, from, to time.Time, limit int) ([]repository.SearchQueryCount, error) {
+	if !s.HistoryListEnabled() {
+		return nil, fmt.Errorf("search history listing not configured")
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	if limit > MaxHistoryLimit {
+		limit = MaxHistoryLimit
+	}
+	return s.historyList.List(ctx, from, to, limit)
+}
+
+// TopQueries returns the most searched queries of the last days days, today included.
+// Non-positive values mean DefaultTopDays and DefaultTopLimit; larger values are capped at
+// MaxTopDays and MaxTopLimit.
+func (s *Service) TopQueries(ctx context.Context, days, limit int) ([]repository.SearchQueryCount, error) {
+	if !s.HistoryListEnabled() {
+		return nil, fmt.Errorf("search history listing not configured")
+	}
+	if days <= 0 {
+		days = DefaultTopDays
+	}
+	if days > MaxTopDays {
+		days = MaxTopDays
+	}
+	if limit <= 0 {
+		limit = DefaultTopLimit
+	}
+	if limit > MaxTopLimit {
+		limit = MaxTopLimit
+	}
+	return s.historyList.Top(ctx, days, limit)
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// hashedQueryPrefix starts the history keys of hashed queries.
+const hashedQueryPrefix = "sha256:"
+
+// HistoryPrivacy controls what searches leave in the search history.
+type HistoryPrivacy struct {
+	// Hash records a salted hash of each query with its length and term count instead of the
+	// query text. Equal queries still aggregate, so counts and rankings keep working.
+	Hash bool
+	// Salt keys the hash. Changing it starts new aggregates for every query.
+	Salt string
+	// MaxLength skips recording queries longer than this many characters (0 disables).
+	MaxLength int
+}
+
+// WithHistoryPrivacy applies p to the queries recorded by SearchWithCacheStatus.
+func (s *Service) WithHistoryPrivacy(p HistoryPrivacy) *Service {
+	s.privacy = p
+	return s
+}
+
+// historyKey returns what to record for query, or false when it must not be recorded.
+func (p HistoryPrivacy) historyKey(query string) (string, bool) {
+	norm := strings.ToLower(strings.TrimSpace(query))
+	length := utf8.RuneCountInString(norm)
+	if norm == "" || (p.MaxLength > 0 && length > p.MaxLength) {
+		return "", false
+	}
+	if !p.Hash {
+		return norm, true
+	}
+	mac := hmac.New(sha256.New, []byte(p.Salt))
+	mac.Write([]byte(norm))
+	terms := len(strings.FieldsFunc(norm, unicode.IsSpace))
+	// 16 bytes keep collisions out of reach for any realistic history.
+	return fmt.Sprintf("%s%s len=%d terms=%d", hashedQueryPrefix, hex.EncodeToString(mac.Sum(nil)[:16]), length, terms), true
+}
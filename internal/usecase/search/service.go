@@ -2,14 +2,31 @@ package search
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	domainArchive "hateblog/internal/domain/archive"
 	domainEntry "hateblog/internal/domain/entry"
+	"hateblog/internal/pkg/cachefallback"
 )
 
+// ErrNoSearchTerms signals a query without any term to match, such as one made only of
+// punctuation. Searching for it would match (or, for LIKE wildcards, list) arbitrary entries.
+var ErrNoSearchTerms = errors.New("q must contain at least one letter or digit")
+
+// ErrSearchTermsTooShort signals a query whose terms are all English terms shorter than the
+// minimum term length.
+var ErrSearchTermsTooShort = errors.New("q must contain a term that is not too short")
+
+// ErrTooManySearchTerms signals a query with more terms than the maximum. Each term adds to
+// the SQL conditions and the English word regex.
+var ErrTooManySearchTerms = errors.New("q has too many terms")
+
 // EntryRepository defines entry access required for search.
 type EntryRepository interface {
 	List(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error)
@@ -27,6 +44,12 @@ type Params struct {
 	Limit            int
 	Offset           int
 	Sort             domainEntry.SortType
+	// MatchTags also matches entries whose tag names contain the query terms.
+	MatchTags bool
+	// EnglishSubstring matches English terms as substrings instead of whole words.
+	EnglishSubstring bool
+	// Relax lowers MinBookmarkCount when fewer entries than the minimum result count match.
+	Relax bool
 }
 
 // Result bundles search results.
@@ -36,14 +59,24 @@ type Result struct {
 	Total   int64
 	Limit   int
 	Offset  int
+	// MinBookmarkCount is the min_users the results were searched with. It is lower than
+	// requested when Relaxed is set.
+	MinBookmarkCount int
+	Relaxed          bool
 }
 
 // Service performs search operations.
 type Service struct {
-	entries EntryRepository
-	history HistoryRepository
-	cache   ResultCache
-	logger  *slog.Logger
+	entries     EntryRepository
+	history     HistoryRepository
+	historyList HistoryLister
+	privacy     HistoryPrivacy
+	cache       ResultCache
+	logger      *slog.Logger
+	minResults  int
+	minTermLen  int
+	maxTerms    int
+	truncate    bool
 }
 
 // ResultCache caches search results.
@@ -68,8 +101,63 @@ func (s *Service) Search(ctx context.Context, query string, params Params) (Resu
 	return result, err
 }
 
-// SearchWithCacheStatus executes a keyword search and returns cache hit info.
+// WithMinResults sets the total below which searches requested with Relax lower their min_users
+// through the archive thresholds (0 disables relaxing).
+func (s *Service) WithMinResults(n int) *Service {
+	s.minResults = n
+	return s
+}
+
+// WithMinTermLength drops English (ASCII-only) query terms shorter than n characters, which
+// match almost every entry. CJK terms are kept whatever their length. n <= 1 keeps all terms.
+func (s *Service) WithMinTermLength(n int) *Service {
+	s.minTermLen = n
+	return s
+}
+
+// WithMaxTerms caps the number of query terms at n (0 disables the cap), counted after short
+// terms are dropped. Longer queries fail with ErrTooManySearchTerms or, with truncate, are
+// searched for their first n terms.
+func (s *Service) WithMaxTerms(n int, truncate bool) *Service {
+	s.maxTerms = n
+	s.truncate = truncate
+	return s
+}
+
+// SearchWithCacheStatus executes a keyword search and returns cache hit info. With
+// params.Relax, min_users is lowered step by step while fewer than the minimum result count
+// match; each step is a separate (cacheable) search.
 func (s *Service) SearchWithCacheStatus(ctx context.Context, query string, params Params) (Result, bool, error) {
+	result, cacheHit, err := s.search(ctx, query, params)
+	if err != nil {
+		return Result{}, false, err
+	}
+	if params.Relax && s.minResults > 0 {
+		for _, lower := range domainArchive.LowerMinUsers(result.MinBookmarkCount) {
+			if result.Total >= int64(s.minResults) {
+				break
+			}
+			relaxedParams := params
+			relaxedParams.MinBookmarkCount = lower
+			if result, cacheHit, err = s.search(ctx, query, relaxedParams); err != nil {
+				return Result{}, false, err
+			}
+			result.Relaxed = true
+		}
+	}
+
+	if s.history != nil {
+		if key, ok := s.privacy.historyKey(result.Query); ok {
+			if err := s.history.Record(ctx, key, time.Now()); err != nil {
+				s.logDebug("failed to record search history", err)
+			}
+		}
+	}
+	return result, cacheHit, nil
+}
+
+// search runs one search with the given filters.
+func (s *Service) search(ctx context.Context, query string, params Params) (Result, bool, error) {
 	norm := strings.TrimSpace(query)
 	if norm == "" {
 		return Result{}, false, fmt.Errorf("q is required")
@@ -77,6 +165,21 @@ func (s *Service) SearchWithCacheStatus(ctx context.Context, query string, param
 	if len(norm) > 500 {
 		return Result{}, false, fmt.Errorf("q must be <= 500 characters")
 	}
+	if !hasSearchTerm(norm) {
+		return Result{}, false, ErrNoSearchTerms
+	}
+	keyword := s.dropShortTerms(norm)
+	if !hasSearchTerm(keyword) {
+		return Result{}, false, fmt.Errorf("%w (English terms need at least %d characters)", ErrSearchTermsTooShort, s.minTermLen)
+	}
+	if s.maxTerms > 0 {
+		if terms := strings.Fields(keyword); len(terms) > s.maxTerms {
+			if !s.truncate {
+				return Result{}, false, fmt.Errorf("%w (%d, at most %d)", ErrTooManySearchTerms, len(terms), s.maxTerms)
+			}
+			keyword = strings.Join(terms[:s.maxTerms], " ")
+		}
+	}
 	limit := params.Limit
 	if limit <= 0 {
 		limit = domainEntry.DefaultLimit
@@ -104,28 +207,31 @@ func (s *Service) SearchWithCacheStatus(ctx context.Context, query string, param
 		return Result{}, false, fmt.Errorf("sort must be new or hot")
 	}
 
-	useCache := limit == maxLimit && offset == 0 && s.cache != nil
+	// Cache keys do not carry MatchTags or EnglishSubstring, so those searches always hit the
+	// database.
+	useCache := limit == maxLimit && offset == 0 && s.cache != nil && !params.MatchTags && !params.EnglishSubstring
 	if useCache {
 		var cached Result
-		ok, err := s.cache.Get(ctx, norm, sortType, minUsers, limit, offset, &cached)
+		ok, err := s.cache.Get(ctx, keyword, sortType, minUsers, limit, offset, &cached)
 		if err != nil {
+			cachefallback.Record(err)
 			s.logDebug("failed to get search cache", err)
 		} else if ok {
-			if s.history != nil {
-				if err := s.history.Record(ctx, norm, time.Now()); err != nil {
-					s.logDebug("failed to record search history", err)
-				}
-			}
+			// Queries differing only in dropped terms share the entry.
+			cached.Query = norm
+			cached.MinBookmarkCount = minUsers
 			return cached, true, nil
 		}
 	}
 
 	queryParams := domainEntry.ListQuery{
-		Keyword:          norm,
+		Keyword:          keyword,
 		Limit:            limit,
 		Offset:           offset,
 		Sort:             sortType,
 		MinBookmarkCount: minUsers,
+		MatchTags:        params.MatchTags,
+		EnglishSubstring: params.EnglishSubstring,
 	}
 
 	entries, total, err := s.listAndCount(ctx, queryParams)
@@ -133,22 +239,17 @@ func (s *Service) SearchWithCacheStatus(ctx context.Context, query string, param
 		return Result{}, false, err
 	}
 
-	if s.history != nil {
-		if err := s.history.Record(ctx, norm, time.Now()); err != nil {
-			s.logDebug("failed to record search history", err)
-		}
-	}
-
 	result := Result{
-		Query:   norm,
-		Entries: entries,
-		Total:   total,
-		Limit:   limit,
-		Offset:  offset,
+		Query:            norm,
+		Entries:          entries,
+		Total:            total,
+		Limit:            limit,
+		Offset:           offset,
+		MinBookmarkCount: minUsers,
 	}
 
 	if useCache {
-		if err := s.cache.Set(ctx, norm, sortType, minUsers, limit, offset, result); err != nil {
+		if err := s.cache.Set(ctx, keyword, sortType, minUsers, limit, offset, result); err != nil {
 			s.logDebug("failed to set search cache", err)
 		}
 	}
@@ -179,3 +280,45 @@ func (s *Service) logDebug(msg string, err error) {
 		s.logger.Debug(msg, "error", err)
 	}
 }
+
+// hasSearchTerm reports whether any whitespace-separated term of query contains a letter or
+// digit.
+func hasSearchTerm(query string) bool {
+	for _, term := range strings.Fields(query) {
+		if strings.IndexFunc(term, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dropShortTerms removes the English terms of query shorter than the minimum term length and
+// returns the remaining terms joined by single spaces, or query itself when nothing is dropped.
+func (s *Service) dropShortTerms(query string) string {
+	if s.minTermLen <= 1 {
+		return query
+	}
+	terms := strings.Fields(query)
+	kept := terms[:0]
+	for _, term := range terms {
+		if isEnglishTerm(term) && utf8.RuneCountInString(term) < s.minTermLen {
+			continue
+		}
+		kept = append(kept, term)
+	}
+	if len(kept) == len(terms) {
+		return query
+	}
+	return strings.Join(kept, " ")
+}
+
+// isEnglishTerm reports whether term is made of ASCII characters only. A single CJK character
+// is a meaningful term, so only these are subject to the minimum term length.
+func isEnglishTerm(term string) bool {
+	for _, r := range term {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
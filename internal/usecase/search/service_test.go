@@ -3,10 +3,12 @@ package search
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	domainEntry "hateblog/internal/domain/entry"
+	"hateblog/internal/domain/repository"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
@@ -53,3 +55,291 @@ func TestSearchValidatesQuery(t *testing.T) {
 	require.Equal(t, 100, repo.lastQuery.Limit)
 	require.Equal(t, domainEntry.SortHot, repo.lastQuery.Sort)
 }
+
+func TestSearchRejectsQueryWithoutTerms(t *testing.T) {
+	for _, q := range []string{"!!!", "%", "_ %", "？！　…", "- - -"} {
+		repo := &fakeEntryRepo{}
+		svc := NewService(repo, nil, nil, nil)
+
+		_, err := svc.Search(context.Background(), q, Params{})
+		require.ErrorIs(t, err, ErrNoSearchTerms, q)
+		require.Empty(t, repo.lastQuery.Keyword, "repository must not be queried for %q", q)
+	}
+
+	for _, q := range []string{"c++", "!! go", "#入門", "2025"} {
+		repo := &fakeEntryRepo{}
+		_, err := NewService(repo, nil, nil, nil).Search(context.Background(), q, Params{})
+		require.NoError(t, err, q)
+		require.Equal(t, q, repo.lastQuery.Keyword)
+	}
+}
+
+func TestSearchDropsShortEnglishTerms(t *testing.T) {
+	repo := &fakeEntryRepo{}
+	svc := NewService(repo, nil, nil, nil).WithMinTermLength(2)
+
+	result, err := svc.Search(context.Background(), "a  go i", Params{})
+	require.NoError(t, err)
+	require.Equal(t, "go", repo.lastQuery.Keyword)
+	require.Equal(t, "a  go i", result.Query)
+
+	// A single CJK character is kept.
+	_, err = svc.Search(context.Background(), "猫 a", Params{})
+	require.NoError(t, err)
+	require.Equal(t, "猫", repo.lastQuery.Keyword)
+
+	// Nothing is dropped from a query without short English terms.
+	_, err = svc.Search(context.Background(), "go  言語", Params{})
+	require.NoError(t, err)
+	require.Equal(t, "go  言語", repo.lastQuery.Keyword)
+
+	repo = &fakeEntryRepo{}
+	svc = NewService(repo, nil, nil, nil).WithMinTermLength(2)
+	for _, q := range []string{"a", "a i", "!! a"} {
+		_, err = svc.Search(context.Background(), q, Params{})
+		require.ErrorIs(t, err, ErrSearchTermsTooShort, q)
+	}
+	require.Empty(t, repo.lastQuery.Keyword)
+
+	// The default keeps every term.
+	repo = &fakeEntryRepo{}
+	_, err = NewService(repo, nil, nil, nil).Search(context.Background(), "a", Params{})
+	require.NoError(t, err)
+	require.Equal(t, "a", repo.lastQuery.Keyword)
+}
+
+func TestSearchCapsTermCount(t *testing.T) {
+	repo := &fakeEntryRepo{}
+	svc := NewService(repo, nil, nil, nil).WithMaxTerms(3, false)
+
+	_, err := svc.Search(context.Background(), "go rust zig odin", Params{})
+	require.ErrorIs(t, err, ErrTooManySearchTerms)
+	require.Empty(t, repo.lastQuery.Keyword)
+
+	_, err = svc.Search(context.Background(), "go rust zig", Params{})
+	require.NoError(t, err)
+	require.Equal(t, "go rust zig", repo.lastQuery.Keyword)
+
+	// Dropped short terms do not count.
+	_, err = svc.WithMinTermLength(2).Search(context.Background(), "a go rust i zig", Params{})
+	require.NoError(t, err)
+	require.Equal(t, "go rust zig", repo.lastQuery.Keyword)
+
+	repo = &fakeEntryRepo{}
+	result, err := NewService(repo, nil, nil, nil).WithMaxTerms(3, true).
+		Search(context.Background(), "go rust  zig odin 言語", Params{})
+	require.NoError(t, err)
+	require.Equal(t, "go rust zig", repo.lastQuery.Keyword)
+	require.Equal(t, "go rust  zig odin 言語", result.Query)
+}
+
+type fakeResultCache struct {
+	gets, sets int
+}
+
+func (f *fakeResultCache) Get(ctx context.Context, query string, sort domainEntry.SortType, minUsers, limit, offset int, out any) (bool, error) {
+	f.gets++
+	return false, nil
+}
+
+func (f *fakeResultCache) Set(ctx context.Context, query string, sort domainEntry.SortType, minUsers, limit, offset int, value any) error {
+	f.sets++
+	return nil
+}
+
+func TestSearchEnglishSubstringBypassesCache(t *testing.T) {
+	repo := &fakeEntryRepo{}
+	cache := &fakeResultCache{}
+	svc := NewService(repo, nil, cache, nil)
+
+	_, err := svc.Search(context.Background(), "go", Params{Limit: 100, EnglishSubstring: true})
+	require.NoError(t, err)
+	require.True(t, repo.lastQuery.EnglishSubstring)
+	require.Zero(t, cache.gets)
+	require.Zero(t, cache.sets)
+}
+
+func TestSearchMatchTagsBypassesCache(t *testing.T) {
+	repo := &fakeEntryRepo{}
+	cache := &fakeResultCache{}
+	svc := NewService(repo, nil, cache, nil)
+
+	_, err := svc.Search(context.Background(), "go", Params{Limit: 100, MatchTags: true})
+	require.NoError(t, err)
+	require.True(t, repo.lastQuery.MatchTags)
+	require.Zero(t, cache.gets)
+	require.Zero(t, cache.sets)
+
+	_, err = svc.Search(context.Background(), "go", Params{Limit: 100})
+	require.NoError(t, err)
+	require.False(t, repo.lastQuery.MatchTags)
+	require.Equal(t, 1, cache.gets)
+	require.Equal(t, 1, cache.sets)
+}
+
+// thresholdRepo returns the fixed entries that pass query.MinBookmarkCount.
+type thresholdRepo struct {
+	entries []*domainEntry.Entry
+	queries []int
+}
+
+func (r *thresholdRepo) filter(query domainEntry.ListQuery) []*domainEntry.Entry {
+	var out []*domainEntry.Entry
+	for _, e := range r.entries {
+		if e.BookmarkCount >= query.MinBookmarkCount {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (r *thresholdRepo) List(ctx context.Context, query domainEntry.ListQuery) ([]*domainEntry.Entry, error) {
+	r.queries = append(r.queries, query.MinBookmarkCount)
+	return r.filter(query), nil
+}
+
+func (r *thresholdRepo) Count(ctx context.Context, query domainEntry.ListQuery) (int64, error) {
+	return int64(len(r.filter(query))), nil
+}
+
+type countingHistory struct {
+	records int
+}
+
+func (h *countingHistory) Record(ctx context.Context, query string, searchedAt time.Time) error {
+	h.records++
+	return nil
+}
+
+func TestSearchRelaxesMinUsers(t *testing.T) {
+	repo := &thresholdRepo{}
+	for _, count := range []int{700, 40, 7, 1} {
+		repo.entries = append(repo.entries, &domainEntry.Entry{ID: uuid.New(), BookmarkCount: count})
+	}
+	history := &countingHistory{}
+	svc := NewService(repo, history, nil, nil).WithMinResults(3)
+
+	result, err := svc.Search(context.Background(), "go", Params{MinBookmarkCount: 500})
+	require.NoError(t, err)
+	require.False(t, result.Relaxed)
+	require.Equal(t, int64(1), result.Total)
+	require.Equal(t, 500, result.MinBookmarkCount)
+
+	repo.queries = nil
+	result, err = svc.Search(context.Background(), "go", Params{MinBookmarkCount: 500, Relax: true})
+	require.NoError(t, err)
+	require.True(t, result.Relaxed)
+	require.Equal(t, 5, result.MinBookmarkCount)
+	require.Equal(t, int64(3), result.Total)
+	require.Equal(t, []int{500, 100, 50, 10, 5}, repo.queries)
+	require.Equal(t, 2, history.records, "history is recorded once per search")
+
+	// Relaxing is off without a floor.
+	result, err = NewService(repo, nil, nil, nil).Search(context.Background(), "go", Params{MinBookmarkCount: 500, Relax: true})
+	require.NoError(t, err)
+	require.False(t, result.Relaxed)
+}
+
+type fakeHistoryLister struct {
+	days  int
+	limit int
+}
+
+func (f *fakeHistoryLister) List(ctx context.Context, from, to time.Time, limit int) ([]repository.SearchQueryCount, error) {
+	f.limit = limit
+	return []repository.SearchQueryCount{{Query: "go", Count: 1}}, nil
+}
+
+func (f *fakeHistoryLister) Top(ctx context.Context, days int, limit int) ([]repository.SearchQueryCount, error) {
+	f.days, f.limit = days, limit
+	return []repository.SearchQueryCount{{Query: "go", Count: 1}}, nil
+}
+
+func TestListHistory(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 0, 7)
+
+	_, err := NewService(&fakeEntryRepo{}, nil, nil, nil).ListHistory(context.Background(), from, to, 10)
+	require.Error(t, err)
+
+	lister := &fakeHistoryLister{}
+	svc := NewService(&fakeEntryRepo{}, nil, nil, nil).WithHistoryList(lister)
+	require.True(t, svc.HistoryListEnabled())
+
+	rows, err := svc.ListHistory(context.Background(), from, to, 0)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, DefaultHistoryLimit, lister.limit)
+
+	_, err = svc.ListHistory(context.Background(), from, to, MaxHistoryLimit+1)
+	require.NoError(t, err)
+	require.Equal(t, MaxHistoryLimit, lister.limit)
+
+	_, err = svc.ListHistory(context.Background(), to, from, 10)
+	require.Error(t, err)
+}
+
+func TestTopQueries(t *testing.T) {
+	_, err := NewService(&fakeEntryRepo{}, nil, nil, nil).TopQueries(context.Background(), 7, 20)
+	require.Error(t, err)
+
+	lister := &fakeHistoryLister{}
+	svc := NewService(&fakeEntryRepo{}, nil, nil, nil).WithHistoryList(lister)
+
+	_, err = svc.TopQueries(context.Background(), 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, DefaultTopDays, lister.days)
+	require.Equal(t, DefaultTopLimit, lister.limit)
+
+	_, err = svc.TopQueries(context.Background(), MaxTopDays+1, MaxTopLimit+1)
+	require.NoError(t, err)
+	require.Equal(t, MaxTopDays, lister.days)
+	require.Equal(t, MaxTopLimit, lister.limit)
+}
+
+type recordingHistory struct {
+	queries []string
+}
+
+func (h *recordingHistory) Record(ctx context.Context, query string, searchedAt time.Time) error {
+	h.queries = append(h.queries, query)
+	return nil
+}
+
+func TestSearchHistoryPrivacyHash(t *testing.T) {
+	history := &recordingHistory{}
+	svc := NewService(&fakeEntryRepo{}, history, nil, nil).
+		WithHistoryPrivacy(HistoryPrivacy{Hash: true, Salt: "pepper"})
+
+	for _, q := range []string{"Go 入門", " go 入門 ", "rust"} {
+		_, err := svc.Search(context.Background(), q, Params{})
+		require.NoError(t, err)
+	}
+	require.Len(t, history.queries, 3)
+	for _, key := range history.queries {
+		require.True(t, strings.HasPrefix(key, hashedQueryPrefix), key)
+		require.NotContains(t, key, "go")
+		require.NotContains(t, key, "rust")
+	}
+	require.Equal(t, history.queries[0], history.queries[1], "equal queries aggregate under one key")
+	require.NotEqual(t, history.queries[0], history.queries[2])
+	require.True(t, strings.HasSuffix(history.queries[0], " len=5 terms=2"), history.queries[0])
+	require.True(t, strings.HasSuffix(history.queries[2], " len=4 terms=1"), history.queries[2])
+
+	other, ok := HistoryPrivacy{Hash: true, Salt: "salt"}.historyKey("rust")
+	require.True(t, ok)
+	require.NotEqual(t, history.queries[2], other, "the salt keys the hash")
+}
+
+func TestSearchHistoryPrivacySkipsLongQueries(t *testing.T) {
+	history := &recordingHistory{}
+	svc := NewService(&fakeEntryRepo{}, history, nil, nil).
+		WithHistoryPrivacy(HistoryPrivacy{MaxLength: 10})
+
+	for _, q := range []string{"Go 入門", "東京都千代田区千代田一丁目", "0123456789"} {
+		result, err := svc.Search(context.Background(), q, Params{})
+		require.NoError(t, err, "the search itself is not affected")
+		require.Equal(t, q, result.Query)
+	}
+	require.Equal(t, []string{"go 入門", "0123456789"}, history.queries)
+}
@@ -0,0 +1,44 @@
+package source
+
+import (
+	"context"
+	"fmt"
+
+	domainEntry "hateblog/internal/domain/entry"
+)
+
+const (
+	// DefaultLimit is used when no limit is given.
+	DefaultLimit = 20
+	// MaxLimit caps the number of sources returned.
+	MaxLimit = 100
+)
+
+// Repository describes DB operations required by the source service.
+type Repository interface {
+	ListSources(ctx context.Context, limit int) ([]domainEntry.Source, error)
+}
+
+// Service exposes per-host entry statistics.
+type Service struct {
+	repo Repository
+}
+
+// NewService builds a source service.
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// List returns the hosts with the most entries, at most MaxLimit.
+func (s *Service) List(ctx context.Context, limit int) ([]domainEntry.Source, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("source service not initialized")
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	return s.repo.ListSources(ctx, limit)
+}
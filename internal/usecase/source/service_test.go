@@ -0,0 +1,36 @@
+package source
+
+import (
+	"context"
+	"testing"
+
+	domainEntry "hateblog/internal/domain/entry"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSourceRepo struct {
+	limit int
+}
+
+func (f *fakeSourceRepo) ListSources(ctx context.Context, limit int) ([]domainEntry.Source, error) {
+	f.limit = limit
+	return []domainEntry.Source{{Host: "example.com", EntryCount: 3}}, nil
+}
+
+func TestListClampsLimit(t *testing.T) {
+	repo := &fakeSourceRepo{}
+	svc := NewService(repo)
+
+	for _, tt := range []struct{ in, want int }{
+		{0, DefaultLimit},
+		{-1, DefaultLimit},
+		{50, 50},
+		{MaxLimit + 1, MaxLimit},
+	} {
+		sources, err := svc.List(context.Background(), tt.in)
+		require.NoError(t, err)
+		require.Len(t, sources, 1)
+		require.Equal(t, tt.want, repo.limit, "limit %d", tt.in)
+	}
+}
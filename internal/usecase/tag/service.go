@@ -6,6 +6,14 @@ import (
 	"time"
 
 	"hateblog/internal/domain/tag"
+	"hateblog/internal/pkg/cachefallback"
+)
+
+const (
+	// DefaultListLimit is the page size of List when none is given.
+	DefaultListLimit = 50
+	// MaxListLimit is the default cap on the page size of List.
+	MaxListLimit = 200
 )
 
 // Repository describes DB operations required by the tag service.
@@ -13,32 +21,52 @@ type Repository interface {
 	GetByName(ctx context.Context, name string) (*tag.Tag, error)
 	List(ctx context.Context, limit, offset int) ([]tag.Tag, error)
 	IncrementViewHistory(ctx context.Context, tagID tag.ID, viewedAt time.Time) error
+	GetUsage(ctx context.Context, tagID tag.ID) (tag.Usage, error)
+	EntryCounts(ctx context.Context, tagIDs []tag.ID) (map[tag.ID]int, error)
 	GetTrending(ctx context.Context, hours int, minBookmarkCount int, limit int) ([]tag.TrendingTag, error)
 	GetClicked(ctx context.Context, days int, limit int) ([]tag.ClickedTag, error)
 }
 
 // Service exposes tag operations.
 type Service struct {
-	repo  Repository
-	cache ListCache
+	repo         Repository
+	cache        ListCache
+	maxListLimit int
 }
 
-// ListCache stores tag list payloads.
+// ListCache stores tag list payloads. Implementations must key on the whole view as well as
+// the page so that lists in different orders do not overwrite each other.
 type ListCache interface {
-	Get(ctx context.Context, limit, offset int, out any) (bool, error)
-	Set(ctx context.Context, limit, offset int, value any) error
+	Get(ctx context.Context, view tag.ListView, limit, offset int, out any) (bool, error)
+	Set(ctx context.Context, view tag.ListView, limit, offset int, value any) error
 }
 
+// nameListView is the view served by List.
+var nameListView = tag.ListView{Sort: tag.ListSortName}
+
 // NewService builds a tag service.
 func NewService(repo Repository, cache ListCache) *Service {
-	return &Service{repo: repo, cache: cache}
+	return &Service{repo: repo, cache: cache, maxListLimit: MaxListLimit}
+}
+
+// WithMaxListLimit sets the cap on the page size of List (values <= 0 keep MaxListLimit).
+func (s *Service) WithMaxListLimit(n int) *Service {
+	if n > 0 {
+		s.maxListLimit = n
+	}
+	return s
+}
+
+// ListLimits returns the default and maximum page sizes of List.
+func (s *Service) ListLimits() (def, max int) {
+	return min(DefaultListLimit, s.maxListLimit), s.maxListLimit
 }
 
 // GetByName returns tag metadata.
 func (s *Service) GetByName(ctx context.Context, name string) (*tag.Tag, error) {
 	norm := tag.NormalizeName(name)
 	if norm == "" {
-		return nil, fmt.Errorf("tag is required")
+		return nil, fmt.Errorf("%w: tag is required", tag.ErrInvalidTag)
 	}
 	return s.repo.GetByName(ctx, norm)
 }
@@ -49,21 +77,26 @@ func (s *Service) List(ctx context.Context, limit, offset int) ([]tag.Tag, error
 	return tags, err
 }
 
-// ListWithCacheStatus returns tags and cache hit info.
+// ListWithCacheStatus returns tags and cache hit info. A limit of zero or less uses the
+// default page size and a larger one than the cap is lowered to it, so cached pages are keyed
+// by the page size actually served.
 func (s *Service) ListWithCacheStatus(ctx context.Context, limit, offset int) ([]tag.Tag, bool, error) {
+	def, max := s.ListLimits()
 	if limit <= 0 {
-		limit = 50
+		limit = def
+	}
+	if limit > max {
+		limit = max
 	}
 	if offset < 0 {
 		offset = 0
 	}
 	if s.cache != nil {
 		var cached []tag.Tag
-		ok, err := s.cache.Get(ctx, limit, offset, &cached)
+		ok, err := s.cache.Get(ctx, nameListView, limit, offset, &cached)
 		if err != nil {
-			return nil, false, err
-		}
-		if ok {
+			cachefallback.Record(err)
+		} else if ok {
 			return cached, true, nil
 		}
 	}
@@ -72,18 +105,46 @@ func (s *Service) ListWithCacheStatus(ctx context.Context, limit, offset int) ([
 		return nil, false, err
 	}
 	if s.cache != nil {
-		if err := s.cache.Set(ctx, limit, offset, tags); err != nil {
+		if err := s.cache.Set(ctx, nameListView, limit, offset, tags); err != nil {
 			return tags, false, nil
 		}
 	}
 	return tags, false, nil
 }
 
+// ListWithEntryCounts returns a page of the tag list, as List does, with the number of
+// entries carrying each tag. The counts come from one bulk query for the whole page and are
+// never cached, so they stay current while the page itself is served from the cache.
+func (s *Service) ListWithEntryCounts(ctx context.Context, limit, offset int) ([]tag.CountedTag, bool, error) {
+	tags, cacheHit, err := s.ListWithCacheStatus(ctx, limit, offset)
+	if err != nil {
+		return nil, false, err
+	}
+	ids := make([]tag.ID, 0, len(tags))
+	for _, t := range tags {
+		ids = append(ids, t.ID)
+	}
+	counts, err := s.repo.EntryCounts(ctx, ids)
+	if err != nil {
+		return nil, false, err
+	}
+	result := make([]tag.CountedTag, 0, len(tags))
+	for _, t := range tags {
+		result = append(result, tag.CountedTag{ID: t.ID, Name: t.Name, EntryCount: counts[t.ID]})
+	}
+	return result, cacheHit, nil
+}
+
 // RecordView increments the view counter for the tag.
 func (s *Service) RecordView(ctx context.Context, tagID tag.ID, viewedAt time.Time) error {
 	return s.repo.IncrementViewHistory(ctx, tagID, viewedAt)
 }
 
+// GetUsage returns the entry and view counts of the tag.
+func (s *Service) GetUsage(ctx context.Context, tagID tag.ID) (tag.Usage, error) {
+	return s.repo.GetUsage(ctx, tagID)
+}
+
 // GetTrending returns tags from recent popular entries.
 func (s *Service) GetTrending(ctx context.Context, hours int, minBookmarkCount int, limit int) ([]tag.TrendingTag, error) {
 	if hours <= 0 {
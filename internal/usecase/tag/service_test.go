@@ -7,14 +7,21 @@ import (
 	"time"
 
 	domainTag "hateblog/internal/domain/tag"
+	"hateblog/internal/pkg/cachefallback"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 )
 
 type fakeRepo struct {
-	tag domainTag.Tag
-	err error
+	tag   domainTag.Tag
+	usage domainTag.Usage
+	err   error
+
+	counts      map[domainTag.ID]int
+	countCalls  int
+	countedTags []domainTag.ID
 }
 
 func (f *fakeRepo) GetByName(ctx context.Context, name string) (*domainTag.Tag, error) {
@@ -37,6 +44,19 @@ func (f *fakeRepo) IncrementViewHistory(ctx context.Context, tagID domainTag.ID,
 	return nil
 }
 
+func (f *fakeRepo) GetUsage(ctx context.Context, tagID domainTag.ID) (domainTag.Usage, error) {
+	if f.err != nil {
+		return domainTag.Usage{}, f.err
+	}
+	return f.usage, nil
+}
+
+func (f *fakeRepo) EntryCounts(ctx context.Context, tagIDs []domainTag.ID) (map[domainTag.ID]int, error) {
+	f.countCalls++
+	f.countedTags = tagIDs
+	return f.counts, nil
+}
+
 func (f *fakeRepo) GetTrending(ctx context.Context, hours int, minBookmarkCount int, limit int) ([]domainTag.TrendingTag, error) {
 	return nil, nil
 }
@@ -56,3 +76,89 @@ func TestGetByNameNormalizes(t *testing.T) {
 	err = svc.RecordView(context.Background(), repo.tag.ID, time.Now())
 	require.NoError(t, err)
 }
+
+func TestGetUsage(t *testing.T) {
+	repo := &fakeRepo{usage: domainTag.Usage{EntryCount: 12, ViewCount: 340}}
+	svc := NewService(repo, nil)
+
+	usage, err := svc.GetUsage(context.Background(), uuid.New())
+	require.NoError(t, err)
+	require.Equal(t, domainTag.Usage{EntryCount: 12, ViewCount: 340}, usage)
+}
+
+type recordingListCache struct {
+	views  []domainTag.ListView
+	limits []int
+	getErr error
+}
+
+func (c *recordingListCache) Get(ctx context.Context, view domainTag.ListView, limit, offset int, out any) (bool, error) {
+	c.views = append(c.views, view)
+	c.limits = append(c.limits, limit)
+	return false, c.getErr
+}
+
+func (c *recordingListCache) Set(ctx context.Context, view domainTag.ListView, limit, offset int, value any) error {
+	c.views = append(c.views, view)
+	return nil
+}
+
+func TestListUsesNameView(t *testing.T) {
+	cache := &recordingListCache{}
+	svc := NewService(&fakeRepo{tag: domainTag.Tag{ID: uuid.New(), Name: "go"}}, cache)
+
+	_, hit, err := svc.ListWithCacheStatus(context.Background(), 50, 0)
+	require.NoError(t, err)
+	require.False(t, hit)
+	want := domainTag.ListView{Sort: domainTag.ListSortName}
+	require.Equal(t, []domainTag.ListView{want, want}, cache.views)
+}
+
+func TestListFallsBackOnCacheError(t *testing.T) {
+	before := testutil.ToFloat64(cachefallback.Total.WithLabelValues(cachefallback.ReasonError))
+	cache := &recordingListCache{getErr: errors.New("redis: connection refused")}
+	svc := NewService(&fakeRepo{}, cache)
+
+	_, hit, err := svc.ListWithCacheStatus(context.Background(), 50, 0)
+	require.NoError(t, err)
+	require.False(t, hit)
+	require.Equal(t, before+1, testutil.ToFloat64(cachefallback.Total.WithLabelValues(cachefallback.ReasonError)))
+}
+
+func TestListLimitsKeyTheCache(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxLimit int
+		limit    int
+		want     int
+	}{
+		{name: "zero uses default", limit: 0, want: DefaultListLimit},
+		{name: "at cap", limit: MaxListLimit, want: MaxListLimit},
+		{name: "above cap", limit: MaxListLimit + 1, want: MaxListLimit},
+		{name: "configured cap", maxLimit: 30, limit: 100, want: 30},
+		{name: "default above configured cap", maxLimit: 30, limit: 0, want: 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &recordingListCache{}
+			svc := NewService(&fakeRepo{}, cache).WithMaxListLimit(tt.maxLimit)
+
+			_, _, err := svc.ListWithCacheStatus(context.Background(), tt.limit, 0)
+			require.NoError(t, err)
+			require.Equal(t, []int{tt.want}, cache.limits)
+		})
+	}
+}
+
+func TestListWithEntryCounts(t *testing.T) {
+	tg := domainTag.Tag{ID: uuid.New(), Name: "go"}
+	repo := &fakeRepo{tag: tg, counts: map[domainTag.ID]int{tg.ID: 7}}
+	svc := NewService(repo, nil)
+
+	tags, hit, err := svc.ListWithEntryCounts(context.Background(), 50, 0)
+	require.NoError(t, err)
+	require.False(t, hit)
+	require.Equal(t, []domainTag.CountedTag{{ID: tg.ID, Name: "go", EntryCount: 7}}, tags)
+	require.Equal(t, 1, repo.countCalls)
+	require.Equal(t, []domainTag.ID{tg.ID}, repo.countedTags)
+}
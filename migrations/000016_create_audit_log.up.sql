@@ -0,0 +1,28 @@
+-- Create audit_log table
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    operation TEXT NOT NULL,
+    target TEXT NOT NULL DEFAULT '',
+    affected BIGINT NOT NULL DEFAULT 0,
+    actor TEXT NOT NULL DEFAULT '',
+    host TEXT NOT NULL DEFAULT '',
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_audit_log_started_at ON audit_log (started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_operation ON audit_log (operation, started_at DESC);
+
+-- Add comment
+COMMENT ON TABLE audit_log IS '管理コマンド（破壊的操作）の監査ログ';
+COMMENT ON COLUMN audit_log.operation IS '操作名（cache.purge, archive.rebuild など）';
+COMMENT ON COLUMN audit_log.target IS '対象（パターン、テーブル名、IDなど）';
+COMMENT ON COLUMN audit_log.affected IS '影響件数';
+COMMENT ON COLUMN audit_log.actor IS '実行ユーザー（環境変数から取得）';
+COMMENT ON COLUMN audit_log.host IS '実行ホスト名';
+COMMENT ON COLUMN audit_log.error IS '失敗時のエラーメッセージ';
+COMMENT ON COLUMN audit_log.started_at IS '操作開始日時';
+COMMENT ON COLUMN audit_log.duration_ms IS '所要時間（ミリ秒）';
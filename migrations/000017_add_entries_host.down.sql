@@ -0,0 +1,4 @@
+-- Remove entries.host
+
+DROP INDEX IF EXISTS idx_entries_host;
+ALTER TABLE entries DROP COLUMN IF EXISTS host;
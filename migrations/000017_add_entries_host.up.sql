@@ -0,0 +1,12 @@
+-- Add a stored host column derived from url
+-- Existing rows are filled when the column is added; inserts and updates recompute it.
+ALTER TABLE entries
+    ADD COLUMN IF NOT EXISTS host TEXT
+    GENERATED ALWAYS AS (lower(substring(url from '^[A-Za-z][A-Za-z0-9+.-]*://(?:[^@/?#]*@)?([^/:?#]+)'))) STORED;
+
+-- Create index
+CREATE INDEX IF NOT EXISTS idx_entries_host ON entries (host);
+
+-- Add comment
+COMMENT ON COLUMN entries.host IS 'URLのホスト名（小文字、ポート・ユーザー情報を除く）。urlから自動生成';
+COMMENT ON INDEX idx_entries_host IS 'ホスト別エントリー数集計（GET /sources）用';
@@ -0,0 +1,3 @@
+-- Remove the day list composite index
+
+DROP INDEX IF EXISTS idx_entries_created_bookmark;
@@ -0,0 +1,9 @@
+-- Composite index for day and period lists
+-- The day endpoints (/entries/new, /entries/hot) and rankings select a created_at range and
+-- filter by min_users (bookmark_count). Keeping bookmark_count in the index lets the range scan
+-- drop low-bookmark rows without visiting the heap, for every threshold rather than only the
+-- partial index thresholds (idx_entries_min5_created).
+CREATE INDEX IF NOT EXISTS idx_entries_created_bookmark ON entries (created_at, bookmark_count);
+
+-- Add comment
+COMMENT ON INDEX idx_entries_created_bookmark IS '日別・期間別リスト用の複合インデックス（created_at範囲 + bookmark_countフィルタ）';